@@ -51,9 +51,11 @@ import (
 	"github.com/bufbuild/buf/private/pkg/git"
 	"github.com/bufbuild/buf/private/pkg/httpauth"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/bufbuild/buf/private/pkg/transport/http/httpclient"
+	"github.com/bufbuild/connect-go"
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 	"golang.org/x/term"
@@ -71,6 +73,17 @@ const (
 	alphaSuppressWarningsEnvKey = "BUF_ALPHA_SUPPRESS_WARNINGS"
 	betaSuppressWarningsEnvKey  = "BUF_BETA_SUPPRESS_WARNINGS"
 
+	offlineEnvKey   = "BUF_OFFLINE"
+	offlineFlagName = "offline"
+
+	// sharedCacheEnvKey is the environment variable that configures the shared module cache.
+	//
+	// This is either the URL of a HTTP cache server, or the path to a directory shared between machines.
+	sharedCacheEnvKey = "BUF_SHARED_CACHE"
+	// sharedCacheTokenEnvKey is the environment variable that configures the bearer token
+	// sent to the HTTP cache server of the shared module cache.
+	sharedCacheTokenEnvKey = "BUF_SHARED_CACHE_TOKEN"
+
 	inputHashtagFlagName      = "__hashtag__"
 	inputHashtagFlagShortName = "#"
 
@@ -102,7 +115,8 @@ var (
 		SSHKnownHostsFilesEnvKey: inputSSHKnownHostsFilesEnvKey,
 	}
 
-	// AllCacheModuleRelDirPaths are all directory paths for all time concerning the module cache.
+	// AllCacheModuleRelDirPaths are all directory paths for all time concerning the module cache,
+	// and the cache of the responses of plugins invoked by buf generate.
 	//
 	// These are normalized.
 	// These are relative to container.CacheDirPath().
@@ -114,6 +128,8 @@ var (
 		v1CacheModuleDataRelDirPath,
 		v1CacheModuleLockRelDirPath,
 		v1CacheModuleSumRelDirPath,
+		v1CacheModuleAccessRelDirPath,
+		v1CacheGenerateRelDirPath,
 	}
 
 	// ErrNotATTY is returned when an input io.Reader is not a TTY where it is expected.
@@ -147,6 +163,18 @@ var (
 	// These digests are used to make sure that the data written is actually what we expect, and if it is not,
 	// we clear an entry from the cache, i.e. delete the relevant data directory.
 	v1CacheModuleSumRelDirPath = normalpath.Join("v1", "module", "sum")
+	// v1CacheModuleAccessRelDirPath is the relative path to the cache directory where the times at
+	// which modules were last used are stored.
+	//
+	// Normalized.
+	// These times are used to prune the least recently used modules from the cache.
+	v1CacheModuleAccessRelDirPath = normalpath.Join("v1", "module", "access")
+	// v1CacheGenerateRelDirPath is the relative path to the cache directory where the responses
+	// of plugins invoked by buf generate are stored.
+	//
+	// Normalized.
+	// The least recently used responses are deleted by buf generate once this is too large.
+	v1CacheGenerateRelDirPath = normalpath.Join("v1", "generate")
 
 	// allVisibiltyStrings are the possible options that a user can set the visibility flag with.
 	allVisibiltyStrings = []string{
@@ -156,7 +184,9 @@ var (
 )
 
 // GlobalFlags contains global flags for buf commands.
-type GlobalFlags struct{}
+type GlobalFlags struct {
+	Offline bool
+}
 
 // NewGlobalFlags creates a new GlobalFlags with default values..
 func NewGlobalFlags() *GlobalFlags {
@@ -164,7 +194,42 @@ func NewGlobalFlags() *GlobalFlags {
 }
 
 // BindRoot binds the global flags to the root command flag set.
-func (*GlobalFlags) BindRoot(*pflag.FlagSet) {}
+func (g *GlobalFlags) BindRoot(flagSet *pflag.FlagSet) {
+	flagSet.BoolVar(
+		&g.Offline,
+		offlineFlagName,
+		false,
+		fmt.Sprintf(
+			`Never access the network. Dependencies must be in the module cache or vendored with "buf mod vendor". This can also be set with the %s environment variable.`,
+			offlineEnvKey,
+		),
+	)
+}
+
+// NewInterceptor returns a new Interceptor that applies the global flags to the container.
+//
+// This should be applied to all run functions with appflag.BuilderWithInterceptor.
+func (g *GlobalFlags) NewInterceptor() appflag.Interceptor {
+	return func(next func(context.Context, appflag.Container) error) func(context.Context, appflag.Container) error {
+		return func(ctx context.Context, container appflag.Container) error {
+			if g.Offline {
+				container = newEnvOverrideContainer(
+					container,
+					map[string]string{
+						offlineEnvKey: "1",
+					},
+				)
+			}
+			return next(ctx, container)
+		}
+	}
+}
+
+// IsOffline returns true if the network must not be accessed, that is if the
+// offline flag or the BUF_OFFLINE environment variable is set.
+func IsOffline(container app.EnvContainer) bool {
+	return container.Env(offlineEnvKey) != ""
+}
 
 // BindAsFileDescriptorSet binds the exclude-imports flag.
 func BindAsFileDescriptorSet(flagSet *pflag.FlagSet, addr *bool, flagName string) {
@@ -213,6 +278,32 @@ If specified multiple times, the union is taken.`,
 	)
 }
 
+// BindTypes binds the type flag.
+func BindTypes(flagSet *pflag.FlagSet, addr *[]string, flagName string) {
+	flagSet.StringSliceVar(
+		addr,
+		flagName,
+		nil,
+		`Limit to specific fully-qualified types (messages, enums, services, methods or extensions), for example "foo.v1.Bar".
+Only these types and the types they depend on are included, and unused declarations are pruned.
+A method, for example "foo.v1.BarService.GetBar", is included without the other methods of its service.
+If specified multiple times, the union is taken.`,
+	)
+}
+
+// BindExcludeTypes binds the exclude-type flag.
+func BindExcludeTypes(flagSet *pflag.FlagSet, addr *[]string, flagName string) {
+	flagSet.StringSliceVar(
+		addr,
+		flagName,
+		nil,
+		`Exclude specific fully-qualified types and the types nested in them, for example "foo.v1.Bar".
+Types that are only referenced by excluded types are excluded as well.
+It is an error to exclude a type that an included type depends on.
+If specified multiple times, the union is taken.`,
+	)
+}
+
 // BindInputHashtag binds the input hashtag flag.
 //
 // This needs to be added to any command that has the input as the first argument.
@@ -522,20 +613,146 @@ func NewModuleReaderAndCreateCacheDirsWithExternalPaths(
 	)
 }
 
+// NewGenerateCacheDirPathAndCreateCacheDir returns the directory path of the cache of
+// the responses of plugins invoked by buf generate while creating the directory.
+func NewGenerateCacheDirPathAndCreateCacheDir(container appflag.Container) (string, error) {
+	cacheGenerateDirPath := normalpath.Join(container.CacheDirPath(), v1CacheGenerateRelDirPath)
+	if err := checkExistingCacheDirs(container.CacheDirPath(), cacheGenerateDirPath); err != nil {
+		return "", err
+	}
+	if err := createCacheDirs(cacheGenerateDirPath); err != nil {
+		return "", err
+	}
+	return normalpath.Unnormalize(cacheGenerateDirPath), nil
+}
+
+// NewModuleCacheManagerAndCreateCacheDirs returns a new CacheManager for the module cache
+// while creating the required cache directories.
+func NewModuleCacheManagerAndCreateCacheDirs(container appflag.Container) (bufmodulecache.CacheManager, error) {
+	moduleCache, err := newModuleCacheAndCreateCacheDirs(container)
+	if err != nil {
+		return nil, err
+	}
+	return bufmodulecache.NewCacheManager(
+		container.Logger(),
+		moduleCache.fileLocker,
+		moduleCache.dataReadWriteBucket,
+		moduleCache.sumReadWriteBucket,
+		moduleCache.accessReadWriteBucket,
+	), nil
+}
+
 func newModuleReaderAndCreateCacheDirs(
 	container appflag.Container,
 	registryProvider registryv1alpha1apiclient.Provider,
 	moduleReaderOptions ...bufmodulecache.ModuleReaderOption,
 ) (bufmodule.ModuleReader, error) {
+	moduleCache, err := newModuleCacheAndCreateCacheDirs(container)
+	if err != nil {
+		return nil, err
+	}
+	if IsOffline(container) {
+		moduleReaderOptions = append(moduleReaderOptions, bufmodulecache.ModuleReaderWithOffline())
+	}
+	sharedCacheBlobStore, err := newSharedCacheBlobStoreAndCreateDirs(container)
+	if err != nil {
+		return nil, err
+	}
+	if sharedCacheBlobStore != nil {
+		moduleReaderOptions = append(moduleReaderOptions, bufmodulecache.ModuleReaderWithSharedCache(sharedCacheBlobStore))
+	}
+	moduleReader := bufmodulecache.NewModuleReader(
+		container.Logger(),
+		container.VerbosePrinter(),
+		moduleCache.fileLocker,
+		moduleCache.dataReadWriteBucket,
+		moduleCache.sumReadWriteBucket,
+		moduleCache.accessReadWriteBucket,
+		bufapimodule.NewModuleReader(registryProvider),
+		registryProvider,
+		moduleReaderOptions...,
+	)
+	return moduleReader, nil
+}
+
+// newSharedCacheBlobStoreAndCreateDirs returns the BlobStore of the shared module cache
+// configured with the BUF_SHARED_CACHE environment variable, while creating the required
+// directories. Requests to a HTTP cache server are authenticated with the token of the
+// BUF_SHARED_CACHE_TOKEN environment variable, if set.
+//
+// Returns nil if no shared module cache is configured, or if it is a HTTP cache server
+// and the network must not be accessed.
+func newSharedCacheBlobStoreAndCreateDirs(container appflag.Container) (bufmodulecache.BlobStore, error) {
+	sharedCache := container.Env(sharedCacheEnvKey)
+	if sharedCache == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(sharedCache, "http://") || strings.HasPrefix(sharedCache, "https://") {
+		if IsOffline(container) {
+			container.Logger().Debug("shared_cache_skipped_offline", zap.String("url", sharedCache))
+			return nil, nil
+		}
+		config, err := NewConfig(container)
+		if err != nil {
+			return nil, err
+		}
+		var httpBlobStoreOptions []bufmodulecache.HTTPBlobStoreOption
+		if token := container.Env(sharedCacheTokenEnvKey); token != "" {
+			httpBlobStoreOptions = append(httpBlobStoreOptions, bufmodulecache.HTTPBlobStoreWithToken(token))
+		}
+		return bufmodulecache.NewHTTPBlobStore(
+			httpclient.NewClient(
+				httpclient.WithObservability(),
+				httpclient.WithTLSConfig(config.TLS),
+			),
+			sharedCache,
+			httpBlobStoreOptions...,
+		), nil
+	}
+	sharedCacheDirPath, err := normalpath.NormalizeAndAbsolute(sharedCache)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", sharedCacheEnvKey, err)
+	}
+	sharedCacheDataDirPath := normalpath.Join(sharedCacheDirPath, "data")
+	sharedCacheLockDirPath := normalpath.Join(sharedCacheDirPath, "lock")
+	if err := createCacheDirs(
+		sharedCacheDataDirPath,
+		sharedCacheLockDirPath,
+	); err != nil {
+		return nil, err
+	}
+	// do NOT want to enable symlinks for our cache
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(sharedCacheDataDirPath)
+	if err != nil {
+		return nil, err
+	}
+	fileLocker, err := filelock.NewLocker(sharedCacheLockDirPath)
+	if err != nil {
+		return nil, err
+	}
+	return bufmodulecache.NewDirBlobStore(fileLocker, readWriteBucket), nil
+}
+
+// moduleCache contains the buckets and the file locker of the module cache.
+type moduleCache struct {
+	fileLocker            filelock.Locker
+	dataReadWriteBucket   storage.ReadWriteBucket
+	sumReadWriteBucket    storage.ReadWriteBucket
+	accessReadWriteBucket storage.ReadWriteBucket
+}
+
+func newModuleCacheAndCreateCacheDirs(container appflag.Container) (*moduleCache, error) {
 	cacheModuleDataDirPath := normalpath.Join(container.CacheDirPath(), v1CacheModuleDataRelDirPath)
 	cacheModuleLockDirPath := normalpath.Join(container.CacheDirPath(), v1CacheModuleLockRelDirPath)
 	cacheModuleSumDirPath := normalpath.Join(container.CacheDirPath(), v1CacheModuleSumRelDirPath)
+	cacheModuleAccessDirPath := normalpath.Join(container.CacheDirPath(), v1CacheModuleAccessRelDirPath)
 	if err := checkExistingCacheDirs(
 		container.CacheDirPath(),
 		container.CacheDirPath(),
 		cacheModuleDataDirPath,
 		cacheModuleLockDirPath,
 		cacheModuleSumDirPath,
+		cacheModuleAccessDirPath,
 	); err != nil {
 		return nil, err
 	}
@@ -543,6 +760,7 @@ func newModuleReaderAndCreateCacheDirs(
 		cacheModuleDataDirPath,
 		cacheModuleLockDirPath,
 		cacheModuleSumDirPath,
+		cacheModuleAccessDirPath,
 	); err != nil {
 		return nil, err
 	}
@@ -557,21 +775,21 @@ func newModuleReaderAndCreateCacheDirs(
 	if err != nil {
 		return nil, err
 	}
+	// do NOT want to enable symlinks for our cache
+	accessReadWriteBucket, err := storageosProvider.NewReadWriteBucket(cacheModuleAccessDirPath)
+	if err != nil {
+		return nil, err
+	}
 	fileLocker, err := filelock.NewLocker(cacheModuleLockDirPath)
 	if err != nil {
 		return nil, err
 	}
-	moduleReader := bufmodulecache.NewModuleReader(
-		container.Logger(),
-		container.VerbosePrinter(),
-		fileLocker,
-		dataReadWriteBucket,
-		sumReadWriteBucket,
-		bufapimodule.NewModuleReader(registryProvider),
-		registryProvider,
-		moduleReaderOptions...,
-	)
-	return moduleReader, nil
+	return &moduleCache{
+		fileLocker:            fileLocker,
+		dataReadWriteBucket:   dataReadWriteBucket,
+		sumReadWriteBucket:    sumReadWriteBucket,
+		accessReadWriteBucket: accessReadWriteBucket,
+	}, nil
 }
 
 // NewConfig creates a new Config.
@@ -584,13 +802,13 @@ func NewConfig(container appflag.Container) (*bufapp.Config, error) {
 }
 
 // NewRegistryProvider creates a new registryv1alpha1apiclient.Provider which uses a token reader to look
-// up the token in the container or in netrc based on the address of each individual client from the provider.
+// up the token in the container, in a credential helper or in netrc based on the address of each individual client from the provider.
 // It is then set in the header of all outgoing requests from this provider
 func NewRegistryProvider(ctx context.Context, container appflag.Container) (registryv1alpha1apiclient.Provider, error) {
 	return newRegistryProviderWithOptions(
 		container,
 		bufapiclient.RegistryProviderWithAuthInterceptorProvider(
-			bufconnect.NewAuthorizationInterceptorProvider(container),
+			bufconnect.NewAuthorizationInterceptorProvider(container, command.NewRunner()),
 		),
 	)
 }
@@ -624,7 +842,7 @@ func newRegistryProviderWithOptions(container appflag.Container, opts ...bufapic
 			return buftransport.PrependHTTPS(address)
 		}),
 		bufapiclient.RegistryProviderWithInterceptors(
-			bufconnect.NewSetCLIVersionInterceptor(Version),
+			registryInterceptors(container)...,
 		),
 	}
 	options = append(options, opts...)
@@ -632,6 +850,19 @@ func newRegistryProviderWithOptions(container appflag.Container, opts ...bufapic
 	return bufapiclient.NewConnectClientProvider(container.Logger(), client, options...)
 }
 
+// registryInterceptors returns the interceptors for all registry providers.
+func registryInterceptors(container app.EnvContainer) []connect.Interceptor {
+	interceptors := []connect.Interceptor{
+		bufconnect.NewSetCLIVersionInterceptor(Version),
+	}
+	if IsOffline(container) {
+		// Applied first so that no other interceptor, for example a credential helper
+		// looking up the token, runs when offline.
+		interceptors = append([]connect.Interceptor{bufconnect.NewOfflineInterceptor()}, interceptors...)
+	}
+	return interceptors
+}
+
 // PromptUserForDelete is used to receieve user confirmation that a specific
 // entity should be deleted. If the user's answer does not match the expected
 // answer, an error is returned.
@@ -0,0 +1,41 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcli
+
+import (
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+)
+
+// envOverrideContainer is an appflag.Container with overridden environment variables.
+type envOverrideContainer struct {
+	appflag.Container
+	envContainer app.EnvContainer
+}
+
+func newEnvOverrideContainer(container appflag.Container, overrides map[string]string) *envOverrideContainer {
+	return &envOverrideContainer{
+		Container:    container,
+		envContainer: app.NewEnvContainerWithOverrides(container, overrides),
+	}
+}
+
+func (c *envOverrideContainer) Env(key string) string {
+	return c.envContainer.Env(key)
+}
+
+func (c *envOverrideContainer) ForEachEnv(f func(string, string)) {
+	c.envContainer.ForEachEnv(f)
+}
@@ -18,6 +18,8 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/bufbuild/buf/private/buf/bufref"
@@ -31,10 +33,40 @@ const (
 	MessageEncodingBin MessageEncoding = iota + 1
 	// MessageEncodingJSON is the JSON image encoding.
 	MessageEncodingJSON
+	// MessageEncodingBinDelimited is the size-delimited binary encoding.
+	//
+	// Each message is prefixed with its size as a varint. This encoding is a stream
+	// of zero or more messages.
+	MessageEncodingBinDelimited
+	// MessageEncodingJSONL is the newline-delimited JSON encoding.
+	//
+	// Each message is a single line of JSON. This encoding is a stream of zero or
+	// more messages.
+	MessageEncodingJSONL
+	// MessageEncodingTxtpb is the Protobuf text format encoding.
+	MessageEncodingTxtpb
+	// MessageEncodingYAML is the YAML encoding.
+	//
+	// This is the JSON mapping of the message, written as YAML.
+	MessageEncodingYAML
 	// formatBin is the binary format.
 	formatBin = "bin"
 	// formatJSON is the JSON format.
 	formatJSON = "json"
+	// formatBinDelimited is the size-delimited binary format.
+	formatBinDelimited = "bindelim"
+	// formatJSONL is the newline-delimited JSON format.
+	formatJSONL = "jsonl"
+	// formatTxtpb is the Protobuf text format.
+	formatTxtpb = "txtpb"
+	// formatYAML is the YAML format.
+	formatYAML = "yaml"
+
+	formatOptionKey         = "format"
+	emitDefaultsOptionKey   = "emit_defaults"
+	useProtoNamesOptionKey  = "use_proto_names"
+	useEnumNumbersOptionKey = "use_enum_numbers"
+	discardUnknownOptionKey = "discard_unknown"
 )
 
 var (
@@ -45,7 +77,18 @@ var (
 	// sorted
 	messageEncodingFormats = []string{
 		formatBin,
+		formatBinDelimited,
 		formatJSON,
+		formatJSONL,
+		formatTxtpb,
+		formatYAML,
+	}
+	// textprotoExts are the file extensions of the Protobuf text format, other than
+	// the format name.
+	textprotoExts = map[string]struct{}{
+		"textproto": {},
+		"pbtxt":     {},
+		"prototxt":  {},
 	}
 )
 
@@ -56,6 +99,23 @@ type MessageEncoding int
 type MessageEncodingRef interface {
 	Path() string
 	MessageEncoding() MessageEncoding
+	// EmitDefaults says to write fields that are not populated, with their default values.
+	//
+	// This can only be true for MessageEncodingJSON, MessageEncodingJSONL and MessageEncodingYAML.
+	EmitDefaults() bool
+	// UseProtoNames says to write the proto names of fields instead of their JSON names.
+	//
+	// This can only be true for MessageEncodingJSON, MessageEncodingJSONL and MessageEncodingYAML.
+	UseProtoNames() bool
+	// UseEnumNumbers says to write the numbers of enum values instead of their names.
+	//
+	// This can only be true for MessageEncodingJSON, MessageEncodingJSONL and MessageEncodingYAML.
+	UseEnumNumbers() bool
+	// DiscardUnknown says to discard unknown fields when reading instead of returning an error.
+	//
+	// This is true by default, and only has an effect for MessageEncodingJSON,
+	// MessageEncodingJSONL, MessageEncodingTxtpb and MessageEncodingYAML.
+	DiscardUnknown() bool
 }
 
 // NewMessageEncodingRef returns a new MessageEncodingRef.
@@ -66,38 +126,90 @@ func NewMessageEncodingRef(
 ) (MessageEncodingRef, error) {
 	ctx, span := trace.StartSpan(ctx, "new_message_encoding_ref")
 	defer span.End()
-	path, messageEncoding, err := getPathAndMessageEncoding(ctx, value, defaultEncoding)
-	if err != nil {
-		return nil, err
-	}
-	return newMessageEncodingRef(path, messageEncoding), nil
+	return getMessageEncodingRef(ctx, value, defaultEncoding)
 }
 
-func getPathAndMessageEncoding(
+func getMessageEncodingRef(
 	ctx context.Context,
 	value string,
 	defaultEncoding MessageEncoding,
-) (string, MessageEncoding, error) {
+) (*messageEncodingRef, error) {
 	path, options, err := bufref.GetRawPathAndOptions(value)
 	if err != nil {
-		return "", 0, err
+		return nil, err
 	}
-	messageEncoding := parseMessageEncodingExt(filepath.Ext(path), defaultEncoding)
+	messageEncodingRef := newMessageEncodingRef(path, parseMessageEncodingExt(filepath.Ext(path), defaultEncoding))
+	var jsonOptionKeys []string
+	var textOptionKeys []string
 	for key, value := range options {
 		switch key {
-		case "format":
+		case formatOptionKey:
 			if app.IsDevNull(path) {
-				return "", 0, fmt.Errorf("not allowed if path is %s", app.DevNullFilePath)
+				return nil, fmt.Errorf("not allowed if path is %s", app.DevNullFilePath)
+			}
+			messageEncodingRef.messageEncoding, err = parseMessageEncodingFormat(value)
+			if err != nil {
+				return nil, err
+			}
+		case emitDefaultsOptionKey:
+			messageEncodingRef.emitDefaults, err = parseBoolOption(key, value)
+			if err != nil {
+				return nil, err
 			}
-			messageEncoding, err = parseMessageEncodingFormat(value)
+			jsonOptionKeys = append(jsonOptionKeys, key)
+		case useProtoNamesOptionKey:
+			messageEncodingRef.useProtoNames, err = parseBoolOption(key, value)
 			if err != nil {
-				return "", 0, err
+				return nil, err
 			}
+			jsonOptionKeys = append(jsonOptionKeys, key)
+		case useEnumNumbersOptionKey:
+			messageEncodingRef.useEnumNumbers, err = parseBoolOption(key, value)
+			if err != nil {
+				return nil, err
+			}
+			jsonOptionKeys = append(jsonOptionKeys, key)
+		case discardUnknownOptionKey:
+			messageEncodingRef.discardUnknown, err = parseBoolOption(key, value)
+			if err != nil {
+				return nil, err
+			}
+			textOptionKeys = append(textOptionKeys, key)
 		default:
-			return "", 0, fmt.Errorf("invalid options key: %q", key)
+			return nil, fmt.Errorf("invalid options key: %q", key)
+		}
+	}
+	// We validate the options after all options are parsed, as the format
+	// can be given after the options that depend on it.
+	sort.Strings(jsonOptionKeys)
+	sort.Strings(textOptionKeys)
+	switch messageEncodingRef.messageEncoding {
+	case MessageEncodingJSON, MessageEncodingJSONL, MessageEncodingYAML:
+	case MessageEncodingTxtpb:
+		if len(jsonOptionKeys) > 0 {
+			return nil, newOptionNotAllowedError(jsonOptionKeys[0], formatJSON, formatJSONL, formatYAML)
+		}
+	default:
+		if len(jsonOptionKeys) > 0 {
+			return nil, newOptionNotAllowedError(jsonOptionKeys[0], formatJSON, formatJSONL, formatYAML)
 		}
+		if len(textOptionKeys) > 0 {
+			return nil, newOptionNotAllowedError(textOptionKeys[0], formatJSON, formatJSONL, formatTxtpb, formatYAML)
+		}
+	}
+	return messageEncodingRef, nil
+}
+
+func parseBoolOption(key string, value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for option %q, must be true or false: %q", key, value)
 	}
-	return path, messageEncoding, nil
+	return b, nil
+}
+
+func newOptionNotAllowedError(key string, formats ...string) error {
+	return fmt.Errorf("option %q is only allowed for formats %s", key, stringutil.SliceToString(formats))
 }
 
 func parseMessageEncodingExt(ext string, defaultEncoding MessageEncoding) MessageEncoding {
@@ -106,7 +218,18 @@ func parseMessageEncodingExt(ext string, defaultEncoding MessageEncoding) Messag
 		return MessageEncodingBin
 	case formatJSON:
 		return MessageEncodingJSON
+	case formatBinDelimited:
+		return MessageEncodingBinDelimited
+	case formatJSONL:
+		return MessageEncodingJSONL
+	case formatTxtpb:
+		return MessageEncodingTxtpb
+	case formatYAML, "yml":
+		return MessageEncodingYAML
 	default:
+		if _, ok := textprotoExts[strings.TrimPrefix(ext, ".")]; ok {
+			return MessageEncodingTxtpb
+		}
 		return defaultEncoding
 	}
 }
@@ -117,6 +240,14 @@ func parseMessageEncodingFormat(format string) (MessageEncoding, error) {
 		return MessageEncodingBin, nil
 	case formatJSON:
 		return MessageEncodingJSON, nil
+	case formatBinDelimited:
+		return MessageEncodingBinDelimited, nil
+	case formatJSONL:
+		return MessageEncodingJSONL, nil
+	case formatTxtpb:
+		return MessageEncodingTxtpb, nil
+	case formatYAML:
+		return MessageEncodingYAML, nil
 	default:
 		return 0, fmt.Errorf("invalid format for message: %q", format)
 	}
@@ -19,6 +19,10 @@ var _ MessageEncodingRef = &messageEncodingRef{}
 type messageEncodingRef struct {
 	path            string
 	messageEncoding MessageEncoding
+	emitDefaults    bool
+	useProtoNames   bool
+	useEnumNumbers  bool
+	discardUnknown  bool
 }
 
 func newMessageEncodingRef(
@@ -28,6 +32,7 @@ func newMessageEncodingRef(
 	return &messageEncodingRef{
 		path:            path,
 		messageEncoding: messageEncoding,
+		discardUnknown:  true,
 	}
 }
 
@@ -38,3 +43,19 @@ func (r *messageEncodingRef) Path() string {
 func (r *messageEncodingRef) MessageEncoding() MessageEncoding {
 	return r.messageEncoding
 }
+
+func (r *messageEncodingRef) EmitDefaults() bool {
+	return r.emitDefaults
+}
+
+func (r *messageEncodingRef) UseProtoNames() bool {
+	return r.useProtoNames
+}
+
+func (r *messageEncodingRef) UseEnumNumbers() bool {
+	return r.useEnumNumbers
+}
+
+func (r *messageEncodingRef) DiscardUnknown() bool {
+	return r.discardUnknown
+}
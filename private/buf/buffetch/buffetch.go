@@ -128,6 +128,19 @@ type ProtoFileRef interface {
 	internalProtoFileRef() internal.ProtoFileRef
 }
 
+// IsLocalSourceRef returns true if the SourceRef is a directory or a proto file
+// on the local file system, as opposed to an archive or a git repository.
+//
+// The buckets of local SourceRefs are backed by the local file system.
+func IsLocalSourceRef(sourceRef SourceRef) bool {
+	switch sourceRef.internalBucketRef().(type) {
+	case internal.DirRef, internal.ProtoFileRef:
+		return true
+	default:
+		return false
+	}
+}
+
 // ImageRefParser is an image ref parser for Buf.
 type ImageRefParser interface {
 	// GetImageRef gets the reference for the image file.
@@ -16,11 +16,13 @@ package bufformat
 
 import (
 	"context"
+	"io"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"github.com/bufbuild/buf/private/pkg/thread"
+	"github.com/bufbuild/protocompile/ast"
 	"github.com/bufbuild/protocompile/parser"
 	"github.com/bufbuild/protocompile/reporter"
 	"go.uber.org/multierr"
@@ -66,3 +68,10 @@ func Format(ctx context.Context, module bufmodule.Module) (_ storage.ReadBucket,
 	}
 	return readWriteBucket, nil
 }
+
+// FormatFileNode formats the given *ast.FileNode and writes the result to the writer.
+//
+// This can be used to print a file that was modified after it was parsed.
+func FormatFileNode(writer io.Writer, fileNode *ast.FileNode) error {
+	return newFormatter(writer, fileNode).Run()
+}
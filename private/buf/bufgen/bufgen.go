@@ -21,7 +21,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
@@ -31,6 +33,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/verbose"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
@@ -111,14 +114,18 @@ type Generator interface {
 }
 
 // NewGenerator returns a new Generator.
+//
+// The time each plugin took is printed to the verbose.Printer.
 func NewGenerator(
 	logger *zap.Logger,
+	verbosePrinter verbose.Printer,
 	storageosProvider storageos.Provider,
 	runner command.Runner,
 	registryProvider registryv1alpha1apiclient.Provider,
 ) Generator {
 	return newGenerator(
 		logger,
+		verbosePrinter,
 		storageosProvider,
 		runner,
 		registryProvider,
@@ -148,6 +155,35 @@ func GenerateWithIncludeImports() GenerateOption {
 	}
 }
 
+// GenerateWithMaxParallelPlugins returns a new GenerateOption that runs at most
+// the given number of plugins at once.
+//
+// The default is to use thread.Parallelism(). A value of <1 has no meaning.
+func GenerateWithMaxParallelPlugins(maxParallelPlugins int) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.maxParallelPlugins = maxParallelPlugins
+	}
+}
+
+// GenerateWithCacheDirPath returns a new GenerateOption that caches the responses
+// of the plugins in the given directory, and reuses them for invocations that
+// have not changed.
+//
+// The invocation of a local plugin has not changed if the plugin binary and the
+// CodeGeneratorRequests are the same. The invocation of a remote plugin has not
+// changed if the plugin is pinned to the same version, and the options and
+// image are the same.
+//
+// The least recently used responses are deleted after generation once the cache
+// is larger than 512MB.
+//
+// The directory must exist. The default is to not cache the responses.
+func GenerateWithCacheDirPath(cacheDirPath string) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.cacheDirPath = cacheDirPath
+	}
+}
+
 // GenerateWithIncludeWellKnownTypes says to also generate well known types.
 //
 // This option has no effect if GenerateWithIncludeImports is not set.
@@ -157,6 +193,22 @@ func GenerateWithIncludeWellKnownTypes() GenerateOption {
 	}
 }
 
+// ExplainManaged modifies the Image according to the managed mode configuration,
+// and writes the resulting value of each file option managed by managed mode to
+// the writer, along with the setting that decided it.
+//
+// Only the files that are not imports are written.
+// If managed mode is not enabled, nothing is written.
+func ExplainManaged(
+	ctx context.Context,
+	logger *zap.Logger,
+	config *Config,
+	image bufimage.Image,
+	writer io.Writer,
+) error {
+	return explainManaged(ctx, logger, config, image, writer)
+}
+
 // Config is a configuration.
 type Config struct {
 	// Required
@@ -181,6 +233,9 @@ type PluginConfig struct {
 	Path string
 	// Required
 	Strategy Strategy
+	// Optional. The plugin is stopped if it has not completed after this
+	// duration. Zero means no timeout.
+	Timeout time.Duration
 }
 
 // PluginName returns this PluginConfig's plugin name.
@@ -220,7 +275,42 @@ type ManagedConfig struct {
 	JavaPackagePrefix     *JavaPackagePrefixConfig
 	OptimizeFor           *descriptorpb.FileOptions_OptimizeMode
 	GoPackagePrefixConfig *GoPackagePrefixConfig
-	Override              map[string]map[string]string
+	CcGenericServices     *bool
+	JavaGenericServices   *bool
+	PyGenericServices     *bool
+	PhpGenericServices    *bool
+	// SwiftPrefix is not set if empty.
+	SwiftPrefix string
+	// Jstype is set on all of the 64-bit integer fields.
+	Jstype   *descriptorpb.FieldOptions_JSType
+	Override map[string]map[string]string
+	// Rules are applied in order after all of the other settings, and the
+	// last rule that matches a file decides the value of the file option.
+	Rules []*ManagedRule
+}
+
+// ManagedRule is a managed mode rule that sets or disables a file option
+// for the files it matches.
+//
+// A rule matches a file if all of its non-empty matchers match the file.
+type ManagedRule struct {
+	// Required. The option name as written in the configuration, e.g. "go_package_prefix".
+	Option string
+	// Required. The ID of the bufimagemodify modifier of the option.
+	ModifierID string
+	// Optional. If set, Value is the prefix that the option value is computed from.
+	// Only set for the go_package_prefix and java_package_prefix options.
+	Prefix bool
+	// Optional. Matches the files of the module.
+	Module bufmoduleref.ModuleIdentity
+	// Optional. Matches the files whose package is this package or a sub-package of it.
+	PackagePrefix string
+	// Optional. Matches the files whose path, or one of its parent directories, match
+	// this glob. "**" matches any number of path components.
+	Path string
+	// Exactly one of Value and Disable is set.
+	Value   string
+	Disable bool
 }
 
 // JavaPackagePrefixConfig is the java_package prefix configuration.
@@ -294,6 +384,7 @@ type ExternalPluginConfigV1 struct {
 	Opt      interface{} `json:"opt,omitempty" yaml:"opt,omitempty"`
 	Path     string      `json:"path,omitempty" yaml:"path,omitempty"`
 	Strategy string      `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Timeout  string      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 // ExternalManagedConfigV1 is an external managed mode configuration.
@@ -307,7 +398,14 @@ type ExternalManagedConfigV1 struct {
 	JavaPackagePrefix   ExternalJavaPackagePrefixConfigV1 `json:"java_package_prefix,omitempty" yaml:"java_package_prefix,omitempty"`
 	OptimizeFor         string                            `json:"optimize_for,omitempty" yaml:"optimize_for,omitempty"`
 	GoPackagePrefix     ExternalGoPackagePrefixConfigV1   `json:"go_package_prefix,omitempty" yaml:"go_package_prefix,omitempty"`
+	CcGenericServices   *bool                             `json:"cc_generic_services,omitempty" yaml:"cc_generic_services,omitempty"`
+	JavaGenericServices *bool                             `json:"java_generic_services,omitempty" yaml:"java_generic_services,omitempty"`
+	PyGenericServices   *bool                             `json:"py_generic_services,omitempty" yaml:"py_generic_services,omitempty"`
+	PhpGenericServices  *bool                             `json:"php_generic_services,omitempty" yaml:"php_generic_services,omitempty"`
+	SwiftPrefix         string                            `json:"swift_prefix,omitempty" yaml:"swift_prefix,omitempty"`
+	Jstype              string                            `json:"jstype,omitempty" yaml:"jstype,omitempty"`
 	Override            map[string]map[string]string      `json:"override,omitempty" yaml:"override,omitempty"`
+	Rules               []ExternalManagedRuleV1           `json:"rules,omitempty" yaml:"rules,omitempty"`
 }
 
 // IsEmpty returns true if the config is empty, excluding the 'Enabled' setting.
@@ -318,7 +416,24 @@ func (e ExternalManagedConfigV1) IsEmpty() bool {
 		e.JavaPackagePrefix.IsEmpty() &&
 		e.OptimizeFor == "" &&
 		e.GoPackagePrefix.IsEmpty() &&
-		len(e.Override) == 0
+		e.CcGenericServices == nil &&
+		e.JavaGenericServices == nil &&
+		e.PyGenericServices == nil &&
+		e.PhpGenericServices == nil &&
+		e.SwiftPrefix == "" &&
+		e.Jstype == "" &&
+		len(e.Override) == 0 &&
+		len(e.Rules) == 0
+}
+
+// ExternalManagedRuleV1 is an external managed mode rule.
+type ExternalManagedRuleV1 struct {
+	Option        string `json:"option,omitempty" yaml:"option,omitempty"`
+	Module        string `json:"module,omitempty" yaml:"module,omitempty"`
+	PackagePrefix string `json:"package_prefix,omitempty" yaml:"package_prefix,omitempty"`
+	Path          string `json:"path,omitempty" yaml:"path,omitempty"`
+	Value         string `json:"value,omitempty" yaml:"value,omitempty"`
+	Disable       bool   `json:"disable,omitempty" yaml:"disable,omitempty"`
 }
 
 // ExternalJavaPackagePrefixConfigV1 is the external java_package prefix configuration.
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginref"
@@ -147,6 +148,16 @@ func newConfigV1(externalConfig ExternalConfigV1, id string) (*Config, error) {
 			Path:     plugin.Path,
 			Strategy: strategy,
 		}
+		if plugin.Timeout != "" {
+			timeout, err := time.ParseDuration(plugin.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid timeout for plugin %s: %w", id, pluginConfig.PluginName(), err)
+			}
+			if timeout <= 0 {
+				return nil, fmt.Errorf("%s: timeout for plugin %s must be positive", id, pluginConfig.PluginName())
+			}
+			pluginConfig.Timeout = timeout
+		}
 		if pluginConfig.IsRemote() {
 			// Always use StrategyAll for remote plugins
 			pluginConfig.Strategy = StrategyAll
@@ -255,6 +266,17 @@ func newManagedConfigV1(externalManagedConfig ExternalManagedConfigV1) (*Managed
 	if err != nil {
 		return nil, err
 	}
+	var jstype *descriptorpb.FieldOptions_JSType
+	if externalManagedConfig.Jstype != "" {
+		value, ok := descriptorpb.FieldOptions_JSType_value[externalManagedConfig.Jstype]
+		if !ok {
+			return nil, fmt.Errorf(
+				"invalid jstype value; expected one of %v",
+				enumMapToStringSlice(descriptorpb.FieldOptions_JSType_value),
+			)
+		}
+		jstype = descriptorpb.FieldOptions_JSType(value).Enum()
+	}
 	override := externalManagedConfig.Override
 	for overrideID, overrideValue := range override {
 		for importPath := range overrideValue {
@@ -275,6 +297,10 @@ func newManagedConfigV1(externalManagedConfig ExternalManagedConfigV1) (*Managed
 			}
 		}
 	}
+	rules, err := newManagedRulesV1(externalManagedConfig.Rules)
+	if err != nil {
+		return nil, err
+	}
 	return &ManagedConfig{
 		CcEnableArenas:        externalManagedConfig.CcEnableArenas,
 		JavaMultipleFiles:     externalManagedConfig.JavaMultipleFiles,
@@ -282,7 +308,81 @@ func newManagedConfigV1(externalManagedConfig ExternalManagedConfigV1) (*Managed
 		JavaPackagePrefix:     javaPackagePrefixConfig,
 		OptimizeFor:           optimizeFor,
 		GoPackagePrefixConfig: goPackagePrefixConfig,
+		CcGenericServices:     externalManagedConfig.CcGenericServices,
+		JavaGenericServices:   externalManagedConfig.JavaGenericServices,
+		PyGenericServices:     externalManagedConfig.PyGenericServices,
+		PhpGenericServices:    externalManagedConfig.PhpGenericServices,
+		SwiftPrefix:           externalManagedConfig.SwiftPrefix,
+		Jstype:                jstype,
 		Override:              override,
+		Rules:                 rules,
+	}, nil
+}
+
+func newManagedRulesV1(externalManagedRules []ExternalManagedRuleV1) ([]*ManagedRule, error) {
+	if len(externalManagedRules) == 0 {
+		return nil, nil
+	}
+	rules := make([]*ManagedRule, 0, len(externalManagedRules))
+	for i, externalManagedRule := range externalManagedRules {
+		rule, err := newManagedRuleV1(externalManagedRule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid managed mode rule %d: %w", i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func newManagedRuleV1(externalManagedRule ExternalManagedRuleV1) (*ManagedRule, error) {
+	if externalManagedRule.Option == "" {
+		return nil, errors.New("option is required")
+	}
+	modifierID, prefix, ok := managedRuleOptionModifierID(externalManagedRule.Option)
+	if !ok {
+		return nil, fmt.Errorf(
+			"unknown option %q; expected one of %v",
+			externalManagedRule.Option,
+			managedRuleOptionNames(),
+		)
+	}
+	if (externalManagedRule.Value == "") == !externalManagedRule.Disable {
+		return nil, errors.New("exactly one of value and disable must be set")
+	}
+	if externalManagedRule.Value != "" {
+		if err := validateManagedRuleValue(modifierID, externalManagedRule.Value); err != nil {
+			return nil, err
+		}
+	}
+	var moduleIdentity bufmoduleref.ModuleIdentity
+	if externalManagedRule.Module != "" {
+		var err error
+		moduleIdentity, err = bufmoduleref.ModuleIdentityForString(externalManagedRule.Module)
+		if err != nil {
+			return nil, fmt.Errorf("invalid module: %w", err)
+		}
+	}
+	if externalManagedRule.Path != "" {
+		normalizedPath, err := normalpath.NormalizeAndValidate(externalManagedRule.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		if externalManagedRule.Path != normalizedPath {
+			return nil, fmt.Errorf("path must be normalized: %s", externalManagedRule.Path)
+		}
+		if err := validateManagedRulePath(externalManagedRule.Path); err != nil {
+			return nil, err
+		}
+	}
+	return &ManagedRule{
+		Option:        externalManagedRule.Option,
+		ModifierID:    modifierID,
+		Prefix:        prefix,
+		Module:        moduleIdentity,
+		PackagePrefix: externalManagedRule.PackagePrefix,
+		Path:          externalManagedRule.Path,
+		Value:         externalManagedRule.Value,
+		Disable:       externalManagedRule.Disable,
 	}, nil
 }
 
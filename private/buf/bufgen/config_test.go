@@ -19,6 +19,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
@@ -427,6 +428,145 @@ func TestReadConfigV1(t *testing.T) {
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "go_gen_error7.yaml"))
 }
 
+func TestReadConfigV1ManagedRules(t *testing.T) {
+	t.Parallel()
+	moduleIdentity, err := bufmoduleref.ModuleIdentityForString("buf.build/acme/weather")
+	require.NoError(t, err)
+	successConfig := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+		ManagedConfig: &ManagedConfig{
+			Rules: []*ManagedRule{
+				{
+					Option:     "go_package_prefix",
+					ModifierID: bufimagemodify.GoPackageID,
+					Prefix:     true,
+					Module:     moduleIdentity,
+					Value:      "github.com/acme/weather/gen/go",
+				},
+				{
+					Option:     "cc_enable_arenas",
+					ModifierID: bufimagemodify.CcEnableArenasID,
+					Path:       "internal/**",
+					Value:      "true",
+				},
+				{
+					Option:        "csharp_namespace",
+					ModifierID:    bufimagemodify.CsharpNamespaceID,
+					PackagePrefix: "acme.weather",
+					Disable:       true,
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	config, err := ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "rules_success1.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "rules_success1.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig, config)
+
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "rules_error1.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "rules_error2.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "rules_error3.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "rules_error4.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "rules_error5.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "rules_error6.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "rules_error7.yaml"))
+}
+
+func TestReadConfigV1ManagedServiceAndFieldOptions(t *testing.T) {
+	t.Parallel()
+	trueValue := true
+	falseValue := false
+	successConfig := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+		ManagedConfig: &ManagedConfig{
+			CcGenericServices:   &trueValue,
+			JavaGenericServices: &falseValue,
+			PyGenericServices:   &trueValue,
+			PhpGenericServices:  &falseValue,
+			SwiftPrefix:         "Acme",
+			Jstype:              descriptorpb.FieldOptions_JS_STRING.Enum(),
+			Rules: []*ManagedRule{
+				{
+					Option:     "jstype",
+					ModifierID: bufimagemodify.JstypeID,
+					Path:       "legacy/**",
+					Value:      "JS_NUMBER",
+				},
+				{
+					Option:        "java_generic_services",
+					ModifierID:    bufimagemodify.JavaGenericServicesID,
+					PackagePrefix: "acme.weather",
+					Disable:       true,
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	config, err := ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "options_success1.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "options_success1.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig, config)
+
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "options_error1.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "options_error2.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "options_error3.yaml"))
+}
+
+func TestReadConfigV1PluginTimeout(t *testing.T) {
+	t.Parallel()
+	successConfig := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+				Timeout:  90 * time.Second,
+			},
+			{
+				Name:     "java",
+				Out:      "gen/java",
+				Strategy: StrategyDirectory,
+			},
+		},
+	}
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	config, err := ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "timeout_success1.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig, config)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "timeout_success1.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig, config)
+
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "timeout_error1.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "timeout_error2.yaml"))
+}
+
 func testReadConfigError(t *testing.T, provider Provider, readBucket storage.ReadBucket, testFilePath string) {
 	ctx := context.Background()
 	_, err := ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(testFilePath))
@@ -0,0 +1,331 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginref"
+	"github.com/bufbuild/buf/private/bufpkg/bufremoteplugin"
+	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoexec"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// generateCacheKeyVersion is part of every cache key, and is changed if the
+// contents of the keys or the cached responses change.
+const generateCacheKeyVersion = "v1"
+
+const (
+	// defaultGenerateCacheMaxSize is the size in bytes above which the least
+	// recently used responses are deleted from the cache.
+	defaultGenerateCacheMaxSize int64 = 512 * 1024 * 1024
+
+	generateCacheDataDirPath   = "data"
+	generateCacheAccessDirPath = "access"
+)
+
+// generateCache caches the CodeGeneratorResponses of plugin invocations.
+//
+// Each response is stored under a key that is the digest of everything that the
+// response depends on, so entries never need to be invalidated. The time at which
+// each response was last used is recorded next to it, so that prune can delete
+// the least recently used responses once the cache grows above its maximum size.
+type generateCache struct {
+	logger          *zap.Logger
+	readWriteBucket storage.ReadWriteBucket
+	maxSize         int64
+}
+
+func newGenerateCache(
+	logger *zap.Logger,
+	readWriteBucket storage.ReadWriteBucket,
+	maxSize int64,
+) *generateCache {
+	return &generateCache{
+		logger:          logger,
+		readWriteBucket: readWriteBucket,
+		maxSize:         maxSize,
+	}
+}
+
+// get returns the cached response for the key.
+//
+// Returns nil if there is no cached response for the key. Entries that cannot be
+// read are treated as missing, as they are overwritten by the next put.
+func (c *generateCache) get(ctx context.Context, key string) *pluginpb.CodeGeneratorResponse {
+	data, err := storage.ReadPath(ctx, c.readWriteBucket, generateCacheDataPath(key))
+	if err != nil {
+		if !storage.IsNotExist(err) {
+			c.logger.Debug("generate_cache_read_error", zap.String("key", key), zap.Error(err))
+		}
+		return nil
+	}
+	response := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(data, response); err != nil {
+		c.logger.Debug("generate_cache_unmarshal_error", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	c.recordAccess(ctx, key)
+	return response
+}
+
+// put caches the response for the key.
+func (c *generateCache) put(ctx context.Context, key string, response *pluginpb.CodeGeneratorResponse) error {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(response)
+	if err != nil {
+		return err
+	}
+	if err := storage.PutPath(ctx, c.readWriteBucket, generateCacheDataPath(key), data); err != nil {
+		return err
+	}
+	c.recordAccess(ctx, key)
+	return nil
+}
+
+// prune deletes the least recently used responses until the cache is at most
+// its maximum size.
+//
+// Responses without a recorded access are considered as the least recently used.
+func (c *generateCache) prune(ctx context.Context) error {
+	keyToSize := make(map[string]int64)
+	var totalSize int64
+	if err := c.readWriteBucket.Walk(
+		ctx,
+		generateCacheDataDirPath,
+		func(objectInfo storage.ObjectInfo) error {
+			keyToSize[normalpath.Base(objectInfo.Path())] = objectInfo.Size()
+			totalSize += objectInfo.Size()
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+	if totalSize <= c.maxSize {
+		return nil
+	}
+	keys := make([]string, 0, len(keyToSize))
+	keyToLastUsed := make(map[string]time.Time, len(keyToSize))
+	for key := range keyToSize {
+		keys = append(keys, key)
+		keyToLastUsed[key] = c.getLastUsed(ctx, key)
+	}
+	sort.Slice(
+		keys,
+		func(i int, j int) bool {
+			if lastUsedI, lastUsedJ := keyToLastUsed[keys[i]], keyToLastUsed[keys[j]]; !lastUsedI.Equal(lastUsedJ) {
+				return lastUsedI.Before(lastUsedJ)
+			}
+			return keys[i] < keys[j]
+		},
+	)
+	for _, key := range keys {
+		if totalSize <= c.maxSize {
+			break
+		}
+		// Another buf process may have deleted the entry concurrently.
+		if err := c.readWriteBucket.Delete(ctx, generateCacheDataPath(key)); err != nil && !storage.IsNotExist(err) {
+			return err
+		}
+		if err := c.readWriteBucket.Delete(ctx, generateCacheAccessPath(key)); err != nil && !storage.IsNotExist(err) {
+			return err
+		}
+		totalSize -= keyToSize[key]
+	}
+	return nil
+}
+
+// recordAccess records that the response for the key was used now.
+//
+// This is best-effort, as failing to record the access only affects prune.
+func (c *generateCache) recordAccess(ctx context.Context, key string) {
+	if err := storage.PutPath(
+		ctx,
+		c.readWriteBucket,
+		generateCacheAccessPath(key),
+		[]byte(time.Now().UTC().Format(time.RFC3339Nano)),
+	); err != nil {
+		c.logger.Debug("generate_cache_access_record_failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// getLastUsed returns the time at which the response for the key was last used.
+//
+// Returns the zero time if no access was recorded.
+func (c *generateCache) getLastUsed(ctx context.Context, key string) time.Time {
+	data, err := storage.ReadPath(ctx, c.readWriteBucket, generateCacheAccessPath(key))
+	if err != nil {
+		return time.Time{}
+	}
+	lastUsed, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}
+	}
+	return lastUsed
+}
+
+// generateCacheDataPath returns the path of the cached response for the key.
+//
+// The entries are split into directories by the first two characters of the key so
+// that directories do not get too large.
+func generateCacheDataPath(key string) string {
+	return normalpath.Join(generateCacheDataDirPath, key[:2], key)
+}
+
+// generateCacheAccessPath returns the path of the time at which the response for
+// the key was last used.
+func generateCacheAccessPath(key string) string {
+	return normalpath.Join(generateCacheAccessDirPath, key[:2], key)
+}
+
+// localPluginGenerateCacheKey returns the cache key for the invocation of the local
+// plugin with the requests.
+//
+// The key includes the digest of the plugin binary, so that upgrading the plugin
+// invalidates its cached responses.
+func localPluginGenerateCacheKey(
+	pluginConfig *PluginConfig,
+	requests []*pluginpb.CodeGeneratorRequest,
+) (string, error) {
+	pluginPath, err := appprotoexec.FindPluginPath(
+		pluginConfig.PluginName(),
+		appprotoexec.HandlerWithPluginPath(pluginConfig.Path),
+	)
+	if err != nil {
+		return "", err
+	}
+	pluginDigest, err := fileSha256(pluginPath)
+	if err != nil {
+		return "", err
+	}
+	keyBuilder := newGenerateCacheKeyBuilder()
+	keyBuilder.add([]byte("local"))
+	keyBuilder.add([]byte(pluginConfig.PluginName()))
+	keyBuilder.add(pluginDigest)
+	for _, request := range requests {
+		if err := keyBuilder.addMessage(request); err != nil {
+			return "", err
+		}
+	}
+	return keyBuilder.key(), nil
+}
+
+// remotePluginGenerateCacheKey returns the cache key for the invocation of the
+// remote plugin with the image.
+//
+// Returns false if the plugin is not pinned to a version, as the responses of
+// the plugin can then change without any change to the key. Plugins that use
+// "plugin" must also be pinned to a revision, as new revisions of a version can
+// be published.
+func remotePluginGenerateCacheKey(
+	pluginConfig *PluginConfig,
+	image bufimage.Image,
+	includeImports bool,
+	includeWellKnownTypes bool,
+) (string, bool, error) {
+	if pluginConfig.Plugin != "" {
+		if pluginConfig.Revision == 0 {
+			return "", false, nil
+		}
+		if _, err := bufpluginref.PluginReferenceForString(pluginConfig.Plugin, pluginConfig.Revision); err != nil {
+			return "", false, nil
+		}
+	} else {
+		_, _, _, version, err := bufremoteplugin.ParsePluginVersionPath(pluginConfig.Remote)
+		if err != nil || version == "" {
+			return "", false, nil
+		}
+	}
+	keyBuilder := newGenerateCacheKeyBuilder()
+	keyBuilder.add([]byte("remote"))
+	keyBuilder.add([]byte(pluginConfig.Plugin))
+	keyBuilder.add([]byte(strconv.Itoa(pluginConfig.Revision)))
+	keyBuilder.add([]byte(pluginConfig.Remote))
+	keyBuilder.add([]byte(pluginConfig.Opt))
+	keyBuilder.add([]byte(strconv.FormatBool(includeImports)))
+	keyBuilder.add([]byte(strconv.FormatBool(includeWellKnownTypes)))
+	if err := keyBuilder.addMessage(bufimage.ImageToProtoImage(image)); err != nil {
+		return "", false, err
+	}
+	return keyBuilder.key(), true, nil
+}
+
+// generateCacheKeyBuilder computes a cache key from a list of values.
+//
+// Each value is prefixed with its length so that different lists of values
+// never result in the same key.
+type generateCacheKeyBuilder struct {
+	hash      hash.Hash
+	lengthBuf [binary.MaxVarintLen64]byte
+}
+
+func newGenerateCacheKeyBuilder() *generateCacheKeyBuilder {
+	keyBuilder := &generateCacheKeyBuilder{
+		hash: sha256.New(),
+	}
+	keyBuilder.add([]byte(generateCacheKeyVersion))
+	return keyBuilder
+}
+
+func (b *generateCacheKeyBuilder) add(value []byte) {
+	n := binary.PutUvarint(b.lengthBuf[:], uint64(len(value)))
+	// Writes to a hash never return an error.
+	_, _ = b.hash.Write(b.lengthBuf[:n])
+	_, _ = b.hash.Write(value)
+}
+
+func (b *generateCacheKeyBuilder) addMessage(message proto.Message) error {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return err
+	}
+	b.add(data)
+	return nil
+}
+
+func (b *generateCacheKeyBuilder) key() string {
+	return hex.EncodeToString(b.hash.Sum(nil))
+}
+
+// fileSha256 returns the SHA256 digest of the file.
+func fileSha256(filePath string) (_ []byte, retErr error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, file.Close())
+	}()
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return nil, err
+	}
+	return digest.Sum(nil), nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestGenerateCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	generateCache := newGenerateCache(zap.NewNop(), readWriteBucket, defaultGenerateCacheMaxSize)
+	key := newGenerateCacheKeyBuilder().key()
+	assert.Nil(t, generateCache.get(ctx, key))
+	response := &pluginpb.CodeGeneratorResponse{
+		File: []*pluginpb.CodeGeneratorResponse_File{
+			{
+				Name:    proto.String("a.txt"),
+				Content: proto.String("a"),
+			},
+		},
+	}
+	require.NoError(t, generateCache.put(ctx, key, response))
+	assert.True(t, proto.Equal(response, generateCache.get(ctx, key)))
+	// Entries that cannot be read are treated as missing.
+	require.NoError(t, storage.PutPath(ctx, readWriteBucket, generateCacheDataPath(key), []byte("invalid")))
+	assert.Nil(t, generateCache.get(ctx, key))
+}
+
+func TestGenerateCachePrune(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	response := &pluginpb.CodeGeneratorResponse{
+		File: []*pluginpb.CodeGeneratorResponse_File{
+			{
+				Name:    proto.String("a.txt"),
+				Content: proto.String("a"),
+			},
+		},
+	}
+	data, err := proto.Marshal(response)
+	require.NoError(t, err)
+	// Room for two responses.
+	generateCache := newGenerateCache(zap.NewNop(), readWriteBucket, int64(2*len(data)))
+	var keys []string
+	for i := 0; i < 3; i++ {
+		keyBuilder := newGenerateCacheKeyBuilder()
+		keyBuilder.add([]byte(strconv.Itoa(i)))
+		key := keyBuilder.key()
+		require.NoError(t, generateCache.put(ctx, key, response))
+		// Record distinct access times, as the clock may not advance between puts.
+		lastUsed := time.Date(2022, 1, 1+i, 0, 0, 0, 0, time.UTC).Format(time.RFC3339Nano)
+		require.NoError(t, storage.PutPath(ctx, readWriteBucket, generateCacheAccessPath(key), []byte(lastUsed)))
+		keys = append(keys, key)
+	}
+	require.NoError(t, generateCache.prune(ctx))
+	// The first response was put before the others.
+	assert.Nil(t, generateCache.get(ctx, keys[0]))
+	assert.NotNil(t, generateCache.get(ctx, keys[1]))
+	assert.NotNil(t, generateCache.get(ctx, keys[2]))
+	_, err = readWriteBucket.Stat(ctx, generateCacheAccessPath(keys[0]))
+	assert.True(t, storage.IsNotExist(err))
+
+	// Responses without a recorded access are deleted first.
+	require.NoError(t, readWriteBucket.Delete(ctx, generateCacheAccessPath(keys[2])))
+	require.NoError(t, generateCache.put(ctx, keys[0], response))
+	require.NoError(t, generateCache.prune(ctx))
+	assert.NotNil(t, generateCache.get(ctx, keys[0]))
+	assert.NotNil(t, generateCache.get(ctx, keys[1]))
+	assert.Nil(t, generateCache.get(ctx, keys[2]))
+}
+
+func TestLocalPluginGenerateCacheKey(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+	pluginPath := filepath.Join(t.TempDir(), "protoc-gen-test")
+	require.NoError(t, os.WriteFile(pluginPath, []byte("#!/bin/sh\n"), 0700))
+	pluginConfig := &PluginConfig{
+		Name: "test",
+		Out:  "gen",
+		Path: pluginPath,
+	}
+	requests := []*pluginpb.CodeGeneratorRequest{
+		{
+			FileToGenerate: []string{"a.proto"},
+			Parameter:      proto.String("foo"),
+		},
+	}
+	key, err := localPluginGenerateCacheKey(pluginConfig, requests)
+	require.NoError(t, err)
+	sameKey, err := localPluginGenerateCacheKey(pluginConfig, requests)
+	require.NoError(t, err)
+	assert.Equal(t, key, sameKey)
+
+	requests[0].Parameter = proto.String("bar")
+	parameterKey, err := localPluginGenerateCacheKey(pluginConfig, requests)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, parameterKey)
+
+	require.NoError(t, os.WriteFile(pluginPath, []byte("#!/bin/sh\nexit 0\n"), 0700))
+	binaryKey, err := localPluginGenerateCacheKey(pluginConfig, requests)
+	require.NoError(t, err)
+	assert.NotEqual(t, parameterKey, binaryKey)
+
+	pluginConfig.Path = filepath.Join(t.TempDir(), "protoc-gen-missing")
+	_, err = localPluginGenerateCacheKey(pluginConfig, requests)
+	assert.Error(t, err)
+}
+
+func TestRemotePluginGenerateCacheKey(t *testing.T) {
+	t.Parallel()
+	image := testNewManagedRulesImage(
+		t,
+		testNewManagedRulesImageFile(t, "a/v1/a.proto", "a.v1", nil),
+	)
+	testRemotePluginGenerateCacheKeyOK(t, image, &PluginConfig{Remote: "buf.build/acme/plugins/go:v1.28.0-1"}, true)
+	testRemotePluginGenerateCacheKeyOK(t, image, &PluginConfig{Remote: "buf.build/acme/plugins/go"}, false)
+	testRemotePluginGenerateCacheKeyOK(t, image, &PluginConfig{Plugin: "buf.build/acme/go:v1.28.0", Revision: 1}, true)
+	testRemotePluginGenerateCacheKeyOK(t, image, &PluginConfig{Plugin: "buf.build/acme/go:v1.28.0"}, false)
+	testRemotePluginGenerateCacheKeyOK(t, image, &PluginConfig{Plugin: "buf.build/acme/go"}, false)
+
+	pluginConfig := &PluginConfig{Remote: "buf.build/acme/plugins/go:v1.28.0-1"}
+	key, _, err := remotePluginGenerateCacheKey(pluginConfig, image, false, false)
+	require.NoError(t, err)
+	includeImportsKey, _, err := remotePluginGenerateCacheKey(pluginConfig, image, true, false)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, includeImportsKey)
+	pluginConfig.Opt = "paths=source_relative"
+	optKey, _, err := remotePluginGenerateCacheKey(pluginConfig, image, false, false)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, optKey)
+}
+
+func testRemotePluginGenerateCacheKeyOK(
+	t *testing.T,
+	image bufimage.Image,
+	pluginConfig *PluginConfig,
+	expectedOK bool,
+) {
+	_, ok, err := remotePluginGenerateCacheKey(pluginConfig, image, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, expectedOK, ok, "%s%s", pluginConfig.Remote, pluginConfig.Plugin)
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
@@ -34,6 +35,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/thread"
+	"github.com/bufbuild/buf/private/pkg/verbose"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/pluginpb"
@@ -41,6 +43,7 @@ import (
 
 type generator struct {
 	logger                *zap.Logger
+	verbosePrinter        verbose.Printer
 	storageosProvider     storageos.Provider
 	appprotoexecGenerator appprotoexec.Generator
 	registryProvider      registryv1alpha1apiclient.Provider
@@ -48,12 +51,14 @@ type generator struct {
 
 func newGenerator(
 	logger *zap.Logger,
+	verbosePrinter verbose.Printer,
 	storageosProvider storageos.Provider,
 	runner command.Runner,
 	registryProvider registryv1alpha1apiclient.Provider,
 ) *generator {
 	return &generator{
 		logger:                logger,
+		verbosePrinter:        verbosePrinter,
 		storageosProvider:     storageosProvider,
 		appprotoexecGenerator: appprotoexec.NewGenerator(logger, storageosProvider, runner),
 		registryProvider:      registryProvider,
@@ -69,8 +74,8 @@ func newGenerator(
 // in the same protoc invocation; plugins will not be able to insert code into
 // other files that already exist on disk (just like protoc).
 //
-// All of the plugins, both local and remote, are called concurrently. Each
-// plugin returns a single CodeGeneratorResponse, which are cached in-memory in
+// All of the plugins, both local and remote, are called concurrently, up to the
+// maximum number of parallel plugins, if set. Each plugin returns a single CodeGeneratorResponse, which are cached in-memory in
 // the appprotoos.ResponseWriter. Once all of the CodeGeneratorResponses
 // are written in-memory, we flush them to the OS filesystem by closing the
 // appprotoos.ResponseWriter.
@@ -88,7 +93,16 @@ func (g *generator) Generate(
 	for _, option := range options {
 		option(generateOptions)
 	}
-	return g.generate(
+	var generateCache *generateCache
+	if generateOptions.cacheDirPath != "" {
+		// do NOT want to enable symlinks for our cache
+		readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(generateOptions.cacheDirPath)
+		if err != nil {
+			return err
+		}
+		generateCache = newGenerateCache(g.logger, readWriteBucket, defaultGenerateCacheMaxSize)
+	}
+	if err := g.generate(
 		ctx,
 		container,
 		config,
@@ -96,7 +110,18 @@ func (g *generator) Generate(
 		generateOptions.baseOutDirPath,
 		generateOptions.includeImports,
 		generateOptions.includeWellKnownTypes,
-	)
+		generateOptions.maxParallelPlugins,
+		generateCache,
+	); err != nil {
+		return err
+	}
+	if generateCache != nil {
+		if err := generateCache.prune(ctx); err != nil {
+			// The cache is only an optimization, so this is not an error.
+			g.logger.Sugar().Warnf("Could not prune the plugin response cache: %v", err)
+		}
+	}
+	return nil
 }
 
 func (g *generator) generate(
@@ -107,6 +132,8 @@ func (g *generator) generate(
 	baseOutDirPath string,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	maxParallelPlugins int,
+	generateCache *generateCache,
 ) error {
 	if err := modifyImage(ctx, g.logger, config, image); err != nil {
 		return err
@@ -119,6 +146,8 @@ func (g *generator) generate(
 		image,
 		includeImports,
 		includeWellKnownTypes,
+		maxParallelPlugins,
+		generateCache,
 	)
 	if err != nil {
 		return err
@@ -160,6 +189,8 @@ func (g *generator) execPlugins(
 	image bufimage.Image,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	maxParallelPlugins int,
+	generateCache *generateCache,
 ) ([]*pluginpb.CodeGeneratorResponse, error) {
 	imageProvider := newImageProvider(image)
 	// Collect all of the plugin jobs so that they can be executed in parallel.
@@ -169,40 +200,39 @@ func (g *generator) execPlugins(
 	for i, pluginConfig := range config.PluginConfigs {
 		index := i
 		currentPluginConfig := pluginConfig
+		execPlugin := func(ctx context.Context) (*pluginpb.CodeGeneratorResponse, error) {
+			return g.execLocalPlugin(
+				ctx,
+				container,
+				g.appprotoexecGenerator,
+				imageProvider,
+				currentPluginConfig,
+				includeImports,
+				includeWellKnownTypes,
+				generateCache,
+			)
+		}
 		if pluginConfig.IsRemote() {
-			jobs = append(jobs, func(ctx context.Context) error {
-				response, err := g.execRemotePlugin(
+			execPlugin = func(ctx context.Context) (*pluginpb.CodeGeneratorResponse, error) {
+				return g.execRemotePlugin(
 					ctx,
 					container,
 					image,
 					currentPluginConfig,
 					includeImports,
 					includeWellKnownTypes,
+					generateCache,
 				)
-				if err != nil {
-					return err
-				}
-				responses[index] = response
-				return nil
-			})
-		} else {
-			jobs = append(jobs, func(ctx context.Context) error {
-				response, err := g.execLocalPlugin(
-					ctx,
-					container,
-					g.appprotoexecGenerator,
-					imageProvider,
-					currentPluginConfig,
-					includeImports,
-					includeWellKnownTypes,
-				)
-				if err != nil {
-					return err
-				}
-				responses[index] = response
-				return nil
-			})
+			}
 		}
+		jobs = append(jobs, func(ctx context.Context) error {
+			response, err := g.execPluginWithTimeout(ctx, currentPluginConfig, execPlugin)
+			if err != nil {
+				return err
+			}
+			responses[index] = response
+			return nil
+		})
 	}
 	// We execute all of the jobs in parallel, but apply them in order so that any
 	// insertion points are handled correctly.
@@ -218,10 +248,19 @@ func (g *generator) execPlugins(
 	//      out: gen/proto
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	parallelizeOptions := []thread.ParallelizeOption{
+		thread.ParallelizeWithCancel(cancel),
+	}
+	if maxParallelPlugins > 0 {
+		parallelizeOptions = append(
+			parallelizeOptions,
+			thread.ParallelizeWithMaxParallelism(maxParallelPlugins),
+		)
+	}
 	if err := thread.Parallelize(
 		ctx,
 		jobs,
-		thread.ParallelizeWithCancel(cancel),
+		parallelizeOptions...,
 	); err != nil {
 		if errs := multierr.Errors(err); len(errs) > 0 {
 			return nil, errs[0]
@@ -235,6 +274,37 @@ func (g *generator) execPlugins(
 	return responses, nil
 }
 
+// execPluginWithTimeout calls execPlugin, stopping it if it has not completed
+// after the timeout of the plugin, and prints how long the plugin took.
+func (g *generator) execPluginWithTimeout(
+	ctx context.Context,
+	pluginConfig *PluginConfig,
+	execPlugin func(context.Context) (*pluginpb.CodeGeneratorResponse, error),
+) (*pluginpb.CodeGeneratorResponse, error) {
+	if pluginConfig.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, pluginConfig.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	response, err := execPlugin(ctx)
+	duration := time.Since(start)
+	if err != nil {
+		if pluginConfig.Timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("plugin %s timed out after %v", pluginConfig.PluginName(), pluginConfig.Timeout)
+		}
+		return nil, err
+	}
+	g.logger.Debug(
+		"plugin_finished",
+		zap.String("plugin", pluginConfig.PluginName()),
+		zap.String("out", pluginConfig.Out),
+		zap.Duration("duration", duration),
+	)
+	g.verbosePrinter.Printf("plugin %s for %s took %v", pluginConfig.PluginName(), pluginConfig.Out, duration.Round(time.Millisecond))
+	return response, nil
+}
+
 func (g *generator) execLocalPlugin(
 	ctx context.Context,
 	container app.EnvStdioContainer,
@@ -243,27 +313,63 @@ func (g *generator) execLocalPlugin(
 	pluginConfig *PluginConfig,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	generateCache *generateCache,
 ) (*pluginpb.CodeGeneratorResponse, error) {
 	pluginImages, err := imageProvider.GetImages(pluginConfig.Strategy)
 	if err != nil {
 		return nil, err
 	}
-	response, err := appprotoexecGenerator.Generate(
-		ctx,
-		container,
-		pluginConfig.PluginName(),
-		bufimage.ImagesToCodeGeneratorRequests(
-			pluginImages,
-			pluginConfig.Opt,
-			nil,
-			includeImports,
-			includeWellKnownTypes,
-		),
-		appprotoexec.GenerateWithPluginPath(pluginConfig.Path),
+	requests := bufimage.ImagesToCodeGeneratorRequests(
+		pluginImages,
+		pluginConfig.Opt,
+		nil,
+		includeImports,
+		includeWellKnownTypes,
 	)
+	execPlugin := func(ctx context.Context) (*pluginpb.CodeGeneratorResponse, error) {
+		response, err := appprotoexecGenerator.Generate(
+			ctx,
+			container,
+			pluginConfig.PluginName(),
+			requests,
+			appprotoexec.GenerateWithPluginPath(pluginConfig.Path),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
+		}
+		return response, nil
+	}
+	if generateCache == nil {
+		return execPlugin(ctx)
+	}
+	cacheKey, err := localPluginGenerateCacheKey(pluginConfig, requests)
 	if err != nil {
 		return nil, fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
 	}
+	return g.execPluginWithCache(ctx, generateCache, cacheKey, pluginConfig, execPlugin)
+}
+
+// execPluginWithCache returns the cached response for the cache key if there is
+// one, and otherwise calls execPlugin and caches its response.
+func (g *generator) execPluginWithCache(
+	ctx context.Context,
+	generateCache *generateCache,
+	cacheKey string,
+	pluginConfig *PluginConfig,
+	execPlugin func(context.Context) (*pluginpb.CodeGeneratorResponse, error),
+) (*pluginpb.CodeGeneratorResponse, error) {
+	if response := generateCache.get(ctx, cacheKey); response != nil {
+		g.verbosePrinter.Printf("plugin %s for %s is unchanged, using cached response", pluginConfig.PluginName(), pluginConfig.Out)
+		return response, nil
+	}
+	response, err := execPlugin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := generateCache.put(ctx, cacheKey, response); err != nil {
+		// The cache is only an optimization, so this is not an error.
+		g.logger.Sugar().Warnf("Could not cache the response of plugin %s: %v", pluginConfig.PluginName(), err)
+	}
 	return response, nil
 }
 
@@ -274,10 +380,35 @@ func (g *generator) execRemotePlugin(
 	pluginConfig *PluginConfig,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	generateCache *generateCache,
 ) (*pluginpb.CodeGeneratorResponse, error) {
-	if len(pluginConfig.Plugin) > 0 {
-		return g.execRemotePluginV2(ctx, container, image, pluginConfig, includeImports, includeWellKnownTypes)
+	execPlugin := func(ctx context.Context) (*pluginpb.CodeGeneratorResponse, error) {
+		if len(pluginConfig.Plugin) > 0 {
+			return g.execRemotePluginV2(ctx, container, image, pluginConfig, includeImports, includeWellKnownTypes)
+		}
+		return g.execRemotePluginV1(ctx, container, image, pluginConfig, includeImports, includeWellKnownTypes)
+	}
+	if generateCache == nil {
+		return execPlugin(ctx)
 	}
+	cacheKey, ok, err := remotePluginGenerateCacheKey(pluginConfig, image, includeImports, includeWellKnownTypes)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return execPlugin(ctx)
+	}
+	return g.execPluginWithCache(ctx, generateCache, cacheKey, pluginConfig, execPlugin)
+}
+
+func (g *generator) execRemotePluginV1(
+	ctx context.Context,
+	container app.EnvStdioContainer,
+	image bufimage.Image,
+	pluginConfig *PluginConfig,
+	includeImports bool,
+	includeWellKnownTypes bool,
+) (*pluginpb.CodeGeneratorResponse, error) {
 	remote, owner, name, version, err := bufremoteplugin.ParsePluginVersionPath(pluginConfig.Remote)
 	if err != nil {
 		return nil, fmt.Errorf("invalid plugin path: %w", err)
@@ -411,13 +542,32 @@ func newModifier(
 	managedConfig *ManagedConfig,
 	sweeper bufimagemodify.Sweeper,
 ) (bufimagemodify.Modifier, error) {
+	rules := managedRules(managedConfig.Rules)
 	modifier := bufimagemodify.NewMultiModifier(
-		bufimagemodify.JavaOuterClassname(logger, sweeper, managedConfig.Override[bufimagemodify.JavaOuterClassNameID]),
-		bufimagemodify.ObjcClassPrefix(logger, sweeper, managedConfig.Override[bufimagemodify.ObjcClassPrefixID]),
-		bufimagemodify.CsharpNamespace(logger, sweeper, managedConfig.Override[bufimagemodify.CsharpNamespaceID]),
-		bufimagemodify.PhpNamespace(logger, sweeper, managedConfig.Override[bufimagemodify.PhpNamespaceID]),
-		bufimagemodify.PhpMetadataNamespace(logger, sweeper, managedConfig.Override[bufimagemodify.PhpMetadataNamespaceID]),
-		bufimagemodify.RubyPackage(logger, sweeper, managedConfig.Override[bufimagemodify.RubyPackageID]),
+		rules.withDisabledFilesFiltered(
+			bufimagemodify.JavaOuterClassNameID,
+			bufimagemodify.JavaOuterClassname(logger, sweeper, managedConfig.Override[bufimagemodify.JavaOuterClassNameID]),
+		),
+		rules.withDisabledFilesFiltered(
+			bufimagemodify.ObjcClassPrefixID,
+			bufimagemodify.ObjcClassPrefix(logger, sweeper, managedConfig.Override[bufimagemodify.ObjcClassPrefixID]),
+		),
+		rules.withDisabledFilesFiltered(
+			bufimagemodify.CsharpNamespaceID,
+			bufimagemodify.CsharpNamespace(logger, sweeper, managedConfig.Override[bufimagemodify.CsharpNamespaceID]),
+		),
+		rules.withDisabledFilesFiltered(
+			bufimagemodify.PhpNamespaceID,
+			bufimagemodify.PhpNamespace(logger, sweeper, managedConfig.Override[bufimagemodify.PhpNamespaceID]),
+		),
+		rules.withDisabledFilesFiltered(
+			bufimagemodify.PhpMetadataNamespaceID,
+			bufimagemodify.PhpMetadataNamespace(logger, sweeper, managedConfig.Override[bufimagemodify.PhpMetadataNamespaceID]),
+		),
+		rules.withDisabledFilesFiltered(
+			bufimagemodify.RubyPackageID,
+			bufimagemodify.RubyPackage(logger, sweeper, managedConfig.Override[bufimagemodify.RubyPackageID]),
+		),
 	)
 	javaPackagePrefix := &JavaPackagePrefixConfig{Default: bufimagemodify.DefaultJavaPackagePrefix}
 	if managedConfig.JavaPackagePrefix != nil {
@@ -436,7 +586,7 @@ func newModifier(
 	}
 	modifier = bufimagemodify.Merge(
 		modifier,
-		javaPackageModifier,
+		rules.withDisabledFilesFiltered(bufimagemodify.JavaPackageID, javaPackageModifier),
 	)
 	javaMultipleFilesValue := bufimagemodify.DefaultJavaMultipleFilesValue
 	if managedConfig.JavaMultipleFiles != nil {
@@ -451,7 +601,10 @@ func newModifier(
 	if err != nil {
 		return nil, err
 	}
-	modifier = bufimagemodify.Merge(modifier, javaMultipleFilesModifier)
+	modifier = bufimagemodify.Merge(
+		modifier,
+		rules.withDisabledFilesFiltered(bufimagemodify.JavaMultipleFilesID, javaMultipleFilesModifier),
+	)
 	if managedConfig.CcEnableArenas != nil {
 		ccEnableArenasModifier, err := bufimagemodify.CcEnableArenas(
 			logger,
@@ -462,7 +615,10 @@ func newModifier(
 		if err != nil {
 			return nil, err
 		}
-		modifier = bufimagemodify.Merge(modifier, ccEnableArenasModifier)
+		modifier = bufimagemodify.Merge(
+			modifier,
+			rules.withDisabledFilesFiltered(bufimagemodify.CcEnableArenasID, ccEnableArenasModifier),
+		)
 	}
 	if managedConfig.JavaStringCheckUtf8 != nil {
 		javaStringCheckUtf8, err := bufimagemodify.JavaStringCheckUtf8(
@@ -474,7 +630,10 @@ func newModifier(
 		if err != nil {
 			return nil, err
 		}
-		modifier = bufimagemodify.Merge(modifier, javaStringCheckUtf8)
+		modifier = bufimagemodify.Merge(
+			modifier,
+			rules.withDisabledFilesFiltered(bufimagemodify.JavaStringCheckUtf8ID, javaStringCheckUtf8),
+		)
 	}
 	if managedConfig.OptimizeFor != nil {
 		optimizeFor, err := bufimagemodify.OptimizeFor(
@@ -488,7 +647,7 @@ func newModifier(
 		}
 		modifier = bufimagemodify.Merge(
 			modifier,
-			optimizeFor,
+			rules.withDisabledFilesFiltered(bufimagemodify.OptimizeForID, optimizeFor),
 		)
 	}
 	if managedConfig.GoPackagePrefixConfig != nil {
@@ -505,10 +664,66 @@ func newModifier(
 		}
 		modifier = bufimagemodify.Merge(
 			modifier,
-			goPackageModifier,
+			rules.withDisabledFilesFiltered(bufimagemodify.GoPackageID, goPackageModifier),
+		)
+	}
+	for _, genericServices := range []struct {
+		modifierID  string
+		value       *bool
+		newModifier func(*zap.Logger, bufimagemodify.Sweeper, bool, map[string]string) (bufimagemodify.Modifier, error)
+	}{
+		{bufimagemodify.CcGenericServicesID, managedConfig.CcGenericServices, bufimagemodify.CcGenericServices},
+		{bufimagemodify.JavaGenericServicesID, managedConfig.JavaGenericServices, bufimagemodify.JavaGenericServices},
+		{bufimagemodify.PyGenericServicesID, managedConfig.PyGenericServices, bufimagemodify.PyGenericServices},
+		{bufimagemodify.PhpGenericServicesID, managedConfig.PhpGenericServices, bufimagemodify.PhpGenericServices},
+	} {
+		if genericServices.value == nil {
+			continue
+		}
+		genericServicesModifier, err := genericServices.newModifier(
+			logger,
+			sweeper,
+			*genericServices.value,
+			managedConfig.Override[genericServices.modifierID],
+		)
+		if err != nil {
+			return nil, err
+		}
+		modifier = bufimagemodify.Merge(
+			modifier,
+			rules.withDisabledFilesFiltered(genericServices.modifierID, genericServicesModifier),
+		)
+	}
+	if managedConfig.SwiftPrefix != "" {
+		swiftPrefixModifier := bufimagemodify.SwiftPrefix(
+			logger,
+			sweeper,
+			managedConfig.SwiftPrefix,
+			managedConfig.Override[bufimagemodify.SwiftPrefixID],
+		)
+		modifier = bufimagemodify.Merge(
+			modifier,
+			rules.withDisabledFilesFiltered(bufimagemodify.SwiftPrefixID, swiftPrefixModifier),
+		)
+	}
+	if managedConfig.Jstype != nil {
+		jstypeModifier, err := bufimagemodify.Jstype(
+			logger,
+			sweeper,
+			*managedConfig.Jstype,
+			managedConfig.Override[bufimagemodify.JstypeID],
+		)
+		if err != nil {
+			return nil, err
+		}
+		modifier = bufimagemodify.Merge(
+			modifier,
+			rules.withDisabledFilesFiltered(bufimagemodify.JstypeID, jstypeModifier),
 		)
 	}
-	return modifier, nil
+	// The rules are applied last so that they take precedence over all
+	// of the other settings.
+	return bufimagemodify.Merge(modifier, rules.newModifier(sweeper)), nil
 }
 
 // validateResponses verifies that a response is set for each of the
@@ -546,6 +761,8 @@ type generateOptions struct {
 	baseOutDirPath        string
 	includeImports        bool
 	includeWellKnownTypes bool
+	maxParallelPlugins    int
+	cacheDirPath          string
 }
 
 func newGenerateOptions() *generateOptions {
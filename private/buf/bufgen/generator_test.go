@@ -0,0 +1,109 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/verbose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestExecPluginWithTimeout(t *testing.T) {
+	t.Parallel()
+	buffer := bytes.NewBuffer(nil)
+	generator := newGenerator(zap.NewNop(), verbose.NewWritePrinter(buffer, ""), nil, nil, nil)
+	pluginConfig := &PluginConfig{
+		Name:    "go",
+		Out:     "gen/go",
+		Timeout: time.Minute,
+	}
+	expectedResponse := &pluginpb.CodeGeneratorResponse{}
+	response, err := generator.execPluginWithTimeout(
+		context.Background(),
+		pluginConfig,
+		func(ctx context.Context) (*pluginpb.CodeGeneratorResponse, error) {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			return expectedResponse, nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, expectedResponse, response)
+	assert.Contains(t, buffer.String(), "plugin go for gen/go took")
+
+	pluginConfig.Timeout = time.Millisecond
+	_, err = generator.execPluginWithTimeout(
+		context.Background(),
+		pluginConfig,
+		func(ctx context.Context) (*pluginpb.CodeGeneratorResponse, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	)
+	require.EqualError(t, err, "plugin go timed out after 1ms")
+
+	pluginErr := errors.New("plugin failed")
+	pluginConfig.Timeout = 0
+	_, err = generator.execPluginWithTimeout(
+		context.Background(),
+		pluginConfig,
+		func(ctx context.Context) (*pluginpb.CodeGeneratorResponse, error) {
+			_, ok := ctx.Deadline()
+			assert.False(t, ok)
+			return nil, pluginErr
+		},
+	)
+	require.Equal(t, pluginErr, err)
+}
+
+func TestExecPluginWithCacheWriteError(t *testing.T) {
+	t.Parallel()
+	generator := newGenerator(zap.NewNop(), verbose.NopPrinter, nil, nil, nil)
+	generateCache := newGenerateCache(zap.NewNop(), &failingPutReadWriteBucket{ReadWriteBucket: storagemem.NewReadWriteBucket()}, defaultGenerateCacheMaxSize)
+	expectedResponse := &pluginpb.CodeGeneratorResponse{}
+	// The response of the plugin is returned even if it cannot be cached.
+	response, err := generator.execPluginWithCache(
+		context.Background(),
+		generateCache,
+		newGenerateCacheKeyBuilder().key(),
+		&PluginConfig{
+			Name: "go",
+			Out:  "gen/go",
+		},
+		func(ctx context.Context) (*pluginpb.CodeGeneratorResponse, error) {
+			return expectedResponse, nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, expectedResponse, response)
+}
+
+type failingPutReadWriteBucket struct {
+	storage.ReadWriteBucket
+}
+
+func (*failingPutReadWriteBucket) Put(context.Context, string) (storage.WriteObjectCloser, error) {
+	return nil, errors.New("read-only")
+}
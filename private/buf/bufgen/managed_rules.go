@@ -0,0 +1,465 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/gen/data/datawkt"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	goPackagePrefixOptionName   = "go_package_prefix"
+	javaPackagePrefixOptionName = "java_package_prefix"
+
+	unsetExplainValue = "(unset)"
+)
+
+// managedOption is an option managed by managed mode.
+type managedOption struct {
+	// name is the name of the option.
+	name       string
+	modifierID string
+	// fieldOption is true if the option is a field option instead of a file option.
+	fieldOption bool
+}
+
+// managedOptions are the options managed by managed mode, in the order of the
+// fields of descriptorpb.FileOptions, followed by the field options.
+var managedOptions = []managedOption{
+	{name: "java_package", modifierID: bufimagemodify.JavaPackageID},
+	{name: "java_outer_classname", modifierID: bufimagemodify.JavaOuterClassNameID},
+	{name: "java_multiple_files", modifierID: bufimagemodify.JavaMultipleFilesID},
+	{name: "java_string_check_utf8", modifierID: bufimagemodify.JavaStringCheckUtf8ID},
+	{name: "optimize_for", modifierID: bufimagemodify.OptimizeForID},
+	{name: "go_package", modifierID: bufimagemodify.GoPackageID},
+	{name: "cc_generic_services", modifierID: bufimagemodify.CcGenericServicesID},
+	{name: "java_generic_services", modifierID: bufimagemodify.JavaGenericServicesID},
+	{name: "py_generic_services", modifierID: bufimagemodify.PyGenericServicesID},
+	{name: "php_generic_services", modifierID: bufimagemodify.PhpGenericServicesID},
+	{name: "cc_enable_arenas", modifierID: bufimagemodify.CcEnableArenasID},
+	{name: "objc_class_prefix", modifierID: bufimagemodify.ObjcClassPrefixID},
+	{name: "csharp_namespace", modifierID: bufimagemodify.CsharpNamespaceID},
+	{name: "swift_prefix", modifierID: bufimagemodify.SwiftPrefixID},
+	{name: "php_namespace", modifierID: bufimagemodify.PhpNamespaceID},
+	{name: "php_metadata_namespace", modifierID: bufimagemodify.PhpMetadataNamespaceID},
+	{name: "ruby_package", modifierID: bufimagemodify.RubyPackageID},
+	{name: "jstype", modifierID: bufimagemodify.JstypeID, fieldOption: true},
+}
+
+// managedRuleOptionNames returns the sorted option names that can be used in rules.
+func managedRuleOptionNames() []string {
+	optionNames := []string{goPackagePrefixOptionName, javaPackagePrefixOptionName}
+	for _, managedOption := range managedOptions {
+		optionNames = append(optionNames, managedOption.name)
+	}
+	sort.Strings(optionNames)
+	return optionNames
+}
+
+// managedRuleOptionModifierID returns the modifier ID for the option name of a rule,
+// and whether the value of the rule is a prefix.
+func managedRuleOptionModifierID(optionName string) (string, bool, bool) {
+	switch optionName {
+	case goPackagePrefixOptionName:
+		return bufimagemodify.GoPackageID, true, true
+	case javaPackagePrefixOptionName:
+		return bufimagemodify.JavaPackageID, true, true
+	}
+	for _, managedOption := range managedOptions {
+		if managedOption.name == optionName {
+			return managedOption.modifierID, false, true
+		}
+	}
+	return "", false, false
+}
+
+// validateManagedRuleValue validates the value of a rule for the modifier ID.
+func validateManagedRuleValue(modifierID string, value string) error {
+	switch modifierID {
+	case bufimagemodify.CcEnableArenasID,
+		bufimagemodify.JavaMultipleFilesID,
+		bufimagemodify.JavaStringCheckUtf8ID,
+		bufimagemodify.CcGenericServicesID,
+		bufimagemodify.JavaGenericServicesID,
+		bufimagemodify.PyGenericServicesID,
+		bufimagemodify.PhpGenericServicesID:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid boolean value %q", value)
+		}
+	case bufimagemodify.OptimizeForID:
+		if _, ok := descriptorpb.FileOptions_OptimizeMode_value[value]; !ok {
+			return fmt.Errorf(
+				"invalid optimize_for value %q; expected one of %v",
+				value,
+				enumMapToStringSlice(descriptorpb.FileOptions_OptimizeMode_value),
+			)
+		}
+	case bufimagemodify.JstypeID:
+		if _, ok := descriptorpb.FieldOptions_JSType_value[value]; !ok {
+			return fmt.Errorf(
+				"invalid jstype value %q; expected one of %v",
+				value,
+				enumMapToStringSlice(descriptorpb.FieldOptions_JSType_value),
+			)
+		}
+	}
+	return nil
+}
+
+// validateManagedRulePath validates the path glob of a rule.
+func validateManagedRulePath(pathGlob string) error {
+	for _, component := range strings.Split(pathGlob, "/") {
+		if _, err := path.Match(component, ""); err != nil {
+			return fmt.Errorf("invalid path glob %q: %w", pathGlob, err)
+		}
+	}
+	return nil
+}
+
+// managedRules are the rules of a ManagedConfig.
+type managedRules []*ManagedRule
+
+// match returns the last rule for the modifier ID that matches the file, along
+// with its number, starting at 1.
+//
+// Returns nil if no rule matches the file.
+func (r managedRules) match(imageFile bufimage.ImageFile, modifierID string) (*ManagedRule, int) {
+	for i := len(r) - 1; i >= 0; i-- {
+		if r[i].ModifierID == modifierID && managedRuleMatches(r[i], imageFile) {
+			return r[i], i + 1
+		}
+	}
+	return nil, 0
+}
+
+// withDisabledFilesFiltered returns the modifier for the modifier ID such that it
+// does not modify the files that are disabled by a rule.
+func (r managedRules) withDisabledFilesFiltered(modifierID string, modifier bufimagemodify.Modifier) bufimagemodify.Modifier {
+	if len(r) == 0 {
+		return modifier
+	}
+	return bufimagemodify.ModifierWithFileFilter(
+		modifier,
+		func(imageFile bufimage.ImageFile) bool {
+			rule, _ := r.match(imageFile, modifierID)
+			return rule == nil || !rule.Disable
+		},
+	)
+}
+
+// newModifier returns a modifier that sets the file options to the values of the
+// rules that match each file.
+//
+// Returns nil if there are no rules.
+func (r managedRules) newModifier(sweeper bufimagemodify.Sweeper) bufimagemodify.Modifier {
+	if len(r) == 0 {
+		return nil
+	}
+	return bufimagemodify.ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			for _, managedOption := range managedOptions {
+				values := make(map[string]string)
+				for _, imageFile := range image.Files() {
+					rule, _ := r.match(imageFile, managedOption.modifierID)
+					if rule == nil || rule.Disable {
+						continue
+					}
+					values[imageFile.Path()] = managedRuleValueForFile(rule, imageFile)
+				}
+				if len(values) == 0 {
+					continue
+				}
+				modifier, err := bufimagemodify.FileOptionValues(sweeper, managedOption.modifierID, values)
+				if err != nil {
+					return err
+				}
+				if err := modifier.Modify(ctx, image); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// managedRuleValueForFile returns the value of the file option set by the rule.
+func managedRuleValueForFile(rule *ManagedRule, imageFile bufimage.ImageFile) string {
+	if !rule.Prefix {
+		return rule.Value
+	}
+	if rule.ModifierID == bufimagemodify.GoPackageID {
+		return bufimagemodify.GoPackageImportPathForFile(imageFile, rule.Value)
+	}
+	return bufimagemodify.JavaPackageValueForFile(imageFile, rule.Value)
+}
+
+// managedRuleMatches returns true if all of the matchers of the rule match the file.
+func managedRuleMatches(rule *ManagedRule, imageFile bufimage.ImageFile) bool {
+	if rule.Module != nil && !moduleIdentitiesContainFile([]bufmoduleref.ModuleIdentity{rule.Module}, imageFile) {
+		return false
+	}
+	if rule.PackagePrefix != "" {
+		pkg := imageFile.Proto().GetPackage()
+		if pkg != rule.PackagePrefix && !strings.HasPrefix(pkg, rule.PackagePrefix+".") {
+			return false
+		}
+	}
+	if rule.Path != "" {
+		patternComponents := strings.Split(rule.Path, "/")
+		pathComponents := strings.Split(imageFile.Path(), "/")
+		// The glob matches the file if it matches the path of the file,
+		// or the path of one of its parent directories.
+		for i := len(pathComponents); i > 0; i-- {
+			if pathGlobMatches(patternComponents, pathComponents[:i]) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// pathGlobMatches returns true if the components of the path match the components
+// of the pattern. Each component is matched with path.Match, and the "**" component
+// matches any number of components.
+func pathGlobMatches(patternComponents []string, pathComponents []string) bool {
+	if len(patternComponents) == 0 {
+		return len(pathComponents) == 0
+	}
+	if patternComponents[0] == "**" {
+		for i := 0; i <= len(pathComponents); i++ {
+			if pathGlobMatches(patternComponents[1:], pathComponents[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(pathComponents) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(patternComponents[0], pathComponents[0]); !matched {
+		return false
+	}
+	return pathGlobMatches(patternComponents[1:], pathComponents[1:])
+}
+
+func explainManaged(
+	ctx context.Context,
+	logger *zap.Logger,
+	config *Config,
+	image bufimage.Image,
+	writer io.Writer,
+) error {
+	if config.ManagedConfig == nil {
+		return nil
+	}
+	if err := modifyImage(ctx, logger, config, image); err != nil {
+		return err
+	}
+	imageFiles := bufimage.ImageWithoutImports(image).Files()
+	sort.Slice(imageFiles, func(i int, j int) bool { return imageFiles[i].Path() < imageFiles[j].Path() })
+	tabWriter := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tabWriter, "Path\tOption\tValue\tDecided by"); err != nil {
+		return err
+	}
+	for _, imageFile := range imageFiles {
+		for _, managedOption := range managedOptions {
+			if managedOption.fieldOption {
+				// Field options are set on the fields of the file, so there
+				// is no single value to explain for the file.
+				continue
+			}
+			source, ok := explainManagedOptionSource(config.ManagedConfig, imageFile, managedOption.modifierID)
+			if !ok {
+				continue
+			}
+			if _, err := fmt.Fprintf(
+				tabWriter,
+				"%s\t%s\t%s\t%s\n",
+				imageFile.Path(),
+				managedOption.name,
+				fileOptionValueString(imageFile.Proto().GetOptions(), managedOption.name),
+				source,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return tabWriter.Flush()
+}
+
+// explainManagedOptionSource returns the setting that decided the value of the file
+// option of the modifier ID for the file.
+//
+// Returns false if the file option is not managed for the file.
+func explainManagedOptionSource(
+	managedConfig *ManagedConfig,
+	imageFile bufimage.ImageFile,
+	modifierID string,
+) (string, bool) {
+	if datawkt.Exists(imageFile.Path()) {
+		return "well-known type", true
+	}
+	if rule, ruleNumber := managedRules(managedConfig.Rules).match(imageFile, modifierID); rule != nil {
+		if rule.Disable {
+			return fmt.Sprintf("rule %d (disable)", ruleNumber), true
+		}
+		return fmt.Sprintf("rule %d", ruleNumber), true
+	}
+	switch modifierID {
+	case bufimagemodify.CcEnableArenasID:
+		if managedConfig.CcEnableArenas == nil {
+			return "", false
+		}
+	case bufimagemodify.JavaStringCheckUtf8ID:
+		if managedConfig.JavaStringCheckUtf8 == nil {
+			return "", false
+		}
+	case bufimagemodify.OptimizeForID:
+		if managedConfig.OptimizeFor == nil {
+			return "", false
+		}
+	case bufimagemodify.CcGenericServicesID:
+		if managedConfig.CcGenericServices == nil {
+			return "", false
+		}
+	case bufimagemodify.JavaGenericServicesID:
+		if managedConfig.JavaGenericServices == nil {
+			return "", false
+		}
+	case bufimagemodify.PyGenericServicesID:
+		if managedConfig.PyGenericServices == nil {
+			return "", false
+		}
+	case bufimagemodify.PhpGenericServicesID:
+		if managedConfig.PhpGenericServices == nil {
+			return "", false
+		}
+	case bufimagemodify.SwiftPrefixID:
+		if managedConfig.SwiftPrefix == "" {
+			return "", false
+		}
+	case bufimagemodify.GoPackageID:
+		if managedConfig.GoPackagePrefixConfig == nil {
+			return "", false
+		}
+		if moduleIdentitiesContainFile(managedConfig.GoPackagePrefixConfig.Except, imageFile) {
+			return goPackagePrefixOptionName + ".except", true
+		}
+	case bufimagemodify.JavaPackageID:
+		if managedConfig.JavaPackagePrefix != nil && moduleIdentitiesContainFile(managedConfig.JavaPackagePrefix.Except, imageFile) {
+			return javaPackagePrefixOptionName + ".except", true
+		}
+	}
+	if _, ok := managedConfig.Override[modifierID][imageFile.Path()]; ok {
+		return "override", true
+	}
+	switch modifierID {
+	case bufimagemodify.GoPackageID:
+		return explainPackagePrefixSource(goPackagePrefixOptionName, managedConfig.GoPackagePrefixConfig.Override, imageFile), true
+	case bufimagemodify.JavaPackageID:
+		if managedConfig.JavaPackagePrefix != nil {
+			return explainPackagePrefixSource(javaPackagePrefixOptionName, managedConfig.JavaPackagePrefix.Override, imageFile), true
+		}
+	case bufimagemodify.CcEnableArenasID:
+		return "cc_enable_arenas", true
+	case bufimagemodify.JavaMultipleFilesID:
+		if managedConfig.JavaMultipleFiles != nil {
+			return "java_multiple_files", true
+		}
+	case bufimagemodify.JavaStringCheckUtf8ID:
+		return "java_string_check_utf8", true
+	case bufimagemodify.OptimizeForID:
+		return "optimize_for", true
+	case bufimagemodify.CcGenericServicesID:
+		return "cc_generic_services", true
+	case bufimagemodify.JavaGenericServicesID:
+		return "java_generic_services", true
+	case bufimagemodify.PyGenericServicesID:
+		return "py_generic_services", true
+	case bufimagemodify.PhpGenericServicesID:
+		return "php_generic_services", true
+	case bufimagemodify.SwiftPrefixID:
+		return "swift_prefix", true
+	}
+	return "default", true
+}
+
+// explainPackagePrefixSource returns the setting of the package prefix option
+// that decided the prefix for the file.
+func explainPackagePrefixSource(
+	optionName string,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
+	imageFile bufimage.ImageFile,
+) string {
+	for moduleIdentity := range moduleOverrides {
+		if moduleIdentitiesContainFile([]bufmoduleref.ModuleIdentity{moduleIdentity}, imageFile) {
+			return optionName + ".override"
+		}
+	}
+	return optionName + ".default"
+}
+
+// moduleIdentitiesContainFile returns true if the module of the file is one of
+// the module identities.
+func moduleIdentitiesContainFile(moduleIdentities []bufmoduleref.ModuleIdentity, imageFile bufimage.ImageFile) bool {
+	fileModuleIdentity := imageFile.ModuleIdentity()
+	if fileModuleIdentity == nil {
+		return false
+	}
+	for _, moduleIdentity := range moduleIdentities {
+		if moduleIdentity.IdentityString() == fileModuleIdentity.IdentityString() {
+			return true
+		}
+	}
+	return false
+}
+
+// fileOptionValueString returns the value of the file option with the given name,
+// formatted for explanations.
+func fileOptionValueString(options *descriptorpb.FileOptions, name string) string {
+	if options == nil {
+		return unsetExplainValue
+	}
+	message := options.ProtoReflect()
+	field := message.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if field == nil || !message.Has(field) {
+		return unsetExplainValue
+	}
+	value := message.Get(field)
+	switch field.Kind() {
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+	case protoreflect.StringKind:
+		return strconv.Quote(value.String())
+	}
+	return value.String()
+}
@@ -0,0 +1,163 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestPathGlobMatches(t *testing.T) {
+	t.Parallel()
+	testPathGlobMatches(t, "a/v1/a.proto", "a/v1/a.proto", true)
+	testPathGlobMatches(t, "a", "a/v1/a.proto", true)
+	testPathGlobMatches(t, "a/*", "a/v1/a.proto", true)
+	testPathGlobMatches(t, "a/*/*.proto", "a/v1/a.proto", true)
+	testPathGlobMatches(t, "a/**", "a/v1/a.proto", true)
+	testPathGlobMatches(t, "**/a.proto", "a/v1/a.proto", true)
+	testPathGlobMatches(t, "**/v1", "a/v1/a.proto", true)
+	testPathGlobMatches(t, "a/**/a.proto", "a/a.proto", true)
+	testPathGlobMatches(t, "b", "a/v1/a.proto", false)
+	testPathGlobMatches(t, "a/v1/b.proto", "a/v1/a.proto", false)
+	testPathGlobMatches(t, "*.proto", "a/v1/a.proto", false)
+	testPathGlobMatches(t, "a/v", "a/v1/a.proto", false)
+}
+
+func TestManagedRules(t *testing.T) {
+	t.Parallel()
+	moduleIdentity, err := bufmoduleref.ModuleIdentityForString("buf.build/acme/weather")
+	require.NoError(t, err)
+	otherModuleIdentity, err := bufmoduleref.ModuleIdentityForString("buf.build/acme/other")
+	require.NoError(t, err)
+	trueValue := true
+	config := &Config{
+		ManagedConfig: &ManagedConfig{
+			GoPackagePrefixConfig: &GoPackagePrefixConfig{
+				Default: "github.com/acme/gen/go",
+				Except:  []bufmoduleref.ModuleIdentity{otherModuleIdentity},
+			},
+			JavaGenericServices: &trueValue,
+			Rules: []*ManagedRule{
+				{
+					Option:     "go_package_prefix",
+					ModifierID: bufimagemodify.GoPackageID,
+					Prefix:     true,
+					Module:     moduleIdentity,
+					Value:      "github.com/acme/weather/gen/go",
+				},
+				{
+					Option:        "csharp_namespace",
+					ModifierID:    bufimagemodify.CsharpNamespaceID,
+					PackagePrefix: "acme",
+					Value:         "Acme",
+				},
+				{
+					Option:        "csharp_namespace",
+					ModifierID:    bufimagemodify.CsharpNamespaceID,
+					PackagePrefix: "acme.other",
+					Disable:       true,
+				},
+				{
+					Option:     "go_package",
+					ModifierID: bufimagemodify.GoPackageID,
+					Path:       "other/**",
+					Value:      "github.com/acme/other",
+				},
+				{
+					Option:        "swift_prefix",
+					ModifierID:    bufimagemodify.SwiftPrefixID,
+					PackagePrefix: "acme",
+					Value:         "ACME",
+				},
+				{
+					Option:     "java_generic_services",
+					ModifierID: bufimagemodify.JavaGenericServicesID,
+					Path:       "local",
+					Disable:    true,
+				},
+			},
+		},
+	}
+	image := testNewManagedRulesImage(
+		t,
+		testNewManagedRulesImageFile(t, "weather/v1/weather.proto", "acme.weather.v1", moduleIdentity),
+		testNewManagedRulesImageFile(t, "other/v1/other.proto", "acme.other.v1", otherModuleIdentity),
+		testNewManagedRulesImageFile(t, "local/v1/local.proto", "local.v1", nil),
+	)
+	err = modifyImage(context.Background(), zap.NewNop(), config, image)
+	require.NoError(t, err)
+
+	weatherOptions := image.GetFile("weather/v1/weather.proto").Proto().GetOptions()
+	assert.Equal(t, "github.com/acme/weather/gen/go/weather/v1;weatherv1", weatherOptions.GetGoPackage())
+	assert.Equal(t, "Acme", weatherOptions.GetCsharpNamespace())
+	assert.Equal(t, "ACME", weatherOptions.GetSwiftPrefix())
+	assert.True(t, weatherOptions.GetJavaGenericServices())
+	otherOptions := image.GetFile("other/v1/other.proto").Proto().GetOptions()
+	// Rules take precedence over the exceptions of go_package_prefix.
+	assert.Equal(t, "github.com/acme/other", otherOptions.GetGoPackage())
+	assert.Nil(t, otherOptions.CsharpNamespace)
+	localOptions := image.GetFile("local/v1/local.proto").Proto().GetOptions()
+	assert.Equal(t, "github.com/acme/gen/go/local/v1;localv1", localOptions.GetGoPackage())
+	assert.Equal(t, "Local.V1", localOptions.GetCsharpNamespace())
+	assert.Nil(t, localOptions.SwiftPrefix)
+	assert.Nil(t, localOptions.JavaGenericServices)
+}
+
+func testPathGlobMatches(t *testing.T, pattern string, path string, expected bool) {
+	rule := &ManagedRule{Path: pattern}
+	imageFile := testNewManagedRulesImageFile(t, path, "", nil)
+	assert.Equal(t, expected, managedRuleMatches(rule, imageFile), "%s %s", pattern, path)
+}
+
+func testNewManagedRulesImage(t *testing.T, imageFiles ...bufimage.ImageFile) bufimage.Image {
+	image, err := bufimage.NewImage(imageFiles)
+	require.NoError(t, err)
+	return image
+}
+
+func testNewManagedRulesImageFile(
+	t *testing.T,
+	path string,
+	pkg string,
+	moduleIdentity bufmoduleref.ModuleIdentity,
+) bufimage.ImageFile {
+	fileDescriptorProto := &descriptorpb.FileDescriptorProto{
+		Name:   proto.String(path),
+		Syntax: proto.String("proto3"),
+	}
+	if pkg != "" {
+		fileDescriptorProto.Package = proto.String(pkg)
+	}
+	imageFile, err := bufimage.NewImageFile(
+		fileDescriptorProto,
+		moduleIdentity,
+		"",
+		path,
+		false,
+		false,
+		nil,
+	)
+	require.NoError(t, err)
+	return imageFile
+}
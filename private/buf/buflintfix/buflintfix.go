@@ -0,0 +1,57 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package buflintfix applies the mechanical fixes for lint violations to source files.
+package buflintfix
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// Fix applies the Fixes to the source files in the ReadBucket, and returns a ReadBucket
+// that contains every file of the given ReadBucket after the Fixes were applied.
+//
+// The ReadBucket must contain the source of every non-import file of the Image, at the
+// same paths, and the Image must contain every file that these files depend on.
+//
+// Files that are modified are printed with the same printer as buf format. Files that
+// are renamed are placed at their new path, with their external path updated to match.
+// Files that are not modified are returned as-is.
+//
+// Renamed fields keep their JSON name, as a json_name option is added to every renamed
+// field whose default JSON name changes.
+//
+// A rename is only applied if every file that can refer to the renamed element is in
+// the ReadBucket, so that all references are renamed with it. Fixes that cannot be
+// applied for this reason are skipped and logged as warnings. If the fixed files do
+// not compile, for example because a rename would collide with an existing name,
+// an error is returned and no Fixes are applied.
+func Fix(
+	ctx context.Context,
+	logger *zap.Logger,
+	readBucket storage.ReadBucket,
+	image bufimage.Image,
+	fixes []*buflint.Fix,
+) (storage.ReadBucket, error) {
+	fixer, err := newFixer(logger, image)
+	if err != nil {
+		return nil, err
+	}
+	return fixer.fix(ctx, readBucket, fixes)
+}
@@ -0,0 +1,428 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintfix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFix(t *testing.T) {
+	t.Parallel()
+	readBucket, image := testBuild(
+		t,
+		map[string][]byte{
+			"a/FooBar.proto": []byte(`syntax = "proto2";
+
+package a;
+
+import "a/unused.proto";
+
+enum Color {
+  red = 0;
+}
+
+message Msg {
+  optional Color fooBar = 1 [default = red];
+}
+
+service Greeter {}
+`),
+			"a/unused.proto": []byte(`syntax = "proto2";
+
+package a;
+`),
+			"b/b.proto": []byte(`syntax = "proto2";
+
+package b;
+
+import "a/FooBar.proto";
+
+message B {
+  optional a.Color color = 1 [default = red];
+}
+`),
+		},
+	)
+	fixedReadBucket, err := Fix(
+		context.Background(),
+		zaptest.NewLogger(t),
+		readBucket,
+		image,
+		[]*buflint.Fix{
+			{
+				Type:    buflint.FixTypeRenameField,
+				Path:    "a/FooBar.proto",
+				Element: "a.Msg.fooBar",
+				NewName: "foo_bar",
+			},
+			{
+				Type:    buflint.FixTypeRenameEnumValue,
+				Path:    "a/FooBar.proto",
+				Element: "a.Color.red",
+				NewName: "COLOR_RED_UNSPECIFIED",
+			},
+			{
+				Type:    buflint.FixTypeRenameService,
+				Path:    "a/FooBar.proto",
+				Element: "a.Greeter",
+				NewName: "GreeterService",
+			},
+			{
+				Type:    buflint.FixTypeRemoveImport,
+				Path:    "a/FooBar.proto",
+				Element: "a/unused.proto",
+			},
+			{
+				Type:    buflint.FixTypeRenameFile,
+				Path:    "a/FooBar.proto",
+				NewName: "a/foo_bar.proto",
+			},
+		},
+	)
+	require.NoError(t, err)
+	paths, err := storage.AllPaths(context.Background(), fixedReadBucket, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/foo_bar.proto", "a/unused.proto", "b/b.proto"}, paths)
+	testAssertPath(
+		t,
+		fixedReadBucket,
+		"a/foo_bar.proto",
+		`syntax = "proto2";
+
+package a;
+
+enum Color {
+  COLOR_RED_UNSPECIFIED = 0;
+}
+
+message Msg {
+  optional Color foo_bar = 1 [default = COLOR_RED_UNSPECIFIED];
+}
+
+service GreeterService {}
+`,
+	)
+	testAssertPath(
+		t,
+		fixedReadBucket,
+		"b/b.proto",
+		`syntax = "proto2";
+
+package b;
+
+import "a/foo_bar.proto";
+
+message B {
+  optional a.Color color = 1 [default = COLOR_RED_UNSPECIFIED];
+}
+`,
+	)
+}
+
+func TestFixKeepsJSONNames(t *testing.T) {
+	t.Parallel()
+	readBucket, image := testBuild(
+		t,
+		map[string][]byte{
+			"a.proto": []byte(`syntax = "proto3";
+
+package a;
+
+message A {
+  string FooBar = 1;
+  string Foo_Baz = 2 [deprecated = true];
+  string FooQux = 3 [json_name = "qux"];
+  map<string, string> FooMap = 4;
+  string fooBarBaz = 5;
+}
+`),
+		},
+	)
+	var fixes []*buflint.Fix
+	for _, name := range []string{"FooBar", "Foo_Baz", "FooQux", "FooMap", "fooBarBaz"} {
+		fixes = append(
+			fixes,
+			&buflint.Fix{
+				Type:    buflint.FixTypeRenameField,
+				Path:    "a.proto",
+				Element: "a.A." + name,
+				NewName: stringutil.ToLowerSnakeCase(name),
+			},
+		)
+	}
+	fixedReadBucket, err := Fix(
+		context.Background(),
+		zaptest.NewLogger(t),
+		readBucket,
+		image,
+		fixes,
+	)
+	require.NoError(t, err)
+	testAssertPath(
+		t,
+		fixedReadBucket,
+		"a.proto",
+		`syntax = "proto3";
+
+package a;
+
+message A {
+  string foo_bar = 1 [json_name = "FooBar"];
+  string foo_baz = 2 [
+    deprecated = true,
+    json_name = "FooBaz"
+  ];
+  string foo_qux = 3 [json_name = "qux"];
+  map<string, string> foo_map = 4 [json_name = "FooMap"];
+  string foo_bar_baz = 5;
+}
+`,
+	)
+}
+
+func TestFixRenamesOnlyOptionValuesOfRenamedEnum(t *testing.T) {
+	t.Parallel()
+	readBucket, image := testBuild(
+		t,
+		map[string][]byte{
+			"a.proto": []byte(`syntax = "proto2";
+
+package a;
+
+import "google/protobuf/descriptor.proto";
+
+option (opts) = {
+  color: red
+  shades: [red]
+};
+
+enum Color {
+  red = 0;
+}
+
+message M {
+  enum Shade {
+    COLOR_RED_UNSPECIFIED = 0;
+    red = 1;
+  }
+}
+
+message Opts {
+  optional Color color = 1;
+  repeated M.Shade shades = 2;
+}
+
+message N {
+  option (color) = red;
+  option (shade) = red;
+  optional string s = 1 [(field_shade) = red];
+}
+
+extend google.protobuf.FileOptions {
+  optional Opts opts = 50000;
+}
+
+extend google.protobuf.MessageOptions {
+  optional Color color = 50001;
+  optional M.Shade shade = 50002;
+}
+
+extend google.protobuf.FieldOptions {
+  optional M.Shade field_shade = 50003;
+}
+`),
+		},
+	)
+	fixedReadBucket, err := Fix(
+		context.Background(),
+		zaptest.NewLogger(t),
+		readBucket,
+		image,
+		[]*buflint.Fix{
+			{
+				Type:    buflint.FixTypeRenameEnumValue,
+				Path:    "a.proto",
+				Element: "a.Color.red",
+				NewName: "COLOR_RED_UNSPECIFIED",
+			},
+		},
+	)
+	require.NoError(t, err)
+	testAssertPath(
+		t,
+		fixedReadBucket,
+		"a.proto",
+		`syntax = "proto2";
+
+package a;
+
+import "google/protobuf/descriptor.proto";
+
+option (opts) = {
+  color: COLOR_RED_UNSPECIFIED
+  shades: [red]
+};
+
+enum Color {
+  COLOR_RED_UNSPECIFIED = 0;
+}
+
+message M {
+  enum Shade {
+    COLOR_RED_UNSPECIFIED = 0;
+    red = 1;
+  }
+}
+
+message Opts {
+  optional Color color = 1;
+  repeated M.Shade shades = 2;
+}
+
+message N {
+  option (color) = COLOR_RED_UNSPECIFIED;
+  option (shade) = red;
+  optional string s = 1 [(field_shade) = red];
+}
+
+extend google.protobuf.FileOptions {
+  optional Opts opts = 50000;
+}
+
+extend google.protobuf.MessageOptions {
+  optional Color color = 50001;
+  optional M.Shade shade = 50002;
+}
+
+extend google.protobuf.FieldOptions {
+  optional M.Shade field_shade = 50003;
+}
+`,
+	)
+}
+
+func TestFixSkipsRenameWithImporterNotInBucket(t *testing.T) {
+	t.Parallel()
+	readBucket, image := testBuild(
+		t,
+		map[string][]byte{
+			"a.proto": []byte(`syntax = "proto3";
+
+package a;
+
+message A {
+  string fooBar = 1;
+}
+`),
+			"b.proto": []byte(`syntax = "proto3";
+
+package a;
+
+import "a.proto";
+
+message B {
+  A a = 1;
+}
+`),
+		},
+	)
+	sourceReadBucket := storage.MapReadBucket(readBucket, storage.MatchPathEqual("a.proto"))
+	fixedReadBucket, err := Fix(
+		context.Background(),
+		zaptest.NewLogger(t),
+		sourceReadBucket,
+		image,
+		[]*buflint.Fix{
+			{
+				Type:    buflint.FixTypeRenameField,
+				Path:    "a.proto",
+				Element: "a.A.fooBar",
+				NewName: "foo_bar",
+			},
+		},
+	)
+	require.NoError(t, err)
+	data, err := storage.ReadPath(context.Background(), readBucket, "a.proto")
+	require.NoError(t, err)
+	testAssertPath(t, fixedReadBucket, "a.proto", string(data))
+}
+
+func TestFixDoesNotCompile(t *testing.T) {
+	t.Parallel()
+	readBucket, image := testBuild(
+		t,
+		map[string][]byte{
+			"a.proto": []byte(`syntax = "proto2";
+
+package a;
+
+enum E {
+  a = 0;
+  A = 1;
+}
+`),
+		},
+	)
+	_, err := Fix(
+		context.Background(),
+		zaptest.NewLogger(t),
+		readBucket,
+		image,
+		[]*buflint.Fix{
+			{
+				Type:    buflint.FixTypeRenameEnumValue,
+				Path:    "a.proto",
+				Element: "a.E.a",
+				NewName: "A",
+			},
+		},
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no fixes were applied")
+}
+
+func testBuild(t *testing.T, pathToData map[string][]byte) (storage.ReadBucket, bufimage.Image) {
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(pathToData)
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForBucket(ctx, readBucket)
+	require.NoError(t, err)
+	image, fileAnnotations, err := bufimagebuild.NewBuilder(zaptest.NewLogger(t)).Build(
+		ctx,
+		bufmodule.NewModuleFileSet(module, nil),
+		bufimagebuild.WithExcludeSourceCodeInfo(),
+	)
+	require.NoError(t, err)
+	require.Empty(t, fileAnnotations)
+	return readBucket, image
+}
+
+func testAssertPath(t *testing.T, readBucket storage.ReadBucket, path string, expected string) {
+	data, err := storage.ReadPath(context.Background(), readBucket, path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
@@ -0,0 +1,780 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintfix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/buf/bufformat"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/ast"
+	"github.com/bufbuild/protocompile/parser"
+	"github.com/bufbuild/protocompile/reporter"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type fixer struct {
+	logger *zap.Logger
+	image  bufimage.Image
+	// The descriptors of the image, used to resolve the types of option values.
+	files *protoregistry.Files
+
+	// The paths of the files that import a given file, including the files that
+	// import it through a public import.
+	pathToImporters map[string]map[string]struct{}
+	// The fully-qualified names of enum fields to the fully-qualified names of their enums.
+	fieldToEnum map[string]string
+
+	pathToNewPath        map[string]string
+	pathToRemovedImports map[string]map[string]struct{}
+	fieldToNewName       map[string]string
+	serviceToNewName     map[string]string
+	enumValueToNewName   map[string]string
+	enumToValueToNewName map[string]map[string]string
+}
+
+func newFixer(logger *zap.Logger, image bufimage.Image) (*fixer, error) {
+	files, err := protodesc.NewFiles(bufimage.ImageToFileDescriptorSet(image))
+	if err != nil {
+		return nil, err
+	}
+	return &fixer{
+		logger:               logger,
+		image:                image,
+		files:                files,
+		pathToImporters:      getPathToImporters(image),
+		fieldToEnum:          getFieldToEnum(image),
+		pathToNewPath:        make(map[string]string),
+		pathToRemovedImports: make(map[string]map[string]struct{}),
+		fieldToNewName:       make(map[string]string),
+		serviceToNewName:     make(map[string]string),
+		enumValueToNewName:   make(map[string]string),
+		enumToValueToNewName: make(map[string]map[string]string),
+	}, nil
+}
+
+func (f *fixer) fix(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	fixes []*buflint.Fix,
+) (storage.ReadBucket, error) {
+	paths, err := storage.AllPaths(ctx, readBucket, "")
+	if err != nil {
+		return nil, err
+	}
+	sourcePaths := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		sourcePaths[path] = struct{}{}
+	}
+	for _, fix := range fixes {
+		if err := f.addFix(fix, sourcePaths); err != nil {
+			f.logger.Sugar().Warnf("cannot %s: %v", fix.String(), err)
+		}
+	}
+	pathToData := make(map[string][]byte, len(paths))
+	pathToExternalPath := make(map[string]string, len(paths))
+	for _, path := range paths {
+		objectInfo, err := readBucket.Stat(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		data, err := storage.ReadPath(ctx, readBucket, path)
+		if err != nil {
+			return nil, err
+		}
+		fixedData, err := f.fixFile(path, data)
+		if err != nil {
+			return nil, err
+		}
+		newPath := path
+		externalPath := objectInfo.ExternalPath()
+		if renamedPath, ok := f.pathToNewPath[path]; ok {
+			newPath = renamedPath
+			externalPath = strings.TrimSuffix(externalPath, normalpath.Base(path)) + normalpath.Base(newPath)
+		}
+		pathToData[newPath] = fixedData
+		pathToExternalPath[newPath] = externalPath
+	}
+	if err := f.verify(ctx, sourcePaths, pathToData); err != nil {
+		return nil, err
+	}
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	for path, data := range pathToData {
+		if err := putPathWithExternalPath(ctx, readWriteBucket, path, pathToExternalPath[path], data); err != nil {
+			return nil, err
+		}
+	}
+	return readWriteBucket, nil
+}
+
+// addFix records the Fix, or returns an error if it cannot be applied.
+func (f *fixer) addFix(fix *buflint.Fix, sourcePaths map[string]struct{}) error {
+	if _, ok := sourcePaths[fix.Path]; !ok {
+		return fmt.Errorf("%s is not a source file that can be rewritten", fix.Path)
+	}
+	if fix.Type != buflint.FixTypeRemoveImport {
+		for _, importer := range stringutil.MapToSortedSlice(f.pathToImporters[fix.Path]) {
+			if _, ok := sourcePaths[importer]; !ok {
+				return fmt.Errorf("%s imports %s and is not a source file that can be rewritten", importer, fix.Path)
+			}
+		}
+	}
+	switch fix.Type {
+	case buflint.FixTypeRenameField:
+		f.fieldToNewName[fix.Element] = fix.NewName
+	case buflint.FixTypeRenameEnumValue:
+		oldName := shortNameForElement(fix.Element)
+		enum := strings.TrimSuffix(fix.Element, "."+oldName)
+		valueToNewName, ok := f.enumToValueToNewName[enum]
+		if !ok {
+			valueToNewName = make(map[string]string)
+			f.enumToValueToNewName[enum] = valueToNewName
+		}
+		valueToNewName[oldName] = fix.NewName
+		f.enumValueToNewName[fix.Element] = fix.NewName
+	case buflint.FixTypeRenameService:
+		f.serviceToNewName[fix.Element] = fix.NewName
+	case buflint.FixTypeRemoveImport:
+		removedImports, ok := f.pathToRemovedImports[fix.Path]
+		if !ok {
+			removedImports = make(map[string]struct{})
+			f.pathToRemovedImports[fix.Path] = removedImports
+		}
+		removedImports[fix.Element] = struct{}{}
+	case buflint.FixTypeRenameFile:
+		if f.image.GetFile(fix.NewName) != nil {
+			return fmt.Errorf("%s already exists", fix.NewName)
+		}
+		for _, newPath := range f.pathToNewPath {
+			if newPath == fix.NewName {
+				return fmt.Errorf("another file is already renamed to %s", fix.NewName)
+			}
+		}
+		f.pathToNewPath[fix.Path] = fix.NewName
+	default:
+		return fmt.Errorf("unknown fix type: %v", fix.Type)
+	}
+	return nil
+}
+
+// fixFile applies the recorded fixes to the file.
+//
+// The data is returned unmodified if no fix applies to the file.
+func (f *fixer) fixFile(path string, data []byte) ([]byte, error) {
+	fileNode, err := parser.Parse(path, bytes.NewReader(data), reporter.NewHandler(nil))
+	if err != nil {
+		return nil, err
+	}
+	// The printer writes string literals as they appear in the source to
+	// preserve their quoting, so renamed imports and the JSON names of renamed
+	// fields are written to the source before we modify the AST.
+	if editedData, err := f.editSource(fileNode, data); err != nil {
+		return nil, err
+	} else if editedData != nil {
+		data = editedData
+		fileNode, err = parser.Parse(path, bytes.NewReader(data), reporter.NewHandler(nil))
+		if err != nil {
+			return nil, err
+		}
+	}
+	modified := f.removeImports(path, fileNode)
+	renamed, err := f.renameElements(fileNode)
+	if err != nil {
+		return nil, err
+	}
+	if !modified && !renamed {
+		return data, nil
+	}
+	buffer := bytes.NewBuffer(nil)
+	if err := bufformat.FormatFileNode(buffer, fileNode); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// editSource returns the data with the imports of renamed files replaced, and the
+// JSON names of renamed fields added, or nil if there is nothing to edit.
+func (f *fixer) editSource(fileNode *ast.FileNode, data []byte) ([]byte, error) {
+	jsonNameEdits, err := f.getJSONNameEdits(fileNode)
+	if err != nil {
+		return nil, err
+	}
+	edits := append(f.getRenameImportEdits(fileNode), jsonNameEdits...)
+	if len(edits) == 0 {
+		return nil, nil
+	}
+	sort.Slice(edits, func(i int, j int) bool { return edits[i].start < edits[j].start })
+	buffer := bytes.NewBuffer(nil)
+	last := 0
+	for _, edit := range edits {
+		_, _ = buffer.Write(data[last:edit.start])
+		_, _ = buffer.WriteString(edit.text)
+		last = edit.end
+	}
+	_, _ = buffer.Write(data[last:])
+	return buffer.Bytes(), nil
+}
+
+// getRenameImportEdits returns the edits that replace the imports of renamed files.
+func (f *fixer) getRenameImportEdits(fileNode *ast.FileNode) []*sourceEdit {
+	var edits []*sourceEdit
+	for _, fileElement := range fileNode.Decls {
+		importNode, ok := fileElement.(*ast.ImportNode)
+		if !ok {
+			continue
+		}
+		newPath, ok := f.pathToNewPath[importNode.Name.AsString()]
+		if !ok {
+			continue
+		}
+		nodeInfo := fileNode.NodeInfo(importNode.Name)
+		start := nodeInfo.Start().Offset
+		edits = append(
+			edits,
+			&sourceEdit{
+				start: start,
+				end:   start + len(nodeInfo.RawText()),
+				text:  strconv.Quote(newPath),
+			},
+		)
+	}
+	return edits
+}
+
+// getJSONNameEdits returns the edits that add the json_name option to the renamed fields
+// whose JSON name would otherwise change, so that the JSON encoding of the messages
+// stays the same.
+func (f *fixer) getJSONNameEdits(fileNode *ast.FileNode) ([]*sourceEdit, error) {
+	var scope []string
+	for _, fileElement := range fileNode.Decls {
+		if packageNode, ok := fileElement.(*ast.PackageNode); ok {
+			scope = append(scope, string(packageNode.Name.AsIdentifier()))
+		}
+	}
+	var edits []*sourceEdit
+	addEdit := func(name *ast.IdentNode, options *ast.CompactOptionsNode, semicolon *ast.RuneNode) {
+		fullName := strings.Join(append(scope[:len(scope):len(scope)], name.Val), ".")
+		newName, ok := f.fieldToNewName[fullName]
+		if !ok || hasJSONNameOption(options) {
+			return
+		}
+		descriptor, err := f.files.FindDescriptorByName(protoreflect.FullName(fullName))
+		if err != nil {
+			return
+		}
+		fieldDescriptor, ok := descriptor.(protoreflect.FieldDescriptor)
+		if !ok || fieldDescriptor.JSONName() == jsonCamelCase(newName) {
+			return
+		}
+		jsonNameOption := "json_name = " + strconv.Quote(fieldDescriptor.JSONName())
+		if options != nil {
+			start := fileNode.NodeInfo(options.CloseBracket).Start().Offset
+			edits = append(edits, &sourceEdit{start: start, end: start, text: ", " + jsonNameOption})
+			return
+		}
+		start := fileNode.NodeInfo(semicolon).Start().Offset
+		edits = append(edits, &sourceEdit{start: start, end: start, text: " [" + jsonNameOption + "]"})
+	}
+	before := func(node ast.Node) error {
+		switch node := node.(type) {
+		case *ast.MessageNode:
+			scope = append(scope, node.Name.Val)
+		case *ast.GroupNode:
+			scope = append(scope, node.Name.Val)
+		case *ast.FieldNode:
+			// The json_name option is not allowed on extensions.
+			if node.Extendee == nil {
+				addEdit(node.Name, node.Options, node.Semicolon)
+			}
+		case *ast.MapFieldNode:
+			addEdit(node.Name, node.Options, node.Semicolon)
+		}
+		return nil
+	}
+	after := func(node ast.Node) error {
+		switch node.(type) {
+		case *ast.MessageNode, *ast.GroupNode:
+			if len(scope) == 0 {
+				// Unreachable.
+				return errors.New("internal error: unbalanced scope when adding JSON names")
+			}
+			scope = scope[:len(scope)-1]
+		}
+		return nil
+	}
+	if err := ast.Walk(fileNode, ast.NoOpVisitor{}, ast.WithBefore(before), ast.WithAfter(after)); err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
+
+// removeImports removes the unused imports of the file, and returns true if any
+// import was removed.
+func (f *fixer) removeImports(path string, fileNode *ast.FileNode) bool {
+	removedImports := f.pathToRemovedImports[path]
+	if len(removedImports) == 0 {
+		return false
+	}
+	decls := make([]ast.FileElement, 0, len(fileNode.Decls))
+	for _, fileElement := range fileNode.Decls {
+		if importNode, ok := fileElement.(*ast.ImportNode); ok {
+			if _, ok := removedImports[importNode.Name.AsString()]; ok {
+				continue
+			}
+		}
+		decls = append(decls, fileElement)
+	}
+	removed := len(decls) != len(fileNode.Decls)
+	fileNode.Decls = decls
+	return removed
+}
+
+// renameElements renames the declarations of renamed elements in the file, and
+// the references to renamed enum values in option values.
+//
+// Returns true if anything was renamed.
+func (f *fixer) renameElements(fileNode *ast.FileNode) (bool, error) {
+	var scope []string
+	for _, fileElement := range fileNode.Decls {
+		if packageNode, ok := fileElement.(*ast.PackageNode); ok {
+			scope = append(scope, string(packageNode.Name.AsIdentifier()))
+		}
+	}
+	fullNameFor := func(name string) string {
+		return strings.Join(append(scope[:len(scope):len(scope)], name), ".")
+	}
+	// The ancestors of the visited node.
+	var parents []ast.Node
+	renamed := false
+	rename := func(identNode *ast.IdentNode, newName string) {
+		if newName != "" && identNode.Val != newName {
+			identNode.Val = newName
+			renamed = true
+		}
+	}
+	before := func(node ast.Node) error {
+		switch node := node.(type) {
+		case *ast.MessageNode:
+			scope = append(scope, node.Name.Val)
+		case *ast.GroupNode:
+			scope = append(scope, node.Name.Val)
+		case *ast.EnumNode:
+			scope = append(scope, node.Name.Val)
+		case *ast.FieldNode:
+			fullName := fullNameFor(node.Name.Val)
+			rename(node.Name, f.fieldToNewName[fullName])
+			if enum, ok := f.fieldToEnum[fullName]; ok && node.Options != nil {
+				for _, optionNode := range node.Options.Options {
+					if !isDefaultOption(optionNode) {
+						continue
+					}
+					if identNode, ok := optionNode.Val.(*ast.IdentNode); ok {
+						rename(identNode, f.enumToValueToNewName[enum][identNode.Val])
+					}
+				}
+			}
+		case *ast.MapFieldNode:
+			rename(node.Name, f.fieldToNewName[fullNameFor(node.Name.Val)])
+		case *ast.EnumValueNode:
+			rename(node.Name, f.enumValueToNewName[fullNameFor(node.Name.Val)])
+		case *ast.ServiceNode:
+			rename(node.Name, f.serviceToNewName[fullNameFor(node.Name.Val)])
+		case *ast.OptionNode:
+			if !isDefaultOption(node) {
+				if fieldDescriptor := f.resolveOptionField(scope, parents, node); fieldDescriptor != nil {
+					f.renameEnumValueReferences(scope, fieldDescriptor, node.Val, rename)
+				}
+			}
+		}
+		parents = append(parents, node)
+		return nil
+	}
+	after := func(node ast.Node) error {
+		if len(parents) == 0 {
+			// Unreachable.
+			return errors.New("internal error: unbalanced parents when renaming elements")
+		}
+		parents = parents[:len(parents)-1]
+		switch node.(type) {
+		case *ast.MessageNode, *ast.GroupNode, *ast.EnumNode:
+			if len(scope) == 0 {
+				// Unreachable.
+				return errors.New("internal error: unbalanced scope when renaming elements")
+			}
+			scope = scope[:len(scope)-1]
+		}
+		return nil
+	}
+	if err := ast.Walk(fileNode, ast.NoOpVisitor{}, ast.WithBefore(before), ast.WithAfter(after)); err != nil {
+		return false, err
+	}
+	return renamed, nil
+}
+
+// resolveOptionField returns the descriptor of the field that the option sets, or nil if
+// it cannot be resolved.
+//
+// The parents are the ancestors of the option node, and the scope is the fully-qualified
+// name of the innermost message, or the package, that the option appears in.
+func (f *fixer) resolveOptionField(scope []string, parents []ast.Node, optionNode *ast.OptionNode) protoreflect.FieldDescriptor {
+	if optionNode.Name == nil || len(parents) == 0 {
+		return nil
+	}
+	parent := parents[len(parents)-1]
+	_, compact := parent.(*ast.CompactOptionsNode)
+	if compact {
+		if len(parents) < 2 {
+			return nil
+		}
+		parent = parents[len(parents)-2]
+	}
+	messageDescriptor := getOptionsMessageDescriptor(parent, compact)
+	if messageDescriptor == nil {
+		return nil
+	}
+	var fieldDescriptor protoreflect.FieldDescriptor
+	for i, part := range optionNode.Name.Parts {
+		if i > 0 {
+			messageDescriptor = fieldDescriptor.Message()
+			if messageDescriptor == nil {
+				return nil
+			}
+		}
+		fieldDescriptor = f.resolveField(scope, messageDescriptor, part)
+		if fieldDescriptor == nil {
+			return nil
+		}
+	}
+	return fieldDescriptor
+}
+
+// resolveField returns the descriptor of the field of the message that the field reference
+// refers to, or nil if it cannot be resolved.
+//
+// Extension names are resolved relative to the scope, from the innermost scope outwards.
+func (f *fixer) resolveField(
+	scope []string,
+	messageDescriptor protoreflect.MessageDescriptor,
+	fieldReferenceNode *ast.FieldReferenceNode,
+) protoreflect.FieldDescriptor {
+	name := string(fieldReferenceNode.Name.AsIdentifier())
+	if !fieldReferenceNode.IsExtension() {
+		return messageDescriptor.Fields().ByName(protoreflect.Name(name))
+	}
+	if strings.HasPrefix(name, ".") {
+		return f.findExtension(messageDescriptor, strings.TrimPrefix(name, "."))
+	}
+	for i := len(scope); i >= 0; i-- {
+		if fieldDescriptor := f.findExtension(messageDescriptor, strings.Join(append(scope[:i:i], name), ".")); fieldDescriptor != nil {
+			return fieldDescriptor
+		}
+	}
+	return nil
+}
+
+// findExtension returns the extension of the message with the fully-qualified name, or nil
+// if there is no such extension.
+func (f *fixer) findExtension(messageDescriptor protoreflect.MessageDescriptor, fullName string) protoreflect.FieldDescriptor {
+	descriptor, err := f.files.FindDescriptorByName(protoreflect.FullName(fullName))
+	if err != nil {
+		return nil
+	}
+	fieldDescriptor, ok := descriptor.(protoreflect.FieldDescriptor)
+	if !ok || !fieldDescriptor.IsExtension() || fieldDescriptor.ContainingMessage().FullName() != messageDescriptor.FullName() {
+		return nil
+	}
+	return fieldDescriptor
+}
+
+// renameEnumValueReferences renames the identifiers within the value of the field that
+// refer to renamed enum values.
+//
+// Only identifiers that are values of the enum of a renamed value are renamed, so
+// that values with the same name in other enums are left as-is.
+func (f *fixer) renameEnumValueReferences(
+	scope []string,
+	fieldDescriptor protoreflect.FieldDescriptor,
+	valueNode ast.ValueNode,
+	rename func(*ast.IdentNode, string),
+) {
+	switch valueNode := valueNode.(type) {
+	case *ast.IdentNode:
+		if enumDescriptor := fieldDescriptor.Enum(); enumDescriptor != nil {
+			rename(valueNode, f.enumToValueToNewName[string(enumDescriptor.FullName())][valueNode.Val])
+		}
+	case *ast.ArrayLiteralNode:
+		for _, element := range valueNode.Elements {
+			f.renameEnumValueReferences(scope, fieldDescriptor, element, rename)
+		}
+	case *ast.MessageLiteralNode:
+		messageDescriptor := fieldDescriptor.Message()
+		if messageDescriptor == nil {
+			return
+		}
+		for _, element := range valueNode.Elements {
+			if elementFieldDescriptor := f.resolveField(scope, messageDescriptor, element.Name); elementFieldDescriptor != nil {
+				f.renameEnumValueReferences(scope, elementFieldDescriptor, element.Val, rename)
+			}
+		}
+	}
+}
+
+// verify verifies that the fixed files compile.
+//
+// Files that are not source files are resolved from the image.
+func (f *fixer) verify(
+	ctx context.Context,
+	sourcePaths map[string]struct{},
+	pathToData map[string][]byte,
+) error {
+	resolver := protocompile.ResolverFunc(
+		func(path string) (protocompile.SearchResult, error) {
+			if data, ok := pathToData[path]; ok {
+				return protocompile.SearchResult{Source: bytes.NewReader(data)}, nil
+			}
+			if _, ok := sourcePaths[path]; !ok {
+				if imageFile := f.image.GetFile(path); imageFile != nil {
+					return protocompile.SearchResult{Proto: imageFile.Proto()}, nil
+				}
+			}
+			return protocompile.SearchResult{}, fmt.Errorf("%s: file does not exist", path)
+		},
+	)
+	paths := make([]string, 0, len(pathToData))
+	for path := range pathToData {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	compiler := protocompile.Compiler{
+		Resolver: resolver,
+	}
+	if _, err := compiler.Compile(ctx, paths...); err != nil {
+		return fmt.Errorf("the fixed files do not compile, no fixes were applied: %w", err)
+	}
+	return nil
+}
+
+func putPathWithExternalPath(
+	ctx context.Context,
+	writeBucket storage.WriteBucket,
+	path string,
+	externalPath string,
+	data []byte,
+) (retErr error) {
+	writeObjectCloser, err := writeBucket.Put(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, writeObjectCloser.Close())
+	}()
+	if _, err := writeObjectCloser.Write(data); err != nil {
+		return err
+	}
+	return writeObjectCloser.SetExternalPath(externalPath)
+}
+
+// sourceEdit replaces the source between the start and end offsets with the text.
+type sourceEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+func hasJSONNameOption(compactOptionsNode *ast.CompactOptionsNode) bool {
+	if compactOptionsNode == nil {
+		return false
+	}
+	for _, optionNode := range compactOptionsNode.Options {
+		if optionNode.Name == nil || len(optionNode.Name.Parts) != 1 {
+			continue
+		}
+		part := optionNode.Name.Parts[0]
+		if !part.IsExtension() && string(part.Name.AsIdentifier()) == "json_name" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonCamelCase returns the default JSON name of a field with the given name, as
+// computed by protoc.
+func jsonCamelCase(name string) string {
+	var builder strings.Builder
+	upperNext := false
+	for _, r := range name {
+		if r == '_' {
+			upperNext = true
+			continue
+		}
+		if upperNext && 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upperNext = false
+		_, _ = builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+func isDefaultOption(optionNode *ast.OptionNode) bool {
+	if optionNode.Name == nil || len(optionNode.Name.Parts) != 1 {
+		return false
+	}
+	part := optionNode.Name.Parts[0]
+	return !part.IsExtension() && string(part.Name.AsIdentifier()) == "default"
+}
+
+// getOptionsMessageDescriptor returns the descriptor of the options message of the
+// element, or nil if the element has no options.
+//
+// Compact options are the options within brackets, such as the options of fields.
+func getOptionsMessageDescriptor(element ast.Node, compact bool) protoreflect.MessageDescriptor {
+	var optionsMessage proto.Message
+	if compact {
+		switch element.(type) {
+		case *ast.FieldNode, *ast.MapFieldNode, *ast.GroupNode:
+			optionsMessage = &descriptorpb.FieldOptions{}
+		case *ast.EnumValueNode:
+			optionsMessage = &descriptorpb.EnumValueOptions{}
+		case *ast.ExtensionRangeNode:
+			optionsMessage = &descriptorpb.ExtensionRangeOptions{}
+		}
+	} else {
+		switch element.(type) {
+		case *ast.FileNode:
+			optionsMessage = &descriptorpb.FileOptions{}
+		case *ast.MessageNode, *ast.GroupNode:
+			optionsMessage = &descriptorpb.MessageOptions{}
+		case *ast.OneOfNode:
+			optionsMessage = &descriptorpb.OneofOptions{}
+		case *ast.EnumNode:
+			optionsMessage = &descriptorpb.EnumOptions{}
+		case *ast.ServiceNode:
+			optionsMessage = &descriptorpb.ServiceOptions{}
+		case *ast.RPCNode:
+			optionsMessage = &descriptorpb.MethodOptions{}
+		}
+	}
+	if optionsMessage == nil {
+		return nil
+	}
+	return optionsMessage.ProtoReflect().Descriptor()
+}
+
+func shortNameForElement(element string) string {
+	if index := strings.LastIndex(element, "."); index >= 0 {
+		return element[index+1:]
+	}
+	return element
+}
+
+func getPathToImporters(image bufimage.Image) map[string]map[string]struct{} {
+	pathToDirectImporters := make(map[string][]string)
+	pathToPublicImporters := make(map[string][]string)
+	for _, imageFile := range image.Files() {
+		fileDescriptorProto := imageFile.Proto()
+		for _, dependency := range fileDescriptorProto.GetDependency() {
+			pathToDirectImporters[dependency] = append(pathToDirectImporters[dependency], imageFile.Path())
+		}
+		for _, index := range fileDescriptorProto.GetPublicDependency() {
+			if int(index) < len(fileDescriptorProto.GetDependency()) {
+				dependency := fileDescriptorProto.GetDependency()[index]
+				pathToPublicImporters[dependency] = append(pathToPublicImporters[dependency], imageFile.Path())
+			}
+		}
+	}
+	pathToImporters := make(map[string]map[string]struct{})
+	for _, imageFile := range image.Files() {
+		importers := make(map[string]struct{})
+		addImporters(imageFile.Path(), pathToDirectImporters, pathToPublicImporters, importers, make(map[string]struct{}))
+		pathToImporters[imageFile.Path()] = importers
+	}
+	return pathToImporters
+}
+
+// addImporters adds the direct importers of the path, and the importers of every file
+// that publicly imports the path, as these can refer to the elements of the path too.
+func addImporters(
+	path string,
+	pathToDirectImporters map[string][]string,
+	pathToPublicImporters map[string][]string,
+	importers map[string]struct{},
+	seen map[string]struct{},
+) {
+	if _, ok := seen[path]; ok {
+		return
+	}
+	seen[path] = struct{}{}
+	for _, importer := range pathToDirectImporters[path] {
+		importers[importer] = struct{}{}
+	}
+	for _, publicImporter := range pathToPublicImporters[path] {
+		addImporters(publicImporter, pathToDirectImporters, pathToPublicImporters, importers, seen)
+	}
+}
+
+func getFieldToEnum(image bufimage.Image) map[string]string {
+	fieldToEnum := make(map[string]string)
+	for _, imageFile := range image.Files() {
+		fileDescriptorProto := imageFile.Proto()
+		addFieldToEnum(fieldToEnum, fileDescriptorProto.GetPackage(), fileDescriptorProto.GetExtension())
+		for _, descriptorProto := range fileDescriptorProto.GetMessageType() {
+			addMessageFieldToEnum(fieldToEnum, fileDescriptorProto.GetPackage(), descriptorProto)
+		}
+	}
+	return fieldToEnum
+}
+
+func addMessageFieldToEnum(fieldToEnum map[string]string, scope string, descriptorProto *descriptorpb.DescriptorProto) {
+	scope = joinFullName(scope, descriptorProto.GetName())
+	addFieldToEnum(fieldToEnum, scope, descriptorProto.GetField())
+	addFieldToEnum(fieldToEnum, scope, descriptorProto.GetExtension())
+	for _, nestedDescriptorProto := range descriptorProto.GetNestedType() {
+		addMessageFieldToEnum(fieldToEnum, scope, nestedDescriptorProto)
+	}
+}
+
+func addFieldToEnum(fieldToEnum map[string]string, scope string, fieldDescriptorProtos []*descriptorpb.FieldDescriptorProto) {
+	for _, fieldDescriptorProto := range fieldDescriptorProtos {
+		if fieldDescriptorProto.GetType() == descriptorpb.FieldDescriptorProto_TYPE_ENUM {
+			fieldToEnum[joinFullName(scope, fieldDescriptorProto.GetName())] = strings.TrimPrefix(fieldDescriptorProto.GetTypeName(), ".")
+		}
+	}
+}
+
+func joinFullName(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
@@ -197,6 +197,7 @@ func (m *v1beta1Migrator) maybeMigrateConfig(dirPath string) (bool, error) {
 				Use:                    v1beta1Config.Breaking.Use,
 				Except:                 v1beta1Config.Breaking.Except,
 				IgnoreUnstablePackages: v1beta1Config.Breaking.IgnoreUnstablePackages,
+				CustomOptions:          v1beta1Config.Breaking.CustomOptions,
 			},
 			Lint: buflintconfig.ExternalConfigV1{
 				Use:                                  v1beta1Config.Lint.Use,
@@ -207,6 +208,7 @@ func (m *v1beta1Migrator) maybeMigrateConfig(dirPath string) (bool, error) {
 				RPCAllowGoogleProtobufEmptyRequests:  v1beta1Config.Lint.RPCAllowGoogleProtobufEmptyRequests,
 				RPCAllowGoogleProtobufEmptyResponses: v1beta1Config.Lint.RPCAllowGoogleProtobufEmptyResponses,
 				AllowCommentIgnores:                  v1beta1Config.Lint.AllowCommentIgnores,
+				Plugins:                              v1beta1Config.Lint.Plugins,
 			},
 		}
 
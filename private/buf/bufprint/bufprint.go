@@ -20,6 +20,7 @@ import (
 	"io"
 	"strconv"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulecache"
 	"github.com/bufbuild/buf/private/gen/proto/apiclient/buf/alpha/registry/v1alpha1/registryv1alpha1apiclient"
 	registryv1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/registry/v1alpha1"
 	"github.com/bufbuild/buf/private/pkg/protoencoding"
@@ -181,6 +182,16 @@ func NewTemplateVersionPrinter(writer io.Writer) TemplateVersionPrinter {
 	return newTemplateVersionPrinter(writer)
 }
 
+// ModuleCacheEntryPrinter is a printer for the entries of the module cache.
+type ModuleCacheEntryPrinter interface {
+	PrintModuleCacheEntries(ctx context.Context, format Format, cacheEntries ...bufmodulecache.CacheEntry) error
+}
+
+// NewModuleCacheEntryPrinter returns a new ModuleCacheEntryPrinter.
+func NewModuleCacheEntryPrinter(writer io.Writer) ModuleCacheEntryPrinter {
+	return newModuleCacheEntryPrinter(writer)
+}
+
 // TokenPrinter is a printer Tokens.
 //
 // TODO: update to same format as other printers.
@@ -0,0 +1,106 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufprint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulecache"
+	"github.com/bufbuild/buf/private/pkg/bytesize"
+)
+
+type moduleCacheEntryPrinter struct {
+	writer io.Writer
+}
+
+func newModuleCacheEntryPrinter(writer io.Writer) *moduleCacheEntryPrinter {
+	return &moduleCacheEntryPrinter{
+		writer: writer,
+	}
+}
+
+func (p *moduleCacheEntryPrinter) PrintModuleCacheEntries(ctx context.Context, format Format, cacheEntries ...bufmodulecache.CacheEntry) error {
+	switch format {
+	case FormatText:
+		return p.printModuleCacheEntriesText(cacheEntries)
+	case FormatJSON:
+		// Print one JSON object per line so that the output can be streamed.
+		encoder := json.NewEncoder(p.writer)
+		for _, cacheEntry := range cacheEntries {
+			if err := encoder.Encode(cacheEntryToOutputModuleCacheEntry(cacheEntry)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %v", format)
+	}
+}
+
+func (p *moduleCacheEntryPrinter) printModuleCacheEntriesText(cacheEntries []bufmodulecache.CacheEntry) error {
+	if len(cacheEntries) == 0 {
+		return nil
+	}
+	return WithTabWriter(
+		p.writer,
+		[]string{
+			"Module",
+			"Commit",
+			"Size",
+			"Last used",
+		},
+		func(tabWriter TabWriter) error {
+			for _, cacheEntry := range cacheEntries {
+				lastUsed := "unknown"
+				if !cacheEntry.LastUsed.IsZero() {
+					lastUsed = cacheEntry.LastUsed.Format(time.RFC3339)
+				}
+				if err := tabWriter.Write(
+					cacheEntry.ModulePin.IdentityString(),
+					cacheEntry.ModulePin.Commit(),
+					bytesize.Format(cacheEntry.Size),
+					lastUsed,
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+type outputModuleCacheEntry struct {
+	Module   string     `json:"module,omitempty"`
+	Commit   string     `json:"commit,omitempty"`
+	Size     int64      `json:"size"`
+	LastUsed *time.Time `json:"last_used,omitempty"`
+}
+
+func cacheEntryToOutputModuleCacheEntry(cacheEntry bufmodulecache.CacheEntry) outputModuleCacheEntry {
+	outputModuleCacheEntry := outputModuleCacheEntry{
+		Module: cacheEntry.ModulePin.IdentityString(),
+		Commit: cacheEntry.ModulePin.Commit(),
+		Size:   cacheEntry.Size,
+	}
+	if !cacheEntry.LastUsed.IsZero() {
+		lastUsed := cacheEntry.LastUsed
+		outputModuleCacheEntry.LastUsed = &lastUsed
+	}
+	return outputModuleCacheEntry
+}
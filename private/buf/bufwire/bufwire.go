@@ -209,7 +209,7 @@ func NewImageWriter(
 type ProtoEncodingReader interface {
 	// GetMessage reads the message by the messageRef.
 	//
-	// Currently, this support bin and JSON format.
+	// Stream encodings must contain exactly one message.
 	GetMessage(
 		ctx context.Context,
 		container app.EnvStdinContainer,
@@ -217,6 +217,17 @@ type ProtoEncodingReader interface {
 		typeName string,
 		messageRef bufconvert.MessageEncodingRef,
 	) (proto.Message, error)
+	// NewMessageReader returns a new MessageReader that reads the messages by the messageRef.
+	//
+	// Stream encodings are read one message at a time, so that they do not need to fit
+	// in memory. Other encodings are read as exactly one message.
+	NewMessageReader(
+		ctx context.Context,
+		container app.EnvStdinContainer,
+		image bufimage.Image,
+		typeName string,
+		messageRef bufconvert.MessageEncodingRef,
+	) (MessageReader, error)
 }
 
 // NewProtoEncodingReader returns a new ProtoEncodingReader.
@@ -232,8 +243,6 @@ func NewProtoEncodingReader(
 type ProtoEncodingWriter interface {
 	// PutMessage writes the message to the path, which can be
 	// a path in file system, or stdout represented by "-".
-	//
-	// Currently, this support bin and JSON format.
 	PutMessage(
 		ctx context.Context,
 		container app.EnvStdoutContainer,
@@ -241,6 +250,35 @@ type ProtoEncodingWriter interface {
 		message proto.Message,
 		messageRef bufconvert.MessageEncodingRef,
 	) error
+	// NewMessageWriter returns a new MessageWriter that writes messages to the path
+	// of the messageRef, which can be a path in file system, or stdout represented by "-".
+	//
+	// Stream encodings are written one message at a time. Other encodings only accept
+	// a single message.
+	NewMessageWriter(
+		ctx context.Context,
+		container app.EnvStdoutContainer,
+		image bufimage.Image,
+		messageRef bufconvert.MessageEncodingRef,
+	) (MessageWriter, error)
+}
+
+// MessageReader reads messages one at a time.
+type MessageReader interface {
+	// Next returns the next message.
+	//
+	// Returns io.EOF if there are no more messages.
+	Next() (proto.Message, error)
+	// Close closes the underlying file, if any.
+	Close() error
+}
+
+// MessageWriter writes messages one at a time.
+type MessageWriter interface {
+	// Write writes the message.
+	Write(message proto.Message) error
+	// Close flushes the written messages and closes the underlying file, if any.
+	Close() error
 }
 
 // NewProtoEncodingWriter returns a new ProtoEncodingWriter.
@@ -227,6 +227,7 @@ func (i *imageConfigReader) getImageImageConfig(
 		ctx,
 		readWriteBucket,
 		bufconfig.ReadConfigOSWithOverride(configOverride),
+		bufconfig.ReadConfigOSWithLocalBucket(),
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,155 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufwire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// singleMessageReader reads exactly one message from all of the data of the reader.
+type singleMessageReader struct {
+	readCloser io.ReadCloser
+	newMessage func([]byte) (proto.Message, error)
+	done       bool
+}
+
+func newSingleMessageReader(
+	readCloser io.ReadCloser,
+	newMessage func([]byte) (proto.Message, error),
+) *singleMessageReader {
+	return &singleMessageReader{
+		readCloser: readCloser,
+		newMessage: newMessage,
+	}
+}
+
+func (s *singleMessageReader) Next() (proto.Message, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	s.done = true
+	data, err := io.ReadAll(s.readCloser)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("size of input message must not be zero")
+	}
+	return s.newMessage(data)
+}
+
+func (s *singleMessageReader) Close() error {
+	return s.readCloser.Close()
+}
+
+// streamMessageReader reads size-delimited binary or newline-delimited JSON messages
+// one at a time.
+type streamMessageReader struct {
+	readCloser  io.ReadCloser
+	reader      *bufio.Reader
+	messageType protoreflect.MessageType
+	unmarshaler protoencoding.Unmarshaler
+	jsonl       bool
+	// index is the index of the next message, used for errors.
+	index int
+}
+
+func newStreamMessageReader(
+	readCloser io.ReadCloser,
+	messageType protoreflect.MessageType,
+	unmarshaler protoencoding.Unmarshaler,
+	jsonl bool,
+) *streamMessageReader {
+	return &streamMessageReader{
+		readCloser:  readCloser,
+		reader:      bufio.NewReader(readCloser),
+		messageType: messageType,
+		unmarshaler: unmarshaler,
+		jsonl:       jsonl,
+	}
+}
+
+func (s *streamMessageReader) Next() (proto.Message, error) {
+	var data []byte
+	var err error
+	if s.jsonl {
+		data, err = s.nextLine()
+	} else {
+		data, err = s.nextSizeDelimited()
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := s.index
+	s.index++
+	message := s.messageType.New().Interface()
+	if err := s.unmarshaler.Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal message %d: %v", index, err)
+	}
+	return message, nil
+}
+
+func (s *streamMessageReader) Close() error {
+	return s.readCloser.Close()
+}
+
+// nextLine returns the next non-empty line.
+func (s *streamMessageReader) nextLine() ([]byte, error) {
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, io.EOF
+		}
+	}
+}
+
+// nextSizeDelimited returns the data of the next message prefixed with its size as a varint.
+func (s *streamMessageReader) nextSizeDelimited() ([]byte, error) {
+	size, err := binary.ReadUvarint(s.reader)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("unable to read the size of message %d: %w", s.index, err)
+	}
+	if size > math.MaxInt32 {
+		return nil, fmt.Errorf("size of message %d is too large: %d", s.index, size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(s.reader, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("unable to read message %d: %w", s.index, err)
+	}
+	return data, nil
+}
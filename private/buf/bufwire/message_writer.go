@@ -0,0 +1,107 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufwire
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/proto"
+)
+
+// singleMessageWriter writes exactly one message.
+type singleMessageWriter struct {
+	writeCloser io.WriteCloser
+	marshaler   protoencoding.Marshaler
+	written     bool
+}
+
+func newSingleMessageWriter(
+	writeCloser io.WriteCloser,
+	marshaler protoencoding.Marshaler,
+) *singleMessageWriter {
+	return &singleMessageWriter{
+		writeCloser: writeCloser,
+		marshaler:   marshaler,
+	}
+}
+
+func (s *singleMessageWriter) Write(message proto.Message) error {
+	if s.written {
+		return errors.New("output format only supports a single message, use bindelim or jsonl to write multiple messages")
+	}
+	s.written = true
+	data, err := s.marshaler.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = s.writeCloser.Write(data)
+	return err
+}
+
+func (s *singleMessageWriter) Close() error {
+	return s.writeCloser.Close()
+}
+
+// streamMessageWriter writes size-delimited binary or newline-delimited JSON messages
+// one at a time.
+type streamMessageWriter struct {
+	writeCloser io.WriteCloser
+	writer      *bufio.Writer
+	marshaler   protoencoding.Marshaler
+	jsonl       bool
+}
+
+func newStreamMessageWriter(
+	writeCloser io.WriteCloser,
+	marshaler protoencoding.Marshaler,
+	jsonl bool,
+) *streamMessageWriter {
+	return &streamMessageWriter{
+		writeCloser: writeCloser,
+		writer:      bufio.NewWriter(writeCloser),
+		marshaler:   marshaler,
+		jsonl:       jsonl,
+	}
+}
+
+func (s *streamMessageWriter) Write(message proto.Message) error {
+	data, err := s.marshaler.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if !s.jsonl {
+		sizeData := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(sizeData, uint64(len(data)))
+		if _, err := s.writer.Write(sizeData[:n]); err != nil {
+			return err
+		}
+	}
+	if _, err := s.writer.Write(data); err != nil {
+		return err
+	}
+	if s.jsonl {
+		return s.writer.WriteByte('\n')
+	}
+	return nil
+}
+
+func (s *streamMessageWriter) Close() error {
+	return multierr.Append(s.writer.Flush(), s.writeCloser.Close())
+}
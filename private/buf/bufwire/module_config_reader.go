@@ -26,6 +26,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulevendor"
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/storage"
@@ -69,7 +70,11 @@ func (m *moduleConfigReader) GetModuleConfigs(
 	ctx, span := trace.StartSpan(ctx, "get_module_config")
 	defer span.End()
 	// We construct a new WorkspaceBuilder here so that the cache is only used for a single call.
-	workspaceBuilder := bufwork.NewWorkspaceBuilder(m.moduleBucketBuilder)
+	var workspaceBuilderOptions []bufwork.WorkspaceBuilderOption
+	if sourceRef, ok := sourceOrModuleRef.(buffetch.SourceRef); ok && buffetch.IsLocalSourceRef(sourceRef) {
+		workspaceBuilderOptions = append(workspaceBuilderOptions, bufwork.WorkspaceBuilderWithLocalBucket())
+	}
+	workspaceBuilder := bufwork.NewWorkspaceBuilder(m.moduleBucketBuilder, workspaceBuilderOptions...)
 	switch t := sourceOrModuleRef.(type) {
 	case buffetch.ProtoFileRef:
 		return m.getProtoFileModuleSourceConfigs(
@@ -540,16 +545,22 @@ func (m *moduleConfigReader) getModuleConfig(
 				}
 			}
 		}
-		return newModuleConfig(module, moduleConfig, workspace), nil
+		return newVendoredModuleConfig(ctx, readBucket, subDirPath, module, moduleConfig, workspace)
 	}
 	mappedReadBucket := readBucket
 	if subDirPath != "." {
 		mappedReadBucket = storage.MapReadBucket(readBucket, storage.MapOnPrefix(subDirPath))
 	}
+	readConfigOSOptions := []bufconfig.ReadConfigOSOption{
+		bufconfig.ReadConfigOSWithOverride(configOverride),
+	}
+	if buffetch.IsLocalSourceRef(sourceRef) {
+		readConfigOSOptions = append(readConfigOSOptions, bufconfig.ReadConfigOSWithLocalBucket())
+	}
 	moduleConfig, err := bufconfig.ReadConfigOS(
 		ctx,
 		mappedReadBucket,
-		bufconfig.ReadConfigOSWithOverride(configOverride),
+		readConfigOSOptions...,
 	)
 	if err != nil {
 		return nil, err
@@ -666,6 +677,26 @@ func (m *moduleConfigReader) getModuleConfig(
 	if err != nil {
 		return nil, err
 	}
+	return newVendoredModuleConfig(ctx, readBucket, subDirPath, module, moduleConfig, workspace)
+}
+
+// newVendoredModuleConfig returns a new ModuleConfig whose workspace also contains the
+// dependencies vendored in the directory of the module, if any.
+func newVendoredModuleConfig(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	subDirPath string,
+	module bufmodule.Module,
+	moduleConfig *bufconfig.Config,
+	workspace bufmodule.Workspace,
+) (ModuleConfig, error) {
+	if subDirPath != "." {
+		readBucket = storage.MapReadBucket(readBucket, storage.MapOnPrefix(subDirPath))
+	}
+	workspace, err := bufmodulevendor.NewWorkspace(ctx, readBucket, module, workspace)
+	if err != nil {
+		return nil, err
+	}
 	return newModuleConfig(module, moduleConfig, workspace), nil
 }
 
@@ -55,7 +55,36 @@ func (p *protoEncodingReader) GetMessage(
 ) (_ proto.Message, retErr error) {
 	ctx, span := trace.StartSpan(ctx, "get_message")
 	defer span.End()
-	// Currently, this support bin and JSON format.
+	messageReader, err := p.NewMessageReader(ctx, container, image, typeName, messageRef)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, messageReader.Close())
+	}()
+	message, err := messageReader.Next()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("input must contain a message")
+		}
+		return nil, err
+	}
+	if _, err := messageReader.Next(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("input must contain exactly one message")
+	}
+	return message, nil
+}
+
+func (p *protoEncodingReader) NewMessageReader(
+	ctx context.Context,
+	container app.EnvStdinContainer,
+	image bufimage.Image,
+	typeName string,
+	messageRef bufconvert.MessageEncodingRef,
+) (_ MessageReader, retErr error) {
 	resolver, err := protoencoding.NewResolver(
 		bufimage.ImageToFileDescriptors(
 			image,
@@ -64,12 +93,23 @@ func (p *protoEncodingReader) GetMessage(
 	if err != nil {
 		return nil, err
 	}
+	var jsonUnmarshalerOptions []protoencoding.JSONUnmarshalerOption
+	var textUnmarshalerOptions []protoencoding.TextUnmarshalerOption
+	if messageRef.DiscardUnknown() {
+		textUnmarshalerOptions = append(textUnmarshalerOptions, protoencoding.TextUnmarshalerWithDiscardUnknown())
+	} else {
+		jsonUnmarshalerOptions = append(jsonUnmarshalerOptions, protoencoding.JSONUnmarshalerWithDisallowUnknown())
+	}
 	var unmarshaler protoencoding.Unmarshaler
 	switch messageRef.MessageEncoding() {
-	case bufconvert.MessageEncodingBin:
+	case bufconvert.MessageEncodingBin, bufconvert.MessageEncodingBinDelimited:
 		unmarshaler = protoencoding.NewWireUnmarshaler(resolver)
-	case bufconvert.MessageEncodingJSON:
-		unmarshaler = protoencoding.NewJSONUnmarshaler(resolver)
+	case bufconvert.MessageEncodingJSON, bufconvert.MessageEncodingJSONL:
+		unmarshaler = protoencoding.NewJSONUnmarshaler(resolver, jsonUnmarshalerOptions...)
+	case bufconvert.MessageEncodingTxtpb:
+		unmarshaler = protoencoding.NewTextUnmarshaler(resolver, textUnmarshalerOptions...)
+	case bufconvert.MessageEncodingYAML:
+		unmarshaler = protoencoding.NewYAMLUnmarshaler(resolver, jsonUnmarshalerOptions...)
 	default:
 		return nil, fmt.Errorf("unknown message encoding type")
 	}
@@ -82,21 +122,37 @@ func (p *protoEncodingReader) GetMessage(
 		}
 	}
 	defer func() {
-		retErr = multierr.Append(retErr, readCloser.Close())
+		if retErr != nil {
+			retErr = multierr.Append(retErr, readCloser.Close())
+		}
 	}()
-	data, err := io.ReadAll(readCloser)
-	if err != nil {
-		return nil, err
-	}
-	if len(data) == 0 {
-		return nil, errors.New("size of input message must not be zero")
-	}
-	message, err := bufreflect.NewMessage(ctx, image, typeName)
-	if err != nil {
-		return nil, err
-	}
-	if err := unmarshaler.Unmarshal(data, message); err != nil {
-		return nil, fmt.Errorf("unable to unmarshal the message: %v", err)
+	switch messageRef.MessageEncoding() {
+	case bufconvert.MessageEncodingBinDelimited, bufconvert.MessageEncodingJSONL:
+		// We resolve the type before reading anything, so that an invalid type
+		// fails before any message of the stream is read.
+		message, err := bufreflect.NewMessage(ctx, image, typeName)
+		if err != nil {
+			return nil, err
+		}
+		return newStreamMessageReader(
+			readCloser,
+			message.ProtoReflect().Type(),
+			unmarshaler,
+			messageRef.MessageEncoding() == bufconvert.MessageEncodingJSONL,
+		), nil
+	default:
+		return newSingleMessageReader(
+			readCloser,
+			func(data []byte) (proto.Message, error) {
+				message, err := bufreflect.NewMessage(ctx, image, typeName)
+				if err != nil {
+					return nil, err
+				}
+				if err := unmarshaler.Unmarshal(data, message); err != nil {
+					return nil, fmt.Errorf("unable to unmarshal the message: %v", err)
+				}
+				return message, nil
+			},
+		), nil
 	}
-	return message, nil
 }
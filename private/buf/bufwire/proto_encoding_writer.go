@@ -50,38 +50,72 @@ func (p *protoEncodingWriter) PutMessage(
 	message proto.Message,
 	messageRef bufconvert.MessageEncodingRef,
 ) (retErr error) {
-	// Currently, this support bin and JSON format.
+	messageWriter, err := p.NewMessageWriter(ctx, container, image, messageRef)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, messageWriter.Close())
+	}()
+	return messageWriter.Write(message)
+}
+
+func (p *protoEncodingWriter) NewMessageWriter(
+	ctx context.Context,
+	container app.EnvStdoutContainer,
+	image bufimage.Image,
+	messageRef bufconvert.MessageEncodingRef,
+) (MessageWriter, error) {
 	resolver, err := protoencoding.NewResolver(
 		bufimage.ImageToFileDescriptors(
 			image,
 		)...,
 	)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var jsonMarshalerOptions []protoencoding.JSONMarshalerOption
+	if messageRef.EmitDefaults() {
+		jsonMarshalerOptions = append(jsonMarshalerOptions, protoencoding.JSONMarshalerWithEmitUnpopulated())
+	}
+	if messageRef.UseProtoNames() {
+		jsonMarshalerOptions = append(jsonMarshalerOptions, protoencoding.JSONMarshalerWithUseProtoNames())
+	}
+	if messageRef.UseEnumNumbers() {
+		jsonMarshalerOptions = append(jsonMarshalerOptions, protoencoding.JSONMarshalerWithUseEnumNumbers())
 	}
 	var marshaler protoencoding.Marshaler
 	switch messageRef.MessageEncoding() {
-	case bufconvert.MessageEncodingBin:
+	case bufconvert.MessageEncodingBin, bufconvert.MessageEncodingBinDelimited:
 		marshaler = protoencoding.NewWireMarshaler()
 	case bufconvert.MessageEncodingJSON:
-		marshaler = protoencoding.NewJSONMarshalerIndent(resolver)
+		marshaler = protoencoding.NewJSONMarshaler(
+			resolver,
+			append(jsonMarshalerOptions, protoencoding.JSONMarshalerWithIndent())...,
+		)
+	case bufconvert.MessageEncodingJSONL:
+		// Each message must be on a single line.
+		marshaler = protoencoding.NewJSONMarshaler(resolver, jsonMarshalerOptions...)
+	case bufconvert.MessageEncodingTxtpb:
+		marshaler = protoencoding.NewTextMarshaler(resolver)
+	case bufconvert.MessageEncodingYAML:
+		marshaler = protoencoding.NewYAMLMarshaler(resolver, jsonMarshalerOptions...)
 	default:
-		return fmt.Errorf("unknown message encoding type")
-	}
-	data, err := marshaler.Marshal(message)
-	if err != nil {
-		return err
+		return nil, fmt.Errorf("unknown message encoding type")
 	}
 	writeCloser := ioextended.NopWriteCloser(container.Stdout())
 	if messageRef.Path() != "-" {
 		writeCloser, err = os.Create(messageRef.Path())
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	defer func() {
-		retErr = multierr.Append(retErr, writeCloser.Close())
-	}()
-	_, err = writeCloser.Write(data)
-	return err
+	switch messageRef.MessageEncoding() {
+	case bufconvert.MessageEncodingBinDelimited:
+		return newStreamMessageWriter(writeCloser, marshaler, false), nil
+	case bufconvert.MessageEncodingJSONL:
+		return newStreamMessageWriter(writeCloser, marshaler, true), nil
+	default:
+		return newSingleMessageWriter(writeCloser, marshaler), nil
+	}
 }
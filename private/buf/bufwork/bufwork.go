@@ -119,8 +119,22 @@ type WorkspaceBuilder interface {
 // NewWorkspaceBuilder returns a new WorkspaceBuilder.
 func NewWorkspaceBuilder(
 	moduleBucketBuilder bufmodulebuild.ModuleBucketBuilder,
+	options ...WorkspaceBuilderOption,
 ) WorkspaceBuilder {
-	return newWorkspaceBuilder(moduleBucketBuilder)
+	return newWorkspaceBuilder(moduleBucketBuilder, options...)
+}
+
+// WorkspaceBuilderOption is an option for a new WorkspaceBuilder.
+type WorkspaceBuilderOption func(*workspaceBuilder)
+
+// WorkspaceBuilderWithLocalBucket says that the buckets given to BuildWorkspace are
+// backed by the local file system.
+//
+// See bufconfig.ReadConfigOSWithLocalBucket.
+func WorkspaceBuilderWithLocalBucket() WorkspaceBuilderOption {
+	return func(workspaceBuilder *workspaceBuilder) {
+		workspaceBuilder.localBucket = true
+	}
 }
 
 // BuildOptionsForWorkspaceDirectory returns the bufmodulebuild.BuildOptions required for
@@ -30,15 +30,21 @@ import (
 type workspaceBuilder struct {
 	moduleBucketBuilder bufmodulebuild.ModuleBucketBuilder
 	moduleCache         map[string]*cachedModule
+	localBucket         bool
 }
 
 func newWorkspaceBuilder(
 	moduleBucketBuilder bufmodulebuild.ModuleBucketBuilder,
+	options ...WorkspaceBuilderOption,
 ) *workspaceBuilder {
-	return &workspaceBuilder{
+	workspaceBuilder := &workspaceBuilder{
 		moduleBucketBuilder: moduleBucketBuilder,
 		moduleCache:         make(map[string]*cachedModule),
 	}
+	for _, option := range options {
+		option(workspaceBuilder)
+	}
+	return workspaceBuilder
 }
 
 // BuildWorkspace builds a bufmodule.Workspace for the given targetSubDirPath.
@@ -93,10 +99,16 @@ func (w *workspaceBuilder) BuildWorkspace(
 		if directory != targetSubDirPath {
 			localConfigOverride = ""
 		}
+		readConfigOSOptions := []bufconfig.ReadConfigOSOption{
+			bufconfig.ReadConfigOSWithOverride(localConfigOverride),
+		}
+		if w.localBucket {
+			readConfigOSOptions = append(readConfigOSOptions, bufconfig.ReadConfigOSWithLocalBucket())
+		}
 		moduleConfig, err := bufconfig.ReadConfigOS(
 			ctx,
 			readBucketForDirectory,
-			bufconfig.ReadConfigOSWithOverride(localConfigOverride),
+			readConfigOSOptions...,
 		)
 		if err != nil {
 			return nil, fmt.Errorf(
@@ -26,6 +26,8 @@ import (
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/registry/token/tokenget"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/registry/token/tokenlist"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/convert"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/freefieldnumbers"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/image/imagediff"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/migratev1beta1"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/registry/commit/commitget"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/registry/commit/commitlist"
@@ -67,6 +69,9 @@ import (
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/generate"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/lint"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/lsfiles"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modcache/modcachels"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modcache/modcacheprune"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modcache/modcacheverify"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modclearcache"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modinit"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modlsbreakingrules"
@@ -74,6 +79,8 @@ import (
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modopen"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modprune"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modupdate"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modvendor"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/mod/modverify"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/push"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/registry/registrylogin"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/registry/registrylogout"
@@ -90,16 +97,18 @@ func Main(name string) {
 //
 // This is public for use in testing.
 func NewRootCommand(name string) *appcmd.Command {
+	globalFlags := bufcli.NewGlobalFlags()
 	builder := appflag.NewBuilder(
 		name,
 		appflag.BuilderWithTimeout(120*time.Second),
 		appflag.BuilderWithTracing(),
+		appflag.BuilderWithInterceptor(globalFlags.NewInterceptor()),
 	)
 	noTimeoutBuilder := appflag.NewBuilder(
 		name,
 		appflag.BuilderWithTracing(),
+		appflag.BuilderWithInterceptor(globalFlags.NewInterceptor()),
 	)
-	globalFlags := bufcli.NewGlobalFlags()
 	return &appcmd.Command{
 		Use:                 name,
 		Short:               "The Buf CLI",
@@ -122,8 +131,19 @@ func NewRootCommand(name string) *appcmd.Command {
 					modinit.NewCommand("init", builder),
 					modprune.NewCommand("prune", builder),
 					modupdate.NewCommand("update", builder),
+					modvendor.NewCommand("vendor", builder),
+					modverify.NewCommand("verify", builder),
 					modopen.NewCommand("open", builder),
 					modclearcache.NewCommand("clear-cache", builder, "cc"),
+					{
+						Use:   "cache",
+						Short: "Manage the Buf module cache.",
+						SubCommands: []*appcmd.Command{
+							modcachels.NewCommand("ls", builder),
+							modcacheverify.NewCommand("verify", builder),
+							modcacheprune.NewCommand("prune", builder),
+						},
+					},
 					modlslintrules.NewCommand("ls-lint-rules", builder),
 					modlsbreakingrules.NewCommand("ls-breaking-rules", builder),
 				},
@@ -141,6 +161,14 @@ func NewRootCommand(name string) *appcmd.Command {
 				Short: "Beta commands. Unstable and likely to change.",
 				SubCommands: []*appcmd.Command{
 					convert.NewCommand("convert", builder),
+					freefieldnumbers.NewCommand("free-field-numbers", builder),
+					{
+						Use:   "image",
+						Short: "Work with Buf images.",
+						SubCommands: []*appcmd.Command{
+							imagediff.NewCommand("diff", builder),
+						},
+					},
 					migratev1beta1.NewCommand("migrate-v1beta1", builder),
 					studioagent.NewCommand("studio-agent", noTimeoutBuilder),
 					{
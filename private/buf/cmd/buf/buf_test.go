@@ -35,6 +35,8 @@ import (
 	"github.com/bufbuild/buf/private/pkg/storage/storagetesting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 func TestSuccess1(t *testing.T) {
@@ -630,7 +632,9 @@ ENUM_VALUE_NO_DELETE                            FILE, PACKAGE
 EXTENSION_MESSAGE_NO_DELETE                     FILE, PACKAGE                   Checks that extension ranges are not deleted from a given message.
 FIELD_NO_DELETE                                 FILE, PACKAGE                   Checks that fields are not deleted from a given message.
 FIELD_SAME_CTYPE                                FILE, PACKAGE                   Checks that fields have the same value for the ctype option.
+FIELD_SAME_DEFAULT                              FILE, PACKAGE                   Checks that fields have the same value for the default option.
 FIELD_SAME_JSTYPE                               FILE, PACKAGE                   Checks that fields have the same value for the jstype option.
+FIELD_SAME_PACKED                               FILE, PACKAGE                   Checks that fields have the same value for the packed option.
 FIELD_SAME_TYPE                                 FILE, PACKAGE                   Checks that fields have the same types in a given message.
 FILE_SAME_CC_ENABLE_ARENAS                      FILE, PACKAGE                   Checks that files have the same value for the cc_enable_arenas option.
 FILE_SAME_CC_GENERIC_SERVICES                   FILE, PACKAGE                   Checks that files have the same value for the cc_generic_services option.
@@ -679,6 +683,14 @@ FIELD_WIRE_JSON_COMPATIBLE_TYPE                 WIRE_JSON
 ENUM_VALUE_NO_DELETE_UNLESS_NUMBER_RESERVED     WIRE_JSON, WIRE                 Checks that enum values are not deleted from a given enum unless the number is reserved.
 FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED          WIRE_JSON, WIRE                 Checks that fields are not deleted from a given message unless the number is reserved.
 FIELD_WIRE_COMPATIBLE_TYPE                      WIRE                            Checks that fields have wire-compatible types in a given message.
+ENUM_SAME_CUSTOM_OPTION                                                         Checks that enums have the same values for custom options.
+ENUM_VALUE_SAME_CUSTOM_OPTION                                                   Checks that enum values have the same values for custom options.
+FIELD_SAME_CUSTOM_OPTION                                                        Checks that fields have the same values for custom options.
+FIELD_SAME_DEPRECATED                                                           Checks that fields have the same value for the deprecated option.
+FILE_SAME_CUSTOM_OPTION                                                         Checks that files have the same values for custom options.
+MESSAGE_SAME_CUSTOM_OPTION                                                      Checks that messages have the same values for custom options.
+RPC_SAME_CUSTOM_OPTION                                                          Checks that rpcs have the same values for custom options.
+SERVICE_SAME_CUSTOM_OPTION                                                      Checks that services have the same values for custom options.
 		`
 	testRunStdout(
 		t,
@@ -1162,6 +1174,41 @@ lint:
 	)
 }
 
+func TestBuildType(t *testing.T) {
+	t.Parallel()
+	testBuildType(
+		t,
+		[]string{"--type", "a.FooService.GetFoo,a.FooService.ListFoos"},
+		[]string{"GetFooRequest", "GetFooResponse", "ListFoosRequest", "ListFoosResponse"},
+		[]string{"GetFoo", "ListFoos"},
+	)
+	testBuildType(
+		t,
+		[]string{"--type", "a.FooService", "--exclude-type", "a.FooService.DeleteFoo"},
+		[]string{"GetFooRequest", "GetFooResponse", "ListFoosRequest", "ListFoosResponse"},
+		[]string{"GetFoo", "ListFoos"},
+	)
+	testBuildType(
+		t,
+		[]string{"--exclude-type", "a.FooService.GetFoo,a.FooService.ListFoos"},
+		[]string{"DeleteFooRequest", "DeleteFooResponse"},
+		[]string{"DeleteFoo"},
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		1,
+		``,
+		`Failure: excluding type "a.FooService": type is required by "a.FooService.GetFoo"`,
+		"build",
+		filepath.Join("testdata", "buildtype"),
+		"--type",
+		"a.FooService.GetFoo",
+		"--exclude-type",
+		"a.FooService",
+	)
+}
+
 func TestExportProto(t *testing.T) {
 	t.Parallel()
 	tempDir := t.TempDir()
@@ -1262,6 +1309,101 @@ func TestExportExcludeImports(t *testing.T) {
 	)
 }
 
+func TestExportType(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"export",
+		"--type",
+		"request.NotImported",
+		"-o",
+		tempDir,
+		filepath.Join("testdata", "export"),
+	)
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(tempDir)
+	require.NoError(t, err)
+	storagetesting.AssertPaths(
+		t,
+		readWriteBucket,
+		"",
+		"another.proto",
+		"unimported.proto",
+	)
+	data, err := storage.ReadPath(context.Background(), readWriteBucket, "unimported.proto")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "message NotImported {")
+	assert.Contains(t, string(data), `import "another.proto";`)
+}
+
+func TestExportTypeExcludeImports(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"export",
+		"--type",
+		"request.NotImported",
+		"--exclude-imports",
+		"-o",
+		tempDir,
+		filepath.Join("testdata", "export", "other", "proto"),
+	)
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(tempDir)
+	require.NoError(t, err)
+	storagetesting.AssertPaths(
+		t,
+		readWriteBucket,
+		"",
+		"unimported.proto",
+	)
+}
+
+func TestExportExcludeType(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"export",
+		"--exclude-type",
+		"request.NotImported",
+		"-o",
+		tempDir,
+		filepath.Join("testdata", "export"),
+	)
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(tempDir)
+	require.NoError(t, err)
+	// another.proto is only used by the excluded type, so it is excluded as well.
+	storagetesting.AssertPaths(
+		t,
+		readWriteBucket,
+		"",
+		"request.proto",
+		"rpc.proto",
+	)
+	testRunStdout(
+		t,
+		nil,
+		1,
+		``,
+		"export",
+		"--exclude-type",
+		"another.Request",
+		"-o",
+		t.TempDir(),
+		filepath.Join("testdata", "export"),
+	)
+}
+
 func TestExportPaths(t *testing.T) {
 	t.Parallel()
 	tempDir := t.TempDir()
@@ -1510,6 +1652,166 @@ a/v3/a.proto:7:10:Field "2" on message "Foo" changed name from "value" to "Value
 	)
 }
 
+func TestBreakingReport(t *testing.T) {
+	tempDir := t.TempDir()
+	testRunStdout(t, nil, 0, ``, "build", filepath.Join("command", "generate", "testdata", "paths"), "-o", filepath.Join(tempDir, "previous.bin"))
+	testRunStdout(t, nil, 0, ``, "build", filepath.Join("testdata", "paths"), "-o", filepath.Join(tempDir, "current.bin"))
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`a/v3/a.proto: changed field "a.v3.Foo.Value": name changed from "value" to "Value"
+a/v3/a.proto: changed field "a.v3.Foo.Value": option "json_name" changed from "value" to "Value"
+a/v3/a.proto: changed field "a.v3.Foo.key": type changed from "string" to "int32"`,
+		"breaking",
+		filepath.Join(tempDir, "current.bin"),
+		"--against",
+		filepath.Join(tempDir, "previous.bin"),
+		"--path",
+		filepath.Join("a", "v3"),
+		"--exclude-path",
+		filepath.Join("a", "v3", "foo"),
+		"--report",
+		"text",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`## Changed
+
+- field `+"`a.v3.Foo.Value` in `a/v3/a.proto`"+`: name changed from "value" to "Value"
+- field `+"`a.v3.Foo.Value` in `a/v3/a.proto`"+`: option "json_name" changed from "value" to "Value"
+- field `+"`a.v3.Foo.key` in `a/v3/a.proto`"+`: type changed from "string" to "int32"`,
+		"breaking",
+		filepath.Join(tempDir, "current.bin"),
+		"--against",
+		filepath.Join(tempDir, "previous.bin"),
+		"--path",
+		filepath.Join("a", "v3"),
+		"--exclude-path",
+		filepath.Join("a", "v3", "foo"),
+		"--report",
+		"markdown",
+	)
+}
+
+func TestBetaFreeFieldNumbers(t *testing.T) {
+	t.Parallel()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`
+		a.Foo.Bar                           free: 1-18997 18999-INF
+		a.Foo.Baz                           free: 1 4-INF
+		a.Foo                               free: 3-4 9-99
+		a.Qux                               free: 1-INF
+		`,
+		"beta",
+		"free-field-numbers",
+		filepath.Join("testdata", "freefieldnumbers"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`
+		a.Foo                               free: 3-4 9-99 suggested: 9 10 11
+		a.Foo.Bar                           free: 1-18997 18999-INF suggested: 18999 20000 20001
+		`,
+		"beta",
+		"free-field-numbers",
+		filepath.Join("testdata", "freefieldnumbers"),
+		"--type",
+		"a.Foo,a.Foo.Bar",
+		"--suggest",
+		"3",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`
+		{"type":"a.Foo.Baz","kind":"enum","path":"a.proto","free":[{"start":1,"end":1},{"start":4,"end":2147483647}],"suggested":[4,5]}
+		`,
+		"beta",
+		"free-field-numbers",
+		filepath.Join("testdata", "freefieldnumbers"),
+		"--type",
+		"a.Foo.Baz",
+		"--suggest",
+		"2",
+		"--format",
+		"json",
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		1,
+		``,
+		`Failure: --type: message or enum "a.Nope" not found in the input files`,
+		"beta",
+		"free-field-numbers",
+		filepath.Join("testdata", "freefieldnumbers"),
+		"--type",
+		"a.Nope",
+	)
+}
+
+func TestBetaImageDiff(t *testing.T) {
+	tempDir := t.TempDir()
+	testRunStdout(t, nil, 0, ``, "build", filepath.Join("command", "generate", "testdata", "paths"), "-o", filepath.Join(tempDir, "previous.bin"))
+	testRunStdout(t, nil, 0, ``, "build", filepath.Join("testdata", "paths"), "-o", filepath.Join(tempDir, "current.bin"))
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"beta",
+		"image",
+		"diff",
+		filepath.Join(tempDir, "current.bin"),
+		filepath.Join("testdata", "paths"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		`diff -u a/a/v3/a.proto b/a/v3/a.proto
+--- a/a/v3/a.proto
++++ b/a/v3/a.proto
+@@ -9,15 +9,15 @@
+           "name": "key",
+           "number": 1,
+           "label": "LABEL_OPTIONAL",
+-          "type": "TYPE_STRING",
++          "type": "TYPE_INT32",
+           "json_name": "key"
+         },
+         {
+-          "name": "value",
++          "name": "Value",
+           "number": 2,
+           "label": "LABEL_OPTIONAL",
+           "type": "TYPE_STRING",
+-          "json_name": "value"
++          "json_name": "Value"
+         }
+       ]
+     }`,
+		"beta",
+		"image",
+		"diff",
+		filepath.Join(tempDir, "previous.bin"),
+		filepath.Join(tempDir, "current.bin"),
+		"--path",
+		filepath.Join("a", "v3"),
+		"--exclude-path",
+		filepath.Join("a", "v3", "foo"),
+	)
+}
+
 func TestVersion(t *testing.T) {
 	t.Parallel()
 	testRunStdout(t, nil, 0, bufcli.Version, "--version")
@@ -1883,6 +2185,258 @@ func TestConvertInvalidTypeName(t *testing.T) {
 	)
 }
 
+func TestConvertStream(t *testing.T) {
+	tempDir := t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"build",
+		filepath.Join("testdata", "success"),
+		"-o",
+		filepath.Join(tempDir, "image.bin"),
+	)
+	testRunStdout(
+		t,
+		strings.NewReader(`{"one":"55"}`+"\n\n"+`{"one": "66"}`+"\n"),
+		0,
+		``,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		"-#format=jsonl",
+		"--to",
+		filepath.Join(tempDir, "messages.bindelim"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`{"one":"55"}
+{"one":"66"}`,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		filepath.Join(tempDir, "messages.bindelim"),
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		1,
+		`{"one":"55"}`,
+		`Failure: output format only supports a single message, use bindelim or jsonl to write multiple messages`,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		filepath.Join(tempDir, "messages.bindelim"),
+		"--to",
+		"-#format=json",
+	)
+	testRunStdout(
+		t,
+		strings.NewReader(""),
+		0,
+		``,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		"-#format=jsonl",
+	)
+}
+
+func TestConvertTxtpbAndYAML(t *testing.T) {
+	tempDir := t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"build",
+		filepath.Join("testdata", "success"),
+		"-o",
+		filepath.Join(tempDir, "image.bin"),
+	)
+	testRunStdout(
+		t,
+		strings.NewReader(`{"one":"55","two":{"name":"foo"}}`),
+		0,
+		`one: "55"
+two:
+  name: foo`,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		"-#format=json",
+		"--to",
+		"-#format=yaml",
+	)
+	testRunStdout(
+		t,
+		strings.NewReader(`{"one":"55","two":{"name":"foo"}}`),
+		0,
+		``,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		"-#format=json",
+		"--to",
+		filepath.Join(tempDir, "payload.yaml"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		filepath.Join(tempDir, "payload.yaml"),
+		"--to",
+		filepath.Join(tempDir, "payload.txtpb"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`{"one":"55","two":{"name":"foo"}}`,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		filepath.Join(tempDir, "payload.txtpb"),
+		"--to",
+		"-#format=json",
+	)
+	testRunStdout(
+		t,
+		strings.NewReader(`one: 55 two { name: "foo" }`),
+		0,
+		`{"one":"55","two":{"name":"foo"}}`,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		"-#format=txtpb",
+		"--to",
+		"-#format=json",
+	)
+}
+
+func TestConvertJSONOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"build",
+		filepath.Join("testdata", "success"),
+		"-o",
+		filepath.Join(tempDir, "image.bin"),
+	)
+	testRunStdout(
+		t,
+		strings.NewReader(`{"one":"55","two":{"field":[{"jsonName":"foo","type":"TYPE_INT32"}]}}`),
+		0,
+		`{"one":"55","two":{"field":[{"type":5,"json_name":"foo"}]}}`,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		"-#format=json",
+		"--to",
+		"-#use_proto_names=true,use_enum_numbers=true,format=jsonl",
+	)
+	testRunStdout(
+		t,
+		strings.NewReader(`{}`),
+		0,
+		`{"one":"0","two":null}`,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		"-#format=json",
+		"--to",
+		"-#format=json,emit_defaults=true",
+	)
+	testRunStdout(
+		t,
+		strings.NewReader(`{"one":"55","unknown":1}`),
+		0,
+		`{"one":"55"}`,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		"-#format=json",
+		"--to",
+		"-#format=json",
+	)
+	// The error message of protojson is deliberately unstable.
+	testRun(
+		t,
+		1,
+		strings.NewReader(`{"one":"55","unknown":1}`),
+		bytes.NewBuffer(nil),
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--from",
+		"-#format=json,discard_unknown=false",
+		"--to",
+		"-#format=json",
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		1,
+		``,
+		`Failure: --to: option "use_proto_names" is only allowed for formats [json,jsonl,yaml]`,
+		"beta",
+		"convert",
+		filepath.Join(tempDir, "image.bin"),
+		"--type",
+		"buf.Foo",
+		"--to",
+		"-#format=bin,use_proto_names=true",
+	)
+}
+
 func TestConvert(t *testing.T) {
 	t.Run("bin-to-json-file-proto", func(t *testing.T) {
 		testRunStdoutFile(t,
@@ -2427,6 +2981,42 @@ func testModInit(t *testing.T, expectedData string, document bool, name string,
 	require.Equal(t, expectedData, string(data))
 }
 
+func testBuildType(t *testing.T, typeArgs []string, expectedMessageNames []string, expectedMethodNames []string) {
+	outputFilePath := filepath.Join(t.TempDir(), "image.bin")
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		append(
+			[]string{
+				"build",
+				filepath.Join("testdata", "buildtype"),
+				"--as-file-descriptor-set",
+				"-o",
+				outputFilePath,
+			},
+			typeArgs...,
+		)...,
+	)
+	data, err := os.ReadFile(outputFilePath)
+	require.NoError(t, err)
+	fileDescriptorSet := &descriptorpb.FileDescriptorSet{}
+	require.NoError(t, proto.Unmarshal(data, fileDescriptorSet))
+	require.Len(t, fileDescriptorSet.File, 1)
+	var messageNames []string
+	for _, messageType := range fileDescriptorSet.File[0].MessageType {
+		messageNames = append(messageNames, messageType.GetName())
+	}
+	assert.Equal(t, expectedMessageNames, messageNames)
+	require.Len(t, fileDescriptorSet.File[0].Service, 1)
+	var methodNames []string
+	for _, method := range fileDescriptorSet.File[0].Service[0].Method {
+		methodNames = append(methodNames, method.GetName())
+	}
+	assert.Equal(t, expectedMethodNames, methodNames)
+}
+
 func testRunStdout(t *testing.T, stdin io.Reader, expectedExitCode int, expectedStdout string, args ...string) {
 	appcmdtesting.RunCommandExitCodeStdout(
 		t,
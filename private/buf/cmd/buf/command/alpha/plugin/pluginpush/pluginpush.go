@@ -21,13 +21,14 @@ import (
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/bufprint"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufconnect"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufplugindocker"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/netextended"
-	"github.com/bufbuild/buf/private/pkg/netrc"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/bufbuild/connect-go"
@@ -251,15 +252,15 @@ func run(
 		return nil
 	}
 
-	machine, err := netrc.GetMachineForName(container, pluginConfig.Name.Remote())
+	credentials, err := bufconnect.GetStoredCredentials(ctx, container, command.NewRunner(), pluginConfig.Name.Remote())
 	if err != nil {
 		return err
 	}
 	authConfig := &bufplugindocker.RegistryAuthConfig{}
-	if machine != nil {
-		authConfig.ServerAddress = machine.Name()
-		authConfig.Username = machine.Login()
-		authConfig.Password = machine.Password()
+	if credentials != nil {
+		authConfig.ServerAddress = credentials.ServerURL
+		authConfig.Username = credentials.Username
+		authConfig.Password = credentials.Secret
 	}
 	pushResponse, err := client.Push(ctx, createdImage, authConfig)
 	if err != nil {
@@ -0,0 +1,237 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufreflect"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// maxRawRecursionDepth is the maximum depth of nested messages and groups that
+// are decoded by --decode_raw, matching the default recursion limit of protoc.
+const maxRawRecursionDepth = 100
+
+// encode reads a text-format message of the type from stdin and writes it in binary to stdout.
+func encode(
+	ctx context.Context,
+	container app.StdioContainer,
+	image bufimage.Image,
+	typeName string,
+) error {
+	resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptors(image)...)
+	if err != nil {
+		return err
+	}
+	message, err := bufreflect.NewMessage(ctx, image, typeName)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(container.Stdin())
+	if err != nil {
+		return err
+	}
+	if err := protoencoding.NewTextUnmarshaler(resolver).Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to parse input: %v", err)
+	}
+	data, err = protoencoding.NewWireMarshaler().Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = container.Stdout().Write(data)
+	return err
+}
+
+// decode reads a binary message of the type from stdin and writes it in text format to stdout.
+func decode(
+	ctx context.Context,
+	container app.StdioContainer,
+	image bufimage.Image,
+	typeName string,
+) error {
+	resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptors(image)...)
+	if err != nil {
+		return err
+	}
+	message, err := bufreflect.NewMessage(ctx, image, typeName)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(container.Stdin())
+	if err != nil {
+		return err
+	}
+	if err := protoencoding.NewWireUnmarshaler(resolver).Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to parse input: %v", err)
+	}
+	data, err = protoencoding.NewTextMarshaler(resolver).Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = container.Stdout().Write(data)
+	return err
+}
+
+// decodeRaw reads an arbitrary binary message from stdin and writes its raw
+// tag/value pairs in text format to stdout, the same way as protoc.
+func decodeRaw(container app.StdioContainer) error {
+	data, err := io.ReadAll(container.Stdin())
+	if err != nil {
+		return err
+	}
+	rawFields, _, err := parseRawFields(data, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to parse input: %v", err)
+	}
+	buffer := bytes.NewBuffer(nil)
+	printRawFields(buffer, rawFields, "")
+	_, err = container.Stdout().Write(buffer.Bytes())
+	return err
+}
+
+// rawField is a field of a message decoded without its schema.
+type rawField struct {
+	number   protowire.Number
+	wireType protowire.Type
+	// value is the value of varint, fixed32 and fixed64 fields.
+	value uint64
+	// bytes is the value of length-delimited fields that are not messages.
+	bytes []byte
+	// fields are the fields of groups, and of length-delimited fields that are messages.
+	fields []*rawField
+}
+
+// parseRawFields parses the fields of the data.
+//
+// If endGroupNumber is not zero, this parses the fields of a group, and stops at the
+// matching end group tag. Returns the number of bytes consumed.
+func parseRawFields(data []byte, depth int, endGroupNumber protowire.Number) ([]*rawField, int, error) {
+	if depth > maxRawRecursionDepth {
+		return nil, 0, errors.New("exceeded maximum recursion depth")
+	}
+	var rawFields []*rawField
+	offset := 0
+	for offset < len(data) {
+		number, wireType, n := protowire.ConsumeTag(data[offset:])
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		offset += n
+		rawField := &rawField{
+			number:   number,
+			wireType: wireType,
+		}
+		switch wireType {
+		case protowire.VarintType:
+			rawField.value, n = protowire.ConsumeVarint(data[offset:])
+		case protowire.Fixed32Type:
+			var value uint32
+			value, n = protowire.ConsumeFixed32(data[offset:])
+			rawField.value = uint64(value)
+		case protowire.Fixed64Type:
+			rawField.value, n = protowire.ConsumeFixed64(data[offset:])
+		case protowire.BytesType:
+			rawField.bytes, n = protowire.ConsumeBytes(data[offset:])
+			// Like protoc, we print length-delimited fields as messages if they can be
+			// parsed as messages, and as strings otherwise.
+			if n >= 0 && len(rawField.bytes) > 0 {
+				if fields, _, err := parseRawFields(rawField.bytes, depth+1, 0); err == nil {
+					rawField.fields = fields
+					rawField.bytes = nil
+				}
+			}
+		case protowire.StartGroupType:
+			var err error
+			rawField.fields, n, err = parseRawFields(data[offset:], depth+1, number)
+			if err != nil {
+				return nil, 0, err
+			}
+		case protowire.EndGroupType:
+			if endGroupNumber == 0 || number != endGroupNumber {
+				return nil, 0, fmt.Errorf("unexpected end group tag for field %d", number)
+			}
+			return rawFields, offset, nil
+		default:
+			return nil, 0, fmt.Errorf("invalid wire type %d for field %d", wireType, number)
+		}
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		offset += n
+		rawFields = append(rawFields, rawField)
+	}
+	if endGroupNumber != 0 {
+		return nil, 0, fmt.Errorf("missing end group tag for field %d", endGroupNumber)
+	}
+	return rawFields, offset, nil
+}
+
+func printRawFields(buffer *bytes.Buffer, rawFields []*rawField, indent string) {
+	for _, rawField := range rawFields {
+		_, _ = buffer.WriteString(indent)
+		switch {
+		case rawField.wireType == protowire.StartGroupType, rawField.fields != nil:
+			_, _ = fmt.Fprintf(buffer, "%d {\n", rawField.number)
+			printRawFields(buffer, rawField.fields, indent+"  ")
+			_, _ = buffer.WriteString(indent + "}\n")
+		case rawField.wireType == protowire.VarintType:
+			_, _ = fmt.Fprintf(buffer, "%d: %d\n", rawField.number, rawField.value)
+		case rawField.wireType == protowire.Fixed32Type:
+			_, _ = fmt.Fprintf(buffer, "%d: 0x%08x\n", rawField.number, rawField.value)
+		case rawField.wireType == protowire.Fixed64Type:
+			_, _ = fmt.Fprintf(buffer, "%d: 0x%016x\n", rawField.number, rawField.value)
+		default:
+			_, _ = fmt.Fprintf(buffer, "%d: \"%s\"\n", rawField.number, cEscape(rawField.bytes))
+		}
+	}
+}
+
+// cEscape escapes the bytes the same way as protoc, using octal escapes for
+// non-printable bytes.
+func cEscape(data []byte) string {
+	var builder strings.Builder
+	for _, b := range data {
+		switch b {
+		case '\n':
+			_, _ = builder.WriteString(`\n`)
+		case '\r':
+			_, _ = builder.WriteString(`\r`)
+		case '\t':
+			_, _ = builder.WriteString(`\t`)
+		case '"':
+			_, _ = builder.WriteString(`\"`)
+		case '\'':
+			_, _ = builder.WriteString(`\'`)
+		case '\\':
+			_, _ = builder.WriteString(`\\`)
+		default:
+			if b < 0x20 || b >= 0x7f {
+				_, _ = fmt.Fprintf(&builder, `\%03o`, b)
+			} else {
+				_ = builder.WriteByte(b)
+			}
+		}
+	}
+	return builder.String()
+}
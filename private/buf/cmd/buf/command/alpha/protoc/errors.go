@@ -22,6 +22,10 @@ import (
 var (
 	errNoInputFiles = errors.New("no input files specified")
 	errArgEmpty     = errors.New("empty argument specified")
+
+	errMultipleEncodeDecode    = fmt.Errorf("only one of --%s, --%s and --%s can be specified", encodeFlagName, decodeFlagName, decodeRawFlagName)
+	errEncodeDecodeWithOutput  = fmt.Errorf("cannot use --%s or --%s and generate code or descriptors at the same time", encodeFlagName, decodeFlagName)
+	errDecodeRawWithInputFiles = fmt.Errorf("when using --%s, no input files should be given", decodeRawFlagName)
 )
 
 func newCannotSpecifyOptWithoutOutError(pluginName string) error {
@@ -59,45 +63,3 @@ func newPluginPathNameInvalidPrefixError(pluginName string) error {
 func newDuplicatePluginPathError(pluginName string) error {
 	return fmt.Errorf("duplicate --%s for protoc-gen-%s", pluginPathValuesFlagName, pluginName)
 }
-
-func newEncodeNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		encodeFlagName,
-	)
-}
-
-func newDecodeNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		decodeFlagName,
-	)
-}
-
-func newDecodeRawNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		decodeRawFlagName,
-	)
-}
-
-func newDescriptorSetInNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf will work with cross-repository imports Buf Schema Registry, which will be based on source files, not pre-built Images.
-We think this is a much safer option that leads to less errors and more consistent results.
-
-Please email us at support@buf.build if this is a need for your organization.`,
-		descriptorSetInFlagName,
-	)
-}
@@ -59,6 +59,10 @@ type flags struct {
 	Output                string
 	ErrorFormat           string
 	ByDir                 bool
+	Encode                string
+	Decode                string
+	DecodeRaw             bool
+	DescriptorSetIn       []string
 }
 
 type env struct {
@@ -74,11 +78,6 @@ type flagsBuilder struct {
 
 	PluginPathValues []string
 
-	Encode          string
-	Decode          string
-	DecodeRaw       bool
-	DescriptorSetIn []string
-
 	pluginFake        []string
 	pluginNameToValue map[string]*pluginValue
 }
@@ -167,30 +166,26 @@ func (f *flagsBuilder) Bind(flagSet *pflag.FlagSet) {
 		&f.Encode,
 		encodeFlagName,
 		"",
-		`Not supported by buf.`,
+		`Read a text-format message of the given type from stdin and write it in binary to stdout.`,
 	)
-	_ = flagSet.MarkHidden(encodeFlagName)
 	flagSet.StringVar(
 		&f.Decode,
 		decodeFlagName,
 		"",
-		`Not supported by buf.`,
+		`Read a binary message of the given type from stdin and write it in text format to stdout.`,
 	)
-	_ = flagSet.MarkHidden(decodeFlagName)
 	flagSet.BoolVar(
 		&f.DecodeRaw,
 		decodeRawFlagName,
 		false,
-		`Not supported by buf.`,
+		`Read an arbitrary binary message from stdin and write the raw tag/value pairs in text format to stdout. No input files should be given.`,
 	)
-	_ = flagSet.MarkHidden(decodeRawFlagName)
 	flagSet.StringSliceVar(
 		&f.DescriptorSetIn,
 		descriptorSetInFlagName,
 		nil,
-		`Not supported by buf.`,
+		`The paths to FileDescriptorSets to resolve input files and imports against before source files. The FileDescriptorSets must contain all of their own imports.`,
 	)
-	_ = flagSet.MarkHidden(descriptorSetInFlagName)
 }
 
 func (f *flagsBuilder) Normalize(flagSet *pflag.FlagSet, name string) string {
@@ -212,9 +207,6 @@ func (f *flagsBuilder) Build(args []string) (*env, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := f.checkUnsupported(); err != nil {
-		return nil, err
-	}
 	for pluginName, pluginInfo := range pluginNameToPluginInfo {
 		if pluginInfo.Out == "" && len(pluginInfo.Opt) > 0 {
 			return nil, newCannotSpecifyOptWithoutOutError(pluginName)
@@ -230,12 +222,18 @@ func (f *flagsBuilder) Build(args []string) (*env, error) {
 	if len(f.IncludeDirPaths) == 0 {
 		f.IncludeDirPaths = defaultIncludeDirPaths
 	} else {
-		f.IncludeDirPaths = splitIncludeDirPaths(f.IncludeDirPaths)
+		f.IncludeDirPaths = splitPathList(f.IncludeDirPaths)
+	}
+	if len(f.DescriptorSetIn) > 0 {
+		f.DescriptorSetIn = splitPathList(f.DescriptorSetIn)
 	}
 	if f.ErrorFormat == "" {
 		f.ErrorFormat = defaultErrorFormat
 	}
-	if len(filePaths) == 0 {
+	if err := f.checkEncodeDecode(pluginNameToPluginInfo, filePaths); err != nil {
+		return nil, err
+	}
+	if len(filePaths) == 0 && !f.DecodeRaw {
 		return nil, errNoInputFiles
 	}
 	return &env{
@@ -476,18 +474,33 @@ func (f *flagsBuilder) getPluginNamesSortedByOutIndex(
 	return pluginNames, nil
 }
 
-func (f *flagsBuilder) checkUnsupported() error {
+// checkEncodeDecode checks the --encode, --decode and --decode_raw flags
+// the same way as protoc.
+func (f *flagsBuilder) checkEncodeDecode(
+	pluginNameToPluginInfo map[string]*pluginInfo,
+	filePaths []string,
+) error {
+	encodeDecodeCount := 0
 	if f.Encode != "" {
-		return newEncodeNotSupportedError()
+		encodeDecodeCount++
 	}
 	if f.Decode != "" {
-		return newDecodeNotSupportedError()
+		encodeDecodeCount++
 	}
 	if f.DecodeRaw {
-		return newDecodeRawNotSupportedError()
+		encodeDecodeCount++
 	}
-	if len(f.DescriptorSetIn) > 0 {
-		return newDescriptorSetInNotSupportedError()
+	if encodeDecodeCount == 0 {
+		return nil
+	}
+	if encodeDecodeCount > 1 {
+		return errMultipleEncodeDecode
+	}
+	if len(pluginNameToPluginInfo) > 0 || f.Output != "" || f.PrintFreeFieldNumbers {
+		return errEncodeDecodeWithOutput
+	}
+	if f.DecodeRaw && len(filePaths) > 0 {
+		return errDecodeRawWithInputFiles
 	}
 	return nil
 }
@@ -509,18 +522,19 @@ func normalizeFunc(f func(*pflag.FlagSet, string) string) func(*pflag.FlagSet, s
 
 // https://github.com/protocolbuffers/protobuf/blob/336ed1820a4f2649c9aa3953d5059b03b7a77100/src/google/protobuf/compiler/command_line_interface.cc#L1699-L1705
 //
-// This roughly supports the equivalent of Java's -classpath flag.
+// This roughly supports the equivalent of Java's -classpath flag, and is used
+// for both --proto_path and --descriptor_set_in.
 // Note that for filenames such as "foo:bar" on unix, this breaks, but our goal is to match
-// this flag from protoc.
-func splitIncludeDirPaths(includeDirPaths []string) []string {
-	copyIncludeDirPaths := make([]string, 0, len(includeDirPaths))
-	for _, includeDirPath := range includeDirPaths {
+// these flags from protoc.
+func splitPathList(paths []string) []string {
+	copyPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
 		// protocolbuffers/protobuf has true for omit_empty
-		for _, splitIncludeDirPath := range strings.Split(includeDirPath, includeDirPathSeparator) {
-			if len(splitIncludeDirPath) > 0 {
-				copyIncludeDirPaths = append(copyIncludeDirPaths, splitIncludeDirPath)
+		for _, splitPath := range strings.Split(path, includeDirPathSeparator) {
+			if len(splitPath) > 0 {
+				copyPaths = append(copyPaths, splitPath)
 			}
 		}
 	}
-	return copyIncludeDirPaths
+	return copyPaths
 }
@@ -585,6 +585,119 @@ func TestParseFlags(t *testing.T) {
 				},
 			},
 		},
+		{
+			Args: []string{
+				"--encode",
+				"foo.Bar",
+				"foo.proto",
+			},
+			Expected: &env{
+				flags: flags{
+					IncludeDirPaths: defaultIncludeDirPaths,
+					ErrorFormat:     defaultErrorFormat,
+					Encode:          "foo.Bar",
+				},
+				FilePaths: []string{
+					"foo.proto",
+				},
+			},
+		},
+		{
+			Args: []string{
+				"--decode",
+				"foo.Bar",
+				"foo.proto",
+			},
+			Expected: &env{
+				flags: flags{
+					IncludeDirPaths: defaultIncludeDirPaths,
+					ErrorFormat:     defaultErrorFormat,
+					Decode:          "foo.Bar",
+				},
+				FilePaths: []string{
+					"foo.proto",
+				},
+			},
+		},
+		{
+			Args: []string{
+				"--decode_raw",
+			},
+			Expected: &env{
+				flags: flags{
+					IncludeDirPaths: defaultIncludeDirPaths,
+					ErrorFormat:     defaultErrorFormat,
+					DecodeRaw:       true,
+				},
+			},
+		},
+		{
+			Args: []string{
+				"--descriptor_set_in",
+				"foo.bin" + includeDirPathSeparator + "bar.bin",
+				"--descriptor_set_in",
+				"baz.bin",
+				"foo.proto",
+			},
+			Expected: &env{
+				flags: flags{
+					IncludeDirPaths: defaultIncludeDirPaths,
+					ErrorFormat:     defaultErrorFormat,
+					DescriptorSetIn: []string{
+						"foo.bin",
+						"bar.bin",
+						"baz.bin",
+					},
+				},
+				FilePaths: []string{
+					"foo.proto",
+				},
+			},
+		},
+		{
+			Args: []string{
+				"--decode_raw",
+				"foo.proto",
+			},
+			ExpectedError: errDecodeRawWithInputFiles,
+		},
+		{
+			Args: []string{
+				"--encode",
+				"foo.Bar",
+				"--decode",
+				"foo.Bar",
+				"foo.proto",
+			},
+			ExpectedError: errMultipleEncodeDecode,
+		},
+		{
+			Args: []string{
+				"--decode",
+				"foo.Bar",
+				"-o",
+				"out.bin",
+				"foo.proto",
+			},
+			ExpectedError: errEncodeDecodeWithOutput,
+		},
+		{
+			Args: []string{
+				"--encode",
+				"foo.Bar",
+				"--go_out",
+				"go_out",
+				"foo.proto",
+			},
+			ExpectedError: errEncodeDecodeWithOutput,
+		},
+		{
+			Args: []string{
+				"--encode",
+				"foo.Bar",
+			},
+			ExpectedError: errNoInputFiles,
+		},
 	}
 	for i, testCase := range testCases {
 		name := fmt.Sprintf("%d", i)
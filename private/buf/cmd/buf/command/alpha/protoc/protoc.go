@@ -17,6 +17,8 @@ package protoc
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
@@ -33,10 +35,13 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoexec"
 	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoos"
 	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 // NewCommand returns a new Command.
@@ -102,73 +107,29 @@ func run(
 		)
 	}
 
-	var buildOption bufmodulebuild.BuildOption
-	if len(env.FilePaths) > 0 {
-		buildOption = bufmodulebuild.WithPaths(env.FilePaths)
-	}
-	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
-	runner := command.NewRunner()
-	module, err := bufmodulebuild.NewModuleIncludeBuilder(container.Logger(), storageosProvider).BuildForIncludes(
-		ctx,
-		env.IncludeDirPaths,
-		buildOption,
-	)
-	if err != nil {
-		return err
-	}
-	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
-	if err != nil {
-		return err
-	}
-	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
-	if err != nil {
-		return err
+	if env.DecodeRaw {
+		return decodeRaw(container)
 	}
-	moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
-		zap.NewNop(),
-		moduleReader,
-	).Build(
-		ctx,
-		module,
-	)
+
+	image, err := buildImage(ctx, container, env)
 	if err != nil {
 		return err
 	}
-	var buildOptions []bufimagebuild.BuildOption
-	// we always need source code info if we are doing generation
-	if len(env.PluginNameToPluginInfo) == 0 && !env.IncludeSourceInfo {
-		buildOptions = append(buildOptions, bufimagebuild.WithExcludeSourceCodeInfo())
-	}
-	image, fileAnnotations, err := bufimagebuild.NewBuilder(container.Logger()).Build(
-		ctx,
-		moduleFileSet,
-		buildOptions...,
-	)
-	if err != nil {
-		return err
+
+	if env.Encode != "" {
+		return encode(ctx, container, image, env.Encode)
 	}
-	if len(fileAnnotations) > 0 {
-		if err := bufanalysis.PrintFileAnnotations(
-			container.Stderr(),
-			fileAnnotations,
-			env.ErrorFormat,
-		); err != nil {
-			return err
-		}
-		// we do this even though we're in protoc compatibility mode as we just need to do non-zero
-		// but this also makes us consistent with the rest of buf
-		return bufcli.ErrFileAnnotation
+	if env.Decode != "" {
+		return decode(ctx, container, image, env.Decode)
 	}
-
 	if env.PrintFreeFieldNumbers {
-		fileInfos, err := module.TargetFileInfos(ctx)
-		if err != nil {
-			return err
-		}
 		var filePaths []string
-		for _, fileInfo := range fileInfos {
-			filePaths = append(filePaths, fileInfo.Path())
+		for _, imageFile := range image.Files() {
+			if !imageFile.IsImport() {
+				filePaths = append(filePaths, imageFile.Path())
+			}
 		}
+		sort.Strings(filePaths)
 		s, err := bufimageutil.FreeMessageRangeStrings(ctx, filePaths, image)
 		if err != nil {
 			return err
@@ -179,6 +140,8 @@ func run(
 		return nil
 	}
 	if len(env.PluginNameToPluginInfo) > 0 {
+		storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
+		runner := command.NewRunner()
 		images := []bufimage.Image{image}
 		if env.ByDir {
 			_, span := trace.StartSpan(ctx, "image_by_dir")
@@ -249,3 +212,126 @@ func run(
 		!env.IncludeImports,
 	)
 }
+
+// buildImage builds the Image for the input files.
+//
+// Input files contained in the FileDescriptorSets of --descriptor_set_in are taken
+// from the FileDescriptorSets, all other input files are compiled from source, resolving
+// their imports against the FileDescriptorSets first.
+func buildImage(
+	ctx context.Context,
+	container appflag.Container,
+	env *env,
+) (bufimage.Image, error) {
+	// we always need source code info if we are doing generation
+	excludeSourceCodeInfo := len(env.PluginNameToPluginInfo) == 0 && !env.IncludeSourceInfo
+	var descriptorSetImage bufimage.Image
+	if len(env.DescriptorSetIn) > 0 {
+		var err error
+		descriptorSetImage, err = readDescriptorSetImage(env.DescriptorSetIn, excludeSourceCodeInfo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var sourceFilePaths []string
+	var descriptorSetFilePaths []string
+	for _, filePath := range env.FilePaths {
+		if descriptorSetImage != nil && descriptorSetImage.GetFile(normalpath.Normalize(filePath)) != nil {
+			descriptorSetFilePaths = append(descriptorSetFilePaths, normalpath.Normalize(filePath))
+		} else {
+			sourceFilePaths = append(sourceFilePaths, filePath)
+		}
+	}
+	var images []bufimage.Image
+	if len(descriptorSetFilePaths) > 0 {
+		image, err := bufimage.ImageWithOnlyPaths(descriptorSetImage, descriptorSetFilePaths, nil)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	if len(sourceFilePaths) > 0 {
+		storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
+		module, err := bufmodulebuild.NewModuleIncludeBuilder(container.Logger(), storageosProvider).BuildForIncludes(
+			ctx,
+			env.IncludeDirPaths,
+			bufmodulebuild.WithPaths(sourceFilePaths),
+		)
+		if err != nil {
+			return nil, err
+		}
+		registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
+		if err != nil {
+			return nil, err
+		}
+		moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
+		if err != nil {
+			return nil, err
+		}
+		moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
+			zap.NewNop(),
+			moduleReader,
+		).Build(
+			ctx,
+			module,
+		)
+		if err != nil {
+			return nil, err
+		}
+		var buildOptions []bufimagebuild.BuildOption
+		if excludeSourceCodeInfo {
+			buildOptions = append(buildOptions, bufimagebuild.WithExcludeSourceCodeInfo())
+		}
+		if descriptorSetImage != nil {
+			buildOptions = append(buildOptions, bufimagebuild.WithDependencyImage(descriptorSetImage))
+		}
+		image, fileAnnotations, err := bufimagebuild.NewBuilder(container.Logger()).Build(
+			ctx,
+			moduleFileSet,
+			buildOptions...,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(fileAnnotations) > 0 {
+			if err := bufanalysis.PrintFileAnnotations(
+				container.Stderr(),
+				fileAnnotations,
+				env.ErrorFormat,
+			); err != nil {
+				return nil, err
+			}
+			// we do this even though we're in protoc compatibility mode as we just need to do non-zero
+			// but this also makes us consistent with the rest of buf
+			return nil, bufcli.ErrFileAnnotation
+		}
+		images = append(images, image)
+	}
+	return bufimage.MergeImages(images...)
+}
+
+// readDescriptorSetImage reads the FileDescriptorSets at the paths into a single Image.
+func readDescriptorSetImage(descriptorSetInPaths []string, excludeSourceCodeInfo bool) (bufimage.Image, error) {
+	fileDescriptorSets := make([]*descriptorpb.FileDescriptorSet, len(descriptorSetInPaths))
+	for i, descriptorSetInPath := range descriptorSetInPaths {
+		data, err := os.ReadFile(descriptorSetInPath)
+		if err != nil {
+			return nil, err
+		}
+		fileDescriptorSet := &descriptorpb.FileDescriptorSet{}
+		if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(data, fileDescriptorSet); err != nil {
+			return nil, fmt.Errorf("%s: could not parse FileDescriptorSet: %v", descriptorSetInPath, err)
+		}
+		if excludeSourceCodeInfo {
+			for _, fileDescriptorProto := range fileDescriptorSet.File {
+				fileDescriptorProto.SourceCodeInfo = nil
+			}
+		}
+		fileDescriptorSets[i] = fileDescriptorSet
+	}
+	image, err := bufimage.NewImageForFileDescriptorSets(fileDescriptorSets...)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", descriptorSetInFlagName, err)
+	}
+	return image, nil
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bufbuild/buf/private/bufpkg/buftesting"
@@ -83,6 +84,229 @@ func TestOverlap(t *testing.T) {
 	)
 }
 
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+	encoded := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		nil,
+		strings.NewReader(`one: 150 two: "testing" three { four: 1 five: 2 }`),
+		encoded,
+		"-I",
+		filepath.Join("testdata", "encode"),
+		"--encode",
+		"a.Foo",
+		filepath.Join("testdata", "encode", "a.proto"),
+	)
+	decoded := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		nil,
+		bytes.NewReader(encoded.Bytes()),
+		decoded,
+		"-I",
+		filepath.Join("testdata", "encode"),
+		"--decode",
+		"a.Foo",
+		filepath.Join("testdata", "encode", "a.proto"),
+	)
+	// The text format output is not stable, so we check that it encodes to the same message.
+	reencoded := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		nil,
+		bytes.NewReader(decoded.Bytes()),
+		reencoded,
+		"-I",
+		filepath.Join("testdata", "encode"),
+		"--encode",
+		"a.Foo",
+		filepath.Join("testdata", "encode", "a.proto"),
+	)
+	assert.Equal(t, encoded.Bytes(), reencoded.Bytes())
+	appcmdtesting.RunCommandSuccessStdout(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		`1: 150
+2: "testing"
+3 {
+  4: 0x0000000000000001
+  5: 0x00000002
+}
+`,
+		nil,
+		bytes.NewReader(encoded.Bytes()),
+		"--decode_raw",
+	)
+}
+
+func TestDecodeRaw(t *testing.T) {
+	t.Parallel()
+	appcmdtesting.RunCommandSuccessStdout(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		`1: 1
+2: "\001\377\n\"\'"
+3 {
+  4: 2
+}
+`,
+		nil,
+		bytes.NewReader([]byte{
+			0x08, 0x01,
+			0x12, 0x05, 0x01, 0xff, '\n', '"', '\'',
+			0x1b, 0x20, 0x02, 0x1c,
+		}),
+		"--decode_raw",
+	)
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		1,
+		nil,
+		bytes.NewReader([]byte{0x08}),
+		bytes.NewBuffer(nil),
+		bytes.NewBuffer(nil),
+		"--decode_raw",
+	)
+}
+
+func TestDescriptorSetIn(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	aFilePath := filepath.Join(tempDirPath, "a.bin")
+	appcmdtesting.RunCommandSuccess(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		nil,
+		nil,
+		nil,
+		"-I",
+		filepath.Join("testdata", "descriptorsetin", "a"),
+		"-o",
+		aFilePath,
+		filepath.Join("testdata", "descriptorsetin", "a", "a.proto"),
+	)
+	// imports of source files are resolved against the FileDescriptorSet
+	stdout := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		nil,
+		nil,
+		stdout,
+		"-I",
+		filepath.Join("testdata", "descriptorsetin", "b"),
+		"--descriptor_set_in",
+		aFilePath,
+		"--include_imports",
+		"-o",
+		"-",
+		filepath.Join("testdata", "descriptorsetin", "b", "b.proto"),
+	)
+	fileDescriptorSet := &descriptorpb.FileDescriptorSet{}
+	require.NoError(t, protoencoding.NewWireUnmarshaler(nil).Unmarshal(stdout.Bytes(), fileDescriptorSet))
+	require.Len(t, fileDescriptorSet.File, 2)
+	assert.Equal(t, "a.proto", fileDescriptorSet.File[0].GetName())
+	assert.Equal(t, "b.proto", fileDescriptorSet.File[1].GetName())
+	bFilePath := filepath.Join(tempDirPath, "b.bin")
+	require.NoError(t, os.WriteFile(bFilePath, stdout.Bytes(), 0600))
+	// input files are taken from the FileDescriptorSets
+	stdout = bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		nil,
+		nil,
+		stdout,
+		"--descriptor_set_in",
+		aFilePath+includeDirPathSeparator+bFilePath,
+		"-o",
+		"-",
+		"b.proto",
+	)
+	fileDescriptorSet = &descriptorpb.FileDescriptorSet{}
+	require.NoError(t, protoencoding.NewWireUnmarshaler(nil).Unmarshal(stdout.Bytes(), fileDescriptorSet))
+	require.Len(t, fileDescriptorSet.File, 1)
+	assert.Equal(t, "b.proto", fileDescriptorSet.File[0].GetName())
+	// FileDescriptorSets must contain their imports
+	bWithoutImportsFilePath := filepath.Join(tempDirPath, "b_without_imports.bin")
+	data, err := protoencoding.NewWireMarshaler().Marshal(
+		&descriptorpb.FileDescriptorSet{
+			File: fileDescriptorSet.File,
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(bWithoutImportsFilePath, data, 0600))
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		1,
+		nil,
+		nil,
+		nil,
+		nil,
+		"--descriptor_set_in",
+		bWithoutImportsFilePath,
+		"-o",
+		app.DevNullFilePath,
+		"b.proto",
+	)
+}
+
 func TestComparePrintFreeFieldNumbersGoogleapis(t *testing.T) {
 	t.Parallel()
 	googleapisDirPath := buftesting.GetGoogleapisDirPath(t, buftestingDirPath)
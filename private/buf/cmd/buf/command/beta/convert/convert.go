@@ -16,7 +16,9 @@ package convert
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/bufconvert"
@@ -26,6 +28,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
 )
 
 const (
@@ -77,6 +80,32 @@ $ buf build -o - | buf beta convert -#format=bin --type buf.Foo --from=payload.j
 # Use a module on the bsr
 
 buf beta convert buf.build/<org>/<repo> --type buf.Foo --from=payload.json
+
+# Convert a stream of messages
+
+The bindelim format is a stream of binary messages, each prefixed with its size as a
+varint. The jsonl format is a stream of JSON messages, one per line. Streams are
+converted one message at a time, so that large files do not need to fit in memory.
+
+$ buf beta convert example.proto --type buf.Foo --from=messages.jsonl --to=messages.bin#format=bindelim
+
+# Convert from and to the Protobuf text format and YAML
+
+The txtpb format is the Protobuf text format, detected from the .txtpb, .textproto,
+.pbtxt and .prototxt extensions. The yaml format is the JSON mapping of the message
+written as YAML, detected from the .yaml and .yml extensions. Any and extensions are
+resolved against the types of <input>.
+
+$ buf beta convert example.proto --type buf.Foo --from=payload.txtpb --to=payload.yaml
+
+# Control the JSON mapping
+
+The json, jsonl and yaml formats accept the emit_defaults, use_proto_names and
+use_enum_numbers options when writing. The json, jsonl, txtpb and yaml formats accept
+the discard_unknown option when reading, which is true by default.
+
+$ buf beta convert example.proto --type buf.Foo --from=payload.bin --to=out.json#emit_defaults=true,use_proto_names=true,use_enum_numbers=true
+$ buf beta convert example.proto --type buf.Foo --from=payload.json#discard_unknown=false
 `,
 		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
@@ -144,7 +173,7 @@ func run(
 	ctx context.Context,
 	container appflag.Container,
 	flags *flags,
-) error {
+) (retErr error) {
 	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
@@ -171,9 +200,9 @@ func run(
 	if err != nil {
 		return fmt.Errorf("--%s: %v", outputFlagName, err)
 	}
-	message, err := bufcli.NewWireProtoEncodingReader(
+	messageReader, err := bufcli.NewWireProtoEncodingReader(
 		container.Logger(),
-	).GetMessage(
+	).NewMessageReader(
 		ctx,
 		container,
 		image,
@@ -183,6 +212,9 @@ func run(
 	if err != nil {
 		return err
 	}
+	defer func() {
+		retErr = multierr.Append(retErr, messageReader.Close())
+	}()
 	defaultToEncoding, err := inverseEncoding(fromMessageRef.MessageEncoding())
 	if err != nil {
 		return err
@@ -191,15 +223,40 @@ func run(
 	if err != nil {
 		return fmt.Errorf("--%s: %v", outputFlagName, err)
 	}
-	return bufcli.NewWireProtoEncodingWriter(
+	// We read the first message before creating the output, so that an invalid
+	// input does not result in an empty output file.
+	// An empty stream results in an empty output.
+	message, err := messageReader.Next()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	messageWriter, err := bufcli.NewWireProtoEncodingWriter(
 		container.Logger(),
-	).PutMessage(
+	).NewMessageWriter(
 		ctx,
 		container,
 		image,
-		message,
 		outputMessageRef,
 	)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, messageWriter.Close())
+	}()
+	for message != nil {
+		if err := messageWriter.Write(message); err != nil {
+			return err
+		}
+		message, err = messageReader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
 }
 
 // inverseEncoding returns the opposite encoding of the provided encoding,
@@ -210,6 +267,12 @@ func inverseEncoding(encoding bufconvert.MessageEncoding) (bufconvert.MessageEnc
 		return bufconvert.MessageEncodingJSON, nil
 	case bufconvert.MessageEncodingJSON:
 		return bufconvert.MessageEncodingBin, nil
+	case bufconvert.MessageEncodingBinDelimited:
+		return bufconvert.MessageEncodingJSONL, nil
+	case bufconvert.MessageEncodingJSONL:
+		return bufconvert.MessageEncodingBinDelimited, nil
+	case bufconvert.MessageEncodingTxtpb, bufconvert.MessageEncodingYAML:
+		return bufconvert.MessageEncodingBin, nil
 	default:
 		return 0, fmt.Errorf("unknown message encoding %v", encoding)
 	}
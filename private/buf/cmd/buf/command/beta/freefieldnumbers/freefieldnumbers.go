@@ -0,0 +1,333 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freefieldnumbers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	errorFormatFlagName     = "error-format"
+	configFlagName          = "config"
+	pathsFlagName           = "path"
+	excludePathsFlagName    = "exclude-path"
+	disableSymlinksFlagName = "disable-symlinks"
+	typeFlagName            = "type"
+	suggestFlagName         = "suggest"
+	formatFlagName          = "format"
+
+	formatText = "text"
+	formatJSON = "json"
+
+	kindMessage = "message"
+	kindEnum    = "enum"
+)
+
+var allFormats = []string{formatText, formatJSON}
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <input>",
+		Short: "Print the free field numbers of messages and the free numbers of enums.",
+		Long: `The free numbers are the numbers that are not used by a field or value, and that are
+not reserved. Extension ranges are not free. Only non-negative numbers are printed for enums.
+
+The free numbers of all messages and enums of the input files are printed, unless --` + typeFlagName + `
+is given. The text output matches the output of protoc --print_free_field_numbers.
+
+With --` + suggestFlagName + ` N, up to N numbers that are safe to use for new fields or values are
+also printed. These numbers are greater than all used and reserved numbers, so that numbers of
+deleted fields and values that were not reserved are never suggested. For messages, extension
+ranges and the numbers 19000 to 19999 reserved for the Protobuf implementation are skipped.
+
+` + bufcli.GetInputLong(`the source, module, or image to print the free numbers for`),
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	ErrorFormat     string
+	Config          string
+	Paths           []string
+	ExcludePaths    []string
+	DisableSymlinks bool
+	Types           []string
+	Suggest         int
+	Format          string
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stderr. Must be one of %s.",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The file or data to use for configuration.`,
+	)
+	flagSet.StringSliceVar(
+		&f.Types,
+		typeFlagName,
+		nil,
+		`The fully-qualified names of the messages and enums to print the free numbers for. If not specified, all messages and enums of the input files are printed.`,
+	)
+	flagSet.IntVar(
+		&f.Suggest,
+		suggestFlagName,
+		0,
+		`The number of safe numbers to suggest for new fields and values of each message and enum.`,
+	)
+	flagSet.StringVar(
+		&f.Format,
+		formatFlagName,
+		formatText,
+		fmt.Sprintf(
+			"The format to print the free numbers as. Must be one of %s.",
+			stringutil.SliceToString(allFormats),
+		),
+	)
+}
+
+// freeNumbers are the free numbers of a message or enum.
+type freeNumbers struct {
+	Type      string      `json:"type"`
+	Kind      string      `json:"kind"`
+	Path      string      `json:"path"`
+	Free      []freeRange `json:"free"`
+	Suggested []int       `json:"suggested,omitempty"`
+}
+
+// freeRange is an inclusive range of free numbers.
+type freeRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+
+	max bool
+}
+
+// String returns the range in the format of protoc --print_free_field_numbers.
+func (r freeRange) String() string {
+	if r.Start == r.End {
+		return fmt.Sprintf("%d", r.Start)
+	}
+	if r.max {
+		return fmt.Sprintf("%d-INF", r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
+		return err
+	}
+	if flags.Format != formatText && flags.Format != formatJSON {
+		return appcmd.NewInvalidArgumentErrorf(
+			"--%s must be one of %s but was %q",
+			formatFlagName,
+			stringutil.SliceToString(allFormats),
+			flags.Format,
+		)
+	}
+	if flags.Suggest < 0 {
+		return appcmd.NewInvalidArgumentErrorf("--%s must not be negative", suggestFlagName)
+	}
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+	if err != nil {
+		return err
+	}
+	image, err := bufcli.NewImageForSource(
+		ctx,
+		container,
+		input,
+		flags.ErrorFormat,
+		flags.DisableSymlinks,
+		flags.Config,
+		flags.Paths,
+		flags.ExcludePaths,
+		false, // externalDirOrFilePathsAllowNotExist
+		true,  // excludeSourceCodeInfo
+	)
+	if err != nil {
+		return err
+	}
+	files, err := protosource.NewFilesUnstable(
+		ctx,
+		bufimageutil.NewInputFiles(bufimage.ImageWithoutImports(image).Files())...,
+	)
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i int, j int) bool { return files[i].Path() < files[j].Path() })
+	var allFreeNumbers []*freeNumbers
+	for _, file := range files {
+		for _, message := range file.Messages() {
+			allFreeNumbers = getMessageFreeNumbersRec(allFreeNumbers, message, flags.Suggest)
+		}
+		for _, enum := range file.Enums() {
+			allFreeNumbers = append(allFreeNumbers, getEnumFreeNumbers(enum, flags.Suggest))
+		}
+	}
+	if len(flags.Types) > 0 {
+		allFreeNumbers, err = filterFreeNumbersByTypes(allFreeNumbers, flags.Types)
+		if err != nil {
+			return err
+		}
+	}
+	for _, freeNumbers := range allFreeNumbers {
+		if err := printFreeNumbers(container.Stdout(), freeNumbers, flags.Format == formatJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMessageFreeNumbersRec gets the free numbers of the message and its nested
+// messages and enums.
+//
+// Nested messages are printed before their parent, the same as protoc.
+func getMessageFreeNumbersRec(allFreeNumbers []*freeNumbers, message protosource.Message, suggest int) []*freeNumbers {
+	for _, nestedMessage := range message.Messages() {
+		allFreeNumbers = getMessageFreeNumbersRec(allFreeNumbers, nestedMessage, suggest)
+	}
+	for _, nestedEnum := range message.Enums() {
+		allFreeNumbers = append(allFreeNumbers, getEnumFreeNumbers(nestedEnum, suggest))
+	}
+	messageFreeNumbers := &freeNumbers{
+		Type: message.FullName(),
+		Kind: kindMessage,
+		Path: message.File().Path(),
+	}
+	for _, messageRange := range protosource.FreeMessageRanges(message) {
+		messageFreeNumbers.Free = append(messageFreeNumbers.Free, newFreeRange(messageRange))
+	}
+	if suggest > 0 {
+		messageFreeNumbers.Suggested = protosource.SuggestedMessageFieldNumbers(message, suggest)
+	}
+	return append(allFreeNumbers, messageFreeNumbers)
+}
+
+func getEnumFreeNumbers(enum protosource.Enum, suggest int) *freeNumbers {
+	enumFreeNumbers := &freeNumbers{
+		Type: enum.FullName(),
+		Kind: kindEnum,
+		Path: enum.File().Path(),
+	}
+	for _, enumRange := range protosource.FreeEnumRanges(enum) {
+		enumFreeNumbers.Free = append(enumFreeNumbers.Free, newFreeRange(enumRange))
+	}
+	if suggest > 0 {
+		enumFreeNumbers.Suggested = protosource.SuggestedEnumNumbers(enum, suggest)
+	}
+	return enumFreeNumbers
+}
+
+func newFreeRange(tagRange protosource.TagRange) freeRange {
+	return freeRange{
+		Start: tagRange.Start(),
+		End:   tagRange.End(),
+		max:   tagRange.Max(),
+	}
+}
+
+// filterFreeNumbersByTypes returns the free numbers of the types, in the order of the types.
+func filterFreeNumbersByTypes(allFreeNumbers []*freeNumbers, types []string) ([]*freeNumbers, error) {
+	typeToFreeNumbers := make(map[string]*freeNumbers, len(allFreeNumbers))
+	for _, freeNumbers := range allFreeNumbers {
+		typeToFreeNumbers[freeNumbers.Type] = freeNumbers
+	}
+	filteredFreeNumbers := make([]*freeNumbers, 0, len(types))
+	for _, typeName := range types {
+		freeNumbers, ok := typeToFreeNumbers[typeName]
+		if !ok {
+			return nil, fmt.Errorf("--%s: message or enum %q not found in the input files", typeFlagName, typeName)
+		}
+		filteredFreeNumbers = append(filteredFreeNumbers, freeNumbers)
+	}
+	return filteredFreeNumbers, nil
+}
+
+func printFreeNumbers(writer io.Writer, freeNumbers *freeNumbers, asJSON bool) error {
+	if asJSON {
+		data, err := json.Marshal(freeNumbers)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(writer, string(data))
+		return err
+	}
+	freeStrings := make([]string, len(freeNumbers.Free))
+	for i, freeRange := range freeNumbers.Free {
+		freeStrings[i] = freeRange.String()
+	}
+	line := fmt.Sprintf("%- 35s free: %s", freeNumbers.Type, strings.Join(freeStrings, " "))
+	if len(freeNumbers.Suggested) > 0 {
+		suggestedStrings := make([]string, len(freeNumbers.Suggested))
+		for i, suggested := range freeNumbers.Suggested {
+			suggestedStrings[i] = fmt.Sprintf("%d", suggested)
+		}
+		line += " suggested: " + strings.Join(suggestedStrings, " ")
+	}
+	_, err := fmt.Fprintln(writer, line)
+	return err
+}
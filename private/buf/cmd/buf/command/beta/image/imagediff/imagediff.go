@@ -0,0 +1,240 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagediff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/diff"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	errorFormatFlagName     = "error-format"
+	excludeImportsFlagName  = "exclude-imports"
+	pathsFlagName           = "path"
+	excludePathsFlagName    = "exclude-path"
+	disableSymlinksFlagName = "disable-symlinks"
+
+	// devNullFilename is the name printed in the diff header for the
+	// missing side of a file that only exists in one image.
+	devNullFilename = "/dev/null"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <input-a> <input-b>",
+		Short: "Print the descriptor-level diff between two inputs.",
+		Long: `Both inputs are built into images, and the FileDescriptorProtos of every file are
+compared after removing source code info and sorting all elements whose declaration
+order has no meaning, such as imports, messages, fields, enum values, services and
+methods. Comments, formatting and declaration order are therefore not reported. The
+first value of each enum is not sorted, as it is the default value of the enum.
+
+The diff is printed to stdout as a unified diff of the JSON representation of each
+changed FileDescriptorProto, with a/<path> for <input-a> and b/<path> for <input-b>.
+
+This command exits with code 0 if the inputs are equal, and with code ` + fmt.Sprintf("%d", bufcli.ExitCodeFileAnnotation) + ` if
+there is a diff.
+
+<input-a> and <input-b> are the same inputs as any other buf command, for example:
+
+$ buf beta image diff buf.build/acme/weather https://github.com/acme/weather.git#branch=main
+$ buf beta image diff previous.bin .`,
+		Args: cobra.ExactArgs(2),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	ErrorFormat     string
+	ExcludeImports  bool
+	Paths           []string
+	ExcludePaths    []string
+	DisableSymlinks bool
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindExcludeImports(flagSet, &f.ExcludeImports, excludeImportsFlagName)
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stderr. Must be one of %s.",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
+		return err
+	}
+	imageA, err := newImage(ctx, container, container.Arg(0), flags)
+	if err != nil {
+		return err
+	}
+	imageB, err := newImage(ctx, container, container.Arg(1), flags)
+	if err != nil {
+		return err
+	}
+	pathToDataA, err := normalizedImageData(imageA)
+	if err != nil {
+		return err
+	}
+	pathToDataB, err := normalizedImageData(imageB)
+	if err != nil {
+		return err
+	}
+	pathMap := make(map[string]struct{}, len(pathToDataA))
+	for path := range pathToDataA {
+		pathMap[path] = struct{}{}
+	}
+	for path := range pathToDataB {
+		pathMap[path] = struct{}{}
+	}
+	paths := make([]string, 0, len(pathMap))
+	for path := range pathMap {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	runner := command.NewRunner()
+	diffPresent := false
+	for _, path := range paths {
+		dataA, okA := pathToDataA[path]
+		dataB, okB := pathToDataB[path]
+		filenameA := "a/" + path
+		if !okA {
+			filenameA = devNullFilename
+		}
+		filenameB := "b/" + path
+		if !okB {
+			filenameB = devNullFilename
+		}
+		diffData, err := diff.Diff(
+			ctx,
+			runner,
+			dataA,
+			dataB,
+			filenameA,
+			filenameB,
+			diff.DiffWithSuppressTimestamps(),
+		)
+		if err != nil {
+			return err
+		}
+		if len(diffData) == 0 {
+			continue
+		}
+		diffPresent = true
+		if _, err := container.Stdout().Write(diffData); err != nil {
+			return err
+		}
+	}
+	if diffPresent {
+		return bufcli.ErrFileAnnotation
+	}
+	return nil
+}
+
+func newImage(
+	ctx context.Context,
+	container appflag.Container,
+	input string,
+	flags *flags,
+) (bufimage.Image, error) {
+	image, err := bufcli.NewImageForSource(
+		ctx,
+		container,
+		input,
+		flags.ErrorFormat,
+		flags.DisableSymlinks,
+		"", // configOverride
+		flags.Paths,
+		flags.ExcludePaths,
+		true, // externalDirOrFilePathsAllowNotExist
+		true, // excludeSourceCodeInfo
+	)
+	if err != nil {
+		return nil, err
+	}
+	if flags.ExcludeImports {
+		image = bufimage.ImageWithoutImports(image)
+	}
+	return image, nil
+}
+
+// normalizedImageData returns the indented JSON of the normalized
+// FileDescriptorProto of every file in the image, keyed by path.
+func normalizedImageData(image bufimage.Image) (map[string][]byte, error) {
+	resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptors(image)...)
+	if err != nil {
+		return nil, err
+	}
+	marshaler := protoencoding.NewJSONMarshalerUseProtoNames(resolver)
+	pathToData := make(map[string][]byte, len(image.Files()))
+	for _, imageFile := range image.Files() {
+		data, err := marshaler.Marshal(
+			bufimageutil.NormalizedFileDescriptorProto(imageFile.Proto()),
+		)
+		if err != nil {
+			return nil, err
+		}
+		buffer := bytes.NewBuffer(nil)
+		if err := json.Indent(buffer, data, "", "  "); err != nil {
+			return nil, err
+		}
+		_, _ = buffer.WriteString("\n")
+		pathToData[imageFile.Path()] = buffer.Bytes()
+	}
+	return pathToData, nil
+}
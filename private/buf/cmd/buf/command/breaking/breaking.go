@@ -23,7 +23,9 @@ import (
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingreport"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
@@ -43,6 +45,7 @@ const (
 	againstConfigFlagName     = "against-config"
 	excludePathsFlagName      = "exclude-path"
 	disableSymlinksFlagName   = "disable-symlinks"
+	reportFlagName            = "report"
 )
 
 // NewCommand returns a new Command.
@@ -76,6 +79,7 @@ type flags struct {
 	AgainstConfig     string
 	ExcludePaths      []string
 	DisableSymlinks   bool
+	Report            string
 	// special
 	InputHashtag string
 }
@@ -136,6 +140,17 @@ Overrides --%s.`,
 		"",
 		`The file or data to use to configure the against source, module, or image.`,
 	)
+	flagSet.StringVar(
+		&f.Report,
+		reportFlagName,
+		"",
+		fmt.Sprintf(
+			`Print a report of all added, removed, and changed elements to stdout instead of checking for breaking changes.
+This includes changes that are not breaking, and can be used to generate API changelogs.
+Must be one of %s.`,
+			stringutil.SliceToString(bufbreakingreport.AllFormatStrings),
+		),
+	)
 }
 
 func run(
@@ -149,6 +164,11 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
+	if flags.Report != "" {
+		if _, err := bufbreakingreport.ParseFormat(flags.Report); err != nil {
+			return appcmd.NewInvalidArgumentErrorf("--%s: invalid format: %q", reportFlagName, flags.Report)
+		}
+	}
 	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
 	if err != nil {
 		return err
@@ -240,8 +260,32 @@ func run(
 		// we're torched.
 		return fmt.Errorf("input contained %d images, whereas against contained %d images", len(imageConfigs), len(againstImageConfigs))
 	}
+	if flags.Report != "" {
+		var allChanges []bufbreakingreport.Change
+		for i, imageConfig := range imageConfigs {
+			changes, err := reportForImage(
+				ctx,
+				container,
+				imageConfig,
+				againstImageConfigs[i],
+				flags.ExcludeImports,
+			)
+			if err != nil {
+				return err
+			}
+			allChanges = append(allChanges, changes...)
+		}
+		bufbreakingreport.SortChanges(allChanges)
+		return bufbreakingreport.PrintChanges(container.Stdout(), allChanges, flags.Report)
+	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
+	var allRules []bufcheck.Rule
 	for i, imageConfig := range imageConfigs {
+		rules, err := bufbreaking.RulesForConfig(imageConfig.Config().Breaking)
+		if err != nil {
+			return err
+		}
+		allRules = append(allRules, rules...)
 		fileAnnotations, err := breakingForImage(
 			ctx,
 			container,
@@ -260,6 +304,7 @@ func run(
 			container.Stdout(),
 			bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations),
 			flags.ErrorFormat,
+			bufcheck.PrintFileAnnotationsWithRules(allRules),
 		); err != nil {
 			return err
 		}
@@ -292,6 +337,28 @@ func breakingForImage(
 	)
 }
 
+func reportForImage(
+	ctx context.Context,
+	container appflag.Container,
+	imageConfig bufwire.ImageConfig,
+	againstImageConfig bufwire.ImageConfig,
+	excludeImports bool,
+) ([]bufbreakingreport.Change, error) {
+	image := imageConfig.Image()
+	if excludeImports {
+		image = bufimage.ImageWithoutImports(image)
+	}
+	againstImage := againstImageConfig.Image()
+	if excludeImports {
+		againstImage = bufimage.ImageWithoutImports(againstImage)
+	}
+	return bufbreaking.NewHandler(container.Logger()).Report(
+		ctx,
+		againstImage,
+		image,
+	)
+}
+
 func getExternalPathsForImages(imageConfigs []bufwire.ImageConfig, excludeImports bool) ([]string, error) {
 	externalPaths := make(map[string]struct{})
 	for _, imageConfig := range imageConfigs {
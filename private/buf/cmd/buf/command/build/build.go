@@ -41,6 +41,8 @@ const (
 	configFlagName              = "config"
 	excludePathsFlagName        = "exclude-path"
 	disableSymlinksFlagName     = "disable-symlinks"
+	typeFlagName                = "type"
+	excludeTypeFlagName         = "exclude-type"
 )
 
 // NewCommand returns a new Command.
@@ -75,6 +77,7 @@ type flags struct {
 	ExcludePaths        []string
 	DisableSymlinks     bool
 	Types               []string
+	ExcludeTypes        []string
 	// special
 	InputHashtag string
 }
@@ -116,12 +119,8 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		"",
 		`The file or data to use to use for configuration.`,
 	)
-	flagSet.StringSliceVar(
-		&f.Types,
-		"type",
-		nil,
-		"The types (message, enum, service) that should be included in this image. When specified, the resulting image will only include descriptors to describe the requested types.",
-	)
+	bufcli.BindTypes(flagSet, &f.Types, typeFlagName)
+	bufcli.BindExcludeTypes(flagSet, &f.ExcludeTypes, excludeTypeFlagName)
 }
 
 func run(
@@ -158,8 +157,12 @@ func run(
 	if err != nil {
 		return fmt.Errorf("--%s: %v", outputFlagName, err)
 	}
-	if len(flags.Types) > 0 {
-		image, err = bufimageutil.ImageFilteredByTypes(image, flags.Types...)
+	if len(flags.Types) > 0 || len(flags.ExcludeTypes) > 0 {
+		image, err = bufimageutil.ImageFilteredByTypesWithOptions(
+			image,
+			flags.Types,
+			bufimageutil.WithExcludeTypes(flags.ExcludeTypes...),
+		)
 		if err != nil {
 			return err
 		}
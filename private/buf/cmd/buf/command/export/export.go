@@ -17,6 +17,7 @@ package export
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
@@ -24,6 +25,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
@@ -32,6 +34,8 @@ import (
 	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoprint"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"go.uber.org/multierr"
@@ -45,6 +49,8 @@ const (
 	configFlagName          = "config"
 	excludePathsFlagName    = "exclude-path"
 	disableSymlinksFlagName = "disable-symlinks"
+	typeFlagName            = "type"
+	excludeTypeFlagName     = "exclude-type"
 )
 
 // NewCommand returns a new Command.
@@ -56,8 +62,13 @@ func NewCommand(
 	return &appcmd.Command{
 		Use:   name + " <input>",
 		Short: "Export the files from the input location to an output location.",
-		Long:  bufcli.GetInputLong(`the source or module to export`),
-		Args:  cobra.MaximumNArgs(1),
+		Long: `If --` + typeFlagName + ` or --` + excludeTypeFlagName + ` is specified, only the files that are needed to describe
+the requested types are exported, and the exported files are pruned of all declarations
+that the requested types do not depend on. The pruned files are printed from the
+compiled descriptors, so their formatting may differ from the original files.
+
+` + bufcli.GetInputLong(`the source or module to export`),
+		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
 			func(ctx context.Context, container appflag.Container) error {
 				return run(ctx, container, flags)
@@ -75,6 +86,8 @@ type flags struct {
 	Config          string
 	ExcludePaths    []string
 	DisableSymlinks bool
+	Types           []string
+	ExcludeTypes    []string
 
 	// special
 	InputHashtag string
@@ -90,6 +103,8 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	bufcli.BindExcludeImports(flagSet, &f.ExcludeImports, excludeImportsFlagName)
 	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
 	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindTypes(flagSet, &f.Types, typeFlagName)
+	bufcli.BindExcludeTypes(flagSet, &f.ExcludeTypes, excludeTypeFlagName)
 	flagSet.StringVarP(
 		&f.Output,
 		outputFlagName,
@@ -167,9 +182,10 @@ func run(
 		}
 		moduleFileSets[i] = moduleFileSet
 	}
-	// There are two cases where we need an image to filter the output:
+	// There are three cases where we need an image to filter the output:
 	//   1) the input is a proto file reference
 	//   2) ensuring that we are including the relevant imports
+	//   3) filtering by types
 	//
 	// In the first scenario, the imageConfigReader returns imageCongfigs that handle the filtering
 	// for the proto file ref.
@@ -177,11 +193,20 @@ func run(
 	// To handle imports for all other references, unless we are excluding imports, we only want
 	// to export those imports that are actually used. To figure this out, we build an image of images
 	// and use the fact that something is in an image to determine if it is actually used.
+	//
+	// When filtering by types, the filtered image is the basis for outputting the files, which
+	// are printed from the pruned descriptors instead of being copied.
 	var images []bufimage.Image
 	_, isProtoFileRef := sourceOrModuleRef.(buffetch.ProtoFileRef)
+	filterByTypes := len(flags.Types) > 0 || len(flags.ExcludeTypes) > 0
 	// We gate on flags.ExcludeImports/buffetch.ProtoFileRef so that we don't waste time building if the
 	// result of the build is not relevant.
-	if !flags.ExcludeImports {
+	if !flags.ExcludeImports || filterByTypes {
+		var buildOptions []bufimagebuild.BuildOption
+		if !filterByTypes {
+			// SourceCodeInfo is only needed to print the comments of the pruned files.
+			buildOptions = append(buildOptions, bufimagebuild.WithExcludeSourceCodeInfo())
+		}
 		imageBuilder := bufimagebuild.NewBuilder(container.Logger())
 		for _, moduleFileSet := range moduleFileSets {
 			targetFileInfos, err := moduleFileSet.TargetFileInfos(ctx)
@@ -196,7 +221,7 @@ func run(
 			image, fileAnnotations, err := imageBuilder.Build(
 				ctx,
 				moduleFileSet,
-				bufimagebuild.WithExcludeSourceCodeInfo(),
+				buildOptions...,
 			)
 			if err != nil {
 				return err
@@ -270,6 +295,20 @@ func run(
 	if err != nil {
 		return err
 	}
+	if filterByTypes {
+		if mergedImage == nil {
+			return errors.New("no .proto target files found")
+		}
+		filteredImage, err := bufimageutil.ImageFilteredByTypesWithOptions(
+			mergedImage,
+			flags.Types,
+			bufimageutil.WithExcludeTypes(flags.ExcludeTypes...),
+		)
+		if err != nil {
+			return err
+		}
+		return writePrunedFiles(ctx, readWriteBucket, moduleFileSets, filteredImage, flags.ExcludeImports)
+	}
 	fileInfosFunc := bufmodule.ModuleFileSet.AllFileInfos
 	// If we filtered on some paths, only use the targets.
 	// Otherwise, we want to print everything, including potentially imports.
@@ -354,3 +393,60 @@ func run(
 	}
 	return nil
 }
+
+// writePrunedFiles prints the files of the filtered image to the bucket.
+//
+// Only files that are part of the ModuleFileSets are written, so that the Well-Known Types
+// are not written unless they are part of the input, the same as when copying files.
+func writePrunedFiles(
+	ctx context.Context,
+	writeBucket storage.WriteBucket,
+	moduleFileSets []bufmodule.ModuleFileSet,
+	filteredImage bufimage.Image,
+	excludeImports bool,
+) error {
+	moduleFilePaths := make(map[string]struct{})
+	for _, moduleFileSet := range moduleFileSets {
+		fileInfos, err := moduleFileSet.AllFileInfos(ctx)
+		if err != nil {
+			return err
+		}
+		for _, fileInfo := range fileInfos {
+			moduleFilePaths[fileInfo.Path()] = struct{}{}
+		}
+	}
+	fileDescriptors, err := desc.CreateFileDescriptorsFromSet(bufimage.ImageToFileDescriptorSet(filteredImage))
+	if err != nil {
+		return err
+	}
+	printer := &protoprint.Printer{}
+	writtenPaths := 0
+	for _, imageFile := range filteredImage.Files() {
+		path := imageFile.Path()
+		if excludeImports && imageFile.IsImport() {
+			continue
+		}
+		if _, ok := moduleFilePaths[path]; !ok {
+			continue
+		}
+		fileDescriptor, ok := fileDescriptors[path]
+		if !ok {
+			return fmt.Errorf("unexpected missing file descriptor for %q", path)
+		}
+		writeObjectCloser, err := writeBucket.Put(ctx, path)
+		if err != nil {
+			return err
+		}
+		if err := printer.PrintProtoFile(fileDescriptor, writeObjectCloser); err != nil {
+			return multierr.Append(err, writeObjectCloser.Close())
+		}
+		if err := writeObjectCloser.Close(); err != nil {
+			return err
+		}
+		writtenPaths++
+	}
+	if writtenPaths == 0 {
+		return errors.New("no .proto target files found")
+	}
+	return nil
+}
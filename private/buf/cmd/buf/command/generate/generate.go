@@ -23,6 +23,7 @@ import (
 	"github.com/bufbuild/buf/private/buf/bufgen"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
 	"github.com/bufbuild/buf/private/pkg/command"
@@ -43,6 +44,11 @@ const (
 	includeWKTFlagName          = "include-wkt"
 	excludePathsFlagName        = "exclude-path"
 	disableSymlinksFlagName     = "disable-symlinks"
+	typeFlagName                = "type"
+	excludeTypeFlagName         = "exclude-type"
+	explainManagedFlagName      = "explain-managed"
+	maxParallelPluginsFlagName  = "max-parallel-plugins"
+	noCacheFlagName             = "no-cache"
 )
 
 // NewCommand returns a new Command.
@@ -103,6 +109,11 @@ plugins:
     # If omitted, "directory" is used. Most users should not need to set this option.
     # Optional.
     strategy: directory
+    # The maximum duration the plugin can run for, for example "30s" or "5m".
+    # If the plugin has not completed after this duration, it is stopped and buf generate fails.
+    # If omitted, there is no timeout.
+    # Optional.
+    timeout: 1m
   - name: java
     out: gen/java
     # Use the plugin hosted at buf.build/protocolbuffers/plugins/python at version v3.17.0-1.
@@ -164,9 +175,39 @@ Note that all paths must be contained within the same module. For example, if yo
 module in "proto", you cannot specify "--path proto", however "--path proto/foo" is allowed
 as "proto/foo" is contained within "proto".
 
+You can also generate for a subset of the types of your input via the --type flag. Only the
+requested types and the types they depend on are generated, and the files are pruned of
+all other declarations. Types can be left out via the --exclude-type flag:
+
+# Only generate for the service foo.v1.FooService and the types it depends on
+$ buf generate --type foo.v1.FooService
+
+# Generate for all types except foo.v1.Internal
+$ buf generate --exclude-type foo.v1.Internal
+
+If managed mode is enabled in the template, the --explain-managed flag prints the value of each file
+option managed by managed mode for each file, along with the setting or rule that decided it,
+instead of generating:
+
+# Print which managed mode settings and rules apply to each file
+$ buf generate --explain-managed
+
 Plugins are invoked in the order they are specified in the template, but each plugin
 has a per-directory parallel invocation, with results from each invocation combined
-before writing the result.
+before writing the result. The --max-parallel-plugins flag limits how many plugins are
+invoked at once, and --verbose prints how long each plugin took:
+
+# Run at most two plugins at once and print the time of each plugin
+$ buf generate --max-parallel-plugins 2 --verbose
+
+The responses of plugins are cached, and an invocation of a plugin is skipped if it has not
+changed since the response was cached. The invocation of a local plugin has not changed if
+the plugin binary and the requests sent to it are the same. Remote plugins are only cached if
+they are pinned to a version, and for plugins set with "plugin", to a revision. The --no-cache
+flag disables the cache:
+
+# Run all plugins, even if their invocations have not changed
+$ buf generate --no-cache
 
 Insertion points are processed in the order the plugins are specified in the template.
 `,
@@ -192,6 +233,12 @@ type flags struct {
 	IncludeWKT      bool
 	ExcludePaths    []string
 	DisableSymlinks bool
+	Types           []string
+	ExcludeTypes    []string
+	ExplainManaged  bool
+	// MaxParallelPlugins is 0 if not set.
+	MaxParallelPlugins int
+	NoCache            bool
 	// special
 	InputHashtag string
 }
@@ -205,6 +252,8 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
 	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
 	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindTypes(flagSet, &f.Types, typeFlagName)
+	bufcli.BindExcludeTypes(flagSet, &f.ExcludeTypes, excludeTypeFlagName)
 	flagSet.BoolVar(
 		&f.IncludeImports,
 		includeImportsFlagName,
@@ -220,6 +269,24 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 			includeImportsFlagName,
 		),
 	)
+	flagSet.BoolVar(
+		&f.ExplainManaged,
+		explainManagedFlagName,
+		false,
+		"Print the value of each file option managed by managed mode, and the setting that decided it, instead of generating.",
+	)
+	flagSet.IntVar(
+		&f.MaxParallelPlugins,
+		maxParallelPluginsFlagName,
+		0,
+		"The maximum number of plugins to run at once. Defaults to the number of CPUs.",
+	)
+	flagSet.BoolVar(
+		&f.NoCache,
+		noCacheFlagName,
+		false,
+		"Run all plugins instead of reusing the cached responses of plugin invocations that have not changed.",
+	)
 	flagSet.StringVar(
 		&f.Template,
 		templateFlagName,
@@ -266,6 +333,9 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
+	if flags.MaxParallelPlugins < 0 {
+		return appcmd.NewInvalidArgumentErrorf("--%s cannot be negative", maxParallelPluginsFlagName)
+	}
 	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
 	if err != nil {
 		return err
@@ -292,6 +362,9 @@ func run(
 	if err != nil {
 		return err
 	}
+	if flags.ExplainManaged && genConfig.ManagedConfig == nil {
+		return appcmd.NewInvalidArgumentErrorf("--%s requires managed mode to be enabled in the generation template", explainManagedFlagName)
+	}
 	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
 	if err != nil {
 		return err
@@ -332,6 +405,19 @@ func run(
 	if err != nil {
 		return err
 	}
+	if len(flags.Types) > 0 || len(flags.ExcludeTypes) > 0 {
+		image, err = bufimageutil.ImageFilteredByTypesWithOptions(
+			image,
+			flags.Types,
+			bufimageutil.WithExcludeTypes(flags.ExcludeTypes...),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if flags.ExplainManaged {
+		return bufgen.ExplainManaged(ctx, logger, genConfig, image, container.Stdout())
+	}
 	generateOptions := []bufgen.GenerateOption{
 		bufgen.GenerateWithBaseOutDirPath(flags.BaseOutDirPath),
 	}
@@ -347,8 +433,25 @@ func run(
 			bufgen.GenerateWithIncludeWellKnownTypes(),
 		)
 	}
+	if flags.MaxParallelPlugins > 0 {
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithMaxParallelPlugins(flags.MaxParallelPlugins),
+		)
+	}
+	if !flags.NoCache {
+		cacheDirPath, err := bufcli.NewGenerateCacheDirPathAndCreateCacheDir(container)
+		if err != nil {
+			return err
+		}
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithCacheDirPath(cacheDirPath),
+		)
+	}
 	return bufgen.NewGenerator(
 		logger,
+		container.VerbosePrinter(),
 		storageosProvider,
 		runner,
 		registryProvider,
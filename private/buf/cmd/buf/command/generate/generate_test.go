@@ -22,6 +22,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/bufbuild/buf/private/buf/bufgen"
@@ -151,6 +152,223 @@ func TestOutputFlag(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGenerateTypeNotFound(t *testing.T) {
+	t.Parallel()
+	testRunStdoutStderr(
+		t,
+		nil,
+		1,
+		``,
+		`Failure: filtering by type "a.v1.Bar": not found`,
+		"--output",
+		t.TempDir(),
+		"--template",
+		filepath.Join("testdata", "simple", "buf.gen.yaml"),
+		"--type",
+		"a.v1.Bar",
+		filepath.Join("testdata", "simple"),
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		1,
+		``,
+		`Failure: excluding type "a.v1.Bar": not found`,
+		"--output",
+		t.TempDir(),
+		"--template",
+		filepath.Join("testdata", "simple", "buf.gen.yaml"),
+		"--exclude-type",
+		"a.v1.Bar",
+		filepath.Join("testdata", "simple"),
+	)
+}
+
+func TestGenerateExplainManaged(t *testing.T) {
+	t.Parallel()
+	testRunStdoutStderr(
+		t,
+		nil,
+		0,
+		`
+Path                     Option                  Value                              Decided by
+a/v1/a.proto             java_package            "build.buf.acme.a.v1"              rule 1
+a/v1/a.proto             java_outer_classname    "AProto"                           default
+a/v1/a.proto             java_multiple_files     true                               default
+a/v1/a.proto             go_package              "github.com/acme/gen/go/a/v1;av1"  go_package_prefix.default
+a/v1/a.proto             cc_enable_arenas        false                              cc_enable_arenas
+a/v1/a.proto             objc_class_prefix       "ACM"                              rule 3
+a/v1/a.proto             csharp_namespace        "Acme.A"                           override
+a/v1/a.proto             php_namespace           "Acme\\A\\V1"                      default
+a/v1/a.proto             php_metadata_namespace  "Acme\\A\\V1\\GPBMetadata"         default
+a/v1/a.proto             ruby_package            "Acme::A::V1"                      default
+internal/internal.proto  java_package            "com.internal"                     default
+internal/internal.proto  java_outer_classname    "InternalProto"                    default
+internal/internal.proto  java_multiple_files     true                               default
+internal/internal.proto  go_package              "github.com/acme/internal"         rule 4
+internal/internal.proto  cc_enable_arenas        (unset)                            rule 2 (disable)
+internal/internal.proto  objc_class_prefix       "IXX"                              default
+internal/internal.proto  csharp_namespace        "Internal"                         default
+internal/internal.proto  php_namespace           "Internal"                         default
+internal/internal.proto  php_metadata_namespace  "Internal\\GPBMetadata"            default
+internal/internal.proto  ruby_package            "Internal"                         default
+`,
+		``,
+		"--explain-managed",
+		"--template",
+		filepath.Join("testdata", "managed", "buf.gen.yaml"),
+		filepath.Join("testdata", "managed"),
+	)
+}
+
+func TestGeneratePluginTimeout(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+	template := fmt.Sprintf(`
+version: v1
+plugins:
+  - name: sleep
+    out: gen
+    path: %s
+    timeout: 100ms
+`,
+		testWritePluginScript(t, "exec sleep 10"),
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		1,
+		``,
+		`Failure: plugin sleep timed out after 100ms`,
+		filepath.Join("testdata", "simple"),
+		"--template",
+		template,
+		"-o",
+		t.TempDir(),
+	)
+}
+
+func TestGenerateMaxParallelPlugins(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+	// The plugin reads the request and writes an empty response.
+	pluginPath := testWritePluginScript(t, "cat > /dev/null")
+	template := fmt.Sprintf(`
+version: v1
+plugins:
+  - name: empty
+    out: gen/a
+    path: %s
+  - name: empty
+    out: gen/b
+    path: %s
+`,
+		pluginPath,
+		pluginPath,
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		0,
+		``,
+		``,
+		filepath.Join("testdata", "simple"),
+		"--template",
+		template,
+		"-o",
+		t.TempDir(),
+		"--max-parallel-plugins",
+		"1",
+	)
+	stderr := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		1,
+		internaltesting.NewEnvFunc(t),
+		nil,
+		nil,
+		stderr,
+		filepath.Join("testdata", "simple"),
+		"--template",
+		template,
+		"--max-parallel-plugins",
+		"-1",
+	)
+	assert.Contains(t, stderr.String(), "Failure: --max-parallel-plugins cannot be negative")
+}
+
+func TestGenerateCache(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+	// The plugin records each invocation, and writes an empty response.
+	invocationsFilePath := filepath.Join(t.TempDir(), "invocations")
+	pluginPath := testWritePluginScript(t, fmt.Sprintf("echo >> %s\ncat > /dev/null", invocationsFilePath))
+	newTemplate := func(opt string) string {
+		return fmt.Sprintf(`
+version: v1
+plugins:
+  - name: record
+    out: gen
+    path: %s
+    opt: %s
+`,
+			pluginPath,
+			opt,
+		)
+	}
+	// All runs share the same cache directory.
+	envFunc := internaltesting.NewEnvFunc(t)
+	run := func(args ...string) {
+		appcmdtesting.RunCommandSuccess(
+			t,
+			func(name string) *appcmd.Command {
+				return NewCommand(
+					name,
+					appflag.NewBuilder(name),
+				)
+			},
+			envFunc,
+			nil,
+			nil,
+			append(
+				[]string{
+					filepath.Join("testdata", "simple"),
+					"-o",
+					t.TempDir(),
+				},
+				args...,
+			)...,
+		)
+	}
+	assertInvocations := func(expected int) {
+		data, err := os.ReadFile(invocationsFilePath)
+		require.NoError(t, err)
+		assert.Len(t, data, expected)
+	}
+	run("--template", newTemplate("foo"))
+	assertInvocations(1)
+	// The invocation has not changed, so the cached response is used.
+	run("--template", newTemplate("foo"))
+	assertInvocations(1)
+	run("--template", newTemplate("foo"), "--no-cache")
+	assertInvocations(2)
+	// The parameter is part of the request, so the invocation has changed.
+	run("--template", newTemplate("bar"))
+	assertInvocations(3)
+}
+
 func TestProtoFileRefIncludePackageFiles(t *testing.T) {
 	tempDirPath := t.TempDir()
 	testRunSuccess(
@@ -526,6 +744,12 @@ func testRunStdoutStderr(t *testing.T, stdin io.Reader, expectedExitCode int, ex
 	)
 }
 
+func testWritePluginScript(t *testing.T, command string) string {
+	pluginPath := filepath.Join(t.TempDir(), "protoc-gen-test")
+	require.NoError(t, os.WriteFile(pluginPath, []byte("#!/bin/sh\n"+command+"\n"), 0700))
+	return pluginPath
+}
+
 func newExternalConfigV1String(t *testing.T, plugins []*testPluginInfo, out string) string {
 	externalConfig := bufgen.ExternalConfigV1{
 		Version: "v1",
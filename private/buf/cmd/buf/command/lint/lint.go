@@ -15,21 +15,29 @@
 package lint
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/buf/buflintfix"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
 	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
 )
 
 const (
@@ -38,6 +46,10 @@ const (
 	pathsFlagName           = "path"
 	excludePathsFlagName    = "exclude-path"
 	disableSymlinksFlagName = "disable-symlinks"
+	writeBaselineFlagName   = "write-baseline"
+	fixFlagName             = "fix"
+	diffFlagName            = "diff"
+	diffFlagShortName       = "d"
 )
 
 // NewCommand returns a new Command.
@@ -67,6 +79,9 @@ type flags struct {
 	Paths           []string
 	ExcludePaths    []string
 	DisableSymlinks bool
+	WriteBaseline   string
+	Fix             bool
+	Diff            bool
 	// special
 	InputHashtag string
 }
@@ -95,6 +110,37 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		"",
 		`The file or data to use for configuration.`,
 	)
+	flagSet.StringVar(
+		&f.WriteBaseline,
+		writeBaselineFlagName,
+		"",
+		fmt.Sprintf(
+			`Write all current lint violations to the given baseline file instead of reporting them.
+Reference the file with the "baseline" key of the lint configuration, for example %q, so that only new violations fail.`,
+			buflintconfig.DefaultBaselineFilePath,
+		),
+	)
+	flagSet.BoolVar(
+		&f.Fix,
+		fixFlagName,
+		false,
+		fmt.Sprintf(
+			`Rewrite the source files in-place to fix the violations of rules that have a mechanical fix, and report the remaining violations.
+The rules with a mechanical fix are %s.
+References to renamed elements are renamed as well. Renames that would affect files that are not part of the input are skipped.`,
+			stringutil.SliceToHumanString(buflint.FixableIDs),
+		),
+	)
+	flagSet.BoolVarP(
+		&f.Diff,
+		diffFlagName,
+		diffFlagShortName,
+		false,
+		fmt.Sprintf(
+			"Display the diffs of the fixes that --%s would apply instead of rewriting files.",
+			fixFlagName,
+		),
+	)
 }
 
 func run(
@@ -105,6 +151,10 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlagLint(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
+	fix := flags.Fix || flags.Diff
+	if fix && flags.WriteBaseline != "" {
+		return fmt.Errorf("--%s and --%s cannot be used with --%s", fixFlagName, diffFlagName, writeBaselineFlagName)
+	}
 	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
 	if err != nil {
 		return err
@@ -113,6 +163,9 @@ func run(
 	if err != nil {
 		return err
 	}
+	if _, ok := ref.(buffetch.SourceRef); fix && !ok {
+		return fmt.Errorf("--%s and --%s can only be used with source inputs", fixFlagName, diffFlagName)
+	}
 	storageosProvider := bufcli.NewStorageosProvider(flags.DisableSymlinks)
 	runner := command.NewRunner()
 	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
@@ -152,22 +205,75 @@ func run(
 		return bufcli.ErrFileAnnotation
 	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
+	var allRules []bufcheck.Rule
+	var fixed bool
 	for _, imageConfig := range imageConfigs {
-		fileAnnotations, err := buflint.NewHandler(container.Logger()).Check(
+		lintConfig := imageConfig.Config().Lint
+		if flags.WriteBaseline != "" {
+			// We want every violation when writing the baseline, including
+			// the ones recorded in any existing baseline.
+			lintConfigCopy := *lintConfig
+			lintConfigCopy.Baseline = nil
+			lintConfig = &lintConfigCopy
+		}
+		rules, err := buflint.RulesForConfig(lintConfig)
+		if err != nil {
+			return err
+		}
+		allRules = append(allRules, rules...)
+		fileAnnotations, err := buflint.NewHandler(
+			container.Logger(),
+			buflint.HandlerWithPlugins(runner, container),
+		).Check(
 			ctx,
-			imageConfig.Config().Lint,
-			bufimage.ImageWithoutImports(imageConfig.Image()),
+			lintConfig,
+			imageConfig.Image(),
 		)
 		if err != nil {
 			return err
 		}
 		allFileAnnotations = append(allFileAnnotations, fileAnnotations...)
+		if fix {
+			imageFixed, err := fixImage(
+				ctx,
+				container,
+				runner,
+				imageConfig.Image(),
+				buflint.NewFixes(lintConfig, fileAnnotations),
+				flags.Diff,
+			)
+			if err != nil {
+				return err
+			}
+			fixed = fixed || imageFixed
+		}
+	}
+	if flags.Diff {
+		if len(allFileAnnotations) > 0 {
+			return bufcli.ErrFileAnnotation
+		}
+		return nil
+	}
+	if fixed {
+		// The fixes moved and renamed elements, so we lint the input again
+		// to report the remaining violations at their new locations.
+		flagsCopy := *flags
+		flagsCopy.Fix = false
+		return run(ctx, container, &flagsCopy)
+	}
+	if flags.WriteBaseline != "" {
+		data, err := buflintconfig.DataForBaseline(buflint.NewBaseline(allFileAnnotations))
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(flags.WriteBaseline, data, 0644)
 	}
 	if len(allFileAnnotations) > 0 {
 		if err := buflintconfig.PrintFileAnnotations(
 			container.Stdout(),
 			bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations),
 			flags.ErrorFormat,
+			bufcheck.PrintFileAnnotationsWithRules(allRules),
 		); err != nil {
 			return err
 		}
@@ -175,3 +281,125 @@ func run(
 	}
 	return nil
 }
+
+// fixImage applies the fixes to the source files of the image. If diff is true,
+// the diff between the original and fixed files is written to stdout instead.
+//
+// Returns true if any file was rewritten.
+func fixImage(
+	ctx context.Context,
+	container appflag.Container,
+	runner command.Runner,
+	image bufimage.Image,
+	fixes []*buflint.Fix,
+	diff bool,
+) (bool, error) {
+	if len(fixes) == 0 {
+		return false, nil
+	}
+	originalReadWriteBucket := storagemem.NewReadWriteBucket()
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			continue
+		}
+		// The external paths of source inputs are the paths of the files on disk,
+		// just like for buf format.
+		data, err := os.ReadFile(imageFile.ExternalPath())
+		if err != nil {
+			return false, fmt.Errorf("--%s requires the source files to be on disk: %w", fixFlagName, err)
+		}
+		if err := putPathWithExternalPath(ctx, originalReadWriteBucket, imageFile.Path(), imageFile.ExternalPath(), data); err != nil {
+			return false, err
+		}
+	}
+	fixedReadBucket, err := buflintfix.Fix(ctx, container.Logger(), originalReadWriteBucket, image, fixes)
+	if err != nil {
+		return false, err
+	}
+	if diff {
+		diffBuffer := bytes.NewBuffer(nil)
+		if err := storage.Diff(
+			ctx,
+			runner,
+			diffBuffer,
+			originalReadWriteBucket,
+			fixedReadBucket,
+			storage.DiffWithExternalPaths(), // No need to set prefixes as the buckets are from the same location.
+		); err != nil {
+			return false, err
+		}
+		_, err := io.Copy(container.Stdout(), diffBuffer)
+		return false, err
+	}
+	return rewrite(ctx, originalReadWriteBucket, fixedReadBucket)
+}
+
+// rewrite writes the files of the fixed bucket that differ from the original
+// bucket to their external paths, and removes the files that were renamed.
+//
+// Returns true if any file was rewritten.
+func rewrite(
+	ctx context.Context,
+	originalReadBucket storage.ReadBucket,
+	fixedReadBucket storage.ReadBucket,
+) (bool, error) {
+	rewritten := false
+	if err := storage.WalkReadObjects(
+		ctx,
+		fixedReadBucket,
+		"",
+		func(readObject storage.ReadObject) error {
+			data, err := io.ReadAll(readObject)
+			if err != nil {
+				return err
+			}
+			originalData, err := storage.ReadPath(ctx, originalReadBucket, readObject.Path())
+			if err == nil && bytes.Equal(data, originalData) {
+				return nil
+			}
+			if err != nil && !storage.IsNotExist(err) {
+				return err
+			}
+			rewritten = true
+			// We write to the external path for the same reasons as buf format.
+			return os.WriteFile(readObject.ExternalPath(), data, 0644)
+		},
+	); err != nil {
+		return false, err
+	}
+	if err := originalReadBucket.Walk(
+		ctx,
+		"",
+		func(objectInfo storage.ObjectInfo) error {
+			if _, err := fixedReadBucket.Stat(ctx, objectInfo.Path()); err == nil || !storage.IsNotExist(err) {
+				return err
+			}
+			// The file was renamed.
+			rewritten = true
+			return os.Remove(objectInfo.ExternalPath())
+		},
+	); err != nil {
+		return false, err
+	}
+	return rewritten, nil
+}
+
+func putPathWithExternalPath(
+	ctx context.Context,
+	writeBucket storage.WriteBucket,
+	path string,
+	externalPath string,
+	data []byte,
+) (retErr error) {
+	writeObjectCloser, err := writeBucket.Put(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, writeObjectCloser.Close())
+	}()
+	if _, err := writeObjectCloser.Write(data); err != nil {
+		return err
+	}
+	return writeObjectCloser.SetExternalPath(externalPath)
+}
@@ -0,0 +1,125 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modcacheprune
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulecache"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/bytesize"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	olderThanFlagName = "older-than"
+	maxSizeFlagName   = "max-size"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name,
+		Short: "Delete the least recently used modules from the Buf module cache.",
+		Long: `At least one of --older-than and --max-size must be set.
+
+Modules cached before buf started recording the last time they were used are
+considered as the least recently used.`,
+		Args: cobra.NoArgs,
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	OlderThan time.Duration
+	MaxSize   string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	flagSet.DurationVar(
+		&f.OlderThan,
+		olderThanFlagName,
+		0,
+		`Delete the modules that were not used for this long, for example 720h.`,
+	)
+	flagSet.StringVar(
+		&f.MaxSize,
+		maxSizeFlagName,
+		"",
+		`Delete the least recently used modules until the cache is at most this size, for example 500MB or 2GB.`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	var pruneOptions []bufmodulecache.PruneOption
+	if flags.OlderThan < 0 {
+		return appcmd.NewInvalidArgumentErrorf("--%s must not be negative", olderThanFlagName)
+	}
+	if flags.OlderThan > 0 {
+		pruneOptions = append(pruneOptions, bufmodulecache.PruneWithLastUsedBefore(time.Now().Add(-flags.OlderThan)))
+	}
+	if flags.MaxSize != "" {
+		maxSize, err := bytesize.Parse(flags.MaxSize)
+		if err != nil {
+			return appcmd.NewInvalidArgumentErrorf("--%s: %v", maxSizeFlagName, err)
+		}
+		pruneOptions = append(pruneOptions, bufmodulecache.PruneWithMaxSize(maxSize))
+	}
+	if len(pruneOptions) == 0 {
+		return appcmd.NewInvalidArgumentErrorf("at least one of --%s and --%s must be set", olderThanFlagName, maxSizeFlagName)
+	}
+	cacheManager, err := bufcli.NewModuleCacheManagerAndCreateCacheDirs(container)
+	if err != nil {
+		return err
+	}
+	prunedCacheEntries, err := cacheManager.Prune(ctx, pruneOptions...)
+	if err != nil {
+		return err
+	}
+	for _, prunedCacheEntry := range prunedCacheEntries {
+		if _, err := fmt.Fprintf(
+			container.Stderr(),
+			"deleted %s (%s)\n",
+			prunedCacheEntry.ModulePin.String(),
+			bytesize.Format(prunedCacheEntry.Size),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modcacheverify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name,
+		Short: "Verify the integrity of the Buf module cache.",
+		Long: `The digest of every cached module is recomputed and compared to the digest stored when the
+module was downloaded. Corrupted modules are deleted from the cache and downloaded again.
+In offline mode, corrupted modules are only deleted.`,
+		Args: cobra.NoArgs,
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct{}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	cacheManager, err := bufcli.NewModuleCacheManagerAndCreateCacheDirs(container)
+	if err != nil {
+		return err
+	}
+	corruptedCacheEntries, err := cacheManager.Verify(ctx)
+	if err != nil {
+		return err
+	}
+	if len(corruptedCacheEntries) == 0 {
+		return nil
+	}
+	for _, corruptedCacheEntry := range corruptedCacheEntries {
+		if _, err := fmt.Fprintf(container.Stderr(), "deleted corrupted %s\n", corruptedCacheEntry.ModulePin.String()); err != nil {
+			return err
+		}
+	}
+	if bufcli.IsOffline(container) {
+		return nil
+	}
+	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
+	if err != nil {
+		return err
+	}
+	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
+	if err != nil {
+		return err
+	}
+	for _, corruptedCacheEntry := range corruptedCacheEntries {
+		if _, err := moduleReader.GetModule(ctx, corruptedCacheEntry.ModulePin); err != nil {
+			return fmt.Errorf("could not download %s again: %w", corruptedCacheEntry.ModulePin.String(), err)
+		}
+		if _, err := fmt.Fprintf(container.Stderr(), "repaired %s\n", corruptedCacheEntry.ModulePin.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -113,6 +113,11 @@ func run(
 		if err != nil {
 			return bufcli.NewInternalError(err)
 		}
+		// The resolved pins have no digest, so we keep the digests of the lock file.
+		dependencyModulePins, err = bufmoduleref.InheritModulePinDigests(dependencyModulePins, module.DependencyModulePins())
+		if err != nil {
+			return bufcli.NewInternalError(err)
+		}
 	}
 	if err := bufmoduleref.PutDependencyModulePinsToBucket(ctx, readWriteBucket, dependencyModulePins); err != nil {
 		return err
@@ -23,6 +23,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufconnect"
 	"github.com/bufbuild/buf/private/bufpkg/buflock"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/gen/proto/api/buf/alpha/registry/v1alpha1/registryv1alpha1api"
 	modulev1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/module/v1alpha1"
@@ -53,7 +54,9 @@ func NewCommand(
 		Long: "Fetch the latest digests for the specified references in the config file, " +
 			"and write them and their transitive dependencies to the " +
 			buflock.ExternalConfigFilePath +
-			` file. The first argument is the directory of the local module to update. Defaults to "." if no argument is specified.`,
+			" file, together with the digest of the content of each dependency. The dependencies are downloaded to compute their digests, " +
+			"and are verified against these digests whenever they are fetched." +
+			` The first argument is the directory of the local module to update. Defaults to "." if no argument is specified.`,
 		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
 			func(ctx context.Context, container appflag.Container) error {
@@ -163,12 +166,57 @@ func run(
 		container.Logger().Warn(warnMsg)
 	}
 
+	dependencyModulePins, err = getModulePinsWithDigests(ctx, container, dependencyModulePins)
+	if err != nil {
+		return err
+	}
 	if err := bufmoduleref.PutDependencyModulePinsToBucket(ctx, readWriteBucket, dependencyModulePins); err != nil {
 		return bufcli.NewInternalError(err)
 	}
 	return nil
 }
 
+// getModulePinsWithDigests returns the module pins with the digests of the content of
+// the modules, so that the dependencies can be verified against the lock file.
+//
+// The digests are always computed again, so that digests of the current lock file that
+// are outdated or wrong are replaced.
+func getModulePinsWithDigests(
+	ctx context.Context,
+	container appflag.Container,
+	modulePins []bufmoduleref.ModulePin,
+) ([]bufmoduleref.ModulePin, error) {
+	if len(modulePins) == 0 {
+		return modulePins, nil
+	}
+	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
+	if err != nil {
+		return nil, err
+	}
+	modulePinsWithDigests := make([]bufmoduleref.ModulePin, len(modulePins))
+	for i, modulePin := range modulePins {
+		// The resolved pins have no digest, so the module is only verified against
+		// the digest stored in the module cache.
+		module, err := moduleReader.GetModule(ctx, modulePin)
+		if err != nil {
+			return nil, err
+		}
+		digest, err := bufmodule.ModuleDigestB3(ctx, module)
+		if err != nil {
+			return nil, err
+		}
+		modulePinsWithDigests[i], err = bufmoduleref.NewModulePinWithDigest(modulePin, digest)
+		if err != nil {
+			return nil, bufcli.NewInternalError(err)
+		}
+	}
+	return modulePinsWithDigests, nil
+}
+
 func getDependencies(
 	ctx context.Context,
 	container appflag.Container,
@@ -0,0 +1,121 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modvendor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/bufpkg/buflock"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulevendor"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns a new vendor Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	return &appcmd.Command{
+		Use:   name + " <directory>",
+		Short: "Vendor the dependencies pinned in the " + buflock.ExternalConfigFilePath + " file.",
+		Long: "Write all dependencies pinned in the " + buflock.ExternalConfigFilePath + " file to the " +
+			bufmodulevendor.ExternalDirPath + " directory of the module, replacing any existing vendored dependencies. " +
+			"Vendored dependencies are verified against their stored digests and are used instead of the module cache, " +
+			"so that the module can be built without accessing the network, for example with --offline. " +
+			"Dependencies provided by a workspace are used instead of vendored dependencies. " +
+			`The first argument is the directory of the local module to vendor. Defaults to "." if no argument is specified.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+	}
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+) error {
+	directoryInput, err := bufcli.GetInputValue(container, "", ".")
+	if err != nil {
+		return err
+	}
+	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
+	readWriteBucket, err := storageosProvider.NewReadWriteBucket(
+		directoryInput,
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
+	if err != nil {
+		return err
+	}
+	existingConfigFilePath, err := bufconfig.ExistingConfigFilePath(ctx, readWriteBucket)
+	if err != nil {
+		return err
+	}
+	if existingConfigFilePath == "" {
+		return bufcli.ErrNoConfigFile
+	}
+	module, err := bufmodule.NewModuleForBucket(ctx, readWriteBucket)
+	if err != nil {
+		return fmt.Errorf("couldn't read current dependencies: %w", err)
+	}
+	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
+	if err != nil {
+		return err
+	}
+	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
+	if err != nil {
+		return err
+	}
+	dependencyModulePins := module.DependencyModulePins()
+	dependencyModules := make([]bufmodule.Module, 0, len(dependencyModulePins))
+	var offlineDependencyModulePins []bufmoduleref.ModulePin
+	for _, dependencyModulePin := range dependencyModulePins {
+		// The module cache verifies the cached modules against their stored digests.
+		dependencyModule, err := moduleReader.GetModule(ctx, dependencyModulePin)
+		if err != nil {
+			if errors.Is(err, bufmodule.ErrModuleNotAvailableOffline) {
+				offlineDependencyModulePins = append(offlineDependencyModulePins, dependencyModulePin)
+				continue
+			}
+			return err
+		}
+		dependencyModules = append(dependencyModules, dependencyModule)
+	}
+	if len(offlineDependencyModulePins) > 0 {
+		return bufmodule.NewDependenciesNotAvailableOfflineError(offlineDependencyModulePins)
+	}
+	// Only replace the existing vendored dependencies once all dependencies were read.
+	if err := readWriteBucket.DeleteAll(ctx, bufmodulevendor.ExternalDirPath); err != nil {
+		return err
+	}
+	for i, dependencyModulePin := range dependencyModulePins {
+		if err := bufmodulevendor.PutModule(ctx, readWriteBucket, dependencyModulePin, dependencyModules[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/bufpkg/buflock"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulevendor"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns a new verify Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	return &appcmd.Command{
+		Use:   name + " <directory>",
+		Short: "Verify the dependencies pinned in the " + buflock.ExternalConfigFilePath + " file against their digests.",
+		Long: "Check that the content of every dependency pinned in the " + buflock.ExternalConfigFilePath + " file matches " +
+			"the digest recorded by \"buf mod update\", without building the module. " +
+			"Dependencies are read from the " + bufmodulevendor.ExternalDirPath + " directory if the module vendors its dependencies, " +
+			"and from the module cache or the registry otherwise. " +
+			"Dependencies without a digest, or with a digest that cannot be verified such as the b1 digests of older lock files, " +
+			"are reported as unverified, but do not fail the verification. " +
+			`The first argument is the directory of the local module to verify. Defaults to "." if no argument is specified.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+	}
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+) error {
+	directoryInput, err := bufcli.GetInputValue(container, "", ".")
+	if err != nil {
+		return err
+	}
+	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
+	readWriteBucket, err := storageosProvider.NewReadWriteBucket(
+		directoryInput,
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
+	if err != nil {
+		return err
+	}
+	existingConfigFilePath, err := bufconfig.ExistingConfigFilePath(ctx, readWriteBucket)
+	if err != nil {
+		return err
+	}
+	if existingConfigFilePath == "" {
+		return bufcli.ErrNoConfigFile
+	}
+	dependencyModulePins, err := bufmoduleref.DependencyModulePinsForBucket(ctx, readWriteBucket)
+	if err != nil {
+		return err
+	}
+	getModule, err := newGetModuleFunc(ctx, container, readWriteBucket)
+	if err != nil {
+		return err
+	}
+	var mismatchErrs []error
+	var offlineDependencyModulePins []bufmoduleref.ModulePin
+	var unverifiedDependencyModulePins []bufmoduleref.ModulePin
+	for _, dependencyModulePin := range dependencyModulePins {
+		if !bufmodule.ModuleDigestIsVerifiable(dependencyModulePin.Digest()) {
+			unverifiedDependencyModulePins = append(unverifiedDependencyModulePins, dependencyModulePin)
+			continue
+		}
+		// Both the vendor directory and the module cache verify the
+		// module against the digest of the pin.
+		if _, err := getModule(ctx, dependencyModulePin); err != nil {
+			switch {
+			case errors.Is(err, bufmodule.ErrModuleDigestMismatch):
+				mismatchErrs = append(mismatchErrs, err)
+			case errors.Is(err, bufmodule.ErrModuleNotAvailableOffline):
+				offlineDependencyModulePins = append(offlineDependencyModulePins, dependencyModulePin)
+			default:
+				return err
+			}
+		}
+	}
+	if len(unverifiedDependencyModulePins) > 0 {
+		warnUnverifiedDependencies(container, unverifiedDependencyModulePins)
+	}
+	if len(offlineDependencyModulePins) > 0 {
+		return bufmodule.NewDependenciesNotAvailableOfflineError(offlineDependencyModulePins)
+	}
+	if len(mismatchErrs) > 0 {
+		var builder strings.Builder
+		_, _ = builder.WriteString("the following dependencies do not match their digest in " + buflock.ExternalConfigFilePath + ":")
+		for _, mismatchErr := range mismatchErrs {
+			_, _ = builder.WriteString("\n\t- " + mismatchErr.Error())
+		}
+		return errors.New(builder.String())
+	}
+	return nil
+}
+
+// warnUnverifiedDependencies warns about the dependencies that were not verified because
+// they have no digest in the lock file, or a digest that cannot be verified.
+func warnUnverifiedDependencies(container appflag.Container, unverifiedDependencyModulePins []bufmoduleref.ModulePin) {
+	var builder strings.Builder
+	_, _ = builder.WriteString(
+		fmt.Sprintf(
+			"%d of the dependencies in %s could not be verified, run \"buf mod update\" to record their digest:",
+			len(unverifiedDependencyModulePins),
+			buflock.ExternalConfigFilePath,
+		),
+	)
+	for _, unverifiedDependencyModulePin := range unverifiedDependencyModulePins {
+		_, _ = builder.WriteString("\n\t- " + unverifiedDependencyModulePin.String())
+		if digest := unverifiedDependencyModulePin.Digest(); digest != "" {
+			_, _ = builder.WriteString(fmt.Sprintf(" has digest %q which cannot be verified", digest))
+		} else {
+			_, _ = builder.WriteString(" has no digest")
+		}
+	}
+	container.Logger().Warn(builder.String())
+}
+
+// newGetModuleFunc returns a function that gets the modules from the vendor directory
+// of the module if it exists, and from the module cache otherwise.
+func newGetModuleFunc(
+	ctx context.Context,
+	container appflag.Container,
+	readBucket storage.ReadBucket,
+) (func(context.Context, bufmoduleref.ModulePin) (bufmodule.Module, error), error) {
+	isEmpty, err := storage.IsEmpty(ctx, readBucket, bufmodulevendor.ExternalDirPath)
+	if err != nil {
+		return nil, err
+	}
+	if !isEmpty {
+		return func(ctx context.Context, modulePin bufmoduleref.ModulePin) (bufmodule.Module, error) {
+			module, err := bufmodulevendor.GetModule(ctx, readBucket, modulePin)
+			if err != nil && storage.IsNotExist(err) {
+				return nil, fmt.Errorf(`%s is not vendored, run "buf mod vendor"`, modulePin.String())
+			}
+			return module, err
+		}, nil
+	}
+	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
+	if err != nil {
+		return nil, err
+	}
+	return moduleReader.GetModule, nil
+}
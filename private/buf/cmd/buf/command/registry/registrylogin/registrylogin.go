@@ -25,6 +25,8 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufconnect"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/credentialhelper"
 	"github.com/bufbuild/buf/private/pkg/netrc"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -46,8 +48,14 @@ func NewCommand(
 		// TODO: Update when we have self-hosted.
 		Use:   name,
 		Short: `Log in to the Buf Schema Registry.`,
-		Long:  fmt.Sprintf(`This prompts for your BSR username and a BSR token and updates your %s file with these credentials.`, netrc.Filename),
-		Args:  cobra.MaximumNArgs(1),
+		Long: fmt.Sprintf(`This prompts for your BSR username and a BSR token and updates your %s file with these credentials.
+
+If a credential helper is configured for the remote with the BUF_CREDENTIAL_HELPERS
+environment variable, the credentials are stored with the credential helper instead.
+For example, BUF_CREDENTIAL_HELPERS=osxkeychain stores the credentials of all remotes
+with the buf-credential-osxkeychain binary, and BUF_CREDENTIAL_HELPERS=pass@buf.example.com
+only stores the credentials of buf.example.com with the buf-credential-pass binary.`, netrc.Filename),
+		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
 			func(ctx context.Context, container appflag.Container) error {
 				return run(ctx, container, flags)
@@ -198,6 +206,48 @@ func inner(
 	if user.Username != username {
 		return fmt.Errorf("the username associated with that token (%s) does not match the username provided (%s)", user.Username, username)
 	}
+	loggedInMessage, err := storeCredentials(ctx, container, remote, username, token)
+	if err != nil {
+		return err
+	}
+	// Unless we did not prompt at all, print a newline first
+	if flags.Username == "" || !flags.TokenStdin {
+		loggedInMessage = "\n" + loggedInMessage
+	}
+	if _, err := container.Stdout().Write([]byte(loggedInMessage)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// storeCredentials stores the credentials with the credential helper configured for
+// the remote if there is one, and in netrc otherwise.
+//
+// Returns the message to print.
+func storeCredentials(
+	ctx context.Context,
+	container appflag.Container,
+	remote string,
+	username string,
+	token string,
+) (string, error) {
+	helper, err := bufconnect.GetCredentialHelper(container, command.NewRunner(), remote)
+	if err != nil {
+		return "", err
+	}
+	if helper != nil {
+		if err := helper.Store(
+			ctx,
+			&credentialhelper.Credentials{
+				ServerURL: remote,
+				Username:  username,
+				Secret:    token,
+			},
+		); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Credentials saved to credential helper %s.\n", helper.Name()), nil
+	}
 	if err := netrc.PutMachines(
 		container,
 		netrc.NewMachine(
@@ -211,19 +261,11 @@ func inner(
 			token,
 		),
 	); err != nil {
-		return err
+		return "", err
 	}
 	netrcFilePath, err := netrc.GetFilePath(container)
 	if err != nil {
-		return err
+		return "", err
 	}
-	loggedInMessage := fmt.Sprintf("Credentials saved to %s.\n", netrcFilePath)
-	// Unless we did not prompt at all, print a newline first
-	if flags.Username == "" || !flags.TokenStdin {
-		loggedInMessage = "\n" + loggedInMessage
-	}
-	if _, err := container.Stdout().Write([]byte(loggedInMessage)); err != nil {
-		return err
-	}
-	return nil
+	return fmt.Sprintf("Credentials saved to %s.\n", netrcFilePath), nil
 }
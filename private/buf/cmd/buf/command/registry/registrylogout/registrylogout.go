@@ -22,6 +22,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufconnect"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/netrc"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -38,8 +39,11 @@ func NewCommand(
 		// TODO: Update when we have self-hosted.
 		Use:   name,
 		Short: `Log out of the Buf Schema Registry.`,
-		Long:  fmt.Sprintf(`This command removes any BSR credentials from your %s file.`, netrc.Filename),
-		Args:  cobra.MaximumNArgs(1),
+		Long: fmt.Sprintf(`This command removes any BSR credentials from your %s file.
+
+If a credential helper is configured for the remote with the BUF_CREDENTIAL_HELPERS
+environment variable, the credentials are erased from the credential helper instead.`, netrc.Filename),
+		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
 			func(ctx context.Context, container appflag.Container) error {
 				return run(ctx, container, flags)
@@ -67,6 +71,24 @@ func run(
 	if container.NumArgs() == 1 {
 		remote = container.Arg(0)
 	}
+	helper, err := bufconnect.GetCredentialHelper(container, command.NewRunner(), remote)
+	if err != nil {
+		return err
+	}
+	if helper != nil {
+		found, err := helper.Erase(ctx, remote)
+		if err != nil {
+			return err
+		}
+		loggedOutMessage := fmt.Sprintf("All existing BSR credentials removed from credential helper %s.\n", helper.Name())
+		if !found {
+			loggedOutMessage = fmt.Sprintf("No BSR credentials found in credential helper %s; you are already logged out.\n", helper.Name())
+		}
+		if _, err := container.Stdout().Write([]byte(loggedOutMessage)); err != nil {
+			return err
+		}
+		return nil
+	}
 	modified1, err := netrc.DeleteMachineForName(container, remote)
 	if err != nil {
 		return err
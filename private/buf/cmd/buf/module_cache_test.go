@@ -0,0 +1,149 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buf
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd/appcmdtesting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleCache(t *testing.T) {
+	t.Parallel()
+	barModulePin := testNewCacheModulePin(t, "bar")
+	bazModulePin := testNewCacheModulePin(t, "baz")
+	moduleDirPath := testWriteOfflineModule(t, barModulePin)
+	cacheDirPath := t.TempDir()
+	testPutModuleToCache(t, cacheDirPath, barModulePin)
+	testPutModuleToCache(t, cacheDirPath, bazModulePin)
+
+	// Only bar is used by the build.
+	testRunOffline(t, 0, cacheDirPath, nil, "build", "--offline", moduleDirPath)
+	stdout := testRunModuleCache(t, 0, cacheDirPath, "ls", "--format", "json")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"module":"buf.build/foob/bar"`)
+	assert.Contains(t, lines[0], `"last_used"`)
+	assert.Contains(t, lines[1], `"module":"buf.build/foob/baz"`)
+	assert.NotContains(t, lines[1], `"last_used"`)
+	stdout = testRunModuleCache(t, 0, cacheDirPath, "ls")
+	assert.Contains(t, stdout, "Last used")
+	assert.Contains(t, stdout, "unknown")
+
+	// Nothing is corrupted.
+	stderr := testRunOffline(t, 0, cacheDirPath, nil, "mod", "cache", "verify", "--offline")
+	assert.Empty(t, stderr)
+	bazFilePath := filepath.Join(
+		cacheDirPath,
+		"v1",
+		"module",
+		"data",
+		bazModulePin.Remote(),
+		bazModulePin.Owner(),
+		bazModulePin.Repository(),
+		bazModulePin.Commit(),
+		"b",
+		"b.proto",
+	)
+	require.NoError(t, os.WriteFile(bazFilePath, []byte("corrupted"), 0600))
+	stderr = testRunOffline(t, 0, cacheDirPath, nil, "mod", "cache", "verify", "--offline")
+	assert.Contains(t, stderr, "deleted corrupted "+bazModulePin.String())
+	stdout = testRunModuleCache(t, 0, cacheDirPath, "ls")
+	assert.Contains(t, stdout, "buf.build/foob/bar")
+	assert.NotContains(t, stdout, "buf.build/foob/baz")
+
+	// baz was never used, so it is pruned first.
+	testPutModuleToCache(t, cacheDirPath, bazModulePin)
+	stderr = testRunOffline(t, 1, cacheDirPath, nil, "mod", "cache", "prune")
+	assert.Contains(t, stderr, "at least one of --older-than and --max-size must be set")
+	stderr = testRunOffline(t, 1, cacheDirPath, nil, "mod", "cache", "prune", "--max-size", "10XB")
+	assert.Contains(t, stderr, "unknown unit")
+	stderr = testRunOffline(t, 0, cacheDirPath, nil, "mod", "cache", "prune", "--older-than", "1h")
+	assert.Contains(t, stderr, "deleted "+bazModulePin.String())
+	assert.NotContains(t, stderr, barModulePin.String())
+	stderr = testRunOffline(t, 0, cacheDirPath, nil, "mod", "cache", "prune", "--max-size", "0")
+	assert.Contains(t, stderr, "deleted "+barModulePin.String())
+	stdout = testRunModuleCache(t, 0, cacheDirPath, "ls")
+	assert.Empty(t, stdout)
+}
+
+func TestModuleCacheSharedCacheOffline(t *testing.T) {
+	t.Parallel()
+	modulePin := testNewCacheModulePin(t, "bar")
+	moduleDirPath := testWriteOfflineModule(t, modulePin)
+	cacheDirPath := t.TempDir()
+	testPutModuleToCache(t, cacheDirPath, modulePin)
+	sharedCacheDirPath := filepath.Join(t.TempDir(), "shared")
+
+	testRunOffline(t, 0, cacheDirPath, map[string]string{"BUF_SHARED_CACHE": sharedCacheDirPath}, "build", "--offline", moduleDirPath)
+	for _, dirName := range []string{"data", "lock"} {
+		fileInfo, err := os.Stat(filepath.Join(sharedCacheDirPath, dirName))
+		require.NoError(t, err)
+		assert.True(t, fileInfo.IsDir())
+	}
+	// A HTTP cache server is never contacted when offline.
+	testRunOffline(t, 0, cacheDirPath, map[string]string{"BUF_SHARED_CACHE": "http://127.0.0.1:0"}, "build", "--offline", moduleDirPath)
+	stderr := testRunOffline(t, 1, t.TempDir(), map[string]string{"BUF_SHARED_CACHE": sharedCacheDirPath}, "build", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, modulePin.String())
+}
+
+func testNewCacheModulePin(t *testing.T, repository string) bufmoduleref.ModulePin {
+	modulePin, err := bufmoduleref.NewModulePin(
+		"buf.build",
+		"foob",
+		repository,
+		"",
+		bufmoduletesting.TestCommit,
+		"",
+		time.Time{},
+	)
+	require.NoError(t, err)
+	return modulePin
+}
+
+// testRunModuleCache runs buf mod cache with the cache directory, and returns stdout.
+func testRunModuleCache(
+	t *testing.T,
+	expectedExitCode int,
+	cacheDirPath string,
+	args ...string,
+) string {
+	stdout := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(use string) *appcmd.Command { return NewRootCommand(use) },
+		expectedExitCode,
+		func(use string) map[string]string {
+			return map[string]string{
+				strings.ToUpper(use) + "_CACHE_DIR": cacheDirPath,
+			}
+		},
+		nil,
+		stdout,
+		nil,
+		append([]string{"mod", "cache"}, args...)...,
+	)
+	return stdout.String()
+}
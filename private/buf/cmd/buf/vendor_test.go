@@ -0,0 +1,174 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulevendor"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd/appcmdtesting"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineAndVendor(t *testing.T) {
+	t.Parallel()
+	modulePin, err := bufmoduleref.NewModulePin(
+		"buf.build",
+		"foob",
+		"bar",
+		"main",
+		bufmoduletesting.TestCommit,
+		"",
+		time.Now(),
+	)
+	require.NoError(t, err)
+	moduleDirPath := testWriteOfflineModule(t, modulePin)
+	emptyCacheDirPath := t.TempDir()
+	cacheDirPath := t.TempDir()
+	testPutModuleToCache(t, cacheDirPath, modulePin)
+
+	// Nothing is cached or vendored.
+	stderr := testRunOffline(t, 1, emptyCacheDirPath, nil, "build", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, "cannot be downloaded offline")
+	assert.Contains(t, stderr, modulePin.String())
+	stderr = testRunOffline(t, 1, emptyCacheDirPath, map[string]string{"BUF_OFFLINE": "1"}, "build", moduleDirPath)
+	assert.Contains(t, stderr, modulePin.String())
+	stderr = testRunOffline(t, 1, emptyCacheDirPath, nil, "mod", "vendor", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, modulePin.String())
+
+	// The dependency is cached.
+	testRunOffline(t, 0, cacheDirPath, nil, "build", "--offline", moduleDirPath)
+	testRunOffline(t, 0, cacheDirPath, nil, "mod", "vendor", "--offline", moduleDirPath)
+
+	// The dependency is vendored.
+	testRunOffline(t, 0, emptyCacheDirPath, nil, "build", "--offline", moduleDirPath)
+	digestFilePath := filepath.Join(
+		moduleDirPath,
+		bufmodulevendor.ExternalDirPath,
+		modulePin.Remote(),
+		modulePin.Owner(),
+		modulePin.Repository(),
+		modulePin.Commit(),
+		"digest",
+	)
+	require.NoError(t, os.WriteFile(digestFilePath, []byte("b3-foo"), 0600))
+	stderr = testRunOffline(t, 1, emptyCacheDirPath, nil, "build", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, "does not match stored digest")
+
+	// Vendoring again replaces the vendored dependency.
+	testRunOffline(t, 0, cacheDirPath, nil, "mod", "vendor", "--offline", moduleDirPath)
+	testRunOffline(t, 0, emptyCacheDirPath, nil, "build", "--offline", moduleDirPath)
+}
+
+// testWriteOfflineModule writes a module that depends on the module pin, and
+// returns its directory path.
+func testWriteOfflineModule(t *testing.T, modulePin bufmoduleref.ModulePin) string {
+	ctx := context.Background()
+	moduleDirPath := t.TempDir()
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(moduleDirPath)
+	require.NoError(t, err)
+	require.NoError(
+		t,
+		storage.PutPath(
+			ctx,
+			readWriteBucket,
+			"buf.yaml",
+			[]byte("version: v1\ndeps:\n  - "+modulePin.IdentityString()+"\n"),
+		),
+	)
+	require.NoError(
+		t,
+		storage.PutPath(
+			ctx,
+			readWriteBucket,
+			"a.proto",
+			[]byte("syntax = \"proto3\";\npackage a;\nimport \"b/b.proto\";\nmessage A {\n  b.B b = 1;\n}\n"),
+		),
+	)
+	require.NoError(
+		t,
+		bufmoduleref.PutDependencyModulePinsToBucket(ctx, readWriteBucket, []bufmoduleref.ModulePin{modulePin}),
+	)
+	return moduleDirPath
+}
+
+// testPutModuleToCache puts a module for the module pin in the module cache,
+// and returns the digest of the module.
+func testPutModuleToCache(t *testing.T, cacheDirPath string, modulePin bufmoduleref.ModulePin) string {
+	ctx := context.Background()
+	modulePath := filepath.Join(modulePin.Remote(), modulePin.Owner(), modulePin.Repository(), modulePin.Commit())
+	dataDirPath := filepath.Join(cacheDirPath, "v1", "module", "data", modulePath)
+	require.NoError(t, os.MkdirAll(dataDirPath, 0755))
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(dataDirPath)
+	require.NoError(t, err)
+	require.NoError(t, storage.PutPath(ctx, readWriteBucket, "b/b.proto", []byte("syntax = \"proto3\";\npackage b;\nmessage B {}\n")))
+	require.NoError(t, bufmoduleref.PutDependencyModulePinsToBucket(ctx, readWriteBucket, nil))
+	module, err := bufmodule.NewModuleForBucket(
+		ctx,
+		readWriteBucket,
+		bufmodule.ModuleWithModuleIdentityAndCommit(modulePin, modulePin.Commit()),
+	)
+	require.NoError(t, err)
+	digest, err := bufmodule.ModuleDigestB3(ctx, module)
+	require.NoError(t, err)
+	sumFilePath := filepath.Join(cacheDirPath, "v1", "module", "sum", modulePath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(sumFilePath), 0755))
+	require.NoError(t, os.WriteFile(sumFilePath, []byte(digest), 0600))
+	return digest
+}
+
+// testRunOffline runs the command with the cache directory and environment, and returns stderr.
+func testRunOffline(
+	t *testing.T,
+	expectedExitCode int,
+	cacheDirPath string,
+	env map[string]string,
+	args ...string,
+) string {
+	stderr := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(use string) *appcmd.Command { return NewRootCommand(use) },
+		expectedExitCode,
+		func(use string) map[string]string {
+			envMap := map[string]string{
+				strings.ToUpper(use) + "_CACHE_DIR": cacheDirPath,
+				"PATH":                              os.Getenv("PATH"),
+			}
+			for key, value := range env {
+				envMap[key] = value
+			}
+			return envMap
+		},
+		nil,
+		nil,
+		stderr,
+		args...,
+	)
+	return stderr.String()
+}
@@ -0,0 +1,82 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModVerify(t *testing.T) {
+	t.Parallel()
+	modulePin, err := bufmoduleref.NewModulePin(
+		"buf.build",
+		"foob",
+		"bar",
+		"main",
+		bufmoduletesting.TestCommit,
+		"",
+		time.Now(),
+	)
+	require.NoError(t, err)
+	emptyCacheDirPath := t.TempDir()
+	cacheDirPath := t.TempDir()
+	digest := testPutModuleToCache(t, cacheDirPath, modulePin)
+
+	// The lock file has no digest.
+	moduleDirPath := testWriteOfflineModule(t, modulePin)
+	stderr := testRunOffline(t, 0, cacheDirPath, nil, "mod", "verify", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, "1 of the dependencies in buf.lock could not be verified")
+	assert.Contains(t, stderr, modulePin.String()+" has no digest")
+
+	// The lock file has a b1 digest, which cannot be verified.
+	b1ModulePin, err := bufmoduleref.NewModulePinWithDigest(modulePin, "b1-foo")
+	require.NoError(t, err)
+	moduleDirPath = testWriteOfflineModule(t, b1ModulePin)
+	stderr = testRunOffline(t, 0, cacheDirPath, nil, "mod", "verify", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, "1 of the dependencies in buf.lock could not be verified")
+	assert.Contains(t, stderr, modulePin.String()+` has digest "b1-foo" which cannot be verified`)
+
+	// The lock file digest matches the dependency.
+	digestModulePin, err := bufmoduleref.NewModulePinWithDigest(modulePin, digest)
+	require.NoError(t, err)
+	moduleDirPath = testWriteOfflineModule(t, digestModulePin)
+	stderr = testRunOffline(t, 0, cacheDirPath, nil, "mod", "verify", "--offline", moduleDirPath)
+	assert.NotContains(t, stderr, "could not be verified")
+	testRunOffline(t, 0, cacheDirPath, nil, "build", "--offline", moduleDirPath)
+	stderr = testRunOffline(t, 1, emptyCacheDirPath, nil, "mod", "verify", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, "cannot be downloaded offline")
+
+	// The vendored dependency is verified instead of the cached dependency.
+	testRunOffline(t, 0, cacheDirPath, nil, "mod", "vendor", "--offline", moduleDirPath)
+	testRunOffline(t, 0, emptyCacheDirPath, nil, "mod", "verify", "--offline", moduleDirPath)
+
+	// The lock file digest does not match the dependency.
+	mismatchModulePin, err := bufmoduleref.NewModulePinWithDigest(modulePin, "b3-foo")
+	require.NoError(t, err)
+	moduleDirPath = testWriteOfflineModule(t, mismatchModulePin)
+	stderr = testRunOffline(t, 1, cacheDirPath, nil, "mod", "verify", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, "do not match their digest")
+	assert.Contains(t, stderr, modulePin.String())
+	stderr = testRunOffline(t, 1, cacheDirPath, nil, "build", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, `but the lock file expects "b3-foo"`)
+	stderr = testRunOffline(t, 1, cacheDirPath, nil, "mod", "vendor", "--offline", moduleDirPath)
+	assert.Contains(t, stderr, `but the lock file expects "b3-foo"`)
+}
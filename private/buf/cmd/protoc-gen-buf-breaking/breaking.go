@@ -26,6 +26,7 @@ import (
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
@@ -148,7 +149,16 @@ func handle(
 	}
 	if len(fileAnnotations) > 0 {
 		buffer := bytes.NewBuffer(nil)
-		if err := bufanalysis.PrintFileAnnotations(buffer, fileAnnotations, externalConfig.ErrorFormat); err != nil {
+		rules, err := bufbreaking.RulesForConfig(config.Breaking)
+		if err != nil {
+			return err
+		}
+		if err := bufanalysis.PrintFileAnnotations(
+			buffer,
+			fileAnnotations,
+			externalConfig.ErrorFormat,
+			bufcheck.PrintFileAnnotationsWithRules(rules),
+		); err != nil {
 			return err
 		}
 		responseWriter.AddError(strings.TrimSpace(buffer.String()))
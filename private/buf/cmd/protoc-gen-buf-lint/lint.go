@@ -21,6 +21,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
@@ -28,6 +29,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/applog"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/encoding"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"google.golang.org/protobuf/types/pluginpb"
@@ -95,6 +97,7 @@ func handle(
 		bufconfig.ReadConfigOSWithOverride(
 			encoding.GetJSONStringOrStringValue(externalConfig.InputConfig),
 		),
+		bufconfig.ReadConfigOSWithLocalBucket(),
 	)
 	if err != nil {
 		return err
@@ -103,8 +106,10 @@ func handle(
 	if err != nil {
 		return err
 	}
-	image = bufimage.ImageWithoutImports(image)
-	fileAnnotations, err := buflint.NewHandler(logger).Check(
+	fileAnnotations, err := buflint.NewHandler(
+		logger,
+		buflint.HandlerWithPlugins(command.NewRunner(), container),
+	).Check(
 		ctx,
 		config.Lint,
 		image,
@@ -114,7 +119,16 @@ func handle(
 	}
 	if len(fileAnnotations) > 0 {
 		buffer := bytes.NewBuffer(nil)
-		if err := buflintconfig.PrintFileAnnotations(buffer, fileAnnotations, externalConfig.ErrorFormat); err != nil {
+		rules, err := buflint.RulesForConfig(config.Lint)
+		if err != nil {
+			return err
+		}
+		if err := buflintconfig.PrintFileAnnotations(
+			buffer,
+			fileAnnotations,
+			externalConfig.ErrorFormat,
+			bufcheck.PrintFileAnnotationsWithRules(rules),
+		); err != nil {
 			return err
 		}
 		responseWriter.AddError(strings.TrimSpace(buffer.String()))
@@ -32,6 +32,8 @@ const (
 	FormatMSVS
 	// FormatJUnit is the JUnit format for FileAnnotations.
 	FormatJUnit
+	// FormatSARIF is the SARIF 2.1.0 format for FileAnnotations.
+	FormatSARIF
 )
 
 var (
@@ -43,6 +45,7 @@ var (
 		"json",
 		"msvs",
 		"junit",
+		"sarif",
 	}
 	// AllFormatStringsWithAliases is all format strings with aliases.
 	//
@@ -53,6 +56,7 @@ var (
 		"json",
 		"msvs",
 		"junit",
+		"sarif",
 	}
 
 	stringToFormat = map[string]Format{
@@ -62,12 +66,14 @@ var (
 		"json":  FormatJSON,
 		"msvs":  FormatMSVS,
 		"junit": FormatJUnit,
+		"sarif": FormatSARIF,
 	}
 	formatToString = map[Format]string{
 		FormatText:  "text",
 		FormatJSON:  "json",
 		FormatMSVS:  "msvs",
 		FormatJUnit: "junit",
+		FormatSARIF: "sarif",
 	}
 )
 
@@ -98,6 +104,18 @@ func ParseFormat(s string) (Format, error) {
 	return 0, fmt.Errorf("unknown format: %q", s)
 }
 
+// Rule is a minimal rule interface.
+//
+// This is used to attach rule metadata to formats that support it, such as FormatSARIF.
+type Rule interface {
+	// ID returns the ID of the Rule.
+	ID() string
+	// Categories returns the categories of the Rule.
+	Categories() []string
+	// Purpose returns the purpose of the Rule.
+	Purpose() string
+}
+
 // FileInfo is a minimal FileInfo interface.
 type FileInfo interface {
 	Path() string
@@ -140,6 +158,17 @@ type FileAnnotation interface {
 	Message() string
 }
 
+// ElementFileAnnotation is a FileAnnotation that knows the element it was reported against.
+type ElementFileAnnotation interface {
+	FileAnnotation
+
+	// Element is the name of the element the annotation was reported against, such as
+	// the fully-qualified name of a message.
+	//
+	// If the element is not known, this will be empty.
+	Element() string
+}
+
 // NewFileAnnotation returns a new FileAnnotation.
 func NewFileAnnotation(
 	fileInfo FileInfo,
@@ -194,11 +223,20 @@ func DeduplicateAndSortFileAnnotations(fileAnnotations []FileAnnotation) []FileA
 }
 
 // PrintFileAnnotations prints the file annotations separated by newlines.
-func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, formatString string) error {
+func PrintFileAnnotations(
+	writer io.Writer,
+	fileAnnotations []FileAnnotation,
+	formatString string,
+	options ...PrintFileAnnotationsOption,
+) error {
 	format, err := ParseFormat(formatString)
 	if err != nil {
 		return err
 	}
+	printFileAnnotationsOptions := newPrintFileAnnotationsOptions()
+	for _, option := range options {
+		option(printFileAnnotationsOptions)
+	}
 
 	switch format {
 	case FormatText:
@@ -209,11 +247,35 @@ func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, fo
 		return printAsMSVS(writer, fileAnnotations)
 	case FormatJUnit:
 		return printAsJUnit(writer, fileAnnotations)
+	case FormatSARIF:
+		return printAsSARIF(writer, fileAnnotations, printFileAnnotationsOptions.rules)
 	default:
 		return fmt.Errorf("unknown FileAnnotation Format: %v", format)
 	}
 }
 
+// PrintFileAnnotationsOption is an option for PrintFileAnnotations.
+type PrintFileAnnotationsOption func(*printFileAnnotationsOptions)
+
+// PrintFileAnnotationsWithRules returns a new PrintFileAnnotationsOption that attaches
+// the metadata of the given Rules to the printed FileAnnotations.
+//
+// FileAnnotations are matched to Rules by Type. This only affects formats that
+// carry rule metadata, such as FormatSARIF.
+func PrintFileAnnotationsWithRules(rules ...Rule) PrintFileAnnotationsOption {
+	return func(printFileAnnotationsOptions *printFileAnnotationsOptions) {
+		printFileAnnotationsOptions.rules = append(printFileAnnotationsOptions.rules, rules...)
+	}
+}
+
+type printFileAnnotationsOptions struct {
+	rules []Rule
+}
+
+func newPrintFileAnnotationsOptions() *printFileAnnotationsOptions {
+	return &printFileAnnotationsOptions{}
+}
+
 // hash returns a hash value that uniquely identifies the given FileAnnotation.
 func hash(fileAnnotation FileAnnotation) string {
 	path := ""
@@ -15,6 +15,7 @@
 package bufanalysistesting
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -94,3 +95,192 @@ path/to/file.proto(2,1) : error FOO : Hello.
 		sb.String(),
 	)
 }
+
+func TestSARIF(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			1,
+			0,
+			1,
+			0,
+			"FOO",
+			"Hello.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			2,
+			1,
+			2,
+			5,
+			"BAR",
+			"Goodbye.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			7,
+			1,
+			7,
+			5,
+			"FOO",
+			"Hello.",
+		),
+	}
+	sb := &strings.Builder{}
+	err := bufanalysis.PrintFileAnnotations(
+		sb,
+		fileAnnotations,
+		"sarif",
+		bufanalysis.PrintFileAnnotationsWithRules(
+			&testRule{
+				id:         "FOO",
+				categories: []string{"MINIMAL", "BASIC"},
+				purpose:    "Checks foo.",
+			},
+		),
+	)
+	require.NoError(t, err)
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID               string `json:"id"`
+						ShortDescription *struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+						Properties *struct {
+							Tags []string `json:"tags"`
+						} `json:"properties"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Message   struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+							EndLine     int `json:"endLine"`
+							EndColumn   int `json:"endColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(sb.String()), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "buf", run.Tool.Driver.Name)
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Equal(t, "FOO", run.Tool.Driver.Rules[0].ID)
+	require.NotNil(t, run.Tool.Driver.Rules[0].ShortDescription)
+	assert.Equal(t, "Checks foo.", run.Tool.Driver.Rules[0].ShortDescription.Text)
+	require.NotNil(t, run.Tool.Driver.Rules[0].Properties)
+	assert.Equal(t, []string{"BASIC", "MINIMAL"}, run.Tool.Driver.Rules[0].Properties.Tags)
+	assert.Equal(t, "BAR", run.Tool.Driver.Rules[1].ID)
+	assert.Nil(t, run.Tool.Driver.Rules[1].ShortDescription)
+	assert.Nil(t, run.Tool.Driver.Rules[1].Properties)
+	require.Len(t, run.Results, 3)
+	assert.Equal(t, "FOO", run.Results[0].RuleID)
+	assert.Equal(t, 0, run.Results[0].RuleIndex)
+	assert.Equal(t, "Hello.", run.Results[0].Message.Text)
+	require.Len(t, run.Results[0].Locations, 1)
+	assert.Equal(t, "path/to/file.proto", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 1, run.Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, "BAR", run.Results[1].RuleID)
+	assert.Equal(t, 1, run.Results[1].RuleIndex)
+	require.Len(t, run.Results[1].Locations, 1)
+	assert.Equal(t, 5, run.Results[1].Locations[0].PhysicalLocation.Region.EndColumn)
+	// results with the same message in the same file have different fingerprints
+	assert.NotEqual(t, run.Results[0].PartialFingerprints, run.Results[2].PartialFingerprints)
+	assert.NotEqual(t, run.Results[0].PartialFingerprints, run.Results[1].PartialFingerprints)
+}
+
+func TestSARIFFingerprintElement(t *testing.T) {
+	t.Parallel()
+	fooAnnotation := &testElementFileAnnotation{
+		FileAnnotation: newFileAnnotation(t, "path/to/file.proto", 1, 0, 1, 0, "FOO", "Hello."),
+		element:        "pkg.Foo",
+	}
+	barAnnotation := &testElementFileAnnotation{
+		FileAnnotation: newFileAnnotation(t, "path/to/file.proto", 3, 0, 3, 0, "FOO", "Hello."),
+		element:        "pkg.Bar",
+	}
+	movedFooAnnotation := &testElementFileAnnotation{
+		FileAnnotation: newFileAnnotation(t, "path/to/file.proto", 5, 0, 5, 0, "FOO", "Hello."),
+		element:        "pkg.Foo",
+	}
+	fingerprints := testSARIFFingerprints(t, fooAnnotation, barAnnotation)
+	require.Len(t, fingerprints, 2)
+	assert.NotEqual(t, fingerprints[0], fingerprints[1])
+	// fingerprints of results with an element do not depend on location or order
+	movedFingerprints := testSARIFFingerprints(t, barAnnotation, movedFooAnnotation)
+	require.Len(t, movedFingerprints, 2)
+	assert.Equal(t, fingerprints[0], movedFingerprints[1])
+	assert.Equal(t, fingerprints[1], movedFingerprints[0])
+}
+
+func testSARIFFingerprints(t *testing.T, fileAnnotations ...bufanalysis.FileAnnotation) []string {
+	sb := &strings.Builder{}
+	require.NoError(t, bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "sarif"))
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(sb.String()), &log))
+	require.Len(t, log.Runs, 1)
+	fingerprints := make([]string, 0, len(log.Runs[0].Results))
+	for _, result := range log.Runs[0].Results {
+		fingerprints = append(fingerprints, result.PartialFingerprints["buf/v1"])
+	}
+	return fingerprints
+}
+
+type testElementFileAnnotation struct {
+	bufanalysis.FileAnnotation
+
+	element string
+}
+
+func (a *testElementFileAnnotation) Element() string {
+	return a.element
+}
+
+type testRule struct {
+	id         string
+	categories []string
+	purpose    string
+}
+
+func (r *testRule) ID() string {
+	return r.id
+}
+
+func (r *testRule) Categories() []string {
+	return r.categories
+}
+
+func (r *testRule) Purpose() string {
+	return r.purpose
+}
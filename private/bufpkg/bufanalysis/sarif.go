@@ -0,0 +1,254 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufanalysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+)
+
+const (
+	sarifVersion        = "2.1.0"
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName       = "buf"
+	sarifToolURI        = "https://github.com/bufbuild/buf"
+	sarifLevelError     = "error"
+	sarifFingerprintKey = "buf/v1"
+)
+
+func printAsSARIF(writer io.Writer, fileAnnotations []FileAnnotation, rules []Rule) error {
+	log := newSARIFLog(fileAnnotations, rules)
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	// Encode writes a trailing newline.
+	return encoder.Encode(log)
+}
+
+// newSARIFLog builds the SARIF log for the FileAnnotations.
+//
+// Rule metadata is taken from the given Rules. FileAnnotations that have a Type
+// that does not match any given Rule still get a rule entry with only an ID, as
+// SARIF consumers expect every result to reference a rule.
+func newSARIFLog(fileAnnotations []FileAnnotation, rules []Rule) *sarifLog {
+	idToRule := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		idToRule[rule.ID()] = rule
+	}
+	var sarifRules []*sarifRule
+	idToRuleIndex := make(map[string]int)
+	results := make([]*sarifResult, 0, len(fileAnnotations))
+	// The number of results seen so far without an element, by the key of the fingerprint.
+	keyToOccurrences := make(map[string]int)
+	for _, fileAnnotation := range fileAnnotations {
+		if fileAnnotation == nil {
+			continue
+		}
+		ruleID := fileAnnotation.Type()
+		if ruleID == "" {
+			// should never happen but just in case
+			ruleID = "FAILURE"
+		}
+		ruleIndex, ok := idToRuleIndex[ruleID]
+		if !ok {
+			ruleIndex = len(sarifRules)
+			idToRuleIndex[ruleID] = ruleIndex
+			sarifRules = append(sarifRules, newSARIFRule(ruleID, idToRule[ruleID]))
+		}
+		result := newSARIFResult(fileAnnotation, ruleID, ruleIndex)
+		element := ""
+		if elementFileAnnotation, ok := fileAnnotation.(ElementFileAnnotation); ok {
+			element = elementFileAnnotation.Element()
+		}
+		if element == "" {
+			// Results of the same rule with the same message in the same file are
+			// told apart by the order in which they occur.
+			key := sarifFingerprint(sarifPath(fileAnnotation), ruleID, result.Message.Text)
+			element = "#" + strconv.Itoa(keyToOccurrences[key])
+			keyToOccurrences[key]++
+		}
+		result.PartialFingerprints = map[string]string{
+			sarifFingerprintKey: sarifFingerprint(sarifPath(fileAnnotation), ruleID, result.Message.Text, element),
+		}
+		results = append(results, result)
+	}
+	return &sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []*sarifRun{
+			{
+				Tool: &sarifTool{
+					Driver: &sarifDriver{
+						Name:           sarifToolName,
+						InformationURI: sarifToolURI,
+						Rules:          sarifRules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+func newSARIFRule(ruleID string, rule Rule) *sarifRule {
+	sarifRule := &sarifRule{
+		ID: ruleID,
+	}
+	if rule == nil {
+		return sarifRule
+	}
+	if purpose := rule.Purpose(); purpose != "" {
+		sarifRule.ShortDescription = &sarifMessage{
+			Text: purpose,
+		}
+	}
+	if categories := rule.Categories(); len(categories) > 0 {
+		tags := make([]string, len(categories))
+		copy(tags, categories)
+		sort.Strings(tags)
+		sarifRule.Properties = &sarifRuleProperties{
+			Tags: tags,
+		}
+	}
+	return sarifRule
+}
+
+func newSARIFResult(fileAnnotation FileAnnotation, ruleID string, ruleIndex int) *sarifResult {
+	message := fileAnnotation.Message()
+	if message == "" {
+		message = ruleID
+	}
+	path := sarifPath(fileAnnotation)
+	result := &sarifResult{
+		RuleID:    ruleID,
+		RuleIndex: ruleIndex,
+		Level:     sarifLevelError,
+		Message: &sarifMessage{
+			Text: message,
+		},
+	}
+	if path != "" {
+		location := &sarifLocation{
+			PhysicalLocation: &sarifPhysicalLocation{
+				ArtifactLocation: &sarifArtifactLocation{
+					URI: path,
+				},
+			},
+		}
+		if fileAnnotation.StartLine() > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{
+				StartLine:   fileAnnotation.StartLine(),
+				StartColumn: fileAnnotation.StartColumn(),
+				EndLine:     fileAnnotation.EndLine(),
+				EndColumn:   fileAnnotation.EndColumn(),
+			}
+		}
+		result.Locations = []*sarifLocation{location}
+	}
+	return result
+}
+
+// sarifPath returns the path of the artifact of the FileAnnotation, or empty if
+// the FileAnnotation has no file.
+func sarifPath(fileAnnotation FileAnnotation) string {
+	if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+		return fileInfo.ExternalPath()
+	}
+	return ""
+}
+
+// sarifFingerprint returns a fingerprint for the given parts of a result.
+//
+// Line and column information is deliberately not part of the fingerprint,
+// so that a result keeps its identity when unrelated edits move it around
+// within the file. Instead, the element of the result, or its occurrence
+// index, tells apart results with the same message.
+func sarifFingerprint(parts ...string) string {
+	hash := sha256.New()
+	for i, part := range parts {
+		if i > 0 {
+			_, _ = hash.Write([]byte{0})
+		}
+		_, _ = hash.Write([]byte(part))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type sarifLog struct {
+	Version string      `json:"version"`
+	Schema  string      `json:"$schema"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	InformationURI string       `json:"informationUri,omitempty"`
+	Rules          []*sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string               `json:"id"`
+	ShortDescription *sarifMessage        `json:"shortDescription,omitempty"`
+	Properties       *sarifRuleProperties `json:"properties,omitempty"`
+}
+
+type sarifRuleProperties struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             *sarifMessage     `json:"message"`
+	Locations           []*sarifLocation  `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
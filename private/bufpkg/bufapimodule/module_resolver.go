@@ -63,6 +63,7 @@ func (m *moduleResolver) GetModulePin(ctx context.Context, moduleReference bufmo
 		moduleReference.Repository(),
 		"",
 		repositoryCommit.Name,
+		"",
 		repositoryCommit.CreateTime.AsTime(),
 	)
 }
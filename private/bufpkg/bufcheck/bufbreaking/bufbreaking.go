@@ -23,6 +23,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingreport"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1beta1"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
@@ -45,6 +46,17 @@ type Handler interface {
 		previousImage bufimage.Image,
 		image bufimage.Image,
 	) ([]bufanalysis.FileAnnotation, error)
+	// Report returns all changes between the previousImage and the image, whether
+	// they are breaking or not.
+	//
+	// The returned Changes are sorted. Neither image needs to have source code info.
+	//
+	// Images should be filtered with regards to imports before passing to this function.
+	Report(
+		ctx context.Context,
+		previousImage bufimage.Image,
+		image bufimage.Image,
+	) ([]bufbreakingreport.Change, error)
 }
 
 // NewHandler returns a new Handler.
@@ -123,6 +135,7 @@ func internalConfigForConfig(config *bufbreakingconfig.Config) (*internal.Config
 		IgnoreRootPaths:               config.IgnoreRootPaths,
 		IgnoreIDOrCategoryToRootPaths: config.IgnoreIDOrCategoryToRootPaths,
 		IgnoreUnstablePackages:        config.IgnoreUnstablePackages,
+		CustomOptions:                 config.CustomOptions,
 	}.NewConfig(
 		versionSpec,
 	)
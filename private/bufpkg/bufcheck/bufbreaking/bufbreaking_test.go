@@ -23,6 +23,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingreport"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
@@ -35,6 +36,31 @@ import (
 	"go.uber.org/zap"
 )
 
+func TestRunBreakingCustomOptions(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_custom_options",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 8, 1, 8, 43, "FILE_SAME_CUSTOM_OPTION"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 12, 3, 12, 44, "MESSAGE_SAME_CUSTOM_OPTION"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 13, 3, 13, 17, "FIELD_SAME_CUSTOM_OPTION"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 14, 3, 14, 57, "FIELD_SAME_CUSTOM_OPTION"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 20, 3, 20, 53, "MESSAGE_SAME_CUSTOM_OPTION"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 24, 3, 24, 45, "ENUM_SAME_CUSTOM_OPTION"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 25, 18, 25, 58, "ENUM_VALUE_SAME_CUSTOM_OPTION"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 29, 3, 29, 48, "SERVICE_SAME_CUSTOM_OPTION"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 31, 5, 31, 49, "RPC_SAME_CUSTOM_OPTION"),
+	)
+}
+
+func TestRunBreakingCustomOptionsAllowlist(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_custom_options_allowlist",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 12, 3, 12, 44, "MESSAGE_SAME_CUSTOM_OPTION"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 13, 3, 13, 17, "FIELD_SAME_CUSTOM_OPTION"),
+	)
+}
+
 func TestRunBreakingEnumNoDelete(t *testing.T) {
 	testBreaking(
 		t,
@@ -165,6 +191,26 @@ func TestRunBreakingFieldSameCType(t *testing.T) {
 	)
 }
 
+func TestRunBreakingFieldSameDefault(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_field_same_default",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 11, 27, 11, 38, "FIELD_SAME_DEFAULT"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 12, 27, 12, 38, "FIELD_SAME_DEFAULT"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 14, 27, 14, 46, "FIELD_SAME_DEFAULT"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 15, 3, 15, 27, "FIELD_SAME_DEFAULT"),
+	)
+}
+
+func TestRunBreakingFieldSameDeprecated(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_field_same_deprecated",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 6, 18, 6, 35, "FIELD_SAME_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 7, 3, 7, 17, "FIELD_SAME_DEPRECATED"),
+	)
+}
+
 func TestRunBreakingFieldSameJSONName(t *testing.T) {
 	testBreaking(
 		t,
@@ -275,6 +321,18 @@ func TestRunBreakingFieldSameOneof(t *testing.T) {
 	)
 }
 
+func TestRunBreakingFieldSamePacked(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_field_same_packed",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 6, 27, 6, 41, "FIELD_SAME_PACKED"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 7, 3, 7, 26, "FIELD_SAME_PACKED"),
+		bufanalysistesting.NewFileAnnotation(t, "2.proto", 6, 27, 6, 40, "FIELD_SAME_PACKED"),
+		bufanalysistesting.NewFileAnnotation(t, "2.proto", 7, 3, 7, 26, "FIELD_SAME_PACKED"),
+		bufanalysistesting.NewFileAnnotation(t, "2.proto", 10, 28, 10, 42, "FIELD_SAME_PACKED"),
+	)
+}
+
 func TestRunBreakingFieldSameType(t *testing.T) {
 	// TODO: double check all this
 	testBreaking(
@@ -709,6 +767,26 @@ func TestRunBreakingMessageMessage(t *testing.T) {
 	)
 }
 
+func TestReport(t *testing.T) {
+	testReport(
+		t,
+		"report",
+		`removed package "b"`,
+		`added package "c"`,
+		`1.proto: added enum value "a.Enum.ENUM_TWO"`,
+		`1.proto: removed message "a.One.Nested"`,
+		`1.proto: changed field "a.One.one": type changed from "int32" to "int64"`,
+		`1.proto: added field "a.One.three"`,
+		`1.proto: changed field "a.One.two": option "deprecated" changed from "false" to "true"`,
+		`1.proto: removed rpc "a.Service.Delete"`,
+		`1.proto: added rpc "a.Service.List"`,
+		`removed file "2.proto"`,
+		`2.proto: removed message "b.Three"`,
+		`added file "3.proto"`,
+		`3.proto: added message "c.Four"`,
+	)
+}
+
 func testBreaking(
 	t *testing.T,
 	relDirPath string,
@@ -719,6 +797,51 @@ func testBreaking(
 	defer cancel()
 	logger := zap.NewNop()
 
+	previousImage, image, config := testGetImages(ctx, t, relDirPath)
+
+	handler := bufbreaking.NewHandler(logger)
+	fileAnnotations, err := handler.Check(
+		ctx,
+		config.Breaking,
+		previousImage,
+		image,
+	)
+	assert.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		fileAnnotations,
+	)
+}
+
+func testReport(
+	t *testing.T,
+	relDirPath string,
+	expectedChanges ...string,
+) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	previousImage, image, _ := testGetImages(ctx, t, relDirPath)
+
+	changes, err := bufbreaking.NewHandler(zap.NewNop()).Report(ctx, previousImage, image)
+	require.NoError(t, err)
+	bufbreakingreport.SortChanges(changes)
+	changeStrings := make([]string, len(changes))
+	for i, change := range changes {
+		changeStrings[i] = change.String()
+	}
+	assert.Equal(t, expectedChanges, changeStrings)
+}
+
+// testGetImages returns the previous and current Images for the relative directory,
+// along with the current Config.
+func testGetImages(
+	ctx context.Context,
+	t *testing.T,
+	relDirPath string,
+) (bufimage.Image, bufimage.Image, *bufconfig.Config) {
 	previousDirPath := filepath.Join("testdata_previous", relDirPath)
 	dirPath := filepath.Join("testdata", relDirPath)
 
@@ -782,19 +905,7 @@ func testBreaking(
 	require.Empty(t, fileAnnotations)
 	image = bufimage.ImageWithoutImports(image)
 
-	handler := bufbreaking.NewHandler(logger)
-	fileAnnotations, err = handler.Check(
-		ctx,
-		config.Breaking,
-		previousImage,
-		image,
-	)
-	assert.NoError(t, err)
-	bufanalysistesting.AssertFileAnnotationsEqual(
-		t,
-		expectedFileAnnotations,
-		fileAnnotations,
-	)
+	return previousImage, image, config
 }
 
 func testGetConfig(
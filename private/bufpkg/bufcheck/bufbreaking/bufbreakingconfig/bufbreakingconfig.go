@@ -46,6 +46,10 @@ type Config struct {
 	//   v\d+(alpha|beta)\d+
 	//   v\d+p\d+(alpha|beta)\d+
 	IgnoreUnstablePackages bool
+	// CustomOptions restricts the *_SAME_CUSTOM_OPTION rules to the custom options with these names.
+	// A name matches the custom option with this fully-qualified name, and all custom options
+	// in the package with this name. If empty, all custom options are checked.
+	CustomOptions []string
 	// Version represents the version of the breaking change rule and category IDs that should be used with this config.
 	Version string
 }
@@ -58,6 +62,7 @@ func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) *Config {
 		IgnoreRootPaths:               externalConfig.Ignore,
 		IgnoreIDOrCategoryToRootPaths: externalConfig.IgnoreOnly,
 		IgnoreUnstablePackages:        externalConfig.IgnoreUnstablePackages,
+		CustomOptions:                 externalConfig.CustomOptions,
 		Version:                       v1Beta1Version,
 	}
 }
@@ -70,6 +75,7 @@ func NewConfigV1(externalConfig ExternalConfigV1) *Config {
 		IgnoreRootPaths:               externalConfig.Ignore,
 		IgnoreIDOrCategoryToRootPaths: externalConfig.IgnoreOnly,
 		IgnoreUnstablePackages:        externalConfig.IgnoreUnstablePackages,
+		CustomOptions:                 externalConfig.CustomOptions,
 		Version:                       v1Version,
 	}
 }
@@ -107,6 +113,7 @@ type ExternalConfigV1Beta1 struct {
 	// IgnoreIDOrCategoryToRootPaths
 	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
 	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	CustomOptions          []string            `json:"custom_options,omitempty" yaml:"custom_options,omitempty"`
 }
 
 // ExternalConfigV1 is an external config.
@@ -118,6 +125,7 @@ type ExternalConfigV1 struct {
 	// IgnoreIDOrCategoryToRootPaths
 	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
 	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	CustomOptions          []string            `json:"custom_options,omitempty" yaml:"custom_options,omitempty"`
 }
 
 // ExternalConfigV1Beta1ForConfig takes a *Config and returns the v1beta1 external config representation.
@@ -128,6 +136,7 @@ func ExternalConfigV1Beta1ForConfig(config *Config) ExternalConfigV1Beta1 {
 		Ignore:                 config.IgnoreRootPaths,
 		IgnoreOnly:             config.IgnoreIDOrCategoryToRootPaths,
 		IgnoreUnstablePackages: config.IgnoreUnstablePackages,
+		CustomOptions:          config.CustomOptions,
 	}
 }
 
@@ -139,6 +148,7 @@ func ExternalConfigV1ForConfig(config *Config) ExternalConfigV1 {
 		Ignore:                 config.IgnoreRootPaths,
 		IgnoreOnly:             config.IgnoreIDOrCategoryToRootPaths,
 		IgnoreUnstablePackages: config.IgnoreUnstablePackages,
+		CustomOptions:          config.CustomOptions,
 	}
 }
 
@@ -158,6 +168,7 @@ type configJSON struct {
 	IgnoreRootPaths               []string      `json:"ignore_root_paths,omitempty"`
 	IgnoreIDOrCategoryToRootPaths []idPathsJSON `json:"ignore_id_to_root_paths,omitempty"`
 	IgnoreUnstablePackages        bool          `json:"ignore_unstable_packages,omitempty"`
+	CustomOptions                 []string      `json:"custom_options,omitempty"`
 	Version                       string        `json:"version,omitempty"`
 }
 
@@ -189,12 +200,16 @@ func configToJSON(config *Config) *configJSON {
 	sort.Strings(use)
 	sort.Strings(except)
 	sort.Strings(ignoreRootPaths)
+	customOptions := make([]string, len(config.CustomOptions))
+	copy(customOptions, config.CustomOptions)
+	sort.Strings(customOptions)
 	return &configJSON{
 		Use:                           use,
 		Except:                        except,
 		IgnoreRootPaths:               ignoreRootPaths,
 		IgnoreIDOrCategoryToRootPaths: ignoreIDPathsJSON,
 		IgnoreUnstablePackages:        config.IgnoreUnstablePackages,
+		CustomOptions:                 customOptions,
 		Version:                       config.Version,
 	}
 }
@@ -0,0 +1,219 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufbreakingreport contains the change report of breaking change detection.
+//
+// A change report lists all changes between two images, whether they are breaking or
+// not, and is meant to be used for generated API changelogs.
+package bufbreakingreport
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FormatText is the text format for Changes.
+	FormatText Format = iota + 1
+	// FormatJSON is the JSON format for Changes.
+	FormatJSON
+	// FormatMarkdown is the Markdown format for Changes.
+	FormatMarkdown
+)
+
+const (
+	// ChangeTypeAdded says that the element was added.
+	ChangeTypeAdded ChangeType = iota + 1
+	// ChangeTypeRemoved says that the element was removed.
+	ChangeTypeRemoved
+	// ChangeTypeChanged says that the element exists on both sides, but has changed.
+	ChangeTypeChanged
+)
+
+const (
+	// ElementTypePackage is a package.
+	ElementTypePackage = "package"
+	// ElementTypeFile is a file.
+	ElementTypeFile = "file"
+	// ElementTypeMessage is a message.
+	ElementTypeMessage = "message"
+	// ElementTypeField is a field of a message.
+	ElementTypeField = "field"
+	// ElementTypeOneof is a oneof of a message.
+	ElementTypeOneof = "oneof"
+	// ElementTypeExtension is an extension.
+	ElementTypeExtension = "extension"
+	// ElementTypeEnum is an enum.
+	ElementTypeEnum = "enum"
+	// ElementTypeEnumValue is a value of an enum.
+	ElementTypeEnumValue = "enum value"
+	// ElementTypeService is a service.
+	ElementTypeService = "service"
+	// ElementTypeRPC is an RPC of a service.
+	ElementTypeRPC = "rpc"
+)
+
+var (
+	// AllFormatStrings is all format strings.
+	//
+	// Sorted in the order we want to display them.
+	AllFormatStrings = []string{
+		"text",
+		"json",
+		"markdown",
+	}
+
+	stringToFormat = map[string]Format{
+		"text":     FormatText,
+		"json":     FormatJSON,
+		"markdown": FormatMarkdown,
+	}
+	formatToString = map[Format]string{
+		FormatText:     "text",
+		FormatJSON:     "json",
+		FormatMarkdown: "markdown",
+	}
+	changeTypeToString = map[ChangeType]string{
+		ChangeTypeAdded:   "added",
+		ChangeTypeRemoved: "removed",
+		ChangeTypeChanged: "changed",
+	}
+)
+
+// Format is a Change format.
+type Format int
+
+// String implements fmt.Stringer.
+func (f Format) String() string {
+	s, ok := formatToString[f]
+	if !ok {
+		return strconv.Itoa(int(f))
+	}
+	return s
+}
+
+// ParseFormat parses the Format.
+//
+// The empty strings defaults to FormatText.
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return FormatText, nil
+	}
+	f, ok := stringToFormat[s]
+	if ok {
+		return f, nil
+	}
+	return 0, fmt.Errorf("unknown format: %q", s)
+}
+
+// ChangeType is the type of a Change.
+type ChangeType int
+
+// String implements fmt.Stringer.
+func (c ChangeType) String() string {
+	s, ok := changeTypeToString[c]
+	if !ok {
+		return strconv.Itoa(int(c))
+	}
+	return s
+}
+
+// Change is a change of an element between two images.
+type Change interface {
+	// Stringer returns the string representation of this change.
+	fmt.Stringer
+
+	// Type is the type of the change.
+	Type() ChangeType
+	// ElementType is the type of the changed element, such as ElementTypeMessage.
+	ElementType() string
+	// ElementName is the name of the changed element.
+	//
+	// This is the fully-qualified name for named elements, the package name for packages,
+	// and the path for files.
+	ElementName() string
+	// Path is the path of the file that contains the element.
+	//
+	// This is the path of the file in the previous image for removed elements. This is
+	// empty for packages.
+	Path() string
+	// Description describes how the element changed, such as
+	// `type changed from "int32" to "int64"`.
+	//
+	// This is empty unless the type is ChangeTypeChanged.
+	Description() string
+}
+
+// NewChange returns a new Change.
+func NewChange(
+	changeType ChangeType,
+	elementType string,
+	elementName string,
+	path string,
+	description string,
+) Change {
+	return newChange(
+		changeType,
+		elementType,
+		elementName,
+		path,
+		description,
+	)
+}
+
+// SortChanges sorts the Changes by path, element name, type and description.
+func SortChanges(changes []Change) {
+	sort.Stable(sortChanges(changes))
+}
+
+// PrintChanges prints the Changes to the writer in the given format.
+//
+// The Changes should be sorted before printing.
+func PrintChanges(writer io.Writer, changes []Change, formatString string) error {
+	format, err := ParseFormat(formatString)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatText:
+		return printAsText(writer, changes)
+	case FormatJSON:
+		return printAsJSON(writer, changes)
+	case FormatMarkdown:
+		return printAsMarkdown(writer, changes)
+	default:
+		return fmt.Errorf("unknown Change Format: %v", format)
+	}
+}
+
+type sortChanges []Change
+
+func (a sortChanges) Len() int          { return len(a) }
+func (a sortChanges) Swap(i int, j int) { a[i], a[j] = a[j], a[i] }
+func (a sortChanges) Less(i int, j int) bool {
+	if a[i].Path() != a[j].Path() {
+		return a[i].Path() < a[j].Path()
+	}
+	if a[i].ElementName() != a[j].ElementName() {
+		return a[i].ElementName() < a[j].ElementName()
+	}
+	if a[i].Type() != a[j].Type() {
+		return a[i].Type() < a[j].Type()
+	}
+	return a[i].Description() < a[j].Description()
+}
@@ -0,0 +1,77 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingreport
+
+import (
+	"strconv"
+)
+
+type change struct {
+	changeType  ChangeType
+	elementType string
+	elementName string
+	path        string
+	description string
+}
+
+func newChange(
+	changeType ChangeType,
+	elementType string,
+	elementName string,
+	path string,
+	description string,
+) *change {
+	return &change{
+		changeType:  changeType,
+		elementType: elementType,
+		elementName: elementName,
+		path:        path,
+		description: description,
+	}
+}
+
+func (c *change) Type() ChangeType {
+	return c.changeType
+}
+
+func (c *change) ElementType() string {
+	return c.elementType
+}
+
+func (c *change) ElementName() string {
+	return c.elementName
+}
+
+func (c *change) Path() string {
+	return c.path
+}
+
+func (c *change) Description() string {
+	return c.description
+}
+
+func (c *change) String() string {
+	if c == nil {
+		return ""
+	}
+	s := c.changeType.String() + " " + c.elementType + " " + strconv.Quote(c.elementName)
+	if c.description != "" {
+		s += ": " + c.description
+	}
+	if c.path != "" && c.elementType != ElementTypeFile {
+		s = c.path + ": " + s
+	}
+	return s
+}
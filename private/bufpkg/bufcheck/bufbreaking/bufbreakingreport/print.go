@@ -0,0 +1,108 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingreport
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// markdownSections are the sections of the Markdown format, in the order they are printed.
+var markdownSections = []struct {
+	changeType ChangeType
+	title      string
+}{
+	{changeType: ChangeTypeAdded, title: "Added"},
+	{changeType: ChangeTypeRemoved, title: "Removed"},
+	{changeType: ChangeTypeChanged, title: "Changed"},
+}
+
+func printAsText(writer io.Writer, changes []Change) error {
+	buffer := bytes.NewBuffer(nil)
+	for _, change := range changes {
+		_, _ = buffer.WriteString(change.String())
+		_, _ = buffer.WriteString("\n")
+	}
+	_, err := writer.Write(buffer.Bytes())
+	return err
+}
+
+func printAsJSON(writer io.Writer, changes []Change) error {
+	buffer := bytes.NewBuffer(nil)
+	for _, change := range changes {
+		data, err := json.Marshal(
+			&externalChange{
+				Type:        change.Type().String(),
+				ElementType: change.ElementType(),
+				ElementName: change.ElementName(),
+				Path:        change.Path(),
+				Description: change.Description(),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		_, _ = buffer.Write(data)
+		_, _ = buffer.WriteString("\n")
+	}
+	_, err := writer.Write(buffer.Bytes())
+	return err
+}
+
+func printAsMarkdown(writer io.Writer, changes []Change) error {
+	buffer := bytes.NewBuffer(nil)
+	for _, section := range markdownSections {
+		var sectionChanges []Change
+		for _, change := range changes {
+			if change.Type() == section.changeType {
+				sectionChanges = append(sectionChanges, change)
+			}
+		}
+		if len(sectionChanges) == 0 {
+			continue
+		}
+		if buffer.Len() > 0 {
+			_, _ = buffer.WriteString("\n")
+		}
+		_, _ = buffer.WriteString("## " + section.title + "\n\n")
+		for _, change := range sectionChanges {
+			_, _ = buffer.WriteString("- " + change.ElementType() + " " + markdownCode(change.ElementName()))
+			if change.Path() != "" && change.ElementType() != ElementTypeFile {
+				_, _ = buffer.WriteString(" in " + markdownCode(change.Path()))
+			}
+			if change.Description() != "" {
+				_, _ = buffer.WriteString(": " + change.Description())
+			}
+			_, _ = buffer.WriteString("\n")
+		}
+	}
+	_, err := writer.Write(buffer.Bytes())
+	return err
+}
+
+// markdownCode returns the value as inline code.
+func markdownCode(value string) string {
+	return "`" + strings.ReplaceAll(value, "`", "") + "`"
+}
+
+type externalChange struct {
+	Type        string `json:"type,omitempty"`
+	ElementType string `json:"element_type,omitempty"`
+	ElementName string `json:"element_name,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Description string `json:"description,omitempty"`
+}
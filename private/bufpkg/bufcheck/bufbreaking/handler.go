@@ -19,6 +19,8 @@ import (
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingreport"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
@@ -62,3 +64,19 @@ func (h *handler) Check(
 	}
 	return h.runner.Check(ctx, internalConfig, previousFiles, files)
 }
+
+func (h *handler) Report(
+	ctx context.Context,
+	previousImage bufimage.Image,
+	image bufimage.Image,
+) ([]bufbreakingreport.Change, error) {
+	previousFiles, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(previousImage.Files())...)
+	if err != nil {
+		return nil, err
+	}
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	if err != nil {
+		return nil, err
+	}
+	return bufbreakingcheck.Changes(previousFiles, files)
+}
@@ -21,8 +21,12 @@
 package bufbreakingbuild
 
 import (
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+	"github.com/bufbuild/buf/private/pkg/protosource"
 )
 
 var (
@@ -32,6 +36,12 @@ var (
 		"enums are not deleted from a given file",
 		bufbreakingcheck.CheckEnumNoDelete,
 	)
+	// EnumSameCustomOptionRuleBuilder is a rule builder.
+	EnumSameCustomOptionRuleBuilder = newCustomOptionRuleBuilder(
+		"ENUM_SAME_CUSTOM_OPTION",
+		"enums",
+		bufbreakingcheck.CheckEnumSameCustomOption,
+	)
 	// EnumValueNoDeleteRuleBuilder is a rule builder.
 	EnumValueNoDeleteRuleBuilder = internal.NewNopRuleBuilder(
 		"ENUM_VALUE_NO_DELETE",
@@ -50,6 +60,12 @@ var (
 		"enum values are not deleted from a given enum unless the number is reserved",
 		bufbreakingcheck.CheckEnumValueNoDeleteUnlessNumberReserved,
 	)
+	// EnumValueSameCustomOptionRuleBuilder is a rule builder.
+	EnumValueSameCustomOptionRuleBuilder = newCustomOptionRuleBuilder(
+		"ENUM_VALUE_SAME_CUSTOM_OPTION",
+		"enum values",
+		bufbreakingcheck.CheckEnumValueSameCustomOption,
+	)
 	// EnumValueSameNameRuleBuilder is a rule builder.
 	EnumValueSameNameRuleBuilder = internal.NewNopRuleBuilder(
 		"ENUM_VALUE_SAME_NAME",
@@ -86,6 +102,24 @@ var (
 		"fields have the same value for the ctype option",
 		bufbreakingcheck.CheckFieldSameCType,
 	)
+	// FieldSameCustomOptionRuleBuilder is a rule builder.
+	FieldSameCustomOptionRuleBuilder = newCustomOptionRuleBuilder(
+		"FIELD_SAME_CUSTOM_OPTION",
+		"fields",
+		bufbreakingcheck.CheckFieldSameCustomOption,
+	)
+	// FieldSameDefaultRuleBuilder is a rule builder.
+	FieldSameDefaultRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_SAME_DEFAULT",
+		"fields have the same value for the default option",
+		bufbreakingcheck.CheckFieldSameDefault,
+	)
+	// FieldSameDeprecatedRuleBuilder is a rule builder.
+	FieldSameDeprecatedRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_SAME_DEPRECATED",
+		"fields have the same value for the deprecated option",
+		bufbreakingcheck.CheckFieldSameDeprecated,
+	)
 	// FieldSameJSONNameRuleBuilder is a rule builder.
 	FieldSameJSONNameRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_SAME_JSON_NAME",
@@ -116,6 +150,12 @@ var (
 		"fields have the same oneofs in a given message",
 		bufbreakingcheck.CheckFieldSameOneof,
 	)
+	// FieldSamePackedRuleBuilder is a rule builder.
+	FieldSamePackedRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_SAME_PACKED",
+		"fields have the same value for the packed option",
+		bufbreakingcheck.CheckFieldSamePacked,
+	)
 	// FieldSameTypeRuleBuilder is a rule builder.
 	FieldSameTypeRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_SAME_TYPE",
@@ -146,6 +186,12 @@ var (
 		"files have the same value for the csharp_namespace option",
 		bufbreakingcheck.CheckFileSameCsharpNamespace,
 	)
+	// FileSameCustomOptionRuleBuilder is a rule builder.
+	FileSameCustomOptionRuleBuilder = newCustomOptionRuleBuilder(
+		"FILE_SAME_CUSTOM_OPTION",
+		"files",
+		bufbreakingcheck.CheckFileSameCustomOption,
+	)
 	// FileSameGoPackageRuleBuilder is a rule builder.
 	FileSameGoPackageRuleBuilder = internal.NewNopRuleBuilder(
 		"FILE_SAME_GO_PACKAGE",
@@ -272,6 +318,12 @@ var (
 		"messages do not change the no_standard_descriptor_accessor option from false or unset to true",
 		bufbreakingcheck.CheckMessageNoRemoveStandardDescriptorAccessor,
 	)
+	// MessageSameCustomOptionRuleBuilder is a rule builder.
+	MessageSameCustomOptionRuleBuilder = newCustomOptionRuleBuilder(
+		"MESSAGE_SAME_CUSTOM_OPTION",
+		"messages",
+		bufbreakingcheck.CheckMessageSameCustomOption,
+	)
 	// MessageSameMessageSetWireFormatRuleBuilder is a rule builder.
 	MessageSameMessageSetWireFormatRuleBuilder = internal.NewNopRuleBuilder(
 		"MESSAGE_SAME_MESSAGE_SET_WIRE_FORMAT",
@@ -338,6 +390,12 @@ var (
 		"rpcs have the same client streaming value",
 		bufbreakingcheck.CheckRPCSameClientStreaming,
 	)
+	// RPCSameCustomOptionRuleBuilder is a rule builder.
+	RPCSameCustomOptionRuleBuilder = newCustomOptionRuleBuilder(
+		"RPC_SAME_CUSTOM_OPTION",
+		"rpcs",
+		bufbreakingcheck.CheckRPCSameCustomOption,
+	)
 	// RPCSameIdempotencyLevelRuleBuilder is a rule builder.
 	RPCSameIdempotencyLevelRuleBuilder = internal.NewNopRuleBuilder(
 		"RPC_SAME_IDEMPOTENCY_LEVEL",
@@ -368,4 +426,37 @@ var (
 		"services are not deleted from a given file",
 		bufbreakingcheck.CheckServiceNoDelete,
 	)
+	// ServiceSameCustomOptionRuleBuilder is a rule builder.
+	ServiceSameCustomOptionRuleBuilder = newCustomOptionRuleBuilder(
+		"SERVICE_SAME_CUSTOM_OPTION",
+		"services",
+		bufbreakingcheck.CheckServiceSameCustomOption,
+	)
 )
+
+// newCustomOptionRuleBuilder returns a new RuleBuilder for a rule that checks that
+// the custom options of the given kind of descriptors have the same values.
+//
+// The checked custom options can be restricted with the custom_options key of the
+// breaking configuration.
+func newCustomOptionRuleBuilder(
+	id string,
+	pluralDescriptorName string,
+	checkFunc func(string, internal.IgnoreFunc, []protosource.File, []protosource.File, []string) ([]bufanalysis.FileAnnotation, error),
+) *internal.RuleBuilder {
+	return internal.NewRuleBuilder(
+		id,
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			if len(configBuilder.CustomOptions) == 0 {
+				return pluralDescriptorName + " have the same values for custom options", nil
+			}
+			return pluralDescriptorName + " have the same values for the custom options " + strings.Join(configBuilder.CustomOptions, ", ") + " (custom options are configurable)", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			customOptions := configBuilder.CustomOptions
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, previousFiles []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return checkFunc(id, ignoreFunc, previousFiles, files, customOptions)
+			}), nil
+		},
+	)
+}
@@ -23,6 +23,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/pkg/protosource"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 )
@@ -52,6 +54,38 @@ func checkEnumNoDelete(add addFunc, corpus *corpus, previousFile protosource.Fil
 	return nil
 }
 
+// CheckEnumSameCustomOption is a check function.
+var CheckEnumSameCustomOption = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionNamer, err := newCustomOptionNamer(customOptions, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	return newEnumPairCheckFunc(
+		func(add addFunc, corpus *corpus, previousEnum protosource.Enum, enum protosource.Enum) error {
+			return checkEnumSameCustomOption(add, customOptionNamer, previousEnum, enum)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkEnumSameCustomOption(add addFunc, customOptionNamer *customOptionNamer, previousEnum protosource.Enum, enum protosource.Enum) error {
+	return checkSameCustomOptions(
+		add,
+		customOptionNamer,
+		"google.protobuf.EnumOptions",
+		previousEnum,
+		enum,
+		enum,
+		enum.Location(),
+		fmt.Sprintf("Enum %q", enum.Name()),
+	)
+}
+
 // CheckEnumValueNoDelete is a check function.
 var CheckEnumValueNoDelete = newEnumPairCheckFunc(checkEnumValueNoDelete)
 
@@ -122,6 +156,49 @@ func isDeletedEnumValueAllowedWithRules(previousNumber int, previousNameToEnumVa
 	return false
 }
 
+// CheckEnumValueSameCustomOption is a check function.
+var CheckEnumValueSameCustomOption = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionNamer, err := newCustomOptionNamer(customOptions, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	return newEnumValuePairCheckFunc(
+		func(add addFunc, corpus *corpus, previousNameToEnumValue map[string]protosource.EnumValue, nameToEnumValue map[string]protosource.EnumValue) error {
+			return checkEnumValueSameCustomOption(add, customOptionNamer, previousNameToEnumValue, nameToEnumValue)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkEnumValueSameCustomOption(add addFunc, customOptionNamer *customOptionNamer, previousNameToEnumValue map[string]protosource.EnumValue, nameToEnumValue map[string]protosource.EnumValue) error {
+	// renames are checked by ENUM_VALUE_SAME_NAME, we only compare the values with the same name
+	for _, name := range getSortedEnumValueNames(nameToEnumValue) {
+		previousEnumValue, ok := previousNameToEnumValue[name]
+		if !ok {
+			continue
+		}
+		enumValue := nameToEnumValue[name]
+		if err := checkSameCustomOptions(
+			add,
+			customOptionNamer,
+			"google.protobuf.EnumValueOptions",
+			previousEnumValue,
+			enumValue,
+			enumValue,
+			enumValue.Location(),
+			fmt.Sprintf("Enum value %q on enum %q", enumValue.Name(), enumValue.Enum().Name()),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CheckEnumValueSameName is a check function.
 var CheckEnumValueSameName = newEnumValuePairCheckFunc(checkEnumValueSameName)
 
@@ -229,6 +306,66 @@ func checkFieldSameCType(add addFunc, corpus *corpus, previousField protosource.
 	return nil
 }
 
+// CheckFieldSameCustomOption is a check function.
+var CheckFieldSameCustomOption = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionNamer, err := newCustomOptionNamer(customOptions, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	return newFieldPairCheckFunc(
+		func(add addFunc, corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+			return checkFieldSameCustomOption(add, customOptionNamer, previousField, field)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkFieldSameCustomOption(add addFunc, customOptionNamer *customOptionNamer, previousField protosource.Field, field protosource.Field) error {
+	// otherwise prints as hex
+	numberString := strconv.FormatInt(int64(field.Number()), 10)
+	return checkSameCustomOptions(
+		add,
+		customOptionNamer,
+		"google.protobuf.FieldOptions",
+		previousField,
+		field,
+		field,
+		field.Location(),
+		fmt.Sprintf("Field %q with name %q on message %q", numberString, field.Name(), field.Message().Name()),
+	)
+}
+
+// CheckFieldSameDefault is a check function.
+var CheckFieldSameDefault = newFieldPairCheckFunc(checkFieldSameDefault)
+
+func checkFieldSameDefault(add addFunc, corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+	if previousField.DefaultValue() != field.DefaultValue() {
+		// otherwise prints as hex
+		numberString := strconv.FormatInt(int64(field.Number()), 10)
+		add(field, nil, withBackupLocation(field.DefaultValueLocation(), field.Location()), `Field %q with name %q on message %q changed option "default" from %q to %q.`, numberString, field.Name(), field.Message().Name(), previousField.DefaultValue(), field.DefaultValue())
+	}
+	return nil
+}
+
+// CheckFieldSameDeprecated is a check function.
+var CheckFieldSameDeprecated = newFieldPairCheckFunc(checkFieldSameDeprecated)
+
+func checkFieldSameDeprecated(add addFunc, corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+	previous := strconv.FormatBool(previousField.Deprecated())
+	current := strconv.FormatBool(field.Deprecated())
+	if previous != current {
+		// otherwise prints as hex
+		numberString := strconv.FormatInt(int64(field.Number()), 10)
+		add(field, nil, withBackupLocation(field.DeprecatedLocation(), field.Location()), `Field %q with name %q on message %q changed option "deprecated" from %q to %q.`, numberString, field.Name(), field.Message().Name(), previous, current)
+	}
+	return nil
+}
+
 // CheckFieldSameJSONName is a check function.
 var CheckFieldSameJSONName = newFieldPairCheckFunc(checkFieldSameJSONName)
 
@@ -331,6 +468,21 @@ func checkFieldSameOneof(add addFunc, corpus *corpus, previousField protosource.
 // breaking_field_same_type/2.proto:64:5:Field "1" on message "Nine" changed type from "int32" to "int64".
 // breaking_field_same_type/2.proto:65:5:Field "2" on message "Nine" changed type from ".a.One" to ".a.Nine".
 
+// CheckFieldSamePacked is a check function.
+var CheckFieldSamePacked = newFieldPairCheckFunc(checkFieldSamePacked)
+
+func checkFieldSamePacked(add addFunc, corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+	// we compare the effective value, as proto3 packs repeated scalar fields by default
+	previous := strconv.FormatBool(fieldIsPacked(previousField))
+	current := strconv.FormatBool(fieldIsPacked(field))
+	if previous != current {
+		// otherwise prints as hex
+		numberString := strconv.FormatInt(int64(field.Number()), 10)
+		add(field, nil, withBackupLocation(field.PackedLocation(), field.Location()), `Field %q with name %q on message %q changed option "packed" from %q to %q.`, numberString, field.Name(), field.Message().Name(), previous, current)
+	}
+	return nil
+}
+
 // CheckFieldSameType is a check function.
 var CheckFieldSameType = newFieldPairCheckFunc(checkFieldSameType)
 
@@ -543,6 +695,38 @@ func checkFileSameCsharpNamespace(add addFunc, corpus *corpus, previousFile prot
 	return checkFileSameValue(add, previousFile.CsharpNamespace(), file.CsharpNamespace(), file, file.CsharpNamespaceLocation(), `option "csharp_namespace"`)
 }
 
+// CheckFileSameCustomOption is a check function.
+var CheckFileSameCustomOption = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionNamer, err := newCustomOptionNamer(customOptions, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	return newFilePairCheckFunc(
+		func(add addFunc, corpus *corpus, previousFile protosource.File, file protosource.File) error {
+			return checkFileSameCustomOption(add, customOptionNamer, previousFile, file)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkFileSameCustomOption(add addFunc, customOptionNamer *customOptionNamer, previousFile protosource.File, file protosource.File) error {
+	return checkSameCustomOptions(
+		add,
+		customOptionNamer,
+		"google.protobuf.FileOptions",
+		previousFile,
+		file,
+		file,
+		nil,
+		fmt.Sprintf("File %q", file.Path()),
+	)
+}
+
 // CheckFileSameGoPackage is a check function.
 var CheckFileSameGoPackage = newFilePairCheckFunc(checkFileSameGoPackage)
 
@@ -724,6 +908,38 @@ func checkMessageNoRemoveStandardDescriptorAccessor(add addFunc, corpus *corpus,
 	return nil
 }
 
+// CheckMessageSameCustomOption is a check function.
+var CheckMessageSameCustomOption = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionNamer, err := newCustomOptionNamer(customOptions, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	return newMessagePairCheckFunc(
+		func(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+			return checkMessageSameCustomOption(add, customOptionNamer, previousMessage, message)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkMessageSameCustomOption(add addFunc, customOptionNamer *customOptionNamer, previousMessage protosource.Message, message protosource.Message) error {
+	return checkSameCustomOptions(
+		add,
+		customOptionNamer,
+		"google.protobuf.MessageOptions",
+		previousMessage,
+		message,
+		message,
+		message.Location(),
+		fmt.Sprintf("Message %q", message.Name()),
+	)
+}
+
 // CheckMessageSameMessageSetWireFormat is a check function.
 var CheckMessageSameMessageSetWireFormat = newMessagePairCheckFunc(checkMessageSameMessageSetWireFormat)
 
@@ -1034,6 +1250,38 @@ func checkRPCSameClientStreaming(add addFunc, corpus *corpus, previousMethod pro
 	return nil
 }
 
+// CheckRPCSameCustomOption is a check function.
+var CheckRPCSameCustomOption = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionNamer, err := newCustomOptionNamer(customOptions, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	return newMethodPairCheckFunc(
+		func(add addFunc, corpus *corpus, previousMethod protosource.Method, method protosource.Method) error {
+			return checkRPCSameCustomOption(add, customOptionNamer, previousMethod, method)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkRPCSameCustomOption(add addFunc, customOptionNamer *customOptionNamer, previousMethod protosource.Method, method protosource.Method) error {
+	return checkSameCustomOptions(
+		add,
+		customOptionNamer,
+		"google.protobuf.MethodOptions",
+		previousMethod,
+		method,
+		method,
+		method.Location(),
+		fmt.Sprintf("RPC %q on service %q", method.Name(), method.Service().Name()),
+	)
+}
+
 // CheckRPCSameIdempotencyLevel is a check function.
 var CheckRPCSameIdempotencyLevel = newMethodPairCheckFunc(checkRPCSameIdempotencyLevel)
 
@@ -1101,3 +1349,35 @@ func checkServiceNoDelete(add addFunc, corpus *corpus, previousFile protosource.
 	}
 	return nil
 }
+
+// CheckServiceSameCustomOption is a check function.
+var CheckServiceSameCustomOption = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	customOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	customOptionNamer, err := newCustomOptionNamer(customOptions, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	return newServicePairCheckFunc(
+		func(add addFunc, corpus *corpus, previousService protosource.Service, service protosource.Service) error {
+			return checkServiceSameCustomOption(add, customOptionNamer, previousService, service)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkServiceSameCustomOption(add addFunc, customOptionNamer *customOptionNamer, previousService protosource.Service, service protosource.Service) error {
+	return checkSameCustomOptions(
+		add,
+		customOptionNamer,
+		"google.protobuf.ServiceOptions",
+		previousService,
+		service,
+		service,
+		service.Location(),
+		fmt.Sprintf("Service %q", service.Name()),
+	)
+}
@@ -0,0 +1,511 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingcheck
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingreport"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+// Changes returns all changes between the previous files and the files.
+//
+// As opposed to the check functions, this reports all added, removed and changed
+// elements, whether the change is breaking or not.
+func Changes(previousFiles []protosource.File, files []protosource.File) ([]bufbreakingreport.Change, error) {
+	customOptionNamer, err := newCustomOptionNamer(nil, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	changes := &changes{
+		customOptionNamer: customOptionNamer,
+	}
+	for _, f := range []func([]protosource.File, []protosource.File) error{
+		changes.addPackageChanges,
+		changes.addFileChanges,
+		changes.addMessageChanges,
+		changes.addExtensionChanges,
+		changes.addEnumChanges,
+		changes.addServiceChanges,
+	} {
+		if err := f(previousFiles, files); err != nil {
+			return nil, err
+		}
+	}
+	bufbreakingreport.SortChanges(changes.changes)
+	return changes.changes, nil
+}
+
+type changes struct {
+	customOptionNamer *customOptionNamer
+	changes           []bufbreakingreport.Change
+}
+
+func (c *changes) add(changeType bufbreakingreport.ChangeType, elementType string, elementName string, path string, description string) {
+	c.changes = append(
+		c.changes,
+		bufbreakingreport.NewChange(changeType, elementType, elementName, path, description),
+	)
+}
+
+// addIfChanged adds a ChangeTypeChanged Change if the values differ.
+//
+// name is what changed, such as `option "go_package"`.
+func (c *changes) addIfChanged(elementType string, descriptor protosource.NamedDescriptor, name string, previousValue string, value string) {
+	if previousValue != value {
+		c.add(
+			bufbreakingreport.ChangeTypeChanged,
+			elementType,
+			descriptor.FullName(),
+			descriptor.File().Path(),
+			fmt.Sprintf("%s changed from %q to %q", name, previousValue, value),
+		)
+	}
+}
+
+// addCustomOptionChanges adds a Change for every custom option that was added, removed, or
+// changed between the previous and current descriptor.
+func (c *changes) addCustomOptionChanges(
+	extendee string,
+	elementType string,
+	elementName string,
+	path string,
+	previousOptionExtensionDescriptor protosource.OptionExtensionDescriptor,
+	optionExtensionDescriptor protosource.OptionExtensionDescriptor,
+) {
+	numbers := make(map[int32]struct{})
+	for _, number := range previousOptionExtensionDescriptor.PresentExtensionNumbers() {
+		numbers[number] = struct{}{}
+	}
+	for _, number := range optionExtensionDescriptor.PresentExtensionNumbers() {
+		numbers[number] = struct{}{}
+	}
+	for _, number := range sortedNumbers(numbers) {
+		name, _ := c.customOptionNamer.name(extendee, number)
+		previousValue := previousOptionExtensionDescriptor.PresentExtensionBytes(number)
+		value := optionExtensionDescriptor.PresentExtensionBytes(number)
+		var description string
+		switch {
+		case bytes.Equal(previousValue, value):
+			continue
+		case previousValue == nil:
+			description = fmt.Sprintf("option %q added", name)
+		case value == nil:
+			description = fmt.Sprintf("option %q removed", name)
+		default:
+			description = fmt.Sprintf("option %q changed", name)
+		}
+		c.add(bufbreakingreport.ChangeTypeChanged, elementType, elementName, path, description)
+	}
+}
+
+func (c *changes) addPackageChanges(previousFiles []protosource.File, files []protosource.File) error {
+	previousPackageToFiles, err := protosource.PackageToFiles(previousFiles...)
+	if err != nil {
+		return err
+	}
+	packageToFiles, err := protosource.PackageToFiles(files...)
+	if err != nil {
+		return err
+	}
+	for previousPackage := range previousPackageToFiles {
+		if _, ok := packageToFiles[previousPackage]; !ok && previousPackage != "" {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypePackage, previousPackage, "", "")
+		}
+	}
+	for pkg := range packageToFiles {
+		if _, ok := previousPackageToFiles[pkg]; !ok && pkg != "" {
+			c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypePackage, pkg, "", "")
+		}
+	}
+	return nil
+}
+
+func (c *changes) addFileChanges(previousFiles []protosource.File, files []protosource.File) error {
+	previousFilePathToFile, err := protosource.FilePathToFile(previousFiles...)
+	if err != nil {
+		return err
+	}
+	filePathToFile, err := protosource.FilePathToFile(files...)
+	if err != nil {
+		return err
+	}
+	for previousFilePath := range previousFilePathToFile {
+		if _, ok := filePathToFile[previousFilePath]; !ok {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypeFile, previousFilePath, previousFilePath, "")
+		}
+	}
+	for filePath, file := range filePathToFile {
+		previousFile, ok := previousFilePathToFile[filePath]
+		if !ok {
+			c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypeFile, filePath, filePath, "")
+			continue
+		}
+		for _, value := range []struct {
+			name     string
+			previous string
+			current  string
+		}{
+			{`syntax`, previousFile.Syntax().String(), file.Syntax().String()},
+			{`package`, previousFile.Package(), file.Package()},
+			{`option "csharp_namespace"`, previousFile.CsharpNamespace(), file.CsharpNamespace()},
+			{`option "go_package"`, previousFile.GoPackage(), file.GoPackage()},
+			{`option "java_multiple_files"`, strconv.FormatBool(previousFile.JavaMultipleFiles()), strconv.FormatBool(file.JavaMultipleFiles())},
+			{`option "java_outer_classname"`, previousFile.JavaOuterClassname(), file.JavaOuterClassname()},
+			{`option "java_package"`, previousFile.JavaPackage(), file.JavaPackage()},
+			{`option "java_string_check_utf8"`, strconv.FormatBool(previousFile.JavaStringCheckUtf8()), strconv.FormatBool(file.JavaStringCheckUtf8())},
+			{`option "objc_class_prefix"`, previousFile.ObjcClassPrefix(), file.ObjcClassPrefix()},
+			{`option "php_class_prefix"`, previousFile.PhpClassPrefix(), file.PhpClassPrefix()},
+			{`option "php_namespace"`, previousFile.PhpNamespace(), file.PhpNamespace()},
+			{`option "php_metadata_namespace"`, previousFile.PhpMetadataNamespace(), file.PhpMetadataNamespace()},
+			{`option "ruby_package"`, previousFile.RubyPackage(), file.RubyPackage()},
+			{`option "swift_prefix"`, previousFile.SwiftPrefix(), file.SwiftPrefix()},
+			{`option "optimize_for"`, previousFile.OptimizeFor().String(), file.OptimizeFor().String()},
+			{`option "cc_generic_services"`, strconv.FormatBool(previousFile.CcGenericServices()), strconv.FormatBool(file.CcGenericServices())},
+			{`option "java_generic_services"`, strconv.FormatBool(previousFile.JavaGenericServices()), strconv.FormatBool(file.JavaGenericServices())},
+			{`option "py_generic_services"`, strconv.FormatBool(previousFile.PyGenericServices()), strconv.FormatBool(file.PyGenericServices())},
+			{`option "php_generic_services"`, strconv.FormatBool(previousFile.PhpGenericServices()), strconv.FormatBool(file.PhpGenericServices())},
+			{`option "cc_enable_arenas"`, strconv.FormatBool(previousFile.CcEnableArenas()), strconv.FormatBool(file.CcEnableArenas())},
+			{`option "deprecated"`, strconv.FormatBool(previousFile.Deprecated()), strconv.FormatBool(file.Deprecated())},
+		} {
+			if value.previous != value.current {
+				c.add(
+					bufbreakingreport.ChangeTypeChanged,
+					bufbreakingreport.ElementTypeFile,
+					filePath,
+					filePath,
+					fmt.Sprintf("%s changed from %q to %q", value.name, value.previous, value.current),
+				)
+			}
+		}
+		c.addCustomOptionChanges("google.protobuf.FileOptions", bufbreakingreport.ElementTypeFile, filePath, filePath, previousFile, file)
+	}
+	return nil
+}
+
+func (c *changes) addMessageChanges(previousFiles []protosource.File, files []protosource.File) error {
+	previousFullNameToMessage, err := protosource.FullNameToMessage(previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToMessage, err := protosource.FullNameToMessage(files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousMessage := range previousFullNameToMessage {
+		// map entries are reported as part of their map field
+		if _, ok := fullNameToMessage[previousFullName]; !ok && !previousMessage.IsMapEntry() {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypeMessage, previousFullName, previousMessage.File().Path(), "")
+		}
+	}
+	for fullName, message := range fullNameToMessage {
+		previousMessage, ok := previousFullNameToMessage[fullName]
+		if !ok {
+			if !message.IsMapEntry() {
+				c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypeMessage, fullName, message.File().Path(), "")
+			}
+			continue
+		}
+		if !message.IsMapEntry() {
+			if previousMessage.File().Path() != message.File().Path() {
+				c.addIfChanged(bufbreakingreport.ElementTypeMessage, message, "file", previousMessage.File().Path(), message.File().Path())
+			}
+			c.addIfChanged(bufbreakingreport.ElementTypeMessage, message, `option "message_set_wire_format"`, strconv.FormatBool(previousMessage.MessageSetWireFormat()), strconv.FormatBool(message.MessageSetWireFormat()))
+			c.addIfChanged(bufbreakingreport.ElementTypeMessage, message, `option "no_standard_descriptor_accessor"`, strconv.FormatBool(previousMessage.NoStandardDescriptorAccessor()), strconv.FormatBool(message.NoStandardDescriptorAccessor()))
+			c.addIfChanged(bufbreakingreport.ElementTypeMessage, message, `option "deprecated"`, strconv.FormatBool(previousMessage.Deprecated()), strconv.FormatBool(message.Deprecated()))
+			c.addCustomOptionChanges("google.protobuf.MessageOptions", bufbreakingreport.ElementTypeMessage, fullName, message.File().Path(), previousMessage, message)
+		}
+		if err := c.addFieldChanges(previousMessage, message); err != nil {
+			return err
+		}
+		if err := c.addOneofChanges(previousMessage, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *changes) addFieldChanges(previousMessage protosource.Message, message protosource.Message) error {
+	previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
+	if err != nil {
+		return err
+	}
+	numberToField, err := protosource.NumberToMessageField(message)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousField := range previousNumberToField {
+		if _, ok := numberToField[previousNumber]; !ok {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypeField, previousField.FullName(), previousField.File().Path(), "")
+		}
+	}
+	for number, field := range numberToField {
+		previousField, ok := previousNumberToField[number]
+		if !ok {
+			c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypeField, field.FullName(), field.File().Path(), "")
+			continue
+		}
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, "name", previousField.Name(), field.Name())
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, "label", previousField.Label().String(), field.Label().String())
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, "type", fieldTypeString(previousField), fieldTypeString(field))
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, "oneof", fieldOneofName(previousField), fieldOneofName(field))
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, `option "json_name"`, previousField.JSONName(), field.JSONName())
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, `option "ctype"`, previousField.CType().String(), field.CType().String())
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, `option "jstype"`, previousField.JSType().String(), field.JSType().String())
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, `option "packed"`, strconv.FormatBool(fieldIsPacked(previousField)), strconv.FormatBool(fieldIsPacked(field)))
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, `option "default"`, previousField.DefaultValue(), field.DefaultValue())
+		c.addIfChanged(bufbreakingreport.ElementTypeField, field, `option "deprecated"`, strconv.FormatBool(previousField.Deprecated()), strconv.FormatBool(field.Deprecated()))
+		c.addCustomOptionChanges("google.protobuf.FieldOptions", bufbreakingreport.ElementTypeField, field.FullName(), field.File().Path(), previousField, field)
+	}
+	return nil
+}
+
+func (c *changes) addOneofChanges(previousMessage protosource.Message, message protosource.Message) error {
+	previousNameToOneof, err := protosource.NameToMessageOneof(previousMessage)
+	if err != nil {
+		return err
+	}
+	nameToOneof, err := protosource.NameToMessageOneof(message)
+	if err != nil {
+		return err
+	}
+	for previousName, previousOneof := range previousNameToOneof {
+		if _, ok := nameToOneof[previousName]; !ok && !oneofIsSynthetic(previousOneof) {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypeOneof, previousOneof.FullName(), previousOneof.File().Path(), "")
+		}
+	}
+	for name, oneof := range nameToOneof {
+		if _, ok := previousNameToOneof[name]; !ok && !oneofIsSynthetic(oneof) {
+			c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypeOneof, oneof.FullName(), oneof.File().Path(), "")
+		}
+	}
+	return nil
+}
+
+func (c *changes) addExtensionChanges(previousFiles []protosource.File, files []protosource.File) error {
+	previousFullNameToExtension, err := fullNameToExtension(previousFiles)
+	if err != nil {
+		return err
+	}
+	fullNameToExtension, err := fullNameToExtension(files)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousExtension := range previousFullNameToExtension {
+		if _, ok := fullNameToExtension[previousFullName]; !ok {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypeExtension, previousFullName, previousExtension.File().Path(), "")
+		}
+	}
+	for fullName, extension := range fullNameToExtension {
+		previousExtension, ok := previousFullNameToExtension[fullName]
+		if !ok {
+			c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypeExtension, fullName, extension.File().Path(), "")
+			continue
+		}
+		c.addIfChanged(bufbreakingreport.ElementTypeExtension, extension, "extendee", previousExtension.Extendee(), extension.Extendee())
+		c.addIfChanged(bufbreakingreport.ElementTypeExtension, extension, "number", strconv.Itoa(previousExtension.Number()), strconv.Itoa(extension.Number()))
+		c.addIfChanged(bufbreakingreport.ElementTypeExtension, extension, "label", previousExtension.Label().String(), extension.Label().String())
+		c.addIfChanged(bufbreakingreport.ElementTypeExtension, extension, "type", fieldTypeString(previousExtension), fieldTypeString(extension))
+	}
+	return nil
+}
+
+func (c *changes) addEnumChanges(previousFiles []protosource.File, files []protosource.File) error {
+	previousFullNameToEnum, err := protosource.FullNameToEnum(previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToEnum, err := protosource.FullNameToEnum(files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousEnum := range previousFullNameToEnum {
+		if _, ok := fullNameToEnum[previousFullName]; !ok {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypeEnum, previousFullName, previousEnum.File().Path(), "")
+		}
+	}
+	for fullName, enum := range fullNameToEnum {
+		previousEnum, ok := previousFullNameToEnum[fullName]
+		if !ok {
+			c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypeEnum, fullName, enum.File().Path(), "")
+			continue
+		}
+		if previousEnum.File().Path() != enum.File().Path() {
+			c.addIfChanged(bufbreakingreport.ElementTypeEnum, enum, "file", previousEnum.File().Path(), enum.File().Path())
+		}
+		c.addIfChanged(bufbreakingreport.ElementTypeEnum, enum, `option "allow_alias"`, strconv.FormatBool(previousEnum.AllowAlias()), strconv.FormatBool(enum.AllowAlias()))
+		c.addIfChanged(bufbreakingreport.ElementTypeEnum, enum, `option "deprecated"`, strconv.FormatBool(previousEnum.Deprecated()), strconv.FormatBool(enum.Deprecated()))
+		c.addCustomOptionChanges("google.protobuf.EnumOptions", bufbreakingreport.ElementTypeEnum, fullName, enum.File().Path(), previousEnum, enum)
+		if err := c.addEnumValueChanges(previousEnum, enum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *changes) addEnumValueChanges(previousEnum protosource.Enum, enum protosource.Enum) error {
+	previousNameToEnumValue, err := protosource.NameToEnumValue(previousEnum)
+	if err != nil {
+		return err
+	}
+	nameToEnumValue, err := protosource.NameToEnumValue(enum)
+	if err != nil {
+		return err
+	}
+	for previousName, previousEnumValue := range previousNameToEnumValue {
+		if _, ok := nameToEnumValue[previousName]; !ok {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypeEnumValue, previousEnumValue.FullName(), previousEnumValue.File().Path(), "")
+		}
+	}
+	for name, enumValue := range nameToEnumValue {
+		previousEnumValue, ok := previousNameToEnumValue[name]
+		if !ok {
+			c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypeEnumValue, enumValue.FullName(), enumValue.File().Path(), "")
+			continue
+		}
+		c.addIfChanged(bufbreakingreport.ElementTypeEnumValue, enumValue, "number", strconv.Itoa(previousEnumValue.Number()), strconv.Itoa(enumValue.Number()))
+		c.addIfChanged(bufbreakingreport.ElementTypeEnumValue, enumValue, `option "deprecated"`, strconv.FormatBool(previousEnumValue.Deprecated()), strconv.FormatBool(enumValue.Deprecated()))
+		c.addCustomOptionChanges("google.protobuf.EnumValueOptions", bufbreakingreport.ElementTypeEnumValue, enumValue.FullName(), enumValue.File().Path(), previousEnumValue, enumValue)
+	}
+	return nil
+}
+
+func (c *changes) addServiceChanges(previousFiles []protosource.File, files []protosource.File) error {
+	previousFullNameToService, err := protosource.FullNameToService(previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToService, err := protosource.FullNameToService(files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousService := range previousFullNameToService {
+		if _, ok := fullNameToService[previousFullName]; !ok {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypeService, previousFullName, previousService.File().Path(), "")
+		}
+	}
+	for fullName, service := range fullNameToService {
+		previousService, ok := previousFullNameToService[fullName]
+		if !ok {
+			c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypeService, fullName, service.File().Path(), "")
+			continue
+		}
+		if previousService.File().Path() != service.File().Path() {
+			c.addIfChanged(bufbreakingreport.ElementTypeService, service, "file", previousService.File().Path(), service.File().Path())
+		}
+		c.addIfChanged(bufbreakingreport.ElementTypeService, service, `option "deprecated"`, strconv.FormatBool(previousService.Deprecated()), strconv.FormatBool(service.Deprecated()))
+		c.addCustomOptionChanges("google.protobuf.ServiceOptions", bufbreakingreport.ElementTypeService, fullName, service.File().Path(), previousService, service)
+		if err := c.addRPCChanges(previousService, service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *changes) addRPCChanges(previousService protosource.Service, service protosource.Service) error {
+	previousNameToMethod, err := protosource.NameToMethod(previousService)
+	if err != nil {
+		return err
+	}
+	nameToMethod, err := protosource.NameToMethod(service)
+	if err != nil {
+		return err
+	}
+	for previousName, previousMethod := range previousNameToMethod {
+		if _, ok := nameToMethod[previousName]; !ok {
+			c.add(bufbreakingreport.ChangeTypeRemoved, bufbreakingreport.ElementTypeRPC, previousMethod.FullName(), previousMethod.File().Path(), "")
+		}
+	}
+	for name, method := range nameToMethod {
+		previousMethod, ok := previousNameToMethod[name]
+		if !ok {
+			c.add(bufbreakingreport.ChangeTypeAdded, bufbreakingreport.ElementTypeRPC, method.FullName(), method.File().Path(), "")
+			continue
+		}
+		c.addIfChanged(bufbreakingreport.ElementTypeRPC, method, "request type", previousMethod.InputTypeName(), method.InputTypeName())
+		c.addIfChanged(bufbreakingreport.ElementTypeRPC, method, "response type", previousMethod.OutputTypeName(), method.OutputTypeName())
+		c.addIfChanged(bufbreakingreport.ElementTypeRPC, method, "client streaming", strconv.FormatBool(previousMethod.ClientStreaming()), strconv.FormatBool(method.ClientStreaming()))
+		c.addIfChanged(bufbreakingreport.ElementTypeRPC, method, "server streaming", strconv.FormatBool(previousMethod.ServerStreaming()), strconv.FormatBool(method.ServerStreaming()))
+		c.addIfChanged(bufbreakingreport.ElementTypeRPC, method, `option "idempotency_level"`, previousMethod.IdempotencyLevel().String(), method.IdempotencyLevel().String())
+		c.addIfChanged(bufbreakingreport.ElementTypeRPC, method, `option "deprecated"`, strconv.FormatBool(previousMethod.Deprecated()), strconv.FormatBool(method.Deprecated()))
+		c.addCustomOptionChanges("google.protobuf.MethodOptions", bufbreakingreport.ElementTypeRPC, method.FullName(), method.File().Path(), previousMethod, method)
+	}
+	return nil
+}
+
+// fieldTypeString returns the type of the field as it would be written in a .proto file.
+func fieldTypeString(field protosource.Field) string {
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeMessage,
+		protosource.FieldDescriptorProtoTypeEnum,
+		protosource.FieldDescriptorProtoTypeGroup:
+		return field.TypeName()
+	default:
+		return field.Type().String()
+	}
+}
+
+// fieldOneofName returns the name of the oneof of the field, or the empty string if the
+// field is not part of a oneof.
+//
+// Synthetic oneofs of proto3 optional fields are not considered.
+func fieldOneofName(field protosource.Field) string {
+	if oneof := field.Oneof(); oneof != nil && !field.Proto3Optional() {
+		return oneof.Name()
+	}
+	return ""
+}
+
+// oneofIsSynthetic returns true if the oneof was created by protoc for a proto3 optional field.
+func oneofIsSynthetic(oneof protosource.Oneof) bool {
+	fields := oneof.Fields()
+	return len(fields) == 1 && fields[0].Proto3Optional()
+}
+
+func fullNameToExtension(files []protosource.File) (map[string]protosource.Field, error) {
+	fullNameToExtension := make(map[string]protosource.Field)
+	add := func(extension protosource.Field) error {
+		fullName := extension.FullName()
+		if _, ok := fullNameToExtension[fullName]; ok {
+			return fmt.Errorf("duplicate extension: %q", fullName)
+		}
+		fullNameToExtension[fullName] = extension
+		return nil
+	}
+	for _, file := range files {
+		for _, extension := range file.Extensions() {
+			if err := add(extension); err != nil {
+				return nil, err
+			}
+		}
+		if err := protosource.ForEachMessage(
+			func(message protosource.Message) error {
+				for _, extension := range message.Extensions() {
+					if err := add(extension); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			file,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return fullNameToExtension, nil
+}
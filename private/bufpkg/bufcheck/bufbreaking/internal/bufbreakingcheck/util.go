@@ -15,8 +15,10 @@
 package bufbreakingcheck
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
@@ -350,3 +352,145 @@ func withBackupLocation(primary protosource.Location, secondary protosource.Loca
 	}
 	return secondary
 }
+
+// fieldIsPacked returns true if the field uses the packed encoding.
+//
+// Only repeated fields of scalar numeric types can be packed. If the packed
+// option is not set, these fields are packed in proto3 but not in proto2.
+func fieldIsPacked(field protosource.Field) bool {
+	if field.Label() != protosource.FieldDescriptorProtoLabelRepeated {
+		return false
+	}
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeString,
+		protosource.FieldDescriptorProtoTypeBytes,
+		protosource.FieldDescriptorProtoTypeMessage,
+		protosource.FieldDescriptorProtoTypeGroup:
+		return false
+	}
+	if packed := field.Packed(); packed != nil {
+		return *packed
+	}
+	return field.File().Syntax() == protosource.SyntaxProto3
+}
+
+// customOptionNamer resolves the names of custom options and determines
+// which custom options are checked.
+type customOptionNamer struct {
+	// customOptions are the configured names of the custom options to check.
+	//
+	// If empty, all custom options are checked.
+	customOptions []string
+	// extendeeToNumberToFullName contains the extensions of the previous files and files.
+	extendeeToNumberToFullName map[string]map[int32]string
+}
+
+func newCustomOptionNamer(customOptions []string, previousFiles []protosource.File, files []protosource.File) (*customOptionNamer, error) {
+	customOptionNamer := &customOptionNamer{
+		customOptions:              customOptions,
+		extendeeToNumberToFullName: make(map[string]map[int32]string),
+	}
+	for _, file := range append(append([]protosource.File{}, previousFiles...), files...) {
+		for _, extension := range file.Extensions() {
+			customOptionNamer.addExtension(extension)
+		}
+		if err := protosource.ForEachMessage(
+			func(message protosource.Message) error {
+				for _, extension := range message.Extensions() {
+					customOptionNamer.addExtension(extension)
+				}
+				return nil
+			},
+			file,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return customOptionNamer, nil
+}
+
+func (c *customOptionNamer) addExtension(extension protosource.Field) {
+	numberToFullName, ok := c.extendeeToNumberToFullName[extension.Extendee()]
+	if !ok {
+		numberToFullName = make(map[int32]string)
+		c.extendeeToNumberToFullName[extension.Extendee()] = numberToFullName
+	}
+	// current files are added last, so we prefer the current name if the option was renamed
+	numberToFullName[int32(extension.Number())] = extension.FullName()
+}
+
+// name returns the display name of the custom option with the given number on the
+// given options message, and whether or not the custom option should be checked.
+//
+// Custom options that cannot be resolved to an extension in the previous files or
+// files are displayed by number, and are only checked if no custom options are configured.
+func (c *customOptionNamer) name(extendee string, number int32) (string, bool) {
+	fullName, ok := c.extendeeToNumberToFullName[extendee][number]
+	if !ok {
+		return strconv.FormatInt(int64(number), 10), len(c.customOptions) == 0
+	}
+	if len(c.customOptions) == 0 {
+		return "(" + fullName + ")", true
+	}
+	for _, customOption := range c.customOptions {
+		if fullName == customOption || strings.HasPrefix(fullName, customOption+".") {
+			return "(" + fullName + ")", true
+		}
+	}
+	return "", false
+}
+
+// checkSameCustomOptions checks that the custom options set on previousDescriptor and
+// descriptor have the same values.
+//
+// extendee is the full name of the options message, such as google.protobuf.MessageOptions.
+// elementDescription starts the message of the FileAnnotation, such as `Message "Foo"`.
+func checkSameCustomOptions(
+	add addFunc,
+	customOptionNamer *customOptionNamer,
+	extendee string,
+	previousOptionExtensionDescriptor protosource.OptionExtensionDescriptor,
+	optionExtensionDescriptor protosource.OptionExtensionDescriptor,
+	descriptor protosource.Descriptor,
+	location protosource.Location,
+	elementDescription string,
+) error {
+	numbers := make(map[int32]struct{})
+	for _, number := range previousOptionExtensionDescriptor.PresentExtensionNumbers() {
+		numbers[number] = struct{}{}
+	}
+	for _, number := range optionExtensionDescriptor.PresentExtensionNumbers() {
+		numbers[number] = struct{}{}
+	}
+	for _, number := range sortedNumbers(numbers) {
+		name, ok := customOptionNamer.name(extendee, number)
+		if !ok {
+			continue
+		}
+		previousValue := previousOptionExtensionDescriptor.PresentExtensionBytes(number)
+		value := optionExtensionDescriptor.PresentExtensionBytes(number)
+		if bytes.Equal(previousValue, value) {
+			continue
+		}
+		optionLocation := withBackupLocation(optionExtensionDescriptor.PresentExtensionLocation(number), location)
+		switch {
+		case previousValue == nil:
+			add(descriptor, nil, optionLocation, `%s added option %q.`, elementDescription, name)
+		case value == nil:
+			add(descriptor, nil, location, `%s removed option %q.`, elementDescription, name)
+		default:
+			add(descriptor, nil, optionLocation, `%s changed the value of option %q.`, elementDescription, name)
+		}
+	}
+	return nil
+}
+
+// sortedNumbers returns the numbers sorted.
+func sortedNumbers(numbers map[int32]struct{}) []int32 {
+	sorted := make([]int32, 0, len(numbers))
+	for number := range numbers {
+		sorted = append(sorted, number)
+	}
+	sort.Slice(sorted, func(i int, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
@@ -23,25 +23,32 @@ var (
 	// v1RuleBuilders are the rule builders.
 	v1RuleBuilders = []*internal.RuleBuilder{
 		bufbreakingbuild.EnumNoDeleteRuleBuilder,
+		bufbreakingbuild.EnumSameCustomOptionRuleBuilder,
 		bufbreakingbuild.EnumValueNoDeleteRuleBuilder,
 		bufbreakingbuild.EnumValueNoDeleteUnlessNameReservedRuleBuilder,
 		bufbreakingbuild.EnumValueNoDeleteUnlessNumberReservedRuleBuilder,
+		bufbreakingbuild.EnumValueSameCustomOptionRuleBuilder,
 		bufbreakingbuild.EnumValueSameNameRuleBuilder,
 		bufbreakingbuild.ExtensionMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNameReservedRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNumberReservedRuleBuilder,
 		bufbreakingbuild.FieldSameCTypeRuleBuilder,
+		bufbreakingbuild.FieldSameCustomOptionRuleBuilder,
+		bufbreakingbuild.FieldSameDefaultRuleBuilder,
+		bufbreakingbuild.FieldSameDeprecatedRuleBuilder,
 		bufbreakingbuild.FieldSameJSONNameRuleBuilder,
 		bufbreakingbuild.FieldSameJSTypeRuleBuilder,
 		bufbreakingbuild.FieldSameLabelRuleBuilder,
 		bufbreakingbuild.FieldSameNameRuleBuilder,
 		bufbreakingbuild.FieldSameOneofRuleBuilder,
+		bufbreakingbuild.FieldSamePackedRuleBuilder,
 		bufbreakingbuild.FieldSameTypeRuleBuilder,
 		bufbreakingbuild.FieldWireCompatibleTypeRuleBuilder,
 		bufbreakingbuild.FieldWireJSONCompatibleTypeRuleBuilder,
 		bufbreakingbuild.FileNoDeleteRuleBuilder,
 		bufbreakingbuild.FileSameCsharpNamespaceRuleBuilder,
+		bufbreakingbuild.FileSameCustomOptionRuleBuilder,
 		bufbreakingbuild.FileSameGoPackageRuleBuilder,
 		bufbreakingbuild.FileSameJavaMultipleFilesRuleBuilder,
 		bufbreakingbuild.FileSameJavaOuterClassnameRuleBuilder,
@@ -63,6 +70,7 @@ var (
 		bufbreakingbuild.FileSameSyntaxRuleBuilder,
 		bufbreakingbuild.MessageNoDeleteRuleBuilder,
 		bufbreakingbuild.MessageNoRemoveStandardDescriptorAccessorRuleBuilder,
+		bufbreakingbuild.MessageSameCustomOptionRuleBuilder,
 		bufbreakingbuild.MessageSameMessageSetWireFormatRuleBuilder,
 		bufbreakingbuild.MessageSameRequiredFieldsRuleBuilder,
 		bufbreakingbuild.OneofNoDeleteRuleBuilder,
@@ -74,11 +82,13 @@ var (
 		bufbreakingbuild.ReservedMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.RPCNoDeleteRuleBuilder,
 		bufbreakingbuild.RPCSameClientStreamingRuleBuilder,
+		bufbreakingbuild.RPCSameCustomOptionRuleBuilder,
 		bufbreakingbuild.RPCSameIdempotencyLevelRuleBuilder,
 		bufbreakingbuild.RPCSameRequestTypeRuleBuilder,
 		bufbreakingbuild.RPCSameResponseTypeRuleBuilder,
 		bufbreakingbuild.RPCSameServerStreamingRuleBuilder,
 		bufbreakingbuild.ServiceNoDeleteRuleBuilder,
+		bufbreakingbuild.ServiceSameCustomOptionRuleBuilder,
 	}
 
 	// v1DefaultCategories are the default categories.
@@ -90,6 +100,7 @@ var (
 		"ENUM_NO_DELETE": {
 			"FILE",
 		},
+		"ENUM_SAME_CUSTOM_OPTION": {},
 		"ENUM_VALUE_NO_DELETE": {
 			"FILE",
 			"PACKAGE",
@@ -101,6 +112,7 @@ var (
 			"WIRE_JSON",
 			"WIRE",
 		},
+		"ENUM_VALUE_SAME_CUSTOM_OPTION": {},
 		"ENUM_VALUE_SAME_NAME": {
 			"FILE",
 			"PACKAGE",
@@ -125,6 +137,12 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"FIELD_SAME_CUSTOM_OPTION": {},
+		"FIELD_SAME_DEFAULT": {
+			"FILE",
+			"PACKAGE",
+		},
+		"FIELD_SAME_DEPRECATED": {},
 		"FIELD_SAME_JSON_NAME": {
 			"FILE",
 			"PACKAGE",
@@ -151,6 +169,10 @@ var (
 			"WIRE_JSON",
 			"WIRE",
 		},
+		"FIELD_SAME_PACKED": {
+			"FILE",
+			"PACKAGE",
+		},
 		"FIELD_SAME_TYPE": {
 			"FILE",
 			"PACKAGE",
@@ -168,6 +190,7 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"FILE_SAME_CUSTOM_OPTION": {},
 		"FILE_SAME_GO_PACKAGE": {
 			"FILE",
 			"PACKAGE",
@@ -253,6 +276,7 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"MESSAGE_SAME_CUSTOM_OPTION": {},
 		"MESSAGE_SAME_MESSAGE_SET_WIRE_FORMAT": {
 			"FILE",
 			"PACKAGE",
@@ -303,6 +327,7 @@ var (
 			"WIRE_JSON",
 			"WIRE",
 		},
+		"RPC_SAME_CUSTOM_OPTION": {},
 		"RPC_SAME_IDEMPOTENCY_LEVEL": {
 			"FILE",
 			"PACKAGE",
@@ -330,5 +355,6 @@ var (
 		"SERVICE_NO_DELETE": {
 			"FILE",
 		},
+		"SERVICE_SAME_CUSTOM_OPTION": {},
 	}
 )
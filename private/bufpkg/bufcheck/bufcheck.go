@@ -26,6 +26,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"go.uber.org/multierr"
 )
 
@@ -55,6 +56,16 @@ type Rule interface {
 	Purpose() string
 }
 
+// PrintFileAnnotationsWithRules returns a new bufanalysis.PrintFileAnnotationsOption
+// that attaches the metadata of the given Rules to the printed FileAnnotations.
+func PrintFileAnnotationsWithRules(rules []Rule) bufanalysis.PrintFileAnnotationsOption {
+	analysisRules := make([]bufanalysis.Rule, len(rules))
+	for i, rule := range rules {
+		analysisRules[i] = rule
+	}
+	return bufanalysis.PrintFileAnnotationsWithRules(analysisRules...)
+}
+
 // PrintRules prints the rules to the writer.
 //
 // The empty string defaults to text.
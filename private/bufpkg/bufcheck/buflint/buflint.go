@@ -28,6 +28,9 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"go.uber.org/zap"
 )
 
@@ -43,7 +46,9 @@ type Handler interface {
 	//
 	// The image should have source code info for this to work properly.
 	//
-	// Images should be filtered with regards to imports before passing to this function.
+	// The image may contain imports. Lint plugins get the full image, like protoc
+	// plugins get all the files in a CodeGeneratorRequest, while all other rules
+	// only check the files that are not imports.
 	Check(
 		ctx context.Context,
 		config *buflintconfig.Config,
@@ -52,14 +57,54 @@ type Handler interface {
 }
 
 // NewHandler returns a new Handler.
-func NewHandler(logger *zap.Logger) Handler {
-	return newHandler(logger)
+func NewHandler(logger *zap.Logger, options ...HandlerOption) Handler {
+	return newHandler(logger, options...)
+}
+
+// HandlerOption is an option for a new Handler.
+type HandlerOption func(*handler)
+
+// HandlerWithPlugins returns a new HandlerOption that enables lint plugins.
+//
+// Lint plugins are run with the runner, and get the environment and stderr of
+// the container. The rules of the plugins are added to the rules of the config
+// version, and can be used in use, except, ignore_only and comment ignores.
+//
+// The default is to return an error if the config has plugins.
+func HandlerWithPlugins(runner command.Runner, container app.EnvStderrContainer) HandlerOption {
+	return func(handler *handler) {
+		handler.pluginRunner = runner
+		handler.pluginContainer = container
+	}
+}
+
+// NewBaseline returns a new Baseline that records the given FileAnnotations.
+//
+// The FileAnnotations should be the result of Check with a config that has no Baseline.
+func NewBaseline(fileAnnotations []bufanalysis.FileAnnotation) *buflintconfig.Baseline {
+	entries := make([]*buflintconfig.BaselineEntry, 0, len(fileAnnotations))
+	for _, fileAnnotation := range fileAnnotations {
+		entries = append(entries, baselineEntryForFileAnnotation(fileAnnotation))
+	}
+	return buflintconfig.NewBaseline(entries)
 }
 
 // RulesForConfig returns the rules for a given config.
 //
+// The rules of lint plugins are not included, as they are only known once the
+// plugins are run.
+//
 // Should only be used for printing.
 func RulesForConfig(config *buflintconfig.Config) ([]bufcheck.Rule, error) {
+	if len(config.Plugins) > 0 {
+		configWithoutPluginIDs := configWithoutUnknownIDs(config, versionSpecForConfig(config))
+		if len(config.Use) > 0 && len(configWithoutPluginIDs.Use) == 0 {
+			// Only plugin rules are used. An empty Use would select the default
+			// categories instead.
+			return nil, nil
+		}
+		config = configWithoutPluginIDs
+	}
 	internalConfig, err := internalConfigForConfig(config)
 	if err != nil {
 		return nil, err
@@ -114,13 +159,13 @@ func GetAllRulesAndCategoriesV1() []string {
 }
 
 func internalConfigForConfig(config *buflintconfig.Config) (*internal.Config, error) {
-	var versionSpec *internal.VersionSpec
-	switch config.Version {
-	case bufconfig.V1Beta1Version:
-		versionSpec = buflintv1beta1.VersionSpec
-	case bufconfig.V1Version:
-		versionSpec = buflintv1.VersionSpec
-	}
+	return internalConfigForConfigAndVersionSpec(config, versionSpecForConfig(config))
+}
+
+func internalConfigForConfigAndVersionSpec(
+	config *buflintconfig.Config,
+	versionSpec *internal.VersionSpec,
+) (*internal.Config, error) {
 	return internal.ConfigBuilder{
 		Use:                                  config.Use,
 		Except:                               config.Except,
@@ -137,6 +182,47 @@ func internalConfigForConfig(config *buflintconfig.Config) (*internal.Config, er
 	)
 }
 
+func versionSpecForConfig(config *buflintconfig.Config) *internal.VersionSpec {
+	switch config.Version {
+	case bufconfig.V1Beta1Version:
+		return buflintv1beta1.VersionSpec
+	case bufconfig.V1Version:
+		return buflintv1.VersionSpec
+	default:
+		return nil
+	}
+}
+
+// configWithoutUnknownIDs returns a copy of the config without the rule and category
+// IDs that are not known to the VersionSpec, such as the IDs of plugin rules.
+func configWithoutUnknownIDs(config *buflintconfig.Config, versionSpec *internal.VersionSpec) *buflintconfig.Config {
+	if versionSpec == nil {
+		return config
+	}
+	known := stringutil.SliceToMap(internal.AllCategoriesAndIDsForVersionSpec(versionSpec))
+	filter := func(ids []string) []string {
+		var filtered []string
+		for _, id := range ids {
+			if _, ok := known[id]; ok {
+				filtered = append(filtered, id)
+			}
+		}
+		return filtered
+	}
+	configCopy := *config
+	configCopy.Use = filter(config.Use)
+	configCopy.Except = filter(config.Except)
+	if config.IgnoreIDOrCategoryToRootPaths != nil {
+		configCopy.IgnoreIDOrCategoryToRootPaths = make(map[string][]string)
+		for id, rootPaths := range config.IgnoreIDOrCategoryToRootPaths {
+			if _, ok := known[id]; ok {
+				configCopy.IgnoreIDOrCategoryToRootPaths[id] = rootPaths
+			}
+		}
+	}
+	return &configCopy
+}
+
 func rulesForInternalRules(rules []*internal.Rule) []bufcheck.Rule {
 	if rules == nil {
 		return nil
@@ -16,6 +16,9 @@ package buflint_test
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -23,18 +26,38 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	lintv1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/lint/v1"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/reflect/protodesc"
 )
 
+// testPluginEnvKey is set in the environment of the test binary when it is run as a lint plugin.
+const testPluginEnvKey = "BUF_LINT_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginEnvKey) != "" {
+		if err := testPluginMain(); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
 // Hint on how to get these:
 // 1. cd into the specific directory
 // 2. buf lint --error-format=json | jq '[.path, .start_line, .start_column, .end_line, .end_column, .type] | @csv' --raw-output
@@ -887,6 +910,255 @@ func TestCommentIgnoresCascadeOn(t *testing.T) {
 	)
 }
 
+func TestBaseline(t *testing.T) {
+	testLintConfigModifier(
+		t,
+		"field_lower_snake_case",
+		func(config *bufconfig.Config) {
+			config.Lint.Baseline = buflintconfig.NewBaseline(
+				[]*buflintconfig.BaselineEntry{
+					{
+						ID:      "FIELD_LOWER_SNAKE_CASE",
+						Path:    "a.proto",
+						Element: "a.One.Fail",
+					},
+					{
+						ID:      "FIELD_LOWER_SNAKE_CASE",
+						Path:    "a.proto",
+						Element: "a.One.FailTwo",
+					},
+					{
+						ID:      "FIELD_LOWER_SNAKE_CASE",
+						Path:    "a.proto",
+						Element: "a.Two.Three.Four.failThree",
+					},
+					// different rule, does not apply
+					{
+						ID:      "FIELD_NO_DESCRIPTOR",
+						Path:    "a.proto",
+						Element: "a.One.failThree",
+					},
+					// stale
+					{
+						ID:      "FIELD_LOWER_SNAKE_CASE",
+						Path:    "a.proto",
+						Element: "a.One.success",
+					},
+				},
+			)
+		},
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 10, 9, 10, 18, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 11, 9, 11, 19, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 12, 9, 12, 19, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 20, 13, 20, 17, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 21, 13, 21, 20, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 23, 13, 23, 23, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 24, 13, 24, 23, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 28, 11, 28, 15, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 29, 11, 29, 18, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 30, 11, 30, 20, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 31, 11, 31, 21, "FIELD_LOWER_SNAKE_CASE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 32, 11, 32, 21, "FIELD_LOWER_SNAKE_CASE"),
+	)
+}
+
+func TestBaselineRoundTrip(t *testing.T) {
+	t.Parallel()
+	baseline := buflintconfig.NewBaseline(
+		[]*buflintconfig.BaselineEntry{
+			{
+				ID:      "FIELD_LOWER_SNAKE_CASE",
+				Path:    "b.proto",
+				Element: "b.One.Fail",
+			},
+			{
+				ID:   "PACKAGE_DIRECTORY_MATCH",
+				Path: "a.proto",
+			},
+			{
+				ID:      "FIELD_LOWER_SNAKE_CASE",
+				Path:    "b.proto",
+				Element: "b.One.Fail",
+			},
+		},
+	)
+	require.Len(t, baseline.Entries, 2)
+	assert.Equal(t, "a.proto", baseline.Entries[0].Path)
+	data, err := buflintconfig.DataForBaseline(baseline)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`version: v1
+entries:
+  - id: PACKAGE_DIRECTORY_MATCH
+    path: a.proto
+  - id: FIELD_LOWER_SNAKE_CASE
+    path: b.proto
+    element: b.One.Fail
+`,
+		string(data),
+	)
+	roundTripBaseline, err := buflintconfig.GetBaselineForData(data)
+	require.NoError(t, err)
+	assert.Equal(t, baseline, roundTripBaseline)
+	_, err = buflintconfig.GetBaselineForData([]byte("version: v2\n"))
+	assert.Error(t, err)
+}
+
+func TestFixes(t *testing.T) {
+	t.Parallel()
+	config, fileAnnotations := testCheck(t, "fix", nil)
+	// MESSAGE_PASCAL_CASE has no fix.
+	require.Len(t, fileAnnotations, 11)
+	assert.Equal(
+		t,
+		[]*buflint.Fix{
+			{
+				Type:    buflint.FixTypeRenameField,
+				Path:    "sub/FooBar.proto",
+				Element: "a.Msg.Nested.someValues",
+				NewName: "some_values",
+				IDs:     []string{"FIELD_LOWER_SNAKE_CASE"},
+			},
+			{
+				Type:    buflint.FixTypeRenameField,
+				Path:    "sub/FooBar.proto",
+				Element: "a.Msg.fooBar",
+				NewName: "foo_bar",
+				IDs:     []string{"FIELD_LOWER_SNAKE_CASE"},
+			},
+			{
+				Type:    buflint.FixTypeRenameEnumValue,
+				Path:    "sub/FooBar.proto",
+				Element: "a.Color.Blue",
+				NewName: "COLOR_BLUE",
+				IDs:     []string{"ENUM_VALUE_PREFIX", "ENUM_VALUE_UPPER_SNAKE_CASE"},
+			},
+			{
+				Type:    buflint.FixTypeRenameEnumValue,
+				Path:    "sub/FooBar.proto",
+				Element: "a.Color.red",
+				NewName: "COLOR_RED_UNSPECIFIED",
+				IDs:     []string{"ENUM_VALUE_PREFIX", "ENUM_VALUE_UPPER_SNAKE_CASE", "ENUM_ZERO_VALUE_SUFFIX"},
+			},
+			{
+				Type:    buflint.FixTypeRenameService,
+				Path:    "sub/FooBar.proto",
+				Element: "a.Greeter",
+				NewName: "GreeterService",
+				IDs:     []string{"SERVICE_SUFFIX"},
+			},
+			{
+				Type:    buflint.FixTypeRemoveImport,
+				Path:    "sub/FooBar.proto",
+				Element: "sub/b.proto",
+				IDs:     []string{"IMPORT_USED"},
+			},
+			{
+				Type:    buflint.FixTypeRenameFile,
+				Path:    "sub/FooBar.proto",
+				NewName: "sub/foo_bar.proto",
+				IDs:     []string{"FILE_LOWER_SNAKE_CASE"},
+			},
+		},
+		buflint.NewFixes(config.Lint, fileAnnotations),
+	)
+}
+
+func TestRunPlugin(t *testing.T) {
+	t.Parallel()
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	_, fileAnnotations := testCheck(
+		t,
+		"plugin",
+		func(config *bufconfig.Config) {
+			// The test binary acts as the plugin, see TestMain. The path is
+			// relative to the directory of the configuration file.
+			config.Lint.Plugins[0].Path = filepath.Base(executable)
+			config.Lint.Plugins[0].DirPath = filepath.Dir(executable)
+		},
+		buflint.HandlerWithPlugins(
+			command.NewRunner(),
+			app.NewContainer(map[string]string{testPluginEnvKey: "1"}, nil, nil, os.Stderr),
+		),
+	)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 9, 8, 14, "ACME_MONEY"),
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 14, 1, 14, 19, "ACME_RPC_POLICY"),
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 15, 7, 15, 10, "ACME_RPC_POLICY"),
+			bufanalysistesting.NewFileAnnotationNoLocation(t, "b.proto", "PACKAGE_DEFINED"),
+		},
+		fileAnnotations,
+	)
+}
+
+// testPluginMain is the main function of the test binary when it is run as a lint plugin.
+//
+// It reports violations of the made-up ACME rules for the files in testdata/plugin.
+func testPluginMain() error {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	request := &lintv1.CheckRequest{}
+	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(data, request); err != nil {
+		return err
+	}
+	response := &lintv1.CheckResponse{
+		Rules: []*lintv1.Rule{
+			{Id: "ACME_FILE_OPTIONS", Categories: []string{"ACME"}, Purpose: "all files have the ACME file options"},
+			{Id: "ACME_MONEY", Categories: []string{"ACME"}, Purpose: "all price fields use acme.type.Money"},
+			{Id: "ACME_RPC_POLICY", Categories: []string{"ACME"}, Purpose: "all RPCs have the acme.auth.v1.policy option"},
+			{Id: "ACME_UNUSED", Purpose: "this rule is never used"},
+		},
+	}
+	if err := testPluginCheckRequest(request); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Annotations = []*lintv1.Annotation{
+			{RuleId: "ACME_FILE_OPTIONS", Path: "a.proto", StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 19},
+			{RuleId: "ACME_MONEY", Path: "a.proto", Element: "a.GetRequest.price", Message: "Use acme.type.Money."},
+			{RuleId: "ACME_MONEY", Path: "b.proto", Element: "B.price", Message: "Use acme.type.Money."},
+			{RuleId: "ACME_RPC_POLICY", Path: "a.proto", StartLine: 14, StartColumn: 1, EndLine: 14, EndColumn: 19, Message: "Service has no default policy."},
+			{RuleId: "ACME_RPC_POLICY", Path: "a.proto", Element: "a.GetService.Get", Message: "RPC has no policy."},
+			{RuleId: "ACME_RPC_POLICY", Path: "a.proto", Element: "a.GetService.GetIgnored", Message: "RPC has no policy."},
+			{RuleId: "ACME_UNUSED", Path: "a.proto", Message: "Never reported."},
+		}
+	}
+	data, err = protoencoding.NewWireMarshaler().Marshal(response)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// testPluginCheckRequest checks that the request has the files to check and their
+// imports, so that plugins can build a registry of the descriptors.
+func testPluginCheckRequest(request *lintv1.CheckRequest) error {
+	if request.GetParameter() != "strict" {
+		return fmt.Errorf("unexpected parameter %q", request.GetParameter())
+	}
+	image, err := bufimage.NewImageForProto(request.GetImage())
+	if err != nil {
+		return err
+	}
+	var targetPaths []string
+	for _, imageFile := range image.Files() {
+		if !imageFile.IsImport() {
+			targetPaths = append(targetPaths, imageFile.Path())
+		}
+	}
+	if len(targetPaths) != 2 || len(image.Files()) != 3 {
+		return fmt.Errorf("unexpected files %v in image with %d files", targetPaths, len(image.Files()))
+	}
+	_, err = protodesc.NewFiles(bufimage.ImageToFileDescriptorSet(image))
+	return err
+}
+
 func testLint(
 	t *testing.T,
 	relDirPath string,
@@ -907,6 +1179,20 @@ func testLintConfigModifier(
 	expectedFileAnnotations ...bufanalysis.FileAnnotation,
 ) {
 	t.Parallel()
+	_, fileAnnotations := testCheck(t, relDirPath, configModifier)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		fileAnnotations,
+	)
+}
+
+func testCheck(
+	t *testing.T,
+	relDirPath string,
+	configModifier func(*bufconfig.Config),
+	handlerOptions ...buflint.HandlerOption,
+) (*bufconfig.Config, []bufanalysis.FileAnnotation) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	logger := zap.NewNop()
@@ -945,20 +1231,15 @@ func testLintConfigModifier(
 	)
 	require.NoError(t, err)
 	require.Empty(t, fileAnnotations)
-	image = bufimage.ImageWithoutImports(image)
 
-	handler := buflint.NewHandler(logger)
+	handler := buflint.NewHandler(logger, handlerOptions...)
 	fileAnnotations, err = handler.Check(
 		ctx,
 		config.Lint,
 		image,
 	)
 	assert.NoError(t, err)
-	bufanalysistesting.AssertFileAnnotationsEqual(
-		t,
-		expectedFileAnnotations,
-		fileAnnotations,
-	)
+	return config, fileAnnotations
 }
 
 func testGetConfig(
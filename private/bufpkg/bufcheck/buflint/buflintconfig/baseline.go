@@ -0,0 +1,164 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintconfig
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bufbuild/buf/private/pkg/encoding"
+)
+
+const (
+	// DefaultBaselineFilePath is the default path of a lint baseline file.
+	DefaultBaselineFilePath = "buf.lint.baseline.yaml"
+
+	baselineV1Version = "v1"
+)
+
+// BaselineEntry is a single recorded violation within a Baseline.
+type BaselineEntry struct {
+	// ID is the rule ID of the violation.
+	ID string
+	// Path is the path of the file that contains the violation, relative to the root of the module.
+	Path string
+	// Element is the fully-qualified name of the element that contains the violation.
+	//
+	// This is empty for violations that are reported against the file as a whole,
+	// in which case the entry is matched by Path.
+	Element string
+}
+
+// Key returns the key used to match violations against this BaselineEntry.
+//
+// Line and column information is deliberately not part of the key so that entries
+// survive unrelated edits to the file.
+func (b *BaselineEntry) Key() string {
+	if b.Element != "" {
+		return b.ID + " " + b.Element
+	}
+	return b.ID + " " + b.Path
+}
+
+// String implements fmt.Stringer.
+func (b *BaselineEntry) String() string {
+	if b.Element != "" {
+		return fmt.Sprintf("%s:%s:%s", b.Path, b.Element, b.ID)
+	}
+	return fmt.Sprintf("%s:%s", b.Path, b.ID)
+}
+
+// Baseline is a set of existing violations that are not reported by lint.
+type Baseline struct {
+	// Entries are the entries of the Baseline.
+	//
+	// Sorted by Path, ID and Element.
+	Entries []*BaselineEntry
+}
+
+// NewBaseline returns a new Baseline for the given entries.
+//
+// Entries are deduplicated and sorted.
+func NewBaseline(entries []*BaselineEntry) *Baseline {
+	keyToEntry := make(map[string]*BaselineEntry, len(entries))
+	deduplicated := make([]*BaselineEntry, 0, len(entries))
+	for _, entry := range entries {
+		if _, ok := keyToEntry[entry.Key()]; ok {
+			continue
+		}
+		keyToEntry[entry.Key()] = entry
+		deduplicated = append(deduplicated, entry)
+	}
+	sort.Slice(
+		deduplicated,
+		func(i int, j int) bool {
+			one := deduplicated[i]
+			two := deduplicated[j]
+			if one.Path != two.Path {
+				return one.Path < two.Path
+			}
+			if one.ID != two.ID {
+				return one.ID < two.ID
+			}
+			return one.Element < two.Element
+		},
+	)
+	return &Baseline{
+		Entries: deduplicated,
+	}
+}
+
+// GetBaselineForData gets the Baseline for the given YAML data.
+//
+// If the data is of length 0, returns an empty Baseline.
+func GetBaselineForData(data []byte) (*Baseline, error) {
+	if len(data) == 0 {
+		return NewBaseline(nil), nil
+	}
+	var externalBaseline ExternalBaselineV1
+	if err := encoding.UnmarshalYAMLStrict(data, &externalBaseline); err != nil {
+		return nil, err
+	}
+	if externalBaseline.Version != baselineV1Version {
+		return nil, fmt.Errorf("lint baseline has an invalid version %q, expected %q", externalBaseline.Version, baselineV1Version)
+	}
+	entries := make([]*BaselineEntry, len(externalBaseline.Entries))
+	for i, externalEntry := range externalBaseline.Entries {
+		if externalEntry.ID == "" {
+			return nil, fmt.Errorf("lint baseline entry %d has no id", i)
+		}
+		if externalEntry.Path == "" && externalEntry.Element == "" {
+			return nil, fmt.Errorf("lint baseline entry %d has neither a path nor an element", i)
+		}
+		entries[i] = &BaselineEntry{
+			ID:      externalEntry.ID,
+			Path:    externalEntry.Path,
+			Element: externalEntry.Element,
+		}
+	}
+	return NewBaseline(entries), nil
+}
+
+// DataForBaseline returns the YAML data for the Baseline.
+func DataForBaseline(baseline *Baseline) ([]byte, error) {
+	externalBaseline := ExternalBaselineV1{
+		Version: baselineV1Version,
+		Entries: make([]ExternalBaselineEntryV1, 0, len(baseline.Entries)),
+	}
+	for _, entry := range baseline.Entries {
+		externalBaseline.Entries = append(
+			externalBaseline.Entries,
+			ExternalBaselineEntryV1{
+				ID:      entry.ID,
+				Path:    entry.Path,
+				Element: entry.Element,
+			},
+		)
+	}
+	return encoding.MarshalYAML(externalBaseline)
+}
+
+// ExternalBaselineV1 is an external baseline.
+type ExternalBaselineV1 struct {
+	Version string                    `json:"version,omitempty" yaml:"version,omitempty"`
+	Entries []ExternalBaselineEntryV1 `json:"entries,omitempty" yaml:"entries,omitempty"`
+}
+
+// ExternalBaselineEntryV1 is an external baseline entry.
+type ExternalBaselineEntryV1 struct {
+	ID      string `json:"id,omitempty" yaml:"id,omitempty"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+	Element string `json:"element,omitempty" yaml:"element,omitempty"`
+}
@@ -59,6 +59,15 @@ type Config struct {
 	ServiceSuffix string
 	// AllowCommentIgnores turns on comment-driven ignores.
 	AllowCommentIgnores bool
+	// BaselinePath is the path to the baseline file, relative to the directory that
+	// contains the configuration file.
+	BaselinePath string
+	// Baseline contains the existing violations that are not reported by the lint check.
+	//
+	// This is read from BaselinePath when the configuration is read from the OS, and may be nil.
+	Baseline *Baseline
+	// Plugins are the lint plugins that provide additional rules.
+	Plugins []*PluginConfig
 	// Version represents the version of the lint rule and category IDs that should be used with this config.
 	Version string
 }
@@ -76,6 +85,8 @@ func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) *Config {
 		RPCAllowGoogleProtobufEmptyResponses: externalConfig.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        externalConfig.ServiceSuffix,
 		AllowCommentIgnores:                  externalConfig.AllowCommentIgnores,
+		BaselinePath:                         externalConfig.Baseline,
+		Plugins:                              pluginConfigsForExternalPluginConfigs(externalConfig.Plugins),
 		Version:                              v1Beta1Version,
 	}
 }
@@ -93,6 +104,8 @@ func NewConfigV1(externalConfig ExternalConfigV1) *Config {
 		RPCAllowGoogleProtobufEmptyResponses: externalConfig.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        externalConfig.ServiceSuffix,
 		AllowCommentIgnores:                  externalConfig.AllowCommentIgnores,
+		BaselinePath:                         externalConfig.Baseline,
+		Plugins:                              pluginConfigsForExternalPluginConfigs(externalConfig.Plugins),
 		Version:                              v1Version,
 	}
 }
@@ -138,13 +151,15 @@ type ExternalConfigV1Beta1 struct {
 	// IgnoreRootPaths
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
-	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	IgnoreOnly                           map[string][]string    `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
+	EnumZeroValueSuffix                  string                 `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
+	RPCAllowSameRequestResponse          bool                   `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool                   `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool                   `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
+	ServiceSuffix                        string                 `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
+	AllowCommentIgnores                  bool                   `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	Baseline                             string                 `json:"baseline,omitempty" yaml:"baseline,omitempty"`
+	Plugins                              []ExternalPluginConfig `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
 // ExternalConfigV1 is an external config.
@@ -154,13 +169,44 @@ type ExternalConfigV1 struct {
 	// IgnoreRootPaths
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
-	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	IgnoreOnly                           map[string][]string    `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
+	EnumZeroValueSuffix                  string                 `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
+	RPCAllowSameRequestResponse          bool                   `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool                   `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool                   `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
+	ServiceSuffix                        string                 `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
+	AllowCommentIgnores                  bool                   `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	Baseline                             string                 `json:"baseline,omitempty" yaml:"baseline,omitempty"`
+	Plugins                              []ExternalPluginConfig `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+}
+
+// PluginConfig is the config for a lint plugin.
+type PluginConfig struct {
+	// Name is the name of the plugin.
+	//
+	// Unless Path is set, the plugin is run as the binary buf-lint-<Name> on the PATH.
+	Name string
+	// Path is the path to the plugin binary, if it is not buf-lint-<Name> on the PATH.
+	//
+	// A relative Path is relative to DirPath.
+	Path string
+	// DirPath is the directory on disk of the configuration file that declares the plugin.
+	//
+	// This is empty if the configuration was not read from disk, for example if it
+	// comes from a remote module or an archive. A plugin with a Path cannot be run
+	// in this case, as the configuration could run any binary.
+	DirPath string
+	// Opt is the parameter that is passed to the plugin.
+	Opt string
+}
+
+// ExternalPluginConfig is an external lint plugin config.
+//
+// This is shared between all versions of the external config.
+type ExternalPluginConfig struct {
+	Plugin string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+	Path   string `json:"path,omitempty" yaml:"path,omitempty"`
+	Opt    string `json:"opt,omitempty" yaml:"opt,omitempty"`
 }
 
 // ExternalConfigV1Beta1ForConfig takes a *Config and returns the v1beta1 externalconfig representation.
@@ -176,6 +222,8 @@ func ExternalConfigV1Beta1ForConfig(config *Config) ExternalConfigV1Beta1 {
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		Baseline:                             config.BaselinePath,
+		Plugins:                              externalPluginConfigsForPluginConfigs(config.Plugins),
 	}
 }
 
@@ -192,6 +240,8 @@ func ExternalConfigV1ForConfig(config *Config) ExternalConfigV1 {
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		Baseline:                             config.BaselinePath,
+		Plugins:                              externalPluginConfigsForPluginConfigs(config.Plugins),
 	}
 }
 
@@ -216,9 +266,17 @@ type configJSON struct {
 	RPCAllowGoogleProtobufEmptyResponses bool          `json:"rpc_allow_google_protobuf_empty_response,omitempty"`
 	ServiceSuffix                        string        `json:"service_suffix,omitempty"`
 	AllowCommentIgnores                  bool          `json:"allow_comment_ignores,omitempty"`
+	BaselinePath                         string        `json:"baseline_path,omitempty"`
+	Plugins                              []pluginJSON  `json:"plugins,omitempty"`
 	Version                              string        `json:"version,omitempty"`
 }
 
+type pluginJSON struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+	Opt  string `json:"opt,omitempty"`
+}
+
 type idPathsJSON struct {
 	ID    string   `json:"id,omitempty"`
 	Paths []string `json:"paths,omitempty"`
@@ -247,6 +305,15 @@ func configToJSON(config *Config) *configJSON {
 	sort.Strings(use)
 	sort.Strings(except)
 	sort.Strings(ignoreRootPaths)
+	// The order of the plugins is preserved, as plugins are run in order.
+	var pluginsJSON []pluginJSON
+	for _, plugin := range config.Plugins {
+		pluginsJSON = append(pluginsJSON, pluginJSON{
+			Name: plugin.Name,
+			Path: plugin.Path,
+			Opt:  plugin.Opt,
+		})
+	}
 	return &configJSON{
 		Use:                                  use,
 		Except:                               except,
@@ -258,6 +325,8 @@ func configToJSON(config *Config) *configJSON {
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		BaselinePath:                         config.BaselinePath,
+		Plugins:                              pluginsJSON,
 		Version:                              config.Version,
 	}
 }
@@ -269,12 +338,13 @@ func PrintFileAnnotations(
 	writer io.Writer,
 	fileAnnotations []bufanalysis.FileAnnotation,
 	formatString string,
+	options ...bufanalysis.PrintFileAnnotationsOption,
 ) error {
 	switch s := strings.ToLower(strings.TrimSpace(formatString)); s {
 	case "config-ignore-yaml":
 		return printFileAnnotationsConfigIgnoreYAML(writer, fileAnnotations)
 	default:
-		return bufanalysis.PrintFileAnnotations(writer, fileAnnotations, s)
+		return bufanalysis.PrintFileAnnotations(writer, fileAnnotations, s, options...)
 	}
 }
 
@@ -334,6 +404,36 @@ lint:
 	return err
 }
 
+func pluginConfigsForExternalPluginConfigs(externalPluginConfigs []ExternalPluginConfig) []*PluginConfig {
+	if externalPluginConfigs == nil {
+		return nil
+	}
+	pluginConfigs := make([]*PluginConfig, len(externalPluginConfigs))
+	for i, externalPluginConfig := range externalPluginConfigs {
+		pluginConfigs[i] = &PluginConfig{
+			Name: externalPluginConfig.Plugin,
+			Path: externalPluginConfig.Path,
+			Opt:  externalPluginConfig.Opt,
+		}
+	}
+	return pluginConfigs
+}
+
+func externalPluginConfigsForPluginConfigs(pluginConfigs []*PluginConfig) []ExternalPluginConfig {
+	if pluginConfigs == nil {
+		return nil
+	}
+	externalPluginConfigs := make([]ExternalPluginConfig, len(pluginConfigs))
+	for i, pluginConfig := range pluginConfigs {
+		externalPluginConfigs[i] = ExternalPluginConfig{
+			Plugin: pluginConfig.Name,
+			Path:   pluginConfig.Path,
+			Opt:    pluginConfig.Opt,
+		}
+	}
+	return externalPluginConfigs
+}
+
 func ignoreIDOrCategoryToRootPathsForProto(protoIgnoreIDPaths []*lintv1.IDPaths) map[string][]string {
 	if protoIgnoreIDPaths == nil {
 		return nil
@@ -0,0 +1,276 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+)
+
+const (
+	// FixTypeRenameField renames a field.
+	//
+	// The field keeps its JSON name, so a json_name option is added to the field if
+	// the JSON name derived from the new name differs.
+	FixTypeRenameField FixType = iota + 1
+	// FixTypeRenameEnumValue renames an enum value.
+	FixTypeRenameEnumValue
+	// FixTypeRenameService renames a service.
+	FixTypeRenameService
+	// FixTypeRemoveImport removes an import.
+	FixTypeRemoveImport
+	// FixTypeRenameFile renames a file.
+	FixTypeRenameFile
+
+	enumValuePrefixID         = "ENUM_VALUE_PREFIX"
+	enumValueUpperSnakeCaseID = "ENUM_VALUE_UPPER_SNAKE_CASE"
+	enumZeroValueSuffixID     = "ENUM_ZERO_VALUE_SUFFIX"
+	fieldLowerSnakeCaseID     = "FIELD_LOWER_SNAKE_CASE"
+	fileLowerSnakeCaseID      = "FILE_LOWER_SNAKE_CASE"
+	importUsedID              = "IMPORT_USED"
+	serviceSuffixID           = "SERVICE_SUFFIX"
+)
+
+var (
+	// FixableIDs are the rule IDs that have a mechanical fix.
+	FixableIDs = []string{
+		enumValuePrefixID,
+		enumValueUpperSnakeCaseID,
+		enumZeroValueSuffixID,
+		fieldLowerSnakeCaseID,
+		fileLowerSnakeCaseID,
+		importUsedID,
+		serviceSuffixID,
+	}
+
+	idToFixType = map[string]FixType{
+		enumValuePrefixID:         FixTypeRenameEnumValue,
+		enumValueUpperSnakeCaseID: FixTypeRenameEnumValue,
+		enumZeroValueSuffixID:     FixTypeRenameEnumValue,
+		fieldLowerSnakeCaseID:     FixTypeRenameField,
+		fileLowerSnakeCaseID:      FixTypeRenameFile,
+		importUsedID:              FixTypeRemoveImport,
+		serviceSuffixID:           FixTypeRenameService,
+	}
+	fixTypeToString = map[FixType]string{
+		FixTypeRenameField:     "rename field",
+		FixTypeRenameEnumValue: "rename enum value",
+		FixTypeRenameService:   "rename service",
+		FixTypeRemoveImport:    "remove import",
+		FixTypeRenameFile:      "rename file",
+	}
+)
+
+// FixType is the type of a Fix.
+type FixType int
+
+// String implements fmt.Stringer.
+func (f FixType) String() string {
+	s, ok := fixTypeToString[f]
+	if !ok {
+		return strconv.Itoa(int(f))
+	}
+	return s
+}
+
+// Fix is a mechanical fix for the lint violations reported against a single element.
+type Fix struct {
+	// Type is the type of the Fix.
+	Type FixType
+	// Path is the path of the file that contains the element, relative to the root of the module.
+	Path string
+	// Element is the fully-qualified name of the element to rename.
+	//
+	// For FixTypeRemoveImport, this is the import to remove.
+	// For FixTypeRenameFile, this is empty, as the file is identified by Path.
+	Element string
+	// NewName is the new name of the element.
+	//
+	// For renames of named elements, this is the new name without the package or any
+	// enclosing elements. For FixTypeRenameFile, this is the new path of the file.
+	// For FixTypeRemoveImport, this is empty.
+	NewName string
+	// IDs are the sorted rule IDs of the violations that are resolved by this Fix.
+	IDs []string
+}
+
+// String implements fmt.Stringer.
+func (f *Fix) String() string {
+	element := f.Element
+	if element == "" {
+		element = f.Path
+	}
+	if f.NewName == "" {
+		return f.Type.String() + " " + element
+	}
+	return f.Type.String() + " " + element + " to " + f.NewName
+}
+
+// NewFixes returns the Fixes for the FileAnnotations that were reported by rules
+// that have a mechanical fix. All other FileAnnotations are ignored.
+//
+// The config should be the config that the FileAnnotations were produced with, as the
+// fixes for some rules depend on the configuration of the rule.
+//
+// Violations of multiple rules against the same element are combined into a single Fix.
+// The returned Fixes are sorted by Path, Type and Element.
+func NewFixes(config *buflintconfig.Config, fileAnnotations []bufanalysis.FileAnnotation) []*Fix {
+	enumZeroValueSuffix := config.EnumZeroValueSuffix
+	if enumZeroValueSuffix == "" {
+		enumZeroValueSuffix = internal.DefaultEnumZeroValueSuffix
+	}
+	serviceSuffix := config.ServiceSuffix
+	if serviceSuffix == "" {
+		serviceSuffix = internal.DefaultServiceSuffix
+	}
+	keyToFix := make(map[string]*Fix)
+	var keys []string
+	for _, fileAnnotation := range fileAnnotations {
+		fixType, ok := idToFixType[fileAnnotation.Type()]
+		if !ok {
+			continue
+		}
+		fileInfo := fileAnnotation.FileInfo()
+		if fileInfo == nil {
+			continue
+		}
+		path := fileInfo.Path()
+		element := internal.ElementForFileAnnotation(fileAnnotation)
+		switch fixType {
+		case FixTypeRenameFile:
+			element = ""
+		case FixTypeRemoveImport:
+			// The element of an import is the path of the file joined with the import.
+			element = strings.TrimPrefix(element, path+":")
+			if element == "" {
+				continue
+			}
+		default:
+			if element == "" {
+				continue
+			}
+		}
+		key := strings.Join([]string{fixType.String(), path, element}, " ")
+		fix, ok := keyToFix[key]
+		if !ok {
+			fix = &Fix{
+				Type:    fixType,
+				Path:    path,
+				Element: element,
+			}
+			keyToFix[key] = fix
+			keys = append(keys, key)
+		}
+		fix.IDs = append(fix.IDs, fileAnnotation.Type())
+	}
+	fixes := make([]*Fix, 0, len(keys))
+	for _, key := range keys {
+		fix := keyToFix[key]
+		fix.IDs = stringutil.SliceToUniqueSortedSlice(fix.IDs)
+		oldName := fixOldName(fix)
+		switch fix.Type {
+		case FixTypeRenameField:
+			fix.NewName = stringutil.ToLowerSnakeCase(oldName)
+		case FixTypeRenameEnumValue:
+			fix.NewName = newEnumValueName(fix, enumZeroValueSuffix)
+		case FixTypeRenameService:
+			fix.NewName = oldName + serviceSuffix
+		case FixTypeRenameFile:
+			fix.NewName = newFilePath(fix.Path)
+		}
+		if fix.Type != FixTypeRemoveImport && (fix.NewName == "" || fix.NewName == oldName) {
+			continue
+		}
+		fixes = append(fixes, fix)
+	}
+	sort.Slice(
+		fixes,
+		func(i int, j int) bool {
+			one := fixes[i]
+			two := fixes[j]
+			if one.Path != two.Path {
+				return one.Path < two.Path
+			}
+			if one.Type != two.Type {
+				return one.Type < two.Type
+			}
+			return one.Element < two.Element
+		},
+	)
+	return fixes
+}
+
+// fixOldName returns the name that the Fix changes, in the same form as NewName.
+func fixOldName(fix *Fix) string {
+	switch fix.Type {
+	case FixTypeRenameFile:
+		return fix.Path
+	case FixTypeRemoveImport:
+		return fix.Element
+	default:
+		return elementNameComponent(fix.Element, 0)
+	}
+}
+
+// newEnumValueName applies the enum value renames in a fixed order, so that the
+// result satisfies every rule that was violated. For example, "foo" with violations
+// of ENUM_VALUE_UPPER_SNAKE_CASE and ENUM_VALUE_PREFIX in the enum Bar becomes "BAR_FOO".
+func newEnumValueName(fix *Fix, enumZeroValueSuffix string) string {
+	name := elementNameComponent(fix.Element, 0)
+	ids := stringutil.SliceToMap(fix.IDs)
+	if _, ok := ids[enumValueUpperSnakeCaseID]; ok {
+		name = stringutil.ToUpperSnakeCase(name)
+	}
+	if _, ok := ids[enumValuePrefixID]; ok {
+		enumName := elementNameComponent(fix.Element, 1)
+		if enumName == "" {
+			return ""
+		}
+		if expectedPrefix := stringutil.ToUpperSnakeCase(enumName) + "_"; !strings.HasPrefix(name, expectedPrefix) {
+			name = expectedPrefix + name
+		}
+	}
+	if _, ok := ids[enumZeroValueSuffixID]; ok {
+		if !strings.HasSuffix(name, enumZeroValueSuffix) {
+			name = name + enumZeroValueSuffix
+		}
+	}
+	return name
+}
+
+// newFilePath returns the path with the base name converted to lower_snake_case.
+func newFilePath(path string) string {
+	base := normalpath.Base(path)
+	ext := normalpath.Ext(path)
+	baseWithoutExt := strings.TrimSuffix(base, ext)
+	return normalpath.Join(normalpath.Dir(path), stringutil.ToLowerSnakeCase(baseWithoutExt)+ext)
+}
+
+// elementNameComponent returns the component of the fully-qualified name that is
+// the given number of components from the end, or empty if there is no such component.
+func elementNameComponent(element string, fromEnd int) string {
+	components := strings.Split(element, ".")
+	if fromEnd >= len(components) {
+		return ""
+	}
+	return components[len(components)-1-fromEnd]
+}
@@ -16,6 +16,7 @@ package buflint
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
@@ -23,17 +24,21 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/protosource"
 	"go.uber.org/zap"
 )
 
 type handler struct {
-	logger *zap.Logger
-	runner *internal.Runner
+	logger          *zap.Logger
+	runner          *internal.Runner
+	pluginRunner    command.Runner
+	pluginContainer app.EnvStderrContainer
 }
 
-func newHandler(logger *zap.Logger) *handler {
-	return &handler{
+func newHandler(logger *zap.Logger, options ...HandlerOption) *handler {
+	handler := &handler{
 		logger: logger,
 		// linting allows for comment ignores
 		// note that comment ignores still need to be enabled within the config
@@ -44,6 +49,10 @@ func newHandler(logger *zap.Logger) *handler {
 			internal.RunnerWithIgnorePrefix(buflintcheck.CommentIgnorePrefix),
 		),
 	}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
 }
 
 func (h *handler) Check(
@@ -51,13 +60,117 @@ func (h *handler) Check(
 	config *buflintconfig.Config,
 	image bufimage.Image,
 ) ([]bufanalysis.FileAnnotation, error) {
-	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	// Lint plugins get the imports, but the builtin rules only check the files that
+	// are not imports.
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(bufimage.ImageWithoutImports(image).Files())...)
 	if err != nil {
 		return nil, err
 	}
-	internalConfig, err := internalConfigForConfig(config)
+	versionSpec := versionSpecForConfig(config)
+	if len(config.Plugins) > 0 {
+		versionSpec, err = h.versionSpecWithPlugins(ctx, config, image, files, versionSpec)
+		if err != nil {
+			return nil, err
+		}
+	}
+	internalConfig, err := internalConfigForConfigAndVersionSpec(config, versionSpec)
 	if err != nil {
 		return nil, err
 	}
-	return h.runner.Check(ctx, internalConfig, nil, files)
+	fileAnnotations, err := h.runner.Check(ctx, internalConfig, nil, files)
+	if err != nil {
+		return nil, err
+	}
+	if config.Baseline == nil {
+		return fileAnnotations, nil
+	}
+	return h.filterBaseline(config.Baseline, files, fileAnnotations), nil
+}
+
+// versionSpecWithPlugins runs the plugins of the config, and returns a copy of the
+// VersionSpec that also contains the rules of the plugins.
+func (h *handler) versionSpecWithPlugins(
+	ctx context.Context,
+	config *buflintconfig.Config,
+	image bufimage.Image,
+	files []protosource.File,
+	versionSpec *internal.VersionSpec,
+) (*internal.VersionSpec, error) {
+	if h.pluginRunner == nil {
+		return nil, errPluginsNotSupported
+	}
+	if versionSpec == nil {
+		return nil, fmt.Errorf("unknown lint config version: %q", config.Version)
+	}
+	request := newPluginRequest(config, image)
+	for i, pluginConfig := range config.Plugins {
+		if pluginConfig.Name == "" {
+			return nil, fmt.Errorf("lint plugin at index %d has no plugin name", i)
+		}
+		// The plugins are run one after another, so the request is reused.
+		request.Parameter = pluginConfig.Opt
+		response, err := runPlugin(ctx, h.pluginRunner, h.pluginContainer, pluginConfig, request)
+		if err != nil {
+			return nil, err
+		}
+		ruleBuilders, idToCategories, err := newPluginRuleBuilders(pluginConfig.Name, response, files)
+		if err != nil {
+			return nil, err
+		}
+		versionSpec, err = internal.VersionSpecWithRuleBuilders(versionSpec, ruleBuilders, idToCategories)
+		if err != nil {
+			return nil, fmt.Errorf("lint plugin %q: %w", pluginConfig.Name, err)
+		}
+	}
+	return versionSpec, nil
+}
+
+// filterBaseline removes the FileAnnotations that are recorded in the Baseline.
+//
+// Entries of the Baseline that did not match any FileAnnotation for a file that
+// was checked are stale, and are reported as warnings.
+func (h *handler) filterBaseline(
+	baseline *buflintconfig.Baseline,
+	files []protosource.File,
+	fileAnnotations []bufanalysis.FileAnnotation,
+) []bufanalysis.FileAnnotation {
+	keyToEntry := make(map[string]*buflintconfig.BaselineEntry, len(baseline.Entries))
+	for _, entry := range baseline.Entries {
+		keyToEntry[entry.Key()] = entry
+	}
+	usedKeys := make(map[string]struct{})
+	filteredFileAnnotations := make([]bufanalysis.FileAnnotation, 0, len(fileAnnotations))
+	for _, fileAnnotation := range fileAnnotations {
+		key := baselineEntryForFileAnnotation(fileAnnotation).Key()
+		if _, ok := keyToEntry[key]; ok {
+			usedKeys[key] = struct{}{}
+			continue
+		}
+		filteredFileAnnotations = append(filteredFileAnnotations, fileAnnotation)
+	}
+	checkedPaths := make(map[string]struct{}, len(files))
+	for _, file := range files {
+		checkedPaths[file.Path()] = struct{}{}
+	}
+	for _, entry := range baseline.Entries {
+		if _, ok := checkedPaths[entry.Path]; !ok {
+			continue
+		}
+		if _, ok := usedKeys[entry.Key()]; !ok {
+			h.logger.Sugar().Warnf("lint baseline entry %s is stale and can be removed", entry.String())
+		}
+	}
+	return filteredFileAnnotations
+}
+
+func baselineEntryForFileAnnotation(fileAnnotation bufanalysis.FileAnnotation) *buflintconfig.BaselineEntry {
+	path := ""
+	if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+		path = fileInfo.Path()
+	}
+	return &buflintconfig.BaselineEntry{
+		ID:      fileAnnotation.Type(),
+		Path:    path,
+		Element: internal.ElementForFileAnnotation(fileAnnotation),
+	}
 }
@@ -0,0 +1,321 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	lintv1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/lint/v1"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"go.opencensus.io/trace"
+)
+
+// pluginBinaryPrefix is the prefix of the binary name of a lint plugin that is
+// found on the PATH.
+const pluginBinaryPrefix = "buf-lint-"
+
+// errPluginsNotSupported is returned if the config has plugins but the Handler
+// was created without HandlerWithPlugins.
+var errPluginsNotSupported = errors.New("lint plugins are not supported in this context")
+
+// runPlugin runs the lint plugin and returns its response.
+func runPlugin(
+	ctx context.Context,
+	runner command.Runner,
+	container app.EnvStderrContainer,
+	pluginConfig *buflintconfig.PluginConfig,
+	request *lintv1.CheckRequest,
+) (*lintv1.CheckResponse, error) {
+	ctx, span := trace.StartSpan(ctx, "lint_plugin")
+	span.AddAttributes(trace.StringAttribute("plugin", pluginConfig.Name))
+	defer span.End()
+	pluginPath, err := getPluginPath(pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	pluginPath, err = exec.LookPath(pluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("lint plugin %q: %w", pluginConfig.Name, err)
+	}
+	requestData, err := protoencoding.NewWireMarshaler().Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	responseBuffer := bytes.NewBuffer(nil)
+	if err := runner.Run(
+		ctx,
+		pluginPath,
+		command.RunWithEnv(app.EnvironMap(container)),
+		command.RunWithStdin(bytes.NewReader(requestData)),
+		command.RunWithStdout(responseBuffer),
+		command.RunWithStderr(container.Stderr()),
+	); err != nil {
+		return nil, fmt.Errorf("lint plugin %q: %w", pluginConfig.Name, err)
+	}
+	response := &lintv1.CheckResponse{}
+	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(responseBuffer.Bytes(), response); err != nil {
+		return nil, fmt.Errorf("lint plugin %q returned an invalid response: %w", pluginConfig.Name, err)
+	}
+	if response.GetError() != "" {
+		return nil, fmt.Errorf("lint plugin %q: %s", pluginConfig.Name, response.GetError())
+	}
+	return response, nil
+}
+
+// getPluginPath returns the path of the binary of the lint plugin.
+//
+// A relative Path is resolved against the directory of the configuration file, so
+// that the same configuration runs the same binary wherever buf is run from.
+func getPluginPath(pluginConfig *buflintconfig.PluginConfig) (string, error) {
+	if pluginConfig.Path == "" {
+		return pluginBinaryPrefix + pluginConfig.Name, nil
+	}
+	if pluginConfig.DirPath == "" {
+		return "", fmt.Errorf("lint plugin %q: path can only be set in configuration files on the local file system", pluginConfig.Name)
+	}
+	pluginPath := filepath.FromSlash(pluginConfig.Path)
+	if filepath.IsAbs(pluginPath) {
+		return pluginPath, nil
+	}
+	return filepath.Join(pluginConfig.DirPath, pluginPath), nil
+}
+
+// newPluginRequest returns the request that is sent to every lint plugin.
+//
+// The request contains the full image, including the imports.
+func newPluginRequest(config *buflintconfig.Config, image bufimage.Image) *lintv1.CheckRequest {
+	return &lintv1.CheckRequest{
+		Image:  bufimage.ImageToProtoImage(image),
+		Config: buflintconfig.ProtoForConfig(config),
+	}
+}
+
+// newPluginRuleBuilders returns the RuleBuilders for the rules in the response of
+// the lint plugin, along with the categories of these rules.
+//
+// The CheckFunc of each rule reports the annotations of the response for this rule
+// against the given Files.
+func newPluginRuleBuilders(
+	pluginName string,
+	response *lintv1.CheckResponse,
+	files []protosource.File,
+) ([]*internal.RuleBuilder, map[string][]string, error) {
+	idToAnnotations := make(map[string][]*lintv1.Annotation)
+	idToCategories := make(map[string][]string)
+	for _, rule := range response.GetRules() {
+		id := rule.GetId()
+		if id == "" {
+			return nil, nil, fmt.Errorf("lint plugin %q returned a rule without an ID", pluginName)
+		}
+		if rule.GetPurpose() == "" {
+			return nil, nil, fmt.Errorf("lint plugin %q returned rule %q without a purpose", pluginName, id)
+		}
+		if _, ok := idToCategories[id]; ok {
+			return nil, nil, fmt.Errorf("lint plugin %q returned duplicate rule %q", pluginName, id)
+		}
+		// The map must contain an entry for every rule, even if it has no categories.
+		idToCategories[id] = append([]string{}, rule.GetCategories()...)
+	}
+	for _, annotation := range response.GetAnnotations() {
+		id := annotation.GetRuleId()
+		if _, ok := idToCategories[id]; !ok {
+			return nil, nil, fmt.Errorf("lint plugin %q returned an annotation for unknown rule %q", pluginName, id)
+		}
+		idToAnnotations[id] = append(idToAnnotations[id], annotation)
+	}
+	index, err := newPluginElementIndex(files)
+	if err != nil {
+		return nil, nil, err
+	}
+	ruleBuilders := make([]*internal.RuleBuilder, 0, len(response.GetRules()))
+	for _, rule := range response.GetRules() {
+		annotations := idToAnnotations[rule.GetId()]
+		ruleBuilders = append(
+			ruleBuilders,
+			internal.NewNopRuleBuilder(
+				rule.GetId(),
+				rule.GetPurpose(),
+				func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, _ []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+					helper := internal.NewHelper(id, ignoreFunc)
+					for _, annotation := range annotations {
+						descriptor, location, err := index.resolve(annotation)
+						if err != nil {
+							return nil, fmt.Errorf("lint plugin %q: %w", pluginName, err)
+						}
+						helper.AddFileAnnotationf(descriptor, location, "%s", annotation.GetMessage())
+					}
+					return helper.FileAnnotations(), nil
+				},
+			),
+		)
+	}
+	return ruleBuilders, idToCategories, nil
+}
+
+// pluginElementIndex resolves the paths and elements of plugin annotations to
+// the Descriptors of the checked Files.
+type pluginElementIndex struct {
+	pathToFile         map[string]protosource.File
+	fullNameToElement  map[string]protosource.NamedDescriptor
+	duplicateFullNames map[string]struct{}
+}
+
+func newPluginElementIndex(files []protosource.File) (*pluginElementIndex, error) {
+	index := &pluginElementIndex{
+		pathToFile:         make(map[string]protosource.File, len(files)),
+		fullNameToElement:  make(map[string]protosource.NamedDescriptor),
+		duplicateFullNames: make(map[string]struct{}),
+	}
+	for _, file := range files {
+		index.pathToFile[file.Path()] = file
+		for _, extension := range file.Extensions() {
+			index.add(extension)
+		}
+		for _, service := range file.Services() {
+			index.add(service)
+			for _, method := range service.Methods() {
+				index.add(method)
+			}
+		}
+		if err := protosource.ForEachEnum(
+			func(enum protosource.Enum) error {
+				index.add(enum)
+				for _, enumValue := range enum.Values() {
+					index.add(enumValue)
+				}
+				return nil
+			},
+			file,
+		); err != nil {
+			return nil, err
+		}
+		if err := protosource.ForEachMessage(
+			func(message protosource.Message) error {
+				index.add(message)
+				for _, field := range message.Fields() {
+					index.add(field)
+				}
+				for _, extension := range message.Extensions() {
+					index.add(extension)
+				}
+				for _, oneof := range message.Oneofs() {
+					index.add(oneof)
+				}
+				return nil
+			},
+			file,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+func (i *pluginElementIndex) add(namedDescriptor protosource.NamedDescriptor) {
+	fullName := namedDescriptor.FullName()
+	if _, ok := i.fullNameToElement[fullName]; ok {
+		// This can only happen for files that do not compile together, such as an
+		// extension that has the same name as a field. We do not guess.
+		i.duplicateFullNames[fullName] = struct{}{}
+		return
+	}
+	i.fullNameToElement[fullName] = namedDescriptor
+}
+
+// resolve returns the Descriptor and Location to report the annotation against.
+func (i *pluginElementIndex) resolve(annotation *lintv1.Annotation) (protosource.Descriptor, protosource.Location, error) {
+	file, ok := i.pathToFile[annotation.GetPath()]
+	if !ok {
+		return nil, nil, fmt.Errorf("annotation for rule %q has unknown path %q", annotation.GetRuleId(), annotation.GetPath())
+	}
+	if element := annotation.GetElement(); element != "" {
+		if _, ok := i.duplicateFullNames[element]; ok {
+			return nil, nil, fmt.Errorf("annotation for rule %q has ambiguous element %q", annotation.GetRuleId(), element)
+		}
+		namedDescriptor, ok := i.fullNameToElement[element]
+		if !ok || namedDescriptor.File().Path() != file.Path() {
+			return nil, nil, fmt.Errorf("annotation for rule %q has unknown element %q in %q", annotation.GetRuleId(), element, file.Path())
+		}
+		return namedDescriptor, namedDescriptor.NameLocation(), nil
+	}
+	if annotation.GetStartLine() == 0 {
+		return file, nil, nil
+	}
+	return file, newPluginLocation(annotation), nil
+}
+
+// pluginLocation is a protosource.Location for the lines and columns of an annotation.
+//
+// It has no comments, so comment ignores do not apply to it.
+type pluginLocation struct {
+	startLine   int
+	startColumn int
+	endLine     int
+	endColumn   int
+}
+
+func newPluginLocation(annotation *lintv1.Annotation) *pluginLocation {
+	location := &pluginLocation{
+		startLine:   int(annotation.GetStartLine()),
+		startColumn: int(annotation.GetStartColumn()),
+		endLine:     int(annotation.GetEndLine()),
+		endColumn:   int(annotation.GetEndColumn()),
+	}
+	if location.endLine == 0 {
+		location.endLine = location.startLine
+		location.endColumn = location.startColumn
+	}
+	return location
+}
+
+func (l *pluginLocation) StartLine() int {
+	return l.startLine
+}
+
+func (l *pluginLocation) StartColumn() int {
+	return l.startColumn
+}
+
+func (l *pluginLocation) EndLine() int {
+	return l.endLine
+}
+
+func (l *pluginLocation) EndColumn() int {
+	return l.endColumn
+}
+
+func (l *pluginLocation) LeadingComments() string {
+	return ""
+}
+
+func (l *pluginLocation) TrailingComments() string {
+	return ""
+}
+
+func (l *pluginLocation) LeadingDetachedComments() []string {
+	return nil
+}
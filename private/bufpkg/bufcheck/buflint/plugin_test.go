@@ -0,0 +1,45 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPluginPath(t *testing.T) {
+	t.Parallel()
+	dirPath, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+	pluginPath, err := getPluginPath(&buflintconfig.PluginConfig{Name: "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, "buf-lint-acme", pluginPath)
+	pluginPath, err = getPluginPath(&buflintconfig.PluginConfig{Name: "acme", Path: "bin/acme", DirPath: dirPath})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dirPath, "bin", "acme"), pluginPath)
+	absPath := filepath.Join(dirPath, "acme")
+	pluginPath, err = getPluginPath(&buflintconfig.PluginConfig{Name: "acme", Path: filepath.ToSlash(absPath), DirPath: dirPath})
+	require.NoError(t, err)
+	assert.Equal(t, absPath, pluginPath)
+	// The configuration was not read from disk, for example from a remote module.
+	_, err = getPluginPath(&buflintconfig.PluginConfig{Name: "acme", Path: "bin/acme"})
+	assert.Error(t, err)
+	_, err = getPluginPath(&buflintconfig.PluginConfig{Name: "acme", Path: absPath})
+	assert.Error(t, err)
+}
@@ -24,8 +24,10 @@ import (
 )
 
 const (
-	defaultEnumZeroValueSuffix = "_UNSPECIFIED"
-	defaultServiceSuffix       = "Service"
+	// DefaultEnumZeroValueSuffix is the default suffix for the ENUM_ZERO_VALUE_SUFFIX rule.
+	DefaultEnumZeroValueSuffix = "_UNSPECIFIED"
+	// DefaultServiceSuffix is the default suffix for the SERVICE_SUFFIX rule.
+	DefaultServiceSuffix = "Service"
 )
 
 // Config is the check config.
@@ -59,6 +61,8 @@ type ConfigBuilder struct {
 	RPCAllowGoogleProtobufEmptyRequests  bool
 	RPCAllowGoogleProtobufEmptyResponses bool
 	ServiceSuffix                        string
+
+	CustomOptions []string
 }
 
 // NewConfig returns a new Config.
@@ -74,10 +78,10 @@ func newConfig(configBuilder ConfigBuilder, versionSpec *VersionSpec) (*Config,
 		configBuilder.Use = versionSpec.DefaultCategories
 	}
 	if configBuilder.EnumZeroValueSuffix == "" {
-		configBuilder.EnumZeroValueSuffix = defaultEnumZeroValueSuffix
+		configBuilder.EnumZeroValueSuffix = DefaultEnumZeroValueSuffix
 	}
 	if configBuilder.ServiceSuffix == "" {
-		configBuilder.ServiceSuffix = defaultServiceSuffix
+		configBuilder.ServiceSuffix = DefaultServiceSuffix
 	}
 	return newConfigForRuleBuilders(
 		configBuilder,
@@ -129,6 +129,19 @@ func (h *Helper) addFileAnnotationf(
 	)
 }
 
+// ElementForFileAnnotation returns the name of the element that the FileAnnotation
+// was reported against.
+//
+// This is the fully-qualified name for named elements, and the file path joined with
+// the import path for imports. Returns empty if the FileAnnotation was reported against a file as a whole, or if
+// the FileAnnotation was not created by a Helper.
+func ElementForFileAnnotation(fileAnnotation bufanalysis.FileAnnotation) string {
+	if elementFileAnnotation, ok := fileAnnotation.(*elementFileAnnotation); ok {
+		return elementFileAnnotation.element
+	}
+	return ""
+}
+
 // FileAnnotations returns the added FileAnnotations.
 func (h *Helper) FileAnnotations() []bufanalysis.FileAnnotation {
 	return h.fileAnnotations
@@ -159,13 +172,42 @@ func newFileAnnotationf(
 	if descriptor != nil {
 		fileInfo = descriptor.File()
 	}
-	return bufanalysis.NewFileAnnotation(
-		fileInfo,
-		startLine,
-		startColumn,
-		endLine,
-		endColumn,
-		id,
-		fmt.Sprintf(format, args...),
-	)
+	return &elementFileAnnotation{
+		FileAnnotation: bufanalysis.NewFileAnnotation(
+			fileInfo,
+			startLine,
+			startColumn,
+			endLine,
+			endColumn,
+			id,
+			fmt.Sprintf(format, args...),
+		),
+		element: elementForDescriptor(descriptor),
+	}
+}
+
+// elementForDescriptor returns the name of the element for the descriptor.
+//
+// Returns empty for files and any other descriptor without a name.
+func elementForDescriptor(descriptor protosource.Descriptor) string {
+	switch t := descriptor.(type) {
+	case protosource.NamedDescriptor:
+		return t.FullName()
+	case protosource.FileImport:
+		return t.File().Path() + ":" + t.Import()
+	default:
+		return ""
+	}
+}
+
+// elementFileAnnotation is a FileAnnotation that also records the element
+// the FileAnnotation was reported against.
+type elementFileAnnotation struct {
+	bufanalysis.FileAnnotation
+
+	element string
+}
+
+func (e *elementFileAnnotation) Element() string {
+	return e.element
 }
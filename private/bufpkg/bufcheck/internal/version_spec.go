@@ -15,6 +15,7 @@
 package internal
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/bufbuild/buf/private/pkg/stringutil"
@@ -73,3 +74,40 @@ func AllCategoriesAndIDsForVersionSpec(versionSpec *VersionSpec) []string {
 	}
 	return stringutil.MapToSortedSlice(m)
 }
+
+// VersionSpecWithRuleBuilders returns a copy of the VersionSpec with the additional
+// RuleBuilders, such as the rules of plugins.
+//
+// idToCategories must contain an entry for every additional RuleBuilder, and may
+// refer to existing categories. Returns error if an additional RuleBuilder has the
+// same ID as an existing rule or category.
+func VersionSpecWithRuleBuilders(
+	versionSpec *VersionSpec,
+	ruleBuilders []*RuleBuilder,
+	idToCategories map[string][]string,
+) (*VersionSpec, error) {
+	existingIDsAndCategories := stringutil.SliceToMap(AllCategoriesAndIDsForVersionSpec(versionSpec))
+	resultRuleBuilders := make([]*RuleBuilder, 0, len(versionSpec.RuleBuilders)+len(ruleBuilders))
+	resultRuleBuilders = append(resultRuleBuilders, versionSpec.RuleBuilders...)
+	resultIDToCategories := make(map[string][]string, len(versionSpec.IDToCategories)+len(idToCategories))
+	for id, categories := range versionSpec.IDToCategories {
+		resultIDToCategories[id] = categories
+	}
+	for _, ruleBuilder := range ruleBuilders {
+		if _, ok := existingIDsAndCategories[ruleBuilder.id]; ok {
+			return nil, fmt.Errorf("rule ID %q is already defined", ruleBuilder.id)
+		}
+		categories, ok := idToCategories[ruleBuilder.id]
+		if !ok {
+			return nil, fmt.Errorf("%q is not configured for categories", ruleBuilder.id)
+		}
+		existingIDsAndCategories[ruleBuilder.id] = struct{}{}
+		resultRuleBuilders = append(resultRuleBuilders, ruleBuilder)
+		resultIDToCategories[ruleBuilder.id] = categories
+	}
+	return &VersionSpec{
+		RuleBuilders:      resultRuleBuilders,
+		DefaultCategories: versionSpec.DefaultCategories,
+		IDToCategories:    resultIDToCategories,
+	}, nil
+}
@@ -204,6 +204,20 @@ func ReadConfigOSWithOverride(override string) ReadConfigOSOption {
 	}
 }
 
+// ReadConfigOSWithLocalBucket says that the bucket is backed by the local file system.
+//
+// The paths of the lint plugins of a configuration file in the bucket are then relative
+// to the directory of the configuration file. Otherwise, lint plugins with a path cannot
+// be run, as the bucket may come from a remote module or an archive.
+//
+// The paths of the lint plugins of an override file are always relative to the directory
+// of the file, and the paths of override data are relative to the current directory.
+func ReadConfigOSWithLocalBucket() ReadConfigOSOption {
+	return func(readConfigOSOptions *readConfigOSOptions) {
+		readConfigOSOptions.localBucket = true
+	}
+}
+
 // ExistingConfigFilePath checks if a configuration file exists, and if so, returns the path
 // within the ReadBucket of this configuration file.
 //
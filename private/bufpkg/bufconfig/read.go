@@ -16,10 +16,14 @@ package bufconfig
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/storage"
 )
 
@@ -33,24 +37,146 @@ func readConfigOS(
 		option(readConfigOSOptions)
 	}
 	if readConfigOSOptions.override != "" {
-		var data []byte
-		var err error
 		switch filepath.Ext(readConfigOSOptions.override) {
 		case ".json", ".yaml", ".yml":
-			data, err = os.ReadFile(readConfigOSOptions.override)
+			data, err := os.ReadFile(readConfigOSOptions.override)
 			if err != nil {
 				return nil, fmt.Errorf("could not read file: %v", err)
 			}
+			config, err := GetConfigForData(ctx, data)
+			if err != nil {
+				return nil, err
+			}
+			// The baseline and the plugins are relative to the directory of the override file.
+			if err := readLintBaselineOS(config, filepath.Dir(readConfigOSOptions.override)); err != nil {
+				return nil, err
+			}
+			if err := setLintPluginDirPath(config, filepath.Dir(readConfigOSOptions.override)); err != nil {
+				return nil, err
+			}
+			return config, nil
 		default:
-			data = []byte(readConfigOSOptions.override)
+			config, err := GetConfigForData(ctx, []byte(readConfigOSOptions.override))
+			if err != nil {
+				return nil, err
+			}
+			if err := readLintBaselineForBucket(ctx, config, readBucket); err != nil {
+				return nil, err
+			}
+			// The configuration data is given on the command line, so the plugins
+			// are relative to the current directory.
+			if err := setLintPluginDirPath(config, "."); err != nil {
+				return nil, err
+			}
+			return config, nil
+		}
+	}
+	config, err := GetConfigForBucket(ctx, readBucket)
+	if err != nil {
+		return nil, err
+	}
+	if err := readLintBaselineForBucket(ctx, config, readBucket); err != nil {
+		return nil, err
+	}
+	if readConfigOSOptions.localBucket {
+		if err := setLintPluginDirPathForBucket(ctx, config, readBucket); err != nil {
+			return nil, err
 		}
-		return GetConfigForData(ctx, data)
 	}
-	return GetConfigForBucket(ctx, readBucket)
+	return config, nil
+}
+
+// readLintBaselineForBucket reads the lint baseline at the configured path within the
+// bucket, if one is configured.
+//
+// A baseline file that does not exist is treated as an empty baseline, so that all
+// violations are reported until the baseline is written.
+func readLintBaselineForBucket(ctx context.Context, config *Config, readBucket storage.ReadBucket) error {
+	if config.Lint == nil || config.Lint.BaselinePath == "" {
+		return nil
+	}
+	baselinePath, err := normalpath.NormalizeAndValidate(config.Lint.BaselinePath)
+	if err != nil {
+		return fmt.Errorf("invalid lint baseline path: %w", err)
+	}
+	data, err := storage.ReadPath(ctx, readBucket, baselinePath)
+	if err != nil && !storage.IsNotExist(err) {
+		return err
+	}
+	return setLintBaselineForData(config, data)
+}
+
+// readLintBaselineOS reads the lint baseline at the configured path relative to dirPath,
+// if one is configured.
+//
+// A baseline file that does not exist is treated as an empty baseline, so that all
+// violations are reported until the baseline is written.
+func readLintBaselineOS(config *Config, dirPath string) error {
+	if config.Lint == nil || config.Lint.BaselinePath == "" {
+		return nil
+	}
+	baselinePath, err := normalpath.NormalizeAndValidate(config.Lint.BaselinePath)
+	if err != nil {
+		return fmt.Errorf("invalid lint baseline path: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dirPath, normalpath.Unnormalize(baselinePath)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not read lint baseline: %v", err)
+	}
+	return setLintBaselineForData(config, data)
+}
+
+func setLintBaselineForData(config *Config, data []byte) error {
+	baseline, err := buflintconfig.GetBaselineForData(data)
+	if err != nil {
+		return fmt.Errorf("invalid lint baseline %q: %w", config.Lint.BaselinePath, err)
+	}
+	config.Lint.Baseline = baseline
+	return nil
+}
+
+// setLintPluginDirPathForBucket sets the directory of the lint plugins to the
+// directory on disk of the configuration file in the bucket.
+//
+// The bucket must be backed by the local file system, so that the external path
+// of the configuration file is its path on disk.
+func setLintPluginDirPathForBucket(ctx context.Context, config *Config, readBucket storage.ReadBucket) error {
+	if config.Lint == nil || len(config.Lint.Plugins) == 0 {
+		return nil
+	}
+	configFilePath, err := ExistingConfigFilePath(ctx, readBucket)
+	if err != nil {
+		return err
+	}
+	if configFilePath == "" {
+		return nil
+	}
+	objectInfo, err := readBucket.Stat(ctx, configFilePath)
+	if err != nil {
+		return err
+	}
+	return setLintPluginDirPath(config, filepath.Dir(objectInfo.ExternalPath()))
+}
+
+// setLintPluginDirPath sets the directory that the paths of the lint plugins are
+// relative to.
+func setLintPluginDirPath(config *Config, dirPath string) error {
+	if config.Lint == nil || len(config.Lint.Plugins) == 0 {
+		return nil
+	}
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, pluginConfig := range config.Lint.Plugins {
+		pluginConfig.DirPath = absDirPath
+	}
+	return nil
 }
 
 type readConfigOSOptions struct {
-	override string
+	override    string
+	localBucket bool
 }
 
 func newReadConfigOSOptions() *readConfigOSOptions {
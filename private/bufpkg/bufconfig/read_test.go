@@ -0,0 +1,69 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigOSLintPluginDirPath(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dirPath := t.TempDir()
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(dirPath, ExternalConfigV1FilePath),
+			[]byte("version: v1\nlint:\n  plugins:\n    - plugin: acme\n      path: bin/acme\n"),
+			0600,
+		),
+	)
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(dirPath)
+	require.NoError(t, err)
+	config, err := ReadConfigOS(ctx, readWriteBucket, ReadConfigOSWithLocalBucket())
+	require.NoError(t, err)
+	require.Len(t, config.Lint.Plugins, 1)
+	absDirPath, err := filepath.Abs(dirPath)
+	require.NoError(t, err)
+	require.Equal(t, absDirPath, config.Lint.Plugins[0].DirPath)
+	// The bucket may come from a remote module or an archive.
+	config, err = ReadConfigOS(ctx, readWriteBucket)
+	require.NoError(t, err)
+	require.Len(t, config.Lint.Plugins, 1)
+	require.Empty(t, config.Lint.Plugins[0].DirPath)
+	// The override file is given on the command line.
+	overrideDirPath := t.TempDir()
+	overrideFilePath := filepath.Join(overrideDirPath, "buf.lint.yaml")
+	require.NoError(
+		t,
+		os.WriteFile(
+			overrideFilePath,
+			[]byte("version: v1\nlint:\n  plugins:\n    - plugin: acme\n      path: bin/acme\n"),
+			0600,
+		),
+	)
+	config, err = ReadConfigOS(ctx, readWriteBucket, ReadConfigOSWithOverride(overrideFilePath))
+	require.NoError(t, err)
+	require.Len(t, config.Lint.Plugins, 1)
+	absOverrideDirPath, err := filepath.Abs(overrideDirPath)
+	require.NoError(t, err)
+	require.Equal(t, absOverrideDirPath, config.Lint.Plugins[0].DirPath)
+}
@@ -0,0 +1,151 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconnect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/credentialhelper"
+	"github.com/bufbuild/buf/private/pkg/netrc"
+)
+
+const (
+	// credentialHelpersEnvKey is the environment variable key for the credential helpers.
+	credentialHelpersEnvKey = "BUF_CREDENTIAL_HELPERS"
+	// credentialHelperBinaryPrefix is the prefix of the binary name of a credential helper.
+	//
+	// The credential helper "foo" is run as the binary "buf-credential-foo".
+	credentialHelperBinaryPrefix = "buf-credential-"
+)
+
+// GetToken returns the token for the remote.
+//
+// The token is looked up in order from the BUF_TOKEN environment variable, the
+// credential helper configured for the remote, and netrc.
+//
+// BUF_TOKEN is either a single token used for all remotes, or a comma-separated
+// list of token@remote pairs, e.g. "tok1@buf.build,tok2@buf.example.com".
+//
+// Returns empty if there is no token for the remote.
+func GetToken(
+	ctx context.Context,
+	container app.EnvContainer,
+	runner command.Runner,
+	remote string,
+) (string, error) {
+	token, err := valueForRemote(tokenEnvKey, container.Env(tokenEnvKey), remote)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		return token, nil
+	}
+	credentials, err := GetStoredCredentials(ctx, container, runner, remote)
+	if err != nil {
+		return "", err
+	}
+	if credentials == nil {
+		return "", nil
+	}
+	return credentials.Secret, nil
+}
+
+// GetStoredCredentials returns the stored credentials for the remote.
+//
+// The credentials are read from the credential helper configured for the remote
+// if there is one, and from netrc otherwise.
+//
+// Returns nil if there are no stored credentials for the remote.
+func GetStoredCredentials(
+	ctx context.Context,
+	container app.EnvContainer,
+	runner command.Runner,
+	remote string,
+) (*credentialhelper.Credentials, error) {
+	helper, err := GetCredentialHelper(container, runner, remote)
+	if err != nil {
+		return nil, err
+	}
+	if helper != nil {
+		return helper.Get(ctx, remote)
+	}
+	machine, err := netrc.GetMachineForName(container, remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server password from netrc: %w", err)
+	}
+	if machine == nil {
+		return nil, nil
+	}
+	return &credentialhelper.Credentials{
+		ServerURL: machine.Name(),
+		Username:  machine.Login(),
+		Secret:    machine.Password(),
+	}, nil
+}
+
+// GetCredentialHelper returns the credential helper configured for the remote.
+//
+// BUF_CREDENTIAL_HELPERS is either a single credential helper name used for all
+// remotes, or a comma-separated list of name@remote pairs, e.g.
+// "osxkeychain@buf.build,pass@buf.example.com". The credential helper with
+// the name "foo" is run as the binary "buf-credential-foo".
+//
+// Returns nil if there is no credential helper configured for the remote.
+func GetCredentialHelper(
+	container app.EnvContainer,
+	runner command.Runner,
+	remote string,
+) (credentialhelper.Helper, error) {
+	name, err := valueForRemote(credentialHelpersEnvKey, container.Env(credentialHelpersEnvKey), remote)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, nil
+	}
+	return credentialhelper.NewHelper(container, runner, credentialHelperBinaryPrefix+name), nil
+}
+
+// valueForRemote returns the value for the remote from the value of the environment variable.
+//
+// If envValue does not contain "@", it is used for all remotes. Otherwise, it
+// is parsed as a comma-separated list of value@remote pairs.
+//
+// Returns empty if there is no value for the remote.
+func valueForRemote(envKey string, envValue string, remote string) (string, error) {
+	envValue = strings.TrimSpace(envValue)
+	if !strings.Contains(envValue, "@") {
+		return envValue, nil
+	}
+	var value string
+	for _, pair := range strings.Split(envValue, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		index := strings.LastIndex(pair, "@")
+		if index <= 0 || index == len(pair)-1 {
+			return "", fmt.Errorf("%s: invalid value, expected a comma-separated list of value@remote pairs", envKey)
+		}
+		if value == "" && pair[index+1:] == remote {
+			value = pair[:index]
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconnect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueForRemote(t *testing.T) {
+	t.Parallel()
+	testValueForRemote(t, "", "buf.build", "")
+	testValueForRemote(t, "tok1", "buf.build", "tok1")
+	testValueForRemote(t, "tok1", "buf.example.com", "tok1")
+	testValueForRemote(t, "tok1@buf.build", "buf.build", "tok1")
+	testValueForRemote(t, "tok1@buf.build", "buf.example.com", "")
+	testValueForRemote(t, "tok1@buf.build,tok2@buf.example.com", "buf.build", "tok1")
+	testValueForRemote(t, "tok1@buf.build,tok2@buf.example.com", "buf.example.com", "tok2")
+	testValueForRemote(t, "tok1@buf.build, tok2@buf.example.com,", "buf.example.com", "tok2")
+	testValueForRemote(t, "t@k1@buf.build", "buf.build", "t@k1")
+	testValueForRemoteError(t, "tok1@buf.build,tok2")
+	testValueForRemoteError(t, "tok1@")
+	testValueForRemoteError(t, "@buf.build")
+}
+
+func TestGetToken(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	runner := command.NewRunner()
+	netrcFilePath := filepath.Join(t.TempDir(), ".netrc")
+	require.NoError(
+		t,
+		os.WriteFile(
+			netrcFilePath,
+			[]byte("machine buf.build\nlogin foo\npassword netrctok\n"),
+			0600,
+		),
+	)
+
+	token, err := GetToken(ctx, app.NewEnvContainer(map[string]string{"NETRC": netrcFilePath}), runner, "buf.build")
+	require.NoError(t, err)
+	assert.Equal(t, "netrctok", token)
+	token, err = GetToken(ctx, app.NewEnvContainer(map[string]string{"NETRC": netrcFilePath}), runner, "buf.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "", token)
+	token, err = GetToken(
+		ctx,
+		app.NewEnvContainer(
+			map[string]string{
+				"NETRC":     netrcFilePath,
+				"BUF_TOKEN": "tok1@buf.example.com",
+			},
+		),
+		runner,
+		"buf.build",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "netrctok", token)
+	token, err = GetToken(
+		ctx,
+		app.NewEnvContainer(
+			map[string]string{
+				"NETRC":     netrcFilePath,
+				"BUF_TOKEN": "tok1@buf.example.com,tok2@buf.build",
+			},
+		),
+		runner,
+		"buf.build",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "tok2", token)
+	_, err = GetToken(
+		ctx,
+		app.NewEnvContainer(
+			map[string]string{
+				"NETRC":     netrcFilePath,
+				"BUF_TOKEN": "tok1@",
+			},
+		),
+		runner,
+		"buf.build",
+	)
+	assert.Error(t, err)
+}
+
+func TestGetTokenCredentialHelper(t *testing.T) {
+	// Not parallel as the credential helper is looked up on the PATH of the process.
+	if runtime.GOOS == "windows" {
+		t.Skip("test credential helpers are shell scripts")
+	}
+	ctx := context.Background()
+	runner := command.NewRunner()
+	dirPath := t.TempDir()
+	netrcFilePath := filepath.Join(dirPath, ".netrc")
+	require.NoError(
+		t,
+		os.WriteFile(
+			netrcFilePath,
+			[]byte("machine buf.build\nlogin foo\npassword netrctok\n"),
+			0600,
+		),
+	)
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(dirPath, credentialHelperBinaryPrefix+"test"),
+			[]byte(`#!/bin/sh
+read serverURL
+echo "{\"ServerURL\":\"${serverURL}\",\"Username\":\"foo\",\"Secret\":\"helpertok\"}"
+`),
+			0700,
+		),
+	)
+	t.Setenv("PATH", dirPath+string(os.PathListSeparator)+os.Getenv("PATH"))
+	env := map[string]string{
+		"NETRC":                  netrcFilePath,
+		"BUF_CREDENTIAL_HELPERS": "test@buf.build",
+	}
+
+	token, err := GetToken(ctx, app.NewEnvContainer(env), runner, "buf.build")
+	require.NoError(t, err)
+	assert.Equal(t, "helpertok", token)
+	token, err = GetToken(ctx, app.NewEnvContainer(env), runner, "buf.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "", token)
+	credentials, err := GetStoredCredentials(ctx, app.NewEnvContainer(env), runner, "buf.build")
+	require.NoError(t, err)
+	require.NotNil(t, credentials)
+	assert.Equal(t, "buf.build", credentials.ServerURL)
+	assert.Equal(t, "foo", credentials.Username)
+	env["BUF_TOKEN"] = "envtok"
+	token, err = GetToken(ctx, app.NewEnvContainer(env), runner, "buf.build")
+	require.NoError(t, err)
+	assert.Equal(t, "envtok", token)
+}
+
+func testValueForRemote(t *testing.T, envValue string, remote string, expected string) {
+	value, err := valueForRemote(tokenEnvKey, envValue, remote)
+	require.NoError(t, err)
+	assert.Equal(t, expected, value)
+}
+
+func testValueForRemoteError(t *testing.T, envValue string) {
+	_, err := valueForRemote(tokenEnvKey, envValue, "buf.build")
+	assert.Error(t, err)
+}
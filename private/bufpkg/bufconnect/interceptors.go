@@ -17,15 +17,20 @@ package bufconnect
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
-	"github.com/bufbuild/buf/private/pkg/app/appflag"
-	"github.com/bufbuild/buf/private/pkg/netrc"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/connect-go"
 )
 
 const (
 	// tokenEnvKey is the environment variable key for the auth token
 	tokenEnvKey = "BUF_TOKEN"
+	// tokenLookupTimeout is the maximum time to look up the auth token of a client,
+	// which may run a credential helper.
+	tokenLookupTimeout = time.Minute
 )
 
 // NewSetCLIVersionInterceptor returns a new Connect Interceptor that sets the Buf CLI version into all request headers
@@ -42,29 +47,64 @@ func NewSetCLIVersionInterceptor(version string) connect.UnaryInterceptorFunc {
 	return interceptor
 }
 
+// NewOfflineInterceptor returns a new Connect Interceptor that fails all requests without sending them.
+//
+// This is used when the network must not be accessed.
+func NewOfflineInterceptor() connect.UnaryInterceptorFunc {
+	interceptor := func(next connect.UnaryFunc) connect.UnaryFunc {
+		return connect.UnaryFunc(func(
+			ctx context.Context,
+			req connect.AnyRequest,
+		) (connect.AnyResponse, error) {
+			return nil, connect.NewError(
+				connect.CodeFailedPrecondition,
+				fmt.Errorf("cannot call %s in offline mode", req.Spec().Procedure),
+			)
+		})
+	}
+	return interceptor
+}
+
 // NewAuthorizationInterceptorProvider returns a new provider function which, when invoked, returns an interceptor
 // which will look up an auth token by address and set it into the request header.  This is used for registry providers
 // where the token is looked up by the client address at the time of client construction (i.e. for clients where a
-// user is already authenticated and the token is stored in .netrc)
+// user is already authenticated and the token is stored in .netrc or in a credential helper)
+//
+// The token is looked up with GetToken on the first request of each client, and reused for subsequent requests.
+// A failed lookup is retried on the next request. The lookup is not bound to the context of the request that
+// triggers it, as its result is shared by all requests of the client.
 //
 // Note that the interceptor returned from this provider is always applied LAST in the series of interceptors added to
 // a client.
-func NewAuthorizationInterceptorProvider(container appflag.Container) func(string) connect.UnaryInterceptorFunc {
+func NewAuthorizationInterceptorProvider(container app.EnvContainer, runner command.Runner) func(string) connect.UnaryInterceptorFunc {
 	return func(address string) connect.UnaryInterceptorFunc {
+		var lock sync.Mutex
+		var token string
+		var tokenFound bool
+		getToken := func() (string, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			if tokenFound {
+				return token, nil
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tokenLookupTimeout)
+			defer cancel()
+			lookedUpToken, err := GetToken(ctx, container, runner, address)
+			if err != nil {
+				return "", err
+			}
+			token = lookedUpToken
+			tokenFound = true
+			return token, nil
+		}
 		interceptor := func(next connect.UnaryFunc) connect.UnaryFunc {
 			return connect.UnaryFunc(func(
 				ctx context.Context,
 				req connect.AnyRequest,
 			) (connect.AnyResponse, error) {
-				token := container.Env(tokenEnvKey)
-				if token == "" {
-					machine, err := netrc.GetMachineForName(container, address)
-					if err != nil {
-						return nil, fmt.Errorf("failed to read server password from netrc: %w", err)
-					}
-					if machine != nil {
-						token = machine.Password()
-					}
+				token, err := getToken()
+				if err != nil {
+					return nil, err
 				}
 				if token != "" {
 					req.Header().Set(AuthenticationHeader, AuthenticationTokenPrefix+token)
@@ -0,0 +1,92 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconnect
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestAuthorizationInterceptorProvider(t *testing.T) {
+	// Not parallel as the credential helper is looked up on the PATH of the process.
+	if runtime.GOOS == "windows" {
+		t.Skip("test credential helpers are shell scripts")
+	}
+	dirPath := t.TempDir()
+	readyFilePath := filepath.Join(dirPath, "ready")
+	countFilePath := filepath.Join(dirPath, "count")
+	// The credential helper fails until the ready file exists, and records each
+	// successful run in the count file.
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(dirPath, credentialHelperBinaryPrefix+"test"),
+			[]byte(`#!/bin/sh
+read serverURL
+if [ ! -f "`+readyFilePath+`" ]; then
+  echo "not ready" >&2
+  exit 1
+fi
+echo run >> "`+countFilePath+`"
+echo "{\"ServerURL\":\"${serverURL}\",\"Username\":\"foo\",\"Secret\":\"helpertok\"}"
+`),
+			0700,
+		),
+	)
+	t.Setenv("PATH", dirPath+string(os.PathListSeparator)+os.Getenv("PATH"))
+	container := app.NewEnvContainer(
+		map[string]string{
+			"NETRC":                  filepath.Join(dirPath, ".netrc"),
+			"BUF_CREDENTIAL_HELPERS": "test@buf.build",
+		},
+	)
+	interceptor := NewAuthorizationInterceptorProvider(container, command.NewRunner())("buf.build")
+	var header http.Header
+	call := interceptor(
+		func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			header = req.Header()
+			return nil, nil
+		},
+	)
+
+	// A failed lookup is not cached.
+	_, err := call(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+	require.Error(t, err)
+	require.NoError(t, os.WriteFile(readyFilePath, nil, 0600))
+	// The lookup does not use the context of the request.
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = call(canceledCtx, connect.NewRequest(&emptypb.Empty{}))
+	require.NoError(t, err)
+	assert.Equal(t, AuthenticationTokenPrefix+"helpertok", header.Get(AuthenticationHeader))
+	// A successful lookup is cached.
+	_, err = call(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+	require.NoError(t, err)
+	assert.Equal(t, AuthenticationTokenPrefix+"helpertok", header.Get(AuthenticationHeader))
+	count, err := os.ReadFile(countFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, "run\n", string(count))
+}
@@ -22,6 +22,7 @@ import (
 	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/protodescriptor"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/types/descriptorpb"
 	"google.golang.org/protobuf/types/pluginpb"
 )
@@ -250,6 +251,40 @@ func NewImageForCodeGeneratorRequest(request *pluginpb.CodeGeneratorRequest) (Im
 	)
 }
 
+// NewImageForFileDescriptorSets returns a new Image for the files of the given FileDescriptorSets.
+//
+// A file can be contained in multiple FileDescriptorSets, but only if it is identical in each.
+// The files must be self-contained, that is all imports must be present and all references
+// must resolve. All files are non-imports, use ImageWithOnlyPaths to select the target files.
+//
+// Reorders the ImageFiles to be in DAG order.
+func NewImageForFileDescriptorSets(fileDescriptorSets ...*descriptorpb.FileDescriptorSet) (Image, error) {
+	fileDescriptorProtos, err := mergeFileDescriptorSets(fileDescriptorSets)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: fileDescriptorProtos}); err != nil {
+		return nil, fmt.Errorf("invalid FileDescriptorSet: %w", err)
+	}
+	imageFiles := make([]ImageFile, len(fileDescriptorProtos))
+	for i, fileDescriptorProto := range fileDescriptorProtos {
+		imageFile, err := NewImageFile(
+			fileDescriptorProto,
+			nil,
+			"",
+			"",
+			false,
+			false,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		imageFiles[i] = imageFile
+	}
+	return newImage(imageFiles, true)
+}
+
 // ImageWithoutImports returns a copy of the Image without imports.
 //
 // The backing Files are not copied.
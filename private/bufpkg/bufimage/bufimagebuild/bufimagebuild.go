@@ -55,3 +55,14 @@ func WithExcludeSourceCodeInfo() BuildOption {
 		buildOptions.excludeSourceCodeInfo = true
 	}
 }
+
+// WithDependencyImage returns a BuildOption that resolves files against the
+// given Image before the sources of the ModuleFileSet.
+//
+// Files resolved from the Image are not compiled. This mimics --descriptor_set_in
+// of protoc.
+func WithDependencyImage(dependencyImage bufimage.Image) BuildOption {
+	return func(buildOptions *buildOptions) {
+		buildOptions.dependencyImage = dependencyImage
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 type builder struct {
@@ -57,6 +58,7 @@ func (b *builder) Build(
 		ctx,
 		moduleFileSet,
 		buildOptions.excludeSourceCodeInfo,
+		buildOptions.dependencyImage,
 	)
 }
 
@@ -64,6 +66,7 @@ func (b *builder) build(
 	ctx context.Context,
 	moduleFileSet bufmodule.ModuleFileSet,
 	excludeSourceCodeInfo bool,
+	dependencyImage bufimage.Image,
 ) (bufimage.Image, []bufanalysis.FileAnnotation, error) {
 	ctx, span := trace.StartSpan(ctx, "build")
 	defer span.End()
@@ -86,6 +89,7 @@ func (b *builder) build(
 		parserAccessorHandler,
 		paths,
 		excludeSourceCodeInfo,
+		dependencyImage,
 	)
 	if buildResult.Err != nil {
 		return nil, nil, buildResult.Err
@@ -117,6 +121,7 @@ func getBuildResult(
 	parserAccessorHandler bufmoduleprotocompile.ParserAccessorHandler,
 	paths []string,
 	excludeSourceCodeInfo bool,
+	dependencyImage bufimage.Image,
 ) *buildResult {
 	var errorsWithPos []reporter.ErrorWithPos
 	var warningErrorsWithPos []reporter.ErrorWithPos
@@ -124,10 +129,25 @@ func getBuildResult(
 	if excludeSourceCodeInfo {
 		sourceInfoMode = protocompile.SourceInfoNone
 	}
+	var resolver protocompile.Resolver = &protocompile.SourceResolver{Accessor: parserAccessorHandler.Open}
+	if dependencyImage != nil {
+		resolver = protocompile.CompositeResolver{
+			protocompile.ResolverFunc(
+				func(path string) (protocompile.SearchResult, error) {
+					imageFile := dependencyImage.GetFile(path)
+					if imageFile == nil {
+						return protocompile.SearchResult{}, protoregistry.NotFound
+					}
+					return protocompile.SearchResult{Proto: imageFile.Proto()}, nil
+				},
+			),
+			resolver,
+		}
+	}
 	compiler := protocompile.Compiler{
 		MaxParallelism: thread.Parallelism(),
 		SourceInfoMode: sourceInfoMode,
-		Resolver:       &protocompile.SourceResolver{Accessor: parserAccessorHandler.Open},
+		Resolver:       resolver,
 		Reporter: reporter.NewReporter(
 			func(errorWithPos reporter.ErrorWithPos) error {
 				errorsWithPos = append(errorsWithPos, errorWithPos)
@@ -442,6 +462,7 @@ func newBuildResult(
 
 type buildOptions struct {
 	excludeSourceCodeInfo bool
+	dependencyImage       bufimage.Image
 }
 
 func newBuildOptions() *buildOptions {
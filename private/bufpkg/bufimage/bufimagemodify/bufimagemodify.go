@@ -90,6 +90,29 @@ func Merge(left Modifier, right Modifier) Modifier {
 	return NewMultiModifier(left, right)
 }
 
+// ModifierWithFileFilter returns a Modifier that only modifies the files of
+// the Image for which the filter returns true.
+//
+// This is used to disable a modifier for a subset of the files.
+func ModifierWithFileFilter(modifier Modifier, filter func(bufimage.ImageFile) bool) Modifier {
+	return newFileFilterModifier(modifier, filter)
+}
+
+// FileOptionValues returns a Modifier that sets the option of the modifier
+// with the given ID to the given values in the files contained in the Image.
+// The values are keyed by file path, and files without a value are not modified.
+// For field options, the value applies to all of the fields of the file.
+//
+// The values are parsed the same way as overrides. Unlike the modifier with the
+// given ID, module exceptions do not apply. Well-known types are not modified.
+func FileOptionValues(
+	sweeper Sweeper,
+	modifierID string,
+	values map[string]string,
+) (Modifier, error) {
+	return fileOptionValues(sweeper, modifierID, values)
+}
+
 // CcEnableArenas returns a Modifier that sets the cc_enable_arenas
 // file option to the given value in all of the files contained in
 // the Image.
@@ -106,6 +129,22 @@ func CcEnableArenas(
 	return ccEnableArenas(logger, sweeper, value, validatedOverrides), nil
 }
 
+// CcGenericServices returns a Modifier that sets the cc_generic_services
+// file option to the given value in all of the files contained in
+// the Image.
+func CcGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", CcGenericServicesID, err)
+	}
+	return ccGenericServices(logger, sweeper, value, validatedOverrides), nil
+}
+
 // GoPackage returns a Modifier that sets the go_package file option
 // according to the given defaultImportPathPrefix, exceptions, and
 // overrides.
@@ -127,6 +166,22 @@ func GoPackage(
 	)
 }
 
+// JavaGenericServices returns a Modifier that sets the java_generic_services
+// file option to the given value in all of the files contained in
+// the Image.
+func JavaGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", JavaGenericServicesID, err)
+	}
+	return javaGenericServices(logger, sweeper, value, validatedOverrides), nil
+}
+
 // JavaMultipleFiles returns a Modifier that sets the java_multiple_files
 // file option to the given value in all of the files contained in
 // the Image.
@@ -188,6 +243,22 @@ func JavaStringCheckUtf8(
 	return javaStringCheckUtf8(logger, sweeper, value, validatedOverrides), nil
 }
 
+// Jstype returns a Modifier that sets the jstype field option to the given
+// value in all of the 64-bit integer fields and extensions of the files contained
+// in the Image. The overrides apply to all of the fields of a file.
+func Jstype(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value descriptorpb.FieldOptions_JSType,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToJstypeOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", JstypeID, err)
+	}
+	return jstype(logger, sweeper, value, validatedOverrides), nil
+}
+
 // OptimizeFor returns a Modifier that sets the optimize_for file
 // option to the given value in all of the files contained in
 // the Image.
@@ -204,6 +275,38 @@ func OptimizeFor(
 	return optimizeFor(logger, sweeper, value, validatedOverrides), nil
 }
 
+// PhpGenericServices returns a Modifier that sets the php_generic_services
+// file option to the given value in all of the files contained in
+// the Image.
+func PhpGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", PhpGenericServicesID, err)
+	}
+	return phpGenericServices(logger, sweeper, value, validatedOverrides), nil
+}
+
+// PyGenericServices returns a Modifier that sets the py_generic_services
+// file option to the given value in all of the files contained in
+// the Image.
+func PyGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", PyGenericServicesID, err)
+	}
+	return pyGenericServices(logger, sweeper, value, validatedOverrides), nil
+}
+
 // GoPackageImportPathForFile returns the go_package import path for the given
 // ImageFile. If the package contains a version suffix, and if there are more
 // than two components, concatenate the final two components. Otherwise, we
@@ -223,6 +326,13 @@ func GoPackageImportPathForFile(imageFile bufimage.ImageFile, importPathPrefix s
 	return goPackageImportPath
 }
 
+// JavaPackageValueForFile returns the java_package for the given ImageFile
+// with the given package prefix. If the image file doesn't have a package
+// declaration, an empty string is returned.
+func JavaPackageValueForFile(imageFile bufimage.ImageFile, packagePrefix string) string {
+	return javaPackageValue(imageFile, packagePrefix)
+}
+
 // ObjcClassPrefix returns a Modifier that sets the objc_class_prefix file option
 // according to the package name. It is set to the uppercase first letter of each package sub-name,
 // not including the package version, with the following rules:
@@ -280,6 +390,17 @@ func RubyPackage(
 	return rubyPackage(logger, sweeper, overrides)
 }
 
+// SwiftPrefix returns a Modifier that sets the swift_prefix file option
+// to the given value in all of the files contained in the Image.
+func SwiftPrefix(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value string,
+	overrides map[string]string,
+) Modifier {
+	return swiftPrefix(logger, sweeper, value, overrides)
+}
+
 // isWellKnownType returns true if the given path is one of the well-known types.
 func isWellKnownType(ctx context.Context, imageFile bufimage.ImageFile) bool {
 	return datawkt.Exists(imageFile.Path())
@@ -321,3 +442,15 @@ func stringOverridesToOptimizeModeOverrides(stringOverrides map[string]string) (
 	}
 	return validatedOverrides, nil
 }
+
+func stringOverridesToJstypeOverrides(stringOverrides map[string]string) (map[string]descriptorpb.FieldOptions_JSType, error) {
+	validatedOverrides := make(map[string]descriptorpb.FieldOptions_JSType, len(stringOverrides))
+	for fileImportPath, stringOverride := range stringOverrides {
+		jstype, ok := descriptorpb.FieldOptions_JSType_value[stringOverride]
+		if !ok {
+			return nil, fmt.Errorf("invalid jstype %s set for file %s", stringOverride, fileImportPath)
+		}
+		validatedOverrides[fileImportPath] = descriptorpb.FieldOptions_JSType(jstype)
+	}
+	return validatedOverrides, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+)
+
+type fileFilterModifier struct {
+	delegate Modifier
+	filter   func(bufimage.ImageFile) bool
+}
+
+func newFileFilterModifier(
+	delegate Modifier,
+	filter func(bufimage.ImageFile) bool,
+) *fileFilterModifier {
+	return &fileFilterModifier{
+		delegate: delegate,
+		filter:   filter,
+	}
+}
+
+func (m *fileFilterModifier) Modify(
+	ctx context.Context,
+	image bufimage.Image,
+) error {
+	imageFiles := image.Files()
+	filteredImageFiles := make([]bufimage.ImageFile, 0, len(imageFiles))
+	for _, imageFile := range imageFiles {
+		if m.filter(imageFile) {
+			filteredImageFiles = append(filteredImageFiles, imageFile)
+		}
+	}
+	switch len(filteredImageFiles) {
+	case 0:
+		return nil
+	case len(imageFiles):
+		return m.delegate.Modify(ctx, image)
+	}
+	// The ImageFiles are shared with the original Image, so the
+	// modifications are reflected in the original Image.
+	filteredImage, err := bufimage.NewImage(filteredImageFiles)
+	if err != nil {
+		return err
+	}
+	return m.delegate.Modify(ctx, filteredImage)
+}
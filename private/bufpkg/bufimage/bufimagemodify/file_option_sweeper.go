@@ -68,10 +68,19 @@ func (s *fileOptionSweeper) Sweep(ctx context.Context, image bufimage.Image) err
 		// are structured as expect, and collect all of the indices that
 		// we need to delete.
 		indices := make(map[int]struct{}, len(paths)*2)
+		// The options of the elements of a file, for example field options, are all
+		// declared within a single pair of brackets, so their parent location is only
+		// removed if none of its other options remain.
+		elementOptionsParentPathKeys := make(map[string]struct{})
 		for i, location := range descriptor.SourceCodeInfo.Location {
 			if _, ok := paths[getPathKey(location.Path)]; !ok {
 				continue
 			}
+			if !isFileOptionPath(location.Path) {
+				indices[i] = struct{}{}
+				elementOptionsParentPathKeys[getPathKey(location.Path[:len(location.Path)-1])] = struct{}{}
+				continue
+			}
 			if i == 0 {
 				return fmt.Errorf("path %v must have a preceding parent path", location.Path)
 			}
@@ -82,6 +91,9 @@ func (s *fileOptionSweeper) Sweep(ctx context.Context, image bufimage.Image) err
 			indices[i-1] = struct{}{}
 			indices[i] = struct{}{}
 		}
+		if len(elementOptionsParentPathKeys) > 0 {
+			removeUnusedOptionsParentLocations(descriptor.SourceCodeInfo.Location, indices, elementOptionsParentPathKeys)
+		}
 		// Now that we know exactly which indices to exclude, we can
 		// filter the SourceCodeInfo_Locations as needed.
 		locations := make(
@@ -100,6 +112,40 @@ func (s *fileOptionSweeper) Sweep(ctx context.Context, image bufimage.Image) err
 	return nil
 }
 
+// isFileOptionPath returns true if the path is the path of a file option,
+// as opposed to the path of an option of an element of the file.
+func isFileOptionPath(path []int32) bool {
+	return len(path) == len(fileOptionPath)+1 && int32SliceIsEqual(path[:len(fileOptionPath)], fileOptionPath)
+}
+
+// removeUnusedOptionsParentLocations adds the indices of the options parent locations
+// for the given parent path keys that no longer have any child location.
+func removeUnusedOptionsParentLocations(
+	locations []*descriptorpb.SourceCodeInfo_Location,
+	indices map[int]struct{},
+	parentPathKeys map[string]struct{},
+) {
+	usedParentPathKeys := make(map[string]struct{}, len(parentPathKeys))
+	for i, location := range locations {
+		if _, ok := indices[i]; ok || len(location.Path) == 0 {
+			continue
+		}
+		parentPathKey := getPathKey(location.Path[:len(location.Path)-1])
+		if _, ok := parentPathKeys[parentPathKey]; ok {
+			usedParentPathKeys[parentPathKey] = struct{}{}
+		}
+	}
+	for i, location := range locations {
+		pathKey := getPathKey(location.Path)
+		if _, ok := parentPathKeys[pathKey]; !ok {
+			continue
+		}
+		if _, ok := usedParentPathKeys[pathKey]; !ok {
+			indices[i] = struct{}{}
+		}
+	}
+}
+
 // getPathKey returns a unique key for the given path.
 func getPathKey(path []int32) string {
 	key := make([]byte, len(path)*4)
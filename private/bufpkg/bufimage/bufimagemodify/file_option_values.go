@@ -0,0 +1,131 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+)
+
+func fileOptionValues(
+	sweeper Sweeper,
+	modifierID string,
+	values map[string]string,
+) (Modifier, error) {
+	forFile, err := newFileOptionValueFunc(modifierID, values)
+	if err != nil {
+		return nil, err
+	}
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			for _, imageFile := range image.Files() {
+				if _, ok := values[imageFile.Path()]; !ok {
+					continue
+				}
+				if err := forFile(ctx, sweeper, imageFile); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	), nil
+}
+
+// newFileOptionValueFunc returns a function that sets the option of the
+// modifier with the given ID to the value of the given ImageFile.
+func newFileOptionValueFunc(
+	modifierID string,
+	values map[string]string,
+) (func(context.Context, Sweeper, bufimage.ImageFile) error, error) {
+	switch modifierID {
+	case CcEnableArenasID,
+		CcGenericServicesID,
+		JavaGenericServicesID,
+		JavaMultipleFilesID,
+		JavaStringCheckUtf8ID,
+		PhpGenericServicesID,
+		PyGenericServicesID:
+		boolValues, err := stringOverridesToBoolOverrides(values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", modifierID, err)
+		}
+		boolForFile := ccEnableArenasForFile
+		switch modifierID {
+		case CcGenericServicesID:
+			boolForFile = ccGenericServicesForFile
+		case JavaGenericServicesID:
+			boolForFile = javaGenericServicesForFile
+		case JavaMultipleFilesID:
+			boolForFile = javaMultipleFilesForFile
+		case JavaStringCheckUtf8ID:
+			boolForFile = javaStringCheckUtf8ForFile
+		case PhpGenericServicesID:
+			boolForFile = phpGenericServicesForFile
+		case PyGenericServicesID:
+			boolForFile = pyGenericServicesForFile
+		}
+		return func(ctx context.Context, sweeper Sweeper, imageFile bufimage.ImageFile) error {
+			return boolForFile(ctx, sweeper, imageFile, boolValues[imageFile.Path()])
+		}, nil
+	case OptimizeForID:
+		optimizeModeValues, err := stringOverridesToOptimizeModeOverrides(values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", modifierID, err)
+		}
+		return func(ctx context.Context, sweeper Sweeper, imageFile bufimage.ImageFile) error {
+			return optimizeForForFile(ctx, sweeper, imageFile, optimizeModeValues[imageFile.Path()])
+		}, nil
+	case JstypeID:
+		jstypeValues, err := stringOverridesToJstypeOverrides(values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", modifierID, err)
+		}
+		return func(ctx context.Context, sweeper Sweeper, imageFile bufimage.ImageFile) error {
+			return jstypeForFile(ctx, sweeper, imageFile, jstypeValues[imageFile.Path()])
+		}, nil
+	case GoPackageID:
+		return func(ctx context.Context, sweeper Sweeper, imageFile bufimage.ImageFile) error {
+			return goPackageForFile(ctx, sweeper, imageFile, values[imageFile.Path()], nil)
+		}, nil
+	case JavaPackageID:
+		return func(ctx context.Context, sweeper Sweeper, imageFile bufimage.ImageFile) error {
+			return javaPackageForFile(ctx, sweeper, imageFile, values[imageFile.Path()], nil)
+		}, nil
+	}
+	var stringForFile func(context.Context, Sweeper, bufimage.ImageFile, string) error
+	switch modifierID {
+	case CsharpNamespaceID:
+		stringForFile = csharpNamespaceForFile
+	case JavaOuterClassNameID:
+		stringForFile = javaOuterClassnameForFile
+	case ObjcClassPrefixID:
+		stringForFile = objcClassPrefixForFile
+	case PhpMetadataNamespaceID:
+		stringForFile = phpMetadataNamespaceForFile
+	case PhpNamespaceID:
+		stringForFile = phpNamespaceForFile
+	case RubyPackageID:
+		stringForFile = rubyPackageForFile
+	case SwiftPrefixID:
+		stringForFile = swiftPrefixForFile
+	default:
+		return nil, fmt.Errorf("unknown modifier ID %q", modifierID)
+	}
+	return func(ctx context.Context, sweeper Sweeper, imageFile bufimage.ImageFile) error {
+		return stringForFile(ctx, sweeper, imageFile, values[imageFile.Path()])
+	}, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFileOptionValues(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "packageversion")
+	t.Run("string option", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoNotEmpty(t, image, goPackagePath)
+
+		sweeper := NewFileOptionSweeper()
+		goPackageModifier, err := FileOptionValues(sweeper, GoPackageID, map[string]string{"a.proto": "foo"})
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(goPackageModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", image.GetFile("a.proto").Proto().GetOptions().GetGoPackage())
+		assert.Equal(t, "weather", image.GetFile("b.proto").Proto().GetOptions().GetGoPackage())
+		assertFileOptionSourceCodeInfoEmpty(t, testImageWithOnlyFile(t, image, "a.proto"), goPackagePath, true)
+		assertFileOptionSourceCodeInfoNotEmpty(t, testImageWithOnlyFile(t, image, "b.proto"), goPackagePath)
+	})
+
+	t.Run("bool option", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+
+		sweeper := NewFileOptionSweeper()
+		modifier, err := FileOptionValues(sweeper, JavaMultipleFilesID, map[string]string{"b.proto": "true"})
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.False(t, image.GetFile("a.proto").Proto().GetOptions().GetJavaMultipleFiles())
+		assert.True(t, image.GetFile("b.proto").Proto().GetOptions().GetJavaMultipleFiles())
+	})
+
+	t.Run("optimize_for option", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+
+		sweeper := NewFileOptionSweeper()
+		modifier, err := FileOptionValues(sweeper, OptimizeForID, map[string]string{"a.proto": "LITE_RUNTIME"})
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, descriptorpb.FileOptions_LITE_RUNTIME, image.GetFile("a.proto").Proto().GetOptions().GetOptimizeFor())
+		assert.Equal(t, descriptorpb.FileOptions_SPEED, image.GetFile("b.proto").Proto().GetOptions().GetOptimizeFor())
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		t.Parallel()
+		sweeper := NewFileOptionSweeper()
+		_, err := FileOptionValues(sweeper, CcEnableArenasID, map[string]string{"a.proto": "foo"})
+		require.Error(t, err)
+		_, err = FileOptionValues(sweeper, OptimizeForID, map[string]string{"a.proto": "foo"})
+		require.Error(t, err)
+		_, err = FileOptionValues(sweeper, "FOO", map[string]string{"a.proto": "foo"})
+		require.Error(t, err)
+	})
+}
+
+func TestModifierWithFileFilter(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "packageversion")
+	image := testGetImage(t, dirPath, true)
+
+	sweeper := NewFileOptionSweeper()
+	csharpNamespaceModifier := ModifierWithFileFilter(
+		CsharpNamespace(zap.NewNop(), sweeper, nil),
+		func(imageFile bufimage.ImageFile) bool {
+			return imageFile.Path() != "b.proto"
+		},
+	)
+	modifier := NewMultiModifier(csharpNamespaceModifier, ModifierFunc(sweeper.Sweep))
+	err := modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "Weather.V1alpha1", image.GetFile("a.proto").Proto().GetOptions().GetCsharpNamespace())
+	assert.Empty(t, image.GetFile("b.proto").Proto().GetOptions().GetCsharpNamespace())
+
+	// If all of the files are filtered out, the modifier is not run.
+	err = ModifierWithFileFilter(
+		ModifierFunc(func(context.Context, bufimage.Image) error {
+			t.Fatal("modifier should not be run")
+			return nil
+		}),
+		func(bufimage.ImageFile) bool {
+			return false
+		},
+	).Modify(context.Background(), image)
+	require.NoError(t, err)
+}
+
+func testImageWithOnlyFile(t *testing.T, image bufimage.Image, path string) bufimage.Image {
+	imageFile := image.GetFile(path)
+	require.NotNil(t, imageFile)
+	image, err := bufimage.NewImage([]bufimage.ImageFile{imageFile})
+	require.NoError(t, err)
+	return image
+}
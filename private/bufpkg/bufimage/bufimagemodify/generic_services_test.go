@@ -0,0 +1,166 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type testGenericServicesModifier struct {
+	name        string
+	newModifier func(*zap.Logger, Sweeper, bool, map[string]string) (Modifier, error)
+	path        []int32
+	getValue    func(*descriptorpb.FileOptions) bool
+}
+
+var testGenericServicesModifiers = []testGenericServicesModifier{
+	{
+		name:        "cc_generic_services",
+		newModifier: CcGenericServices,
+		path:        ccGenericServicesPath,
+		getValue:    (*descriptorpb.FileOptions).GetCcGenericServices,
+	},
+	{
+		name:        "java_generic_services",
+		newModifier: JavaGenericServices,
+		path:        javaGenericServicesPath,
+		getValue:    (*descriptorpb.FileOptions).GetJavaGenericServices,
+	},
+	{
+		name:        "php_generic_services",
+		newModifier: PhpGenericServices,
+		path:        phpGenericServicesPath,
+		getValue:    (*descriptorpb.FileOptions).GetPhpGenericServices,
+	},
+	{
+		name:        "py_generic_services",
+		newModifier: PyGenericServices,
+		path:        pyGenericServicesPath,
+		getValue:    (*descriptorpb.FileOptions).GetPyGenericServices,
+	},
+}
+
+func TestGenericServicesEmptyOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "emptyoptions")
+	for _, testModifier := range testGenericServicesModifiers {
+		testModifier := testModifier
+		t.Run(testModifier.name, func(t *testing.T) {
+			t.Parallel()
+			image := testGetImage(t, dirPath, true)
+			assertFileOptionSourceCodeInfoEmpty(t, image, testModifier.path, true)
+
+			sweeper := NewFileOptionSweeper()
+			genericServicesModifier, err := testModifier.newModifier(zap.NewNop(), sweeper, false, nil)
+			require.NoError(t, err)
+			modifier := NewMultiModifier(genericServicesModifier, ModifierFunc(sweeper.Sweep))
+			err = modifier.Modify(
+				context.Background(),
+				image,
+			)
+			require.NoError(t, err)
+			// The value is the default, so nothing is modified.
+			assert.Equal(t, testGetImage(t, dirPath, true), image)
+		})
+	}
+}
+
+func TestGenericServicesAllOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "alloptions")
+	for _, testModifier := range testGenericServicesModifiers {
+		testModifier := testModifier
+		t.Run(testModifier.name, func(t *testing.T) {
+			t.Parallel()
+			image := testGetImage(t, dirPath, true)
+			assertFileOptionSourceCodeInfoNotEmpty(t, image, testModifier.path)
+
+			sweeper := NewFileOptionSweeper()
+			genericServicesModifier, err := testModifier.newModifier(zap.NewNop(), sweeper, true, nil)
+			require.NoError(t, err)
+			modifier := NewMultiModifier(genericServicesModifier, ModifierFunc(sweeper.Sweep))
+			err = modifier.Modify(
+				context.Background(),
+				image,
+			)
+			require.NoError(t, err)
+
+			for _, imageFile := range image.Files() {
+				assert.True(t, testModifier.getValue(imageFile.Proto().GetOptions()))
+			}
+			assertFileOptionSourceCodeInfoEmpty(t, image, testModifier.path, true)
+		})
+		t.Run(testModifier.name+" with per-file overrides", func(t *testing.T) {
+			t.Parallel()
+			image := testGetImage(t, dirPath, false)
+
+			sweeper := NewFileOptionSweeper()
+			modifier, err := testModifier.newModifier(zap.NewNop(), sweeper, true, map[string]string{"a.proto": "false"})
+			require.NoError(t, err)
+			err = modifier.Modify(
+				context.Background(),
+				image,
+			)
+			require.NoError(t, err)
+
+			for _, imageFile := range image.Files() {
+				assert.False(t, testModifier.getValue(imageFile.Proto().GetOptions()))
+			}
+		})
+		t.Run(testModifier.name+" with invalid overrides", func(t *testing.T) {
+			t.Parallel()
+			_, err := testModifier.newModifier(zap.NewNop(), NewFileOptionSweeper(), true, map[string]string{"a.proto": "foo"})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGenericServicesWellKnownTypes(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "wktimport")
+	for _, testModifier := range testGenericServicesModifiers {
+		testModifier := testModifier
+		t.Run(testModifier.name, func(t *testing.T) {
+			t.Parallel()
+			image := testGetImage(t, dirPath, true)
+
+			sweeper := NewFileOptionSweeper()
+			genericServicesModifier, err := testModifier.newModifier(zap.NewNop(), sweeper, true, nil)
+			require.NoError(t, err)
+			modifier := NewMultiModifier(genericServicesModifier, ModifierFunc(sweeper.Sweep))
+			err = modifier.Modify(
+				context.Background(),
+				image,
+			)
+			require.NoError(t, err)
+
+			for _, imageFile := range image.Files() {
+				descriptor := imageFile.Proto()
+				if isWellKnownType(context.Background(), imageFile) {
+					assert.False(t, testModifier.getValue(descriptor.GetOptions()))
+					continue
+				}
+				assert.True(t, testModifier.getValue(descriptor.GetOptions()))
+			}
+		})
+	}
+}
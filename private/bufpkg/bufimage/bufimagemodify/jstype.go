@@ -0,0 +1,147 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// JstypeID is the ID of the jstype modifier.
+const JstypeID = "JSTYPE"
+
+// The field numbers of the descriptor fields that contain fields, used
+// as elements of SourceCodeInfo paths.
+const (
+	fileMessagesTag          = 4
+	fileExtensionsTag        = 7
+	messageFieldsTag         = 2
+	messageNestedMessagesTag = 3
+	messageExtensionsTag     = 6
+)
+
+// jstypeFieldOptionPath is the SourceCodeInfo path for the jstype option,
+// relative to the path of its field.
+var jstypeFieldOptionPath = []int32{8, 6}
+
+func jstype(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value descriptorpb.FieldOptions_JSType,
+	overrides map[string]descriptorpb.FieldOptions_JSType,
+) Modifier {
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			seenOverrideFiles := make(map[string]struct{}, len(overrides))
+			for _, imageFile := range image.Files() {
+				modifierValue := value
+				if overrideValue, ok := overrides[imageFile.Path()]; ok {
+					modifierValue = overrideValue
+					seenOverrideFiles[imageFile.Path()] = struct{}{}
+				}
+				if err := jstypeForFile(ctx, sweeper, imageFile, modifierValue); err != nil {
+					return err
+				}
+			}
+			for overrideFile := range overrides {
+				if _, ok := seenOverrideFiles[overrideFile]; !ok {
+					logger.Sugar().Warnf("%s override for %q was unused", JstypeID, overrideFile)
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// jstypeForFile sets the jstype option of all of the 64-bit integer fields
+// and extensions of the file, including those of nested messages.
+func jstypeForFile(
+	ctx context.Context,
+	sweeper Sweeper,
+	imageFile bufimage.ImageFile,
+	value descriptorpb.FieldOptions_JSType,
+) error {
+	if isWellKnownType(ctx, imageFile) {
+		// The file is a well-known type, don't do anything.
+		return nil
+	}
+	descriptor := imageFile.Proto()
+	for i, extension := range descriptor.GetExtension() {
+		jstypeForField(sweeper, imageFile.Path(), extension, value, []int32{fileExtensionsTag, int32(i)})
+	}
+	for i, message := range descriptor.GetMessageType() {
+		jstypeForMessage(sweeper, imageFile.Path(), message, value, []int32{fileMessagesTag, int32(i)})
+	}
+	return nil
+}
+
+func jstypeForMessage(
+	sweeper Sweeper,
+	imageFilePath string,
+	message *descriptorpb.DescriptorProto,
+	value descriptorpb.FieldOptions_JSType,
+	messagePath []int32,
+) {
+	for i, field := range message.GetField() {
+		jstypeForField(sweeper, imageFilePath, field, value, appendPath(messagePath, messageFieldsTag, int32(i)))
+	}
+	for i, extension := range message.GetExtension() {
+		jstypeForField(sweeper, imageFilePath, extension, value, appendPath(messagePath, messageExtensionsTag, int32(i)))
+	}
+	for i, nestedMessage := range message.GetNestedType() {
+		jstypeForMessage(sweeper, imageFilePath, nestedMessage, value, appendPath(messagePath, messageNestedMessagesTag, int32(i)))
+	}
+}
+
+func jstypeForField(
+	sweeper Sweeper,
+	imageFilePath string,
+	field *descriptorpb.FieldDescriptorProto,
+	value descriptorpb.FieldOptions_JSType,
+	fieldPath []int32,
+) {
+	switch field.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+	default:
+		// The jstype option only applies to 64-bit integer fields.
+		return
+	}
+	if field.GetOptions().GetJstype() == value {
+		// The option is already set to the same value, or the option is
+		// not set and the value is the default, don't do anything.
+		return
+	}
+	if field.Options == nil {
+		field.Options = &descriptorpb.FieldOptions{}
+	}
+	field.Options.Jstype = value.Enum()
+	if sweeper != nil {
+		sweeper.mark(imageFilePath, appendPath(fieldPath, jstypeFieldOptionPath...))
+	}
+}
+
+// appendPath returns a new path with the elements appended to the path.
+func appendPath(path []int32, elements ...int32) []int32 {
+	newPath := make([]int32, 0, len(path)+len(elements))
+	newPath = append(newPath, path...)
+	return append(newPath, elements...)
+}
@@ -0,0 +1,180 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestJstype(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "jstypeoptions")
+	t.Run("with SourceCodeInfo", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 1, 8}, true)
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 1, 8, 6}, true)
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 2, 8, 6}, true)
+
+		sweeper := NewFileOptionSweeper()
+		jstypeModifier, err := Jstype(zap.NewNop(), sweeper, descriptorpb.FieldOptions_JS_STRING, nil)
+		require.NoError(t, err)
+		modifier := NewMultiModifier(jstypeModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		assertJstypes(
+			t,
+			image,
+			map[string]*descriptorpb.FieldOptions_JSType{
+				"a": descriptorpb.FieldOptions_JS_STRING.Enum(),
+				"b": descriptorpb.FieldOptions_JS_STRING.Enum(),
+				"c": descriptorpb.FieldOptions_JS_STRING.Enum(),
+				"d": nil,
+				"e": nil,
+				"f": descriptorpb.FieldOptions_JS_STRING.Enum(),
+				"g": descriptorpb.FieldOptions_JS_STRING.Enum(),
+				"h": descriptorpb.FieldOptions_JS_STRING.Enum(),
+			},
+		)
+		// The options of b only contained jstype, so the location of its options is removed.
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 1, 8}, false)
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 1, 8, 6}, false)
+		// The options of c also contain deprecated, so the location of its options is kept.
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 2, 8}, true)
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 2, 8, 3}, true)
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 2, 8, 6}, false)
+	})
+
+	t.Run("with default value", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+
+		sweeper := NewFileOptionSweeper()
+		jstypeModifier, err := Jstype(zap.NewNop(), sweeper, descriptorpb.FieldOptions_JS_NORMAL, nil)
+		require.NoError(t, err)
+		modifier := NewMultiModifier(jstypeModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		assertJstypes(
+			t,
+			image,
+			map[string]*descriptorpb.FieldOptions_JSType{
+				"a": nil,
+				"b": descriptorpb.FieldOptions_JS_NORMAL.Enum(),
+				"c": descriptorpb.FieldOptions_JS_NORMAL.Enum(),
+				"d": nil,
+				"e": nil,
+				"f": nil,
+				"g": nil,
+				"h": nil,
+			},
+		)
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 1, 8, 6}, false)
+		assertSourceCodeInfoHasPath(t, image, []int32{4, 0, 2, 2, 8, 6}, true)
+	})
+
+	t.Run("without SourceCodeInfo and with per-file overrides", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+
+		sweeper := NewFileOptionSweeper()
+		modifier, err := Jstype(zap.NewNop(), sweeper, descriptorpb.FieldOptions_JS_STRING, map[string]string{"a.proto": "JS_NUMBER"})
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		assertJstypes(
+			t,
+			image,
+			map[string]*descriptorpb.FieldOptions_JSType{
+				"a": descriptorpb.FieldOptions_JS_NUMBER.Enum(),
+				"b": descriptorpb.FieldOptions_JS_NUMBER.Enum(),
+				"c": descriptorpb.FieldOptions_JS_NUMBER.Enum(),
+				"d": nil,
+				"e": nil,
+				"f": descriptorpb.FieldOptions_JS_NUMBER.Enum(),
+				"g": descriptorpb.FieldOptions_JS_NUMBER.Enum(),
+				"h": descriptorpb.FieldOptions_JS_NUMBER.Enum(),
+			},
+		)
+	})
+
+	t.Run("with invalid overrides", func(t *testing.T) {
+		t.Parallel()
+		_, err := Jstype(zap.NewNop(), NewFileOptionSweeper(), descriptorpb.FieldOptions_JS_STRING, map[string]string{"a.proto": "foo"})
+		require.Error(t, err)
+	})
+}
+
+func assertJstypes(t *testing.T, image bufimage.Image, expected map[string]*descriptorpb.FieldOptions_JSType) {
+	fieldNameToJstype := make(map[string]*descriptorpb.FieldOptions_JSType)
+	var addMessageFields func(*descriptorpb.DescriptorProto)
+	addMessageFields = func(message *descriptorpb.DescriptorProto) {
+		for _, field := range append(message.GetField(), message.GetExtension()...) {
+			fieldNameToJstype[field.GetName()] = fieldJstype(field)
+		}
+		for _, nestedMessage := range message.GetNestedType() {
+			addMessageFields(nestedMessage)
+		}
+	}
+	for _, imageFile := range image.Files() {
+		for _, extension := range imageFile.Proto().GetExtension() {
+			fieldNameToJstype[extension.GetName()] = fieldJstype(extension)
+		}
+		for _, message := range imageFile.Proto().GetMessageType() {
+			addMessageFields(message)
+		}
+	}
+	assert.Equal(t, expected, fieldNameToJstype)
+}
+
+func fieldJstype(field *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldOptions_JSType {
+	if field.GetOptions() == nil {
+		return nil
+	}
+	return field.GetOptions().Jstype
+}
+
+func assertSourceCodeInfoHasPath(t *testing.T, image bufimage.Image, path []int32, expected bool) {
+	for _, imageFile := range image.Files() {
+		var hasPath bool
+		for _, location := range imageFile.Proto().GetSourceCodeInfo().GetLocation() {
+			if int32SliceIsEqual(location.Path, path) {
+				hasPath = true
+				break
+			}
+		}
+		assert.Equal(t, expected, hasPath, "%v", path)
+	}
+}
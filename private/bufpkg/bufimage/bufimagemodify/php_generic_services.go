@@ -0,0 +1,90 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// PhpGenericServicesID is the ID of the php_generic_services modifier.
+const PhpGenericServicesID = "PHP_GENERIC_SERVICES"
+
+// phpGenericServicesPath is the SourceCodeInfo path for the php_generic_services option.
+// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto#L410
+var phpGenericServicesPath = []int32{8, 42}
+
+func phpGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	overrides map[string]bool,
+) Modifier {
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			seenOverrideFiles := make(map[string]struct{}, len(overrides))
+			for _, imageFile := range image.Files() {
+				modifierValue := value
+				if overrideValue, ok := overrides[imageFile.Path()]; ok {
+					modifierValue = overrideValue
+					seenOverrideFiles[imageFile.Path()] = struct{}{}
+				}
+				if err := phpGenericServicesForFile(ctx, sweeper, imageFile, modifierValue); err != nil {
+					return err
+				}
+			}
+			for overrideFile := range overrides {
+				if _, ok := seenOverrideFiles[overrideFile]; !ok {
+					logger.Sugar().Warnf("%s override for %q was unused", PhpGenericServicesID, overrideFile)
+				}
+			}
+			return nil
+		},
+	)
+}
+
+func phpGenericServicesForFile(
+	ctx context.Context,
+	sweeper Sweeper,
+	imageFile bufimage.ImageFile,
+	value bool,
+) error {
+	descriptor := imageFile.Proto()
+	options := descriptor.GetOptions()
+	switch {
+	case isWellKnownType(ctx, imageFile):
+		// The file is a well-known type, don't do anything.
+		return nil
+	case options != nil && options.GetPhpGenericServices() == value:
+		// The option is already set to the same value, don't do anything.
+		return nil
+	case options == nil && descriptorpb.Default_FileOptions_PhpGenericServices == value:
+		// The option is not set, but the value we want to set is the
+		// same as the default, don't do anything.
+		return nil
+	}
+	if options == nil {
+		descriptor.Options = &descriptorpb.FileOptions{}
+	}
+	descriptor.Options.PhpGenericServices = proto.Bool(value)
+	if sweeper != nil {
+		sweeper.mark(imageFile.Path(), phpGenericServicesPath)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSwiftPrefixEmptyOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "emptyoptions")
+	t.Run("with empty value", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoEmpty(t, image, swiftPrefixPath, true)
+
+		sweeper := NewFileOptionSweeper()
+		swiftPrefixModifier := SwiftPrefix(zap.NewNop(), sweeper, "", nil)
+
+		modifier := NewMultiModifier(swiftPrefixModifier, ModifierFunc(sweeper.Sweep))
+		err := modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testGetImage(t, dirPath, true), image)
+	})
+
+	t.Run("with value", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+		assertFileOptionSourceCodeInfoEmpty(t, image, swiftPrefixPath, false)
+
+		sweeper := NewFileOptionSweeper()
+		modifier := SwiftPrefix(zap.NewNop(), sweeper, "Acme", nil)
+		err := modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		for _, imageFile := range image.Files() {
+			assert.Equal(t, "Acme", imageFile.Proto().GetOptions().GetSwiftPrefix())
+		}
+	})
+
+	t.Run("with per-file overrides", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+
+		sweeper := NewFileOptionSweeper()
+		modifier := SwiftPrefix(zap.NewNop(), sweeper, "Acme", map[string]string{"a.proto": "override"})
+		err := modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(image.Files()))
+		descriptor := image.Files()[0].Proto()
+		require.Equal(t, "override", descriptor.GetOptions().GetSwiftPrefix())
+	})
+}
+
+func TestSwiftPrefixAllOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "alloptions")
+	t.Run("with SourceCodeInfo", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoNotEmpty(t, image, swiftPrefixPath)
+
+		sweeper := NewFileOptionSweeper()
+		swiftPrefixModifier := SwiftPrefix(zap.NewNop(), sweeper, "Acme", nil)
+
+		modifier := NewMultiModifier(swiftPrefixModifier, ModifierFunc(sweeper.Sweep))
+		err := modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		for _, imageFile := range image.Files() {
+			descriptor := imageFile.Proto()
+			assert.Equal(t, "Acme", descriptor.GetOptions().GetSwiftPrefix())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, swiftPrefixPath, true)
+	})
+
+	t.Run("with empty value", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+
+		sweeper := NewFileOptionSweeper()
+		swiftPrefixModifier := SwiftPrefix(zap.NewNop(), sweeper, "", nil)
+
+		modifier := NewMultiModifier(swiftPrefixModifier, ModifierFunc(sweeper.Sweep))
+		err := modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		for _, imageFile := range image.Files() {
+			descriptor := imageFile.Proto()
+			assert.Equal(t, "foo", descriptor.GetOptions().GetSwiftPrefix())
+		}
+		assertFileOptionSourceCodeInfoNotEmpty(t, image, swiftPrefixPath)
+	})
+}
+
+func TestSwiftPrefixWellKnownTypes(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "wktimport")
+	image := testGetImage(t, dirPath, true)
+
+	sweeper := NewFileOptionSweeper()
+	swiftPrefixModifier := SwiftPrefix(zap.NewNop(), sweeper, "Acme", nil)
+
+	modifier := NewMultiModifier(swiftPrefixModifier, ModifierFunc(sweeper.Sweep))
+	err := modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+
+	for _, imageFile := range image.Files() {
+		descriptor := imageFile.Proto()
+		if isWellKnownType(context.Background(), imageFile) {
+			assert.NotEqual(t, "Acme", descriptor.GetOptions().GetSwiftPrefix())
+			continue
+		}
+		assert.Equal(t, "Acme", descriptor.GetOptions().GetSwiftPrefix())
+	}
+}
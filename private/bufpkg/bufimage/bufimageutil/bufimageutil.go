@@ -18,6 +18,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/protosource"
@@ -75,7 +77,8 @@ func FreeMessageRangeStrings(
 // ImageFilteredByTypes returns a minimal image containing only the descriptors
 // required to define those types. The resulting contains only files in which
 // those descriptors and their transitive closure of required descriptors, with
-// each file only contains the minimal required types and imports.
+// each file only contains the minimal required types and imports. The source
+// code info of the files is kept for the elements that are retained.
 //
 // Although this returns a new bufimage.Image, it mutates the original image's
 // underlying file's `descriptorpb.FileDescriptorProto` and the old image should
@@ -98,9 +101,15 @@ func FreeMessageRangeStrings(
 //	 - the parent message if this message is a nested definition
 //
 //	Services
-//	 - request & response types referenced in methods
-//	 - custom options for the service, its methods, and the file
-//	   in which the message is defined
+//	 - all methods of the service that are not excluded
+//	 - custom options for the service, and the file in which the
+//	   service is defined
+//
+//	Methods
+//	 - request & response types referenced in the method
+//	 - the service in which the method is defined, without its other methods
+//	 - custom options for the method, and the file in which the
+//	   method is defined
 //
 // As an example, consider the following proto structure:
 //
@@ -141,10 +150,46 @@ func FreeMessageRangeStrings(
 //	 messages:   [pkg.Baz, other.Quux, other.Qux]
 //	 extensions: [other.my_option]
 func ImageFilteredByTypes(image bufimage.Image, types ...string) (bufimage.Image, error) {
+	return ImageFilteredByTypesWithOptions(image, types)
+}
+
+// ImageFilterOption is an option for ImageFilteredByTypesWithOptions.
+type ImageFilterOption func(*imageFilterOptions)
+
+// WithExcludeTypes returns a new ImageFilterOption that excludes the given
+// fully-qualified type names, and the types nested in them, from the filtered image.
+// Types that are only required by excluded types are not included either.
+//
+// The excluded types must exist in the image. If an excluded type is required
+// by a type that is included, an error is returned. Extensions of an included
+// message and methods of an included service that are excluded are omitted.
+func WithExcludeTypes(typeNames ...string) ImageFilterOption {
+	return func(imageFilterOptions *imageFilterOptions) {
+		imageFilterOptions.excludeTypes = append(imageFilterOptions.excludeTypes, typeNames...)
+	}
+}
+
+// ImageFilteredByTypesWithOptions is like ImageFilteredByTypes, but also
+// accepts ImageFilterOptions.
+//
+// If types is empty, all types declared in the non-import files of the image
+// that are not excluded are used.
+func ImageFilteredByTypesWithOptions(image bufimage.Image, types []string, options ...ImageFilterOption) (bufimage.Image, error) {
+	imageFilterOptions := newImageFilterOptions()
+	for _, option := range options {
+		option(imageFilterOptions)
+	}
 	imageIndex, err := newImageIndexForImage(image)
 	if err != nil {
 		return nil, err
 	}
+	excludes := make(map[string]struct{}, len(imageFilterOptions.excludeTypes))
+	for _, excludeTypeName := range imageFilterOptions.excludeTypes {
+		if _, ok := imageIndex.NameToDescriptor[excludeTypeName]; !ok {
+			return nil, fmt.Errorf("excluding type %q: %w", excludeTypeName, ErrImageFilterTypeNotFound)
+		}
+		excludes[excludeTypeName] = struct{}{}
+	}
 	// Check types exist
 	startingDescriptors := make([]protosource.NamedDescriptor, 0, len(types))
 	for _, typeName := range types {
@@ -155,17 +200,52 @@ func ImageFilteredByTypes(image bufimage.Image, types ...string) (bufimage.Image
 		if image.GetFile(descriptor.File().Path()).IsImport() {
 			return nil, fmt.Errorf("filtering by type %q: %w", typeName, ErrImageFilterTypeIsImport)
 		}
+		if _, ok := excludes[typeName]; ok {
+			return nil, fmt.Errorf("filtering by type %q: type is also excluded", typeName)
+		}
 		startingDescriptors = append(startingDescriptors, descriptor)
 	}
-	// Find all types to include in filtered image.
-	seen := make(map[string]struct{})
+	if len(types) == 0 {
+		excludedReferences, err := excludedTypesReferences(imageIndex, excludes)
+		if err != nil {
+			return nil, err
+		}
+		startingDescriptors = nonImportDescriptorsNotExcluded(image, imageIndex, excludes, excludedReferences)
+	}
+	// Find all types to include in filtered image. Excluded types are marked
+	// as seen, so that they are never included.
+	seen := make(map[string]struct{}, len(excludes))
+	for excludeTypeName := range excludes {
+		seen[excludeTypeName] = struct{}{}
+	}
 	neededDescriptors := []descriptorAndDirects{}
 	for _, startingDescriptor := range startingDescriptors {
-		closure, err := descriptorTransitiveClosure(startingDescriptor, imageIndex, seen)
+		closure, err := descriptorTransitiveClosure(startingDescriptor, imageIndex, excludes, seen)
 		if err != nil {
 			return nil, err
 		}
 		neededDescriptors = append(neededDescriptors, closure...)
+		if service, ok := startingDescriptor.(protosource.Service); ok {
+			// A service includes all of its methods that are not excluded.
+			for _, method := range service.Methods() {
+				closure, err := descriptorTransitiveClosure(method, imageIndex, excludes, seen)
+				if err != nil {
+					return nil, err
+				}
+				neededDescriptors = append(neededDescriptors, closure...)
+			}
+		}
+	}
+	for _, neededDescriptor := range neededDescriptors {
+		for _, directDescriptor := range neededDescriptor.Directs {
+			if _, ok := excludes[directDescriptor.FullName()]; ok {
+				return nil, fmt.Errorf(
+					"excluding type %q: type is required by %q",
+					directDescriptor.FullName(),
+					neededDescriptor.Descriptor.FullName(),
+				)
+			}
+		}
 	}
 	descriptorsByFile := make(map[string][]descriptorAndDirects)
 	for _, descriptor := range neededDescriptors {
@@ -201,6 +281,7 @@ func ImageFilteredByTypes(image bufimage.Image, types ...string) (bufimage.Image
 		// the file's PublicDependency/WeakDependency fields.
 		indexFromTo := make(map[int32]int32)
 		indexTo := 0
+		sourcePathsRemap := newSourcePathsRemapTrie()
 		for indexFrom, importPath := range imageFileDescriptor.GetDependency() {
 			// TODO: this only filters the existing imports down to
 			// the ones requested, if there was a type we picked up
@@ -210,25 +291,34 @@ func ImageFilteredByTypes(image bufimage.Image, types ...string) (bufimage.Image
 			// with public import only inserted in the middle). See
 			// TestTransitivePublicFail.
 			if _, ok := importsRequired[importPath]; ok {
+				sourcePathsRemap.markMoved([]int32{fileDependencyTag, int32(indexFrom)}, int32(indexTo))
 				indexFromTo[int32(indexFrom)] = int32(indexTo)
 				imageFileDescriptor.Dependency[indexTo] = importPath
 				indexTo++
+			} else {
+				sourcePathsRemap.markDeleted([]int32{fileDependencyTag, int32(indexFrom)})
 			}
 		}
 		imageFileDescriptor.Dependency = imageFileDescriptor.Dependency[:indexTo]
 		var i int
-		for _, indexFrom := range imageFileDescriptor.PublicDependency {
+		for index, indexFrom := range imageFileDescriptor.PublicDependency {
 			if indexTo, ok := indexFromTo[indexFrom]; ok {
+				sourcePathsRemap.markMoved([]int32{filePublicDependencyTag, int32(index)}, int32(i))
 				imageFileDescriptor.PublicDependency[i] = indexTo
 				i++
+			} else {
+				sourcePathsRemap.markDeleted([]int32{filePublicDependencyTag, int32(index)})
 			}
 		}
 		imageFileDescriptor.PublicDependency = imageFileDescriptor.PublicDependency[:i]
 		i = 0
-		for _, indexFrom := range imageFileDescriptor.WeakDependency {
+		for index, indexFrom := range imageFileDescriptor.WeakDependency {
 			if indexTo, ok := indexFromTo[indexFrom]; ok {
+				sourcePathsRemap.markMoved([]int32{fileWeakDependencyTag, int32(index)}, int32(i))
 				imageFileDescriptor.WeakDependency[i] = indexTo
 				i++
+			} else {
+				sourcePathsRemap.markDeleted([]int32{fileWeakDependencyTag, int32(index)})
 			}
 		}
 		imageFileDescriptor.WeakDependency = imageFileDescriptor.WeakDependency[:i]
@@ -237,62 +327,242 @@ func ImageFilteredByTypes(image bufimage.Image, types ...string) (bufimage.Image
 		if imageFileDescriptor.Package != nil {
 			prefix = imageFileDescriptor.GetPackage() + "."
 		}
-		trimMessages, err := trimMessageDescriptor(imageFileDescriptor.MessageType, prefix, typesToKeep)
+		trimMessages, err := trimMessageDescriptor(
+			imageFileDescriptor.MessageType,
+			prefix,
+			typesToKeep,
+			sourcePathsRemap,
+			[]int32{fileMessagesTag},
+		)
 		if err != nil {
 			return nil, err
 		}
 		imageFileDescriptor.MessageType = trimMessages
-		trimEnums, err := trimEnumDescriptor(imageFileDescriptor.EnumType, prefix, typesToKeep)
+		trimEnums, err := trimEnumDescriptor(
+			imageFileDescriptor.EnumType,
+			prefix,
+			typesToKeep,
+			sourcePathsRemap,
+			[]int32{fileEnumsTag},
+		)
 		if err != nil {
 			return nil, err
 		}
 		imageFileDescriptor.EnumType = trimEnums
-		trimExtensions, err := trimExtensionDescriptors(imageFileDescriptor.Extension, prefix, typesToKeep)
+		trimExtensions, err := trimExtensionDescriptors(
+			imageFileDescriptor.Extension,
+			prefix,
+			typesToKeep,
+			sourcePathsRemap,
+			[]int32{fileExtensionsTag},
+		)
 		if err != nil {
 			return nil, err
 		}
 		imageFileDescriptor.Extension = trimExtensions
 		i = 0
-		for _, serviceDescriptor := range imageFileDescriptor.Service {
+		for index, serviceDescriptor := range imageFileDescriptor.Service {
 			name := prefix + serviceDescriptor.GetName()
+			serviceSourcePath := []int32{fileServicesTag, int32(index)}
 			if _, ok := typesToKeep[name]; ok {
+				sourcePathsRemap.markMoved(serviceSourcePath, int32(i))
+				serviceDescriptor.Method = trimMethodDescriptors(
+					serviceDescriptor.Method,
+					name+".",
+					typesToKeep,
+					sourcePathsRemap,
+					appendSourcePath(serviceSourcePath, serviceMethodsTag),
+				)
 				imageFileDescriptor.Service[i] = serviceDescriptor
 				i++
+			} else {
+				sourcePathsRemap.markDeleted(serviceSourcePath)
 			}
 		}
 		imageFileDescriptor.Service = imageFileDescriptor.Service[:i]
 
-		// TODO: With some from/to mappings, perhaps even sourcecodeinfo
-		// isn't too bad.
-		imageFileDescriptor.SourceCodeInfo = nil
+		// The paths of the source code info locations refer to the elements by
+		// index, so they are remapped to the trimmed and re-indexed elements.
+		remapSourceCodeInfo(imageFileDescriptor.SourceCodeInfo, sourcePathsRemap)
 	}
 	return bufimage.NewImage(includedFiles)
 }
 
+type imageFilterOptions struct {
+	excludeTypes []string
+}
+
+func newImageFilterOptions() *imageFilterOptions {
+	return &imageFilterOptions{}
+}
+
+// nonImportDescriptorsNotExcluded returns the descriptors declared in the
+// non-import files of the image, sorted by name, except those that are
+// excluded, nested in an excluded type, or extensions of an excluded type.
+//
+// The descriptors that are referenced by excluded types are not returned either,
+// so that they are only included if they are required by another descriptor.
+func nonImportDescriptorsNotExcluded(
+	image bufimage.Image,
+	imageIndex *imageIndex,
+	excludes map[string]struct{},
+	excludedReferences map[string]struct{},
+) []protosource.NamedDescriptor {
+	var descriptors []protosource.NamedDescriptor
+	for name, descriptor := range imageIndex.NameToDescriptor {
+		if image.GetFile(descriptor.File().Path()).IsImport() {
+			continue
+		}
+		if isExcludedOrNestedInExcluded(name, excludes) {
+			continue
+		}
+		if _, ok := excludedReferences[name]; ok {
+			continue
+		}
+		if field, ok := descriptor.(protosource.Field); ok && isExcludedOrNestedInExcluded(field.Extendee(), excludes) {
+			continue
+		}
+		descriptors = append(descriptors, descriptor)
+	}
+	sort.Slice(descriptors, func(i int, j int) bool {
+		return descriptors[i].FullName() < descriptors[j].FullName()
+	})
+	return descriptors
+}
+
+// excludedTypesReferences returns the names of the descriptors that are
+// transitively referenced by the excluded types.
+//
+// Parents are not references, so that excluding a nested type or a method
+// does not exclude the message or service in which it is defined.
+func excludedTypesReferences(imageIndex *imageIndex, excludes map[string]struct{}) (map[string]struct{}, error) {
+	nameToDirects := make(map[string][]protosource.NamedDescriptor)
+	seen := make(map[string]struct{})
+	for excludeTypeName := range excludes {
+		closure, err := descriptorTransitiveClosure(
+			imageIndex.NameToDescriptor[excludeTypeName],
+			imageIndex,
+			nil,
+			seen,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, descriptorAndDirects := range closure {
+			nameToDirects[descriptorAndDirects.Descriptor.FullName()] = descriptorAndDirects.Directs
+		}
+	}
+	references := make(map[string]struct{})
+	var stack []string
+	for excludeTypeName := range excludes {
+		stack = append(stack, excludeTypeName)
+	}
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		parentName := parentFullName(imageIndex.NameToDescriptor[name])
+		for _, direct := range nameToDirects[name] {
+			if direct.FullName() == parentName {
+				continue
+			}
+			if _, ok := references[direct.FullName()]; ok {
+				continue
+			}
+			references[direct.FullName()] = struct{}{}
+			stack = append(stack, direct.FullName())
+		}
+	}
+	return references, nil
+}
+
+// parentFullName returns the full name of the message or service in which the
+// descriptor is defined, or the empty string if there is none.
+func parentFullName(descriptor protosource.NamedDescriptor) string {
+	switch typedDescriptor := descriptor.(type) {
+	case protosource.Message:
+		if typedDescriptor.Parent() != nil {
+			return typedDescriptor.Parent().FullName()
+		}
+	case protosource.Enum:
+		if typedDescriptor.Parent() != nil {
+			return typedDescriptor.Parent().FullName()
+		}
+	case protosource.Method:
+		return typedDescriptor.Service().FullName()
+	}
+	return ""
+}
+
+// isExcludedOrNestedInExcluded returns true if the type name or one of its
+// parent names is excluded.
+func isExcludedOrNestedInExcluded(name string, excludes map[string]struct{}) bool {
+	for {
+		if _, ok := excludes[name]; ok {
+			return true
+		}
+		index := strings.LastIndexByte(name, '.')
+		if index < 0 {
+			return false
+		}
+		name = name[:index]
+	}
+}
+
 // trimMessageDescriptor removes (nested) messages and nested enums from a slice
 // of message descriptors if their type names are not found in the toKeep map.
-func trimMessageDescriptor(in []*descriptorpb.DescriptorProto, prefix string, toKeep map[string]struct{}) ([]*descriptorpb.DescriptorProto, error) {
+//
+// The moved and deleted messages are recorded in sourcePathsRemap, where
+// sourcePath is the source path of the slice.
+func trimMessageDescriptor(
+	in []*descriptorpb.DescriptorProto,
+	prefix string,
+	toKeep map[string]struct{},
+	sourcePathsRemap *sourcePathsRemapTrie,
+	sourcePath []int32,
+) ([]*descriptorpb.DescriptorProto, error) {
 	i := 0
-	for _, messageDescriptor := range in {
+	for index, messageDescriptor := range in {
 		name := prefix + messageDescriptor.GetName()
+		messageSourcePath := appendSourcePath(sourcePath, int32(index))
 		if _, ok := toKeep[name]; ok {
-			trimMessages, err := trimMessageDescriptor(messageDescriptor.NestedType, name+".", toKeep)
+			sourcePathsRemap.markMoved(messageSourcePath, int32(i))
+			trimMessages, err := trimMessageDescriptor(
+				messageDescriptor.NestedType,
+				name+".",
+				toKeep,
+				sourcePathsRemap,
+				appendSourcePath(messageSourcePath, messageNestedMessagesTag),
+			)
 			if err != nil {
 				return nil, err
 			}
 			messageDescriptor.NestedType = trimMessages
-			trimEnums, err := trimEnumDescriptor(messageDescriptor.EnumType, name+".", toKeep)
+			trimEnums, err := trimEnumDescriptor(
+				messageDescriptor.EnumType,
+				name+".",
+				toKeep,
+				sourcePathsRemap,
+				appendSourcePath(messageSourcePath, messageEnumsTag),
+			)
 			if err != nil {
 				return nil, err
 			}
 			messageDescriptor.EnumType = trimEnums
-			trimExtensions, err := trimExtensionDescriptors(messageDescriptor.Extension, name+".", toKeep)
+			trimExtensions, err := trimExtensionDescriptors(
+				messageDescriptor.Extension,
+				name+".",
+				toKeep,
+				sourcePathsRemap,
+				appendSourcePath(messageSourcePath, messageExtensionsTag),
+			)
 			if err != nil {
 				return nil, err
 			}
 			messageDescriptor.Extension = trimExtensions
 			in[i] = messageDescriptor
 			i++
+		} else {
+			sourcePathsRemap.markDeleted(messageSourcePath)
 		}
 	}
 	return in[:i], nil
@@ -300,13 +570,23 @@ func trimMessageDescriptor(in []*descriptorpb.DescriptorProto, prefix string, to
 
 // trimEnumDescriptor removes enums from a slice of enum descriptors if their
 // type names are not found in the toKeep map.
-func trimEnumDescriptor(in []*descriptorpb.EnumDescriptorProto, prefix string, toKeep map[string]struct{}) ([]*descriptorpb.EnumDescriptorProto, error) {
+func trimEnumDescriptor(
+	in []*descriptorpb.EnumDescriptorProto,
+	prefix string,
+	toKeep map[string]struct{},
+	sourcePathsRemap *sourcePathsRemapTrie,
+	sourcePath []int32,
+) ([]*descriptorpb.EnumDescriptorProto, error) {
 	i := 0
-	for _, enumDescriptor := range in {
+	for index, enumDescriptor := range in {
 		name := prefix + enumDescriptor.GetName()
+		enumSourcePath := appendSourcePath(sourcePath, int32(index))
 		if _, ok := toKeep[name]; ok {
+			sourcePathsRemap.markMoved(enumSourcePath, int32(i))
 			in[i] = enumDescriptor
 			i++
+		} else {
+			sourcePathsRemap.markDeleted(enumSourcePath)
 		}
 	}
 	return in[:i], nil
@@ -314,18 +594,60 @@ func trimEnumDescriptor(in []*descriptorpb.EnumDescriptorProto, prefix string, t
 
 // trimExtensionDescriptors removes fields from a slice of field descriptors if their
 // type names are not found in the toKeep map.
-func trimExtensionDescriptors(in []*descriptorpb.FieldDescriptorProto, prefix string, toKeep map[string]struct{}) ([]*descriptorpb.FieldDescriptorProto, error) {
+func trimExtensionDescriptors(
+	in []*descriptorpb.FieldDescriptorProto,
+	prefix string,
+	toKeep map[string]struct{},
+	sourcePathsRemap *sourcePathsRemapTrie,
+	sourcePath []int32,
+) ([]*descriptorpb.FieldDescriptorProto, error) {
 	i := 0
-	for _, fieldDescriptor := range in {
+	for index, fieldDescriptor := range in {
 		name := prefix + fieldDescriptor.GetName()
+		fieldSourcePath := appendSourcePath(sourcePath, int32(index))
 		if _, ok := toKeep[name]; ok {
+			sourcePathsRemap.markMoved(fieldSourcePath, int32(i))
 			in[i] = fieldDescriptor
 			i++
+		} else {
+			sourcePathsRemap.markDeleted(fieldSourcePath)
 		}
 	}
 	return in[:i], nil
 }
 
+// trimMethodDescriptors removes methods from a slice of method descriptors if their
+// names are not found in the toKeep map.
+func trimMethodDescriptors(
+	in []*descriptorpb.MethodDescriptorProto,
+	prefix string,
+	toKeep map[string]struct{},
+	sourcePathsRemap *sourcePathsRemapTrie,
+	sourcePath []int32,
+) []*descriptorpb.MethodDescriptorProto {
+	i := 0
+	for index, methodDescriptor := range in {
+		name := prefix + methodDescriptor.GetName()
+		methodSourcePath := appendSourcePath(sourcePath, int32(index))
+		if _, ok := toKeep[name]; ok {
+			sourcePathsRemap.markMoved(methodSourcePath, int32(i))
+			in[i] = methodDescriptor
+			i++
+		} else {
+			sourcePathsRemap.markDeleted(methodSourcePath)
+		}
+	}
+	return in[:i]
+}
+
+// appendSourcePath returns a new source path with the elements appended,
+// so that the given path is never modified.
+func appendSourcePath(sourcePath []int32, elements ...int32) []int32 {
+	newSourcePath := make([]int32, 0, len(sourcePath)+len(elements))
+	newSourcePath = append(newSourcePath, sourcePath...)
+	return append(newSourcePath, elements...)
+}
+
 // descriptorAndDirects holds a protsource.NamedDescriptor and a list of all
 // named descriptors it directly references. A directly referenced dependency is
 // any type that if defined in a different file from the principal descriptor,
@@ -335,7 +657,7 @@ type descriptorAndDirects struct {
 	Directs    []protosource.NamedDescriptor
 }
 
-func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, imageIndex *imageIndex, seen map[string]struct{}) ([]descriptorAndDirects, error) {
+func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, imageIndex *imageIndex, excludes map[string]struct{}, seen map[string]struct{}) ([]descriptorAndDirects, error) {
 	if _, ok := seen[namedDescriptor.FullName()]; ok {
 		return nil, nil
 	}
@@ -355,7 +677,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 					return nil, fmt.Errorf("missing %q", field.TypeName())
 				}
 				directDependencies = append(directDependencies, inputDescriptor)
-				recursiveDescriptors, err := descriptorTransitiveClosure(inputDescriptor, imageIndex, seen)
+				recursiveDescriptors, err := descriptorTransitiveClosure(inputDescriptor, imageIndex, excludes, seen)
 				if err != nil {
 					return nil, err
 				}
@@ -381,7 +703,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 				return nil, fmt.Errorf("unknown field type %d", field.Type())
 			}
 			// fieldoptions
-			explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(field, imageIndex, seen)
+			explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(field, imageIndex, excludes, seen)
 			if err != nil {
 				return nil, err
 			}
@@ -390,8 +712,12 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 		}
 		// Extensions declared for this message
 		for _, extendsDescriptor := range imageIndex.NameToExtensions[namedDescriptor.FullName()] {
+			if _, ok := excludes[extendsDescriptor.FullName()]; ok {
+				// Extensions are not required to describe the message.
+				continue
+			}
 			directDependencies = append(directDependencies, extendsDescriptor)
-			recursiveDescriptors, err := descriptorTransitiveClosure(extendsDescriptor, imageIndex, seen)
+			recursiveDescriptors, err := descriptorTransitiveClosure(extendsDescriptor, imageIndex, excludes, seen)
 			if err != nil {
 				return nil, err
 			}
@@ -400,7 +726,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 		// Messages in which this message is nested
 		if typedDesctriptor.Parent() != nil {
 			directDependencies = append(directDependencies, typedDesctriptor.Parent())
-			recursiveDescriptors, err := descriptorTransitiveClosure(typedDesctriptor.Parent(), imageIndex, seen)
+			recursiveDescriptors, err := descriptorTransitiveClosure(typedDesctriptor.Parent(), imageIndex, excludes, seen)
 			if err != nil {
 				return nil, err
 			}
@@ -408,7 +734,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 		}
 		// Options for all oneofs in this message
 		for _, oneOfDescriptor := range typedDesctriptor.Oneofs() {
-			explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(oneOfDescriptor, imageIndex, seen)
+			explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(oneOfDescriptor, imageIndex, excludes, seen)
 			if err != nil {
 				return nil, err
 			}
@@ -416,7 +742,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 			transitiveDependencies = append(transitiveDependencies, recursedOptionDeps...)
 		}
 		// Options
-		explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(typedDesctriptor, imageIndex, seen)
+		explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(typedDesctriptor, imageIndex, excludes, seen)
 		if err != nil {
 			return nil, err
 		}
@@ -426,14 +752,14 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 		// Parent messages
 		if typedDesctriptor.Parent() != nil {
 			directDependencies = append(directDependencies, typedDesctriptor.Parent())
-			recursiveDescriptors, err := descriptorTransitiveClosure(typedDesctriptor.Parent(), imageIndex, seen)
+			recursiveDescriptors, err := descriptorTransitiveClosure(typedDesctriptor.Parent(), imageIndex, excludes, seen)
 			if err != nil {
 				return nil, err
 			}
 			transitiveDependencies = append(transitiveDependencies, recursiveDescriptors...)
 		}
 		for _, enumValue := range typedDesctriptor.Values() {
-			explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(enumValue, imageIndex, seen)
+			explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(enumValue, imageIndex, excludes, seen)
 			if err != nil {
 				return nil, err
 			}
@@ -441,46 +767,54 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 			transitiveDependencies = append(transitiveDependencies, recursedOptionDeps...)
 		}
 		// Options
-		explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(typedDesctriptor, imageIndex, seen)
+		explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(typedDesctriptor, imageIndex, excludes, seen)
 		if err != nil {
 			return nil, err
 		}
 		directDependencies = append(directDependencies, explicitOptionDeps...)
 		transitiveDependencies = append(transitiveDependencies, recursedOptionDeps...)
 	case protosource.Service:
-		for _, method := range typedDesctriptor.Methods() {
-			inputDescriptor, ok := imageIndex.NameToDescriptor[method.InputTypeName()]
-			if !ok {
-				return nil, fmt.Errorf("missing %q", method.InputTypeName())
-			}
-			recursiveDescriptorsIn, err := descriptorTransitiveClosure(inputDescriptor, imageIndex, seen)
-			if err != nil {
-				return nil, err
-			}
-			transitiveDependencies = append(transitiveDependencies, recursiveDescriptorsIn...)
-			directDependencies = append(directDependencies, inputDescriptor)
+		// Methods are included separately, so that a method can be included
+		// without the other methods of its service.
+		explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(typedDesctriptor, imageIndex, excludes, seen)
+		if err != nil {
+			return nil, err
+		}
+		directDependencies = append(directDependencies, explicitOptionDeps...)
+		transitiveDependencies = append(transitiveDependencies, recursedOptionDeps...)
+	case protosource.Method:
+		// The service in which this method is defined
+		directDependencies = append(directDependencies, typedDesctriptor.Service())
+		recursiveDescriptorsService, err := descriptorTransitiveClosure(typedDesctriptor.Service(), imageIndex, excludes, seen)
+		if err != nil {
+			return nil, err
+		}
+		transitiveDependencies = append(transitiveDependencies, recursiveDescriptorsService...)
 
-			outputDescriptor, ok := imageIndex.NameToDescriptor[method.OutputTypeName()]
-			if !ok {
-				return nil, fmt.Errorf("missing %q", method.OutputTypeName())
-			}
-			recursiveDescriptorsOut, err := descriptorTransitiveClosure(outputDescriptor, imageIndex, seen)
-			if err != nil {
-				return nil, err
-			}
-			transitiveDependencies = append(transitiveDependencies, recursiveDescriptorsOut...)
-			directDependencies = append(directDependencies, outputDescriptor)
+		inputDescriptor, ok := imageIndex.NameToDescriptor[typedDesctriptor.InputTypeName()]
+		if !ok {
+			return nil, fmt.Errorf("missing %q", typedDesctriptor.InputTypeName())
+		}
+		recursiveDescriptorsIn, err := descriptorTransitiveClosure(inputDescriptor, imageIndex, excludes, seen)
+		if err != nil {
+			return nil, err
+		}
+		transitiveDependencies = append(transitiveDependencies, recursiveDescriptorsIn...)
+		directDependencies = append(directDependencies, inputDescriptor)
 
-			// options
-			explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(method, imageIndex, seen)
-			if err != nil {
-				return nil, err
-			}
-			directDependencies = append(directDependencies, explicitOptionDeps...)
-			transitiveDependencies = append(transitiveDependencies, recursedOptionDeps...)
+		outputDescriptor, ok := imageIndex.NameToDescriptor[typedDesctriptor.OutputTypeName()]
+		if !ok {
+			return nil, fmt.Errorf("missing %q", typedDesctriptor.OutputTypeName())
 		}
+		recursiveDescriptorsOut, err := descriptorTransitiveClosure(outputDescriptor, imageIndex, excludes, seen)
+		if err != nil {
+			return nil, err
+		}
+		transitiveDependencies = append(transitiveDependencies, recursiveDescriptorsOut...)
+		directDependencies = append(directDependencies, outputDescriptor)
+
 		// Options
-		explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(typedDesctriptor, imageIndex, seen)
+		explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(typedDesctriptor, imageIndex, excludes, seen)
 		if err != nil {
 			return nil, err
 		}
@@ -498,7 +832,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 			return nil, fmt.Errorf("missing %q", typedDesctriptor.Extendee())
 		}
 		directDependencies = append(directDependencies, extendeeDescriptor)
-		recursiveDescriptors, err := descriptorTransitiveClosure(extendeeDescriptor, imageIndex, seen)
+		recursiveDescriptors, err := descriptorTransitiveClosure(extendeeDescriptor, imageIndex, excludes, seen)
 		if err != nil {
 			return nil, err
 		}
@@ -513,7 +847,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 				return nil, fmt.Errorf("missing %q", typedDesctriptor.TypeName())
 			}
 			directDependencies = append(directDependencies, inputDescriptor)
-			recursiveDescriptors, err := descriptorTransitiveClosure(inputDescriptor, imageIndex, seen)
+			recursiveDescriptors, err := descriptorTransitiveClosure(inputDescriptor, imageIndex, excludes, seen)
 			if err != nil {
 				return nil, err
 			}
@@ -537,7 +871,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 		default:
 			return nil, fmt.Errorf("unknown field type %d", typedDesctriptor.Type())
 		}
-		explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(typedDesctriptor, imageIndex, seen)
+		explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(typedDesctriptor, imageIndex, excludes, seen)
 		if err != nil {
 			return nil, err
 		}
@@ -547,7 +881,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 		return nil, fmt.Errorf("unexpected protosource type %T", typedDesctriptor)
 	}
 
-	explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(namedDescriptor.File(), imageIndex, seen)
+	explicitOptionDeps, recursedOptionDeps, err := exploreCustomOptions(namedDescriptor.File(), imageIndex, excludes, seen)
 	if err != nil {
 		return nil, err
 	}
@@ -560,7 +894,7 @@ func descriptorTransitiveClosure(namedDescriptor protosource.NamedDescriptor, im
 	), nil
 }
 
-func exploreCustomOptions(descriptor protosource.OptionExtensionDescriptor, imageIndex *imageIndex, seen map[string]struct{}) ([]protosource.NamedDescriptor, []descriptorAndDirects, error) {
+func exploreCustomOptions(descriptor protosource.OptionExtensionDescriptor, imageIndex *imageIndex, excludes map[string]struct{}, seen map[string]struct{}) ([]protosource.NamedDescriptor, []descriptorAndDirects, error) {
 	directDependencies := []protosource.NamedDescriptor{}
 	transitiveDependencies := []descriptorAndDirects{}
 
@@ -593,7 +927,7 @@ func exploreCustomOptions(descriptor protosource.OptionExtensionDescriptor, imag
 			return nil, nil, fmt.Errorf("cannot find ext no %d on %s", n, optionName)
 		}
 		directDependencies = append(directDependencies, field)
-		recursiveDescriptors, err := descriptorTransitiveClosure(field, imageIndex, seen)
+		recursiveDescriptors, err := descriptorTransitiveClosure(field, imageIndex, excludes, seen)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -34,6 +34,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 	"golang.org/x/tools/txtar"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 const shouldUpdateExpectations = false
@@ -91,6 +93,91 @@ func TestExtensions(t *testing.T) {
 	runDiffTest(t, "testdata/extensions", []string{"pkg.Foo"}, "extensions.txtar")
 }
 
+func TestMethods(t *testing.T) {
+	t.Parallel()
+	t.Run("method", func(t *testing.T) {
+		runSourceCodeInfoDiffTest(t, "testdata/methods", []string{"pkg.FooService.List"}, "method.txtar")
+	})
+	t.Run("methods", func(t *testing.T) {
+		runDiffTest(t, "testdata/methods", []string{"pkg.FooService.Get", "pkg.BarService.Do"}, "methods.txtar")
+	})
+	t.Run("exclude_method", func(t *testing.T) {
+		runDiffTest(
+			t,
+			"testdata/methods",
+			[]string{"pkg.FooService"},
+			"exclude_method.txtar",
+			WithExcludeTypes("pkg.FooService.Delete"),
+		)
+	})
+	t.Run("all_but_excluded", func(t *testing.T) {
+		runDiffTest(
+			t,
+			"testdata/methods",
+			nil,
+			"exclude.txtar",
+			WithExcludeTypes("pkg.BarService", "pkg.FooService.Get"),
+		)
+	})
+}
+
+func TestExcludeTypes(t *testing.T) {
+	t.Parallel()
+	t.Run("extension", func(t *testing.T) {
+		runDiffTest(
+			t,
+			"testdata/extensions",
+			[]string{"pkg.Foo"},
+			"exclude_extension.txtar",
+			WithExcludeTypes("other.from_other_file"),
+		)
+	})
+	t.Run("all_but_excluded", func(t *testing.T) {
+		runDiffTest(
+			t,
+			"testdata/nesting",
+			nil,
+			"exclude.txtar",
+			WithExcludeTypes("pkg.Baz", "pkg.Foo.NestedButNotUsed"),
+		)
+	})
+}
+
+func TestExcludeTypesErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	bucket, err := storagemem.NewReadBucket(map[string][]byte{
+		"a.proto": []byte(`syntax = "proto3";package pkg;message Foo{ Bar bar = 1; }message Bar{ message Nested{} }message Baz{}service S{ rpc M(Baz) returns (Baz); }`),
+	})
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForBucket(ctx, bucket)
+	require.NoError(t, err)
+	image, analysis, err := bufimagebuild.NewBuilder(zaptest.NewLogger(t)).Build(
+		ctx,
+		bufmodule.NewModuleFileSet(module, nil),
+		bufimagebuild.WithExcludeSourceCodeInfo(),
+	)
+	require.NoError(t, err)
+	require.Empty(t, analysis)
+
+	_, err = ImageFilteredByTypesWithOptions(image, []string{"pkg.Foo"}, WithExcludeTypes("nonexisting"))
+	assert.ErrorIs(t, err, ErrImageFilterTypeNotFound)
+	_, err = ImageFilteredByTypesWithOptions(image, []string{"pkg.Foo"}, WithExcludeTypes("pkg.Foo"))
+	assert.EqualError(t, err, `filtering by type "pkg.Foo": type is also excluded`)
+	_, err = ImageFilteredByTypesWithOptions(image, []string{"pkg.Foo"}, WithExcludeTypes("pkg.Bar"))
+	assert.EqualError(t, err, `excluding type "pkg.Bar": type is required by "pkg.Foo"`)
+	_, err = ImageFilteredByTypesWithOptions(image, []string{"pkg.Bar.Nested"}, WithExcludeTypes("pkg.Bar"))
+	assert.EqualError(t, err, `excluding type "pkg.Bar": type is required by "pkg.Bar.Nested"`)
+	_, err = ImageFilteredByTypesWithOptions(image, []string{"pkg.S.M"}, WithExcludeTypes("pkg.S"))
+	assert.EqualError(t, err, `excluding type "pkg.S": type is required by "pkg.S.M"`)
+	filteredImage, err := ImageFilteredByTypesWithOptions(image, nil, WithExcludeTypes("pkg.Foo", "pkg.Bar"))
+	require.NoError(t, err)
+	require.Len(t, filteredImage.Files(), 1)
+	messageTypes := filteredImage.Files()[0].Proto().GetMessageType()
+	require.Len(t, messageTypes, 1)
+	assert.Equal(t, "Baz", messageTypes[0].GetName())
+}
+
 func TestTransitivePublicFail(t *testing.T) {
 	ctx := context.Background()
 	bucket, err := storagemem.NewReadBucket(map[string][]byte{
@@ -162,7 +249,167 @@ func TestTypesFromMainModule(t *testing.T) {
 	assert.ErrorIs(t, err, ErrImageFilterTypeNotFound)
 }
 
-func runDiffTest(t *testing.T, testdataDir string, typenames []string, expectedFile string) {
+func TestNormalizedFileDescriptorProto(t *testing.T) {
+	t.Parallel()
+	first := &descriptorpb.FileDescriptorProto{
+		Name:             proto.String("a.proto"),
+		Dependency:       []string{"b.proto", "c.proto"},
+		PublicDependency: []int32{1},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Foo"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("one"), Number: proto.Int32(1), OneofIndex: proto.Int32(0)},
+					{Name: proto.String("two"), Number: proto.Int32(2), OneofIndex: proto.Int32(1)},
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{
+					{Name: proto.String("b")},
+					{Name: proto.String("a")},
+				},
+			},
+			{Name: proto.String("Bar")},
+		},
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("Baz"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("BAZ_UNSPECIFIED"), Number: proto.Int32(0)},
+					{Name: proto.String("BAZ_TWO"), Number: proto.Int32(2)},
+					{Name: proto.String("BAZ_ONE"), Number: proto.Int32(1)},
+				},
+			},
+		},
+		SourceCodeInfo: &descriptorpb.SourceCodeInfo{},
+	}
+	second := &descriptorpb.FileDescriptorProto{
+		Name:             proto.String("a.proto"),
+		Dependency:       []string{"c.proto", "b.proto"},
+		PublicDependency: []int32{0},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Bar")},
+			{
+				Name: proto.String("Foo"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("two"), Number: proto.Int32(2), OneofIndex: proto.Int32(0)},
+					{Name: proto.String("one"), Number: proto.Int32(1), OneofIndex: proto.Int32(1)},
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{
+					{Name: proto.String("a")},
+					{Name: proto.String("b")},
+				},
+			},
+		},
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("Baz"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("BAZ_UNSPECIFIED"), Number: proto.Int32(0)},
+					{Name: proto.String("BAZ_ONE"), Number: proto.Int32(1)},
+					{Name: proto.String("BAZ_TWO"), Number: proto.Int32(2)},
+				},
+			},
+		},
+	}
+	firstClone := proto.Clone(first)
+	normalizedFirst := NormalizedFileDescriptorProto(first)
+	normalizedSecond := NormalizedFileDescriptorProto(second)
+	assert.True(t, proto.Equal(normalizedFirst, normalizedSecond), "%v != %v", normalizedFirst, normalizedSecond)
+	assert.Nil(t, normalizedFirst.SourceCodeInfo)
+	assert.Equal(t, []string{"b.proto", "c.proto"}, normalizedFirst.Dependency)
+	assert.Equal(t, []int32{1}, normalizedFirst.PublicDependency)
+	assert.Equal(t, "b", normalizedFirst.MessageType[1].OneofDecl[normalizedFirst.MessageType[1].Field[0].GetOneofIndex()].GetName())
+	// The input is not modified.
+	assert.True(t, proto.Equal(firstClone, first))
+	second.MessageType[1].Field[0].Number = proto.Int32(3)
+	assert.False(t, proto.Equal(normalizedFirst, NormalizedFileDescriptorProto(second)))
+}
+
+func TestNormalizedFileDescriptorProtoKeepsFirstEnumValue(t *testing.T) {
+	t.Parallel()
+	// In proto2, the first value is the default value of enum fields without an
+	// explicit default, so moving another value to the top changes behavior.
+	newFileDescriptorProto := func(values ...*descriptorpb.EnumValueDescriptorProto) *descriptorpb.FileDescriptorProto {
+		return &descriptorpb.FileDescriptorProto{
+			Name:   proto.String("a.proto"),
+			Syntax: proto.String("proto2"),
+			EnumType: []*descriptorpb.EnumDescriptorProto{
+				{
+					Name:  proto.String("Baz"),
+					Value: values,
+				},
+			},
+		}
+	}
+	normalized := NormalizedFileDescriptorProto(
+		newFileDescriptorProto(
+			&descriptorpb.EnumValueDescriptorProto{Name: proto.String("BAZ_TWO"), Number: proto.Int32(2)},
+			&descriptorpb.EnumValueDescriptorProto{Name: proto.String("BAZ_ONE"), Number: proto.Int32(1)},
+		),
+	)
+	assert.Equal(t, "BAZ_TWO", normalized.EnumType[0].Value[0].GetName())
+	assert.False(
+		t,
+		proto.Equal(
+			normalized,
+			NormalizedFileDescriptorProto(
+				newFileDescriptorProto(
+					&descriptorpb.EnumValueDescriptorProto{Name: proto.String("BAZ_ONE"), Number: proto.Int32(1)},
+					&descriptorpb.EnumValueDescriptorProto{Name: proto.String("BAZ_TWO"), Number: proto.Int32(2)},
+				),
+			),
+		),
+	)
+}
+
+func TestSourceCodeInfo(t *testing.T) {
+	t.Parallel()
+	runSourceCodeInfoDiffTest(
+		t,
+		"testdata/sourcecodeinfo",
+		[]string{"pkg.FooService"},
+		"pkg.FooService.txtar",
+		WithExcludeTypes("pkg.not_used"),
+	)
+}
+
+func runDiffTest(t *testing.T, testdataDir string, typenames []string, expectedFile string, options ...ImageFilterOption) {
+	runDiffTestWithPrinter(
+		t,
+		testdataDir,
+		typenames,
+		expectedFile,
+		[]bufimagebuild.BuildOption{bufimagebuild.WithExcludeSourceCodeInfo()},
+		protoprint.Printer{
+			SortElements: true,
+			Compact:      true,
+		},
+		options...,
+	)
+}
+
+// runSourceCodeInfoDiffTest is like runDiffTest, but keeps the source code info,
+// and prints the files in declaration order, so that comments can be compared.
+func runSourceCodeInfoDiffTest(t *testing.T, testdataDir string, typenames []string, expectedFile string, options ...ImageFilterOption) {
+	runDiffTestWithPrinter(
+		t,
+		testdataDir,
+		typenames,
+		expectedFile,
+		nil,
+		protoprint.Printer{},
+		options...,
+	)
+}
+
+func runDiffTestWithPrinter(
+	t *testing.T,
+	testdataDir string,
+	typenames []string,
+	expectedFile string,
+	buildOptions []bufimagebuild.BuildOption,
+	printer protoprint.Printer,
+	options ...ImageFilterOption,
+) {
 	ctx := context.Background()
 	bucket, err := storageos.NewProvider().NewReadWriteBucket(testdataDir)
 	require.NoError(t, err)
@@ -175,12 +422,12 @@ func runDiffTest(t *testing.T, testdataDir string, typenames []string, expectedF
 	image, analysis, err := builder.Build(
 		ctx,
 		bufmodule.NewModuleFileSet(module, nil),
-		bufimagebuild.WithExcludeSourceCodeInfo(),
+		buildOptions...,
 	)
 	require.NoError(t, err)
 	require.Empty(t, analysis)
 
-	filteredImage, err := ImageFilteredByTypes(image, typenames...)
+	filteredImage, err := ImageFilteredByTypesWithOptions(image, typenames, options...)
 	require.NoError(t, err)
 	assert.NotNil(t, image)
 	assert.True(t, imageIsDependencyOrdered(filteredImage), "image files not in dependency order")
@@ -188,10 +435,6 @@ func runDiffTest(t *testing.T, testdataDir string, typenames []string, expectedF
 	reflectDescriptors, err := desc.CreateFileDescriptorsFromSet(bufimage.ImageToFileDescriptorSet(filteredImage))
 	require.NoError(t, err)
 	archive := &txtar.Archive{}
-	printer := protoprint.Printer{
-		SortElements: true,
-		Compact:      true,
-	}
 	for fname, d := range reflectDescriptors {
 		fileBuilder := &bytes.Buffer{}
 		require.NoError(t, printer.PrintProtoFile(d, fileBuilder), "expected no error while printing %q", fname)
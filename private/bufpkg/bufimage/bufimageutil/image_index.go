@@ -85,6 +85,9 @@ func newImageIndexForImage(image bufimage.Image) (*imageIndex, error) {
 				return nil, fmt.Errorf("duplicate for %q: %#v != %#v", service.FullName(), storedDescriptor, service)
 			}
 			index.NameToDescriptor[service.FullName()] = service
+			for _, method := range service.Methods() {
+				index.NameToDescriptor[method.FullName()] = method
+			}
 		}
 		for _, field := range protosourceFile.Extensions() {
 			index.NameToDescriptor[field.FullName()] = field
@@ -0,0 +1,195 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimageutil
+
+import (
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// NormalizedFileDescriptorProto returns a copy of the FileDescriptorProto without
+// source code info, and with all elements whose order has no meaning sorted.
+//
+// Two files that only differ in comments, formatting, or the order in which their
+// imports, types, fields, values, services and methods are declared have equal
+// normalized FileDescriptorProtos, except that the first value of each enum is kept
+// in place, as it is the default value of the enum. The indexes of public and weak dependencies,
+// and the oneof indexes of fields, are updated to match the sorted elements.
+func NormalizedFileDescriptorProto(fileDescriptorProto *descriptorpb.FileDescriptorProto) *descriptorpb.FileDescriptorProto {
+	fileDescriptorProto = proto.Clone(fileDescriptorProto).(*descriptorpb.FileDescriptorProto)
+	fileDescriptorProto.SourceCodeInfo = nil
+	normalizeDependencies(fileDescriptorProto)
+	normalizeMessages(fileDescriptorProto.MessageType)
+	normalizeEnums(fileDescriptorProto.EnumType)
+	sortExtensions(fileDescriptorProto.Extension)
+	sort.SliceStable(
+		fileDescriptorProto.Service,
+		func(i int, j int) bool {
+			return fileDescriptorProto.Service[i].GetName() < fileDescriptorProto.Service[j].GetName()
+		},
+	)
+	for _, service := range fileDescriptorProto.Service {
+		sort.SliceStable(
+			service.Method,
+			func(i int, j int) bool {
+				return service.Method[i].GetName() < service.Method[j].GetName()
+			},
+		)
+	}
+	return fileDescriptorProto
+}
+
+func normalizeDependencies(fileDescriptorProto *descriptorpb.FileDescriptorProto) {
+	oldDependencies := fileDescriptorProto.Dependency
+	newDependencies := append([]string{}, oldDependencies...)
+	sort.Strings(newDependencies)
+	dependencyToNewIndex := make(map[string]int32, len(newDependencies))
+	for i, dependency := range newDependencies {
+		dependencyToNewIndex[dependency] = int32(i)
+	}
+	remap := func(indexes []int32) []int32 {
+		if len(indexes) == 0 {
+			return indexes
+		}
+		newIndexes := make([]int32, 0, len(indexes))
+		for _, index := range indexes {
+			if index < 0 || int(index) >= len(oldDependencies) {
+				// Invalid index, keep it as-is so that the difference is still visible.
+				newIndexes = append(newIndexes, index)
+				continue
+			}
+			newIndexes = append(newIndexes, dependencyToNewIndex[oldDependencies[index]])
+		}
+		sort.Slice(newIndexes, func(i int, j int) bool { return newIndexes[i] < newIndexes[j] })
+		return newIndexes
+	}
+	fileDescriptorProto.Dependency = newDependencies
+	fileDescriptorProto.PublicDependency = remap(fileDescriptorProto.PublicDependency)
+	fileDescriptorProto.WeakDependency = remap(fileDescriptorProto.WeakDependency)
+}
+
+func normalizeMessages(messages []*descriptorpb.DescriptorProto) {
+	sort.SliceStable(
+		messages,
+		func(i int, j int) bool {
+			return messages[i].GetName() < messages[j].GetName()
+		},
+	)
+	for _, message := range messages {
+		normalizeOneofs(message)
+		sort.SliceStable(
+			message.Field,
+			func(i int, j int) bool {
+				return message.Field[i].GetNumber() < message.Field[j].GetNumber()
+			},
+		)
+		normalizeMessages(message.NestedType)
+		normalizeEnums(message.EnumType)
+		sortExtensions(message.Extension)
+		sort.SliceStable(
+			message.ExtensionRange,
+			func(i int, j int) bool {
+				return message.ExtensionRange[i].GetStart() < message.ExtensionRange[j].GetStart()
+			},
+		)
+		sort.SliceStable(
+			message.ReservedRange,
+			func(i int, j int) bool {
+				return message.ReservedRange[i].GetStart() < message.ReservedRange[j].GetStart()
+			},
+		)
+		sort.Strings(message.ReservedName)
+	}
+}
+
+// normalizeOneofs sorts the oneofs of the message by name, and updates the
+// oneof indexes of the fields.
+//
+// Synthetic oneofs of proto3 optional fields are named after their field, so
+// sorting by name is stable across declaration order.
+func normalizeOneofs(message *descriptorpb.DescriptorProto) {
+	if len(message.OneofDecl) == 0 {
+		return
+	}
+	oldOneofs := message.OneofDecl
+	newOneofs := append([]*descriptorpb.OneofDescriptorProto{}, oldOneofs...)
+	sort.SliceStable(
+		newOneofs,
+		func(i int, j int) bool {
+			return newOneofs[i].GetName() < newOneofs[j].GetName()
+		},
+	)
+	oneofToNewIndex := make(map[*descriptorpb.OneofDescriptorProto]int32, len(newOneofs))
+	for i, oneof := range newOneofs {
+		oneofToNewIndex[oneof] = int32(i)
+	}
+	for _, field := range message.Field {
+		if field.OneofIndex == nil {
+			continue
+		}
+		oldIndex := field.GetOneofIndex()
+		if oldIndex < 0 || int(oldIndex) >= len(oldOneofs) {
+			continue
+		}
+		field.OneofIndex = proto.Int32(oneofToNewIndex[oldOneofs[oldIndex]])
+	}
+	message.OneofDecl = newOneofs
+}
+
+func normalizeEnums(enums []*descriptorpb.EnumDescriptorProto) {
+	sort.SliceStable(
+		enums,
+		func(i int, j int) bool {
+			return enums[i].GetName() < enums[j].GetName()
+		},
+	)
+	for _, enum := range enums {
+		if len(enum.Value) > 0 {
+			// The first value is the default value of the enum, for example of proto2
+			// fields without an explicit default, so it is kept in place.
+			values := enum.Value[1:]
+			sort.SliceStable(
+				values,
+				func(i int, j int) bool {
+					if values[i].GetNumber() != values[j].GetNumber() {
+						return values[i].GetNumber() < values[j].GetNumber()
+					}
+					return values[i].GetName() < values[j].GetName()
+				},
+			)
+		}
+		sort.SliceStable(
+			enum.ReservedRange,
+			func(i int, j int) bool {
+				return enum.ReservedRange[i].GetStart() < enum.ReservedRange[j].GetStart()
+			},
+		)
+		sort.Strings(enum.ReservedName)
+	}
+}
+
+func sortExtensions(extensions []*descriptorpb.FieldDescriptorProto) {
+	sort.SliceStable(
+		extensions,
+		func(i int, j int) bool {
+			if extensions[i].GetExtendee() != extensions[j].GetExtendee() {
+				return extensions[i].GetExtendee() < extensions[j].GetExtendee()
+			}
+			return extensions[i].GetNumber() < extensions[j].GetNumber()
+		},
+	)
+}
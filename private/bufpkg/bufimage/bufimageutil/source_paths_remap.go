@@ -0,0 +1,222 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimageutil
+
+import (
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// The field numbers of the descriptor fields that are trimmed, used as
+// elements of source code info paths.
+const (
+	fileDependencyTag        = 3
+	fileMessagesTag          = 4
+	fileEnumsTag             = 5
+	fileServicesTag          = 6
+	fileExtensionsTag        = 7
+	filePublicDependencyTag  = 10
+	fileWeakDependencyTag    = 11
+	messageNestedMessagesTag = 3
+	messageEnumsTag          = 4
+	messageExtensionsTag     = 6
+	serviceMethodsTag        = 2
+)
+
+// sourcePathsRemapTrie records how the elements of a file descriptor moved
+// or were deleted while the file was trimmed, so that the source code info
+// paths can be remapped afterwards.
+//
+// Each node is keyed by a path element. Nodes for indexes of repeated fields
+// hold the new index of the element, or are marked as deleted. Paths that
+// are not in the trie are unchanged.
+type sourcePathsRemapTrie struct {
+	root *sourcePathsRemapTrieNode
+}
+
+type sourcePathsRemapTrieNode struct {
+	newIndex int32
+	deleted  bool
+	children map[int32]*sourcePathsRemapTrieNode
+}
+
+func newSourcePathsRemapTrie() *sourcePathsRemapTrie {
+	return &sourcePathsRemapTrie{
+		root: newSourcePathsRemapTrieNode(0),
+	}
+}
+
+func newSourcePathsRemapTrieNode(newIndex int32) *sourcePathsRemapTrieNode {
+	return &sourcePathsRemapTrieNode{
+		newIndex: newIndex,
+		children: make(map[int32]*sourcePathsRemapTrieNode),
+	}
+}
+
+// markMoved records that the element at the old path moved to the new index.
+//
+// The last element of oldPath is the old index of the element.
+func (t *sourcePathsRemapTrie) markMoved(oldPath []int32, newIndex int32) {
+	t.getOrCreateNode(oldPath).newIndex = newIndex
+}
+
+// markDeleted records that the element at the old path was deleted, along
+// with all its children.
+func (t *sourcePathsRemapTrie) markDeleted(oldPath []int32) {
+	t.getOrCreateNode(oldPath).deleted = true
+}
+
+// newPath returns the new path for the old path, or false if the element
+// at the old path, or one of its parents, was deleted.
+func (t *sourcePathsRemapTrie) newPath(oldPath []int32) ([]int32, bool) {
+	newPath := make([]int32, len(oldPath))
+	copy(newPath, oldPath)
+	node := t.root
+	for i, element := range oldPath {
+		child, ok := node.children[element]
+		if !ok {
+			break
+		}
+		if child.deleted {
+			return nil, false
+		}
+		newPath[i] = child.newIndex
+		node = child
+	}
+	return newPath, true
+}
+
+func (t *sourcePathsRemapTrie) getOrCreateNode(path []int32) *sourcePathsRemapTrieNode {
+	node := t.root
+	for _, element := range path {
+		child, ok := node.children[element]
+		if !ok {
+			// Elements are unchanged until they are marked.
+			child = newSourcePathsRemapTrieNode(element)
+			node.children[element] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// remapSourceCodeInfo remaps the paths of the locations of the source code info
+// after the file was trimmed, and removes the locations of deleted elements.
+func remapSourceCodeInfo(sourceCodeInfo *descriptorpb.SourceCodeInfo, sourcePathsRemap *sourcePathsRemapTrie) {
+	if sourceCodeInfo == nil {
+		return
+	}
+	locations := sourceCodeInfo.Location[:0]
+	for _, location := range sourceCodeInfo.Location {
+		newPath, ok := sourcePathsRemap.newPath(location.Path)
+		if !ok {
+			continue
+		}
+		location.Path = newPath
+		locations = append(locations, location)
+	}
+	// An extend block has a location of its own with the path of the repeated
+	// extension field. If all the extensions of a block were deleted, the
+	// location of the block is deleted as well.
+	sourceCodeInfo.Location = make([]*descriptorpb.SourceCodeInfo_Location, 0, len(locations))
+	for _, location := range locations {
+		if isExtendBlockPath(location.Path) && !extendBlockHasExtension(location, locations) {
+			continue
+		}
+		sourceCodeInfo.Location = append(sourceCodeInfo.Location, location)
+	}
+}
+
+// isExtendBlockPath returns true if the path is the path of the extensions of
+// a file or message, without an index.
+func isExtendBlockPath(path []int32) bool {
+	if len(path) == 1 {
+		return path[0] == fileExtensionsTag
+	}
+	if len(path)%2 == 0 || path[0] != fileMessagesTag || path[len(path)-1] != messageExtensionsTag {
+		return false
+	}
+	for i := 2; i < len(path)-1; i += 2 {
+		if path[i] != messageNestedMessagesTag {
+			return false
+		}
+	}
+	return true
+}
+
+// extendBlockHasExtension returns true if an extension location is contained
+// in the span of the extend block location.
+func extendBlockHasExtension(
+	extendBlockLocation *descriptorpb.SourceCodeInfo_Location,
+	locations []*descriptorpb.SourceCodeInfo_Location,
+) bool {
+	blockPath := extendBlockLocation.Path
+	for _, location := range locations {
+		if len(location.Path) != len(blockPath)+1 {
+			continue
+		}
+		if !pathHasPrefix(location.Path, blockPath) {
+			continue
+		}
+		if spanContains(extendBlockLocation.Span, location.Span) {
+			return true
+		}
+	}
+	return false
+}
+
+func pathHasPrefix(path []int32, prefix []int32) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i, element := range prefix {
+		if path[i] != element {
+			return false
+		}
+	}
+	return true
+}
+
+// spanContains returns true if the outer span contains the inner span.
+//
+// Spans are either [startLine, startColumn, endLine, endColumn], or
+// [startLine, startColumn, endColumn] if the start and end lines are the same.
+func spanContains(outer []int32, inner []int32) bool {
+	outerStartLine, outerStartColumn, outerEndLine, outerEndColumn, ok := spanPositions(outer)
+	if !ok {
+		return false
+	}
+	innerStartLine, innerStartColumn, innerEndLine, innerEndColumn, ok := spanPositions(inner)
+	if !ok {
+		return false
+	}
+	if innerStartLine < outerStartLine || (innerStartLine == outerStartLine && innerStartColumn < outerStartColumn) {
+		return false
+	}
+	if innerEndLine > outerEndLine || (innerEndLine == outerEndLine && innerEndColumn > outerEndColumn) {
+		return false
+	}
+	return true
+}
+
+func spanPositions(span []int32) (startLine int32, startColumn int32, endLine int32, endColumn int32, ok bool) {
+	switch len(span) {
+	case 3:
+		return span[0], span[1], span[0], span[2], true
+	case 4:
+		return span[0], span[1], span[2], span[3], true
+	default:
+		return 0, 0, 0, 0, false
+	}
+}
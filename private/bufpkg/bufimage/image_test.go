@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 func TestMergeImagesWithImports(t *testing.T) {
@@ -137,3 +138,76 @@ func TestMergeImagesOrdered(t *testing.T) {
 	}
 	assert.Equal(t, []string{"a.proto", "b.proto", "c.proto", "d.proto"}, paths)
 }
+
+func TestNewImageForFileDescriptorSets(t *testing.T) {
+	t.Parallel()
+	aFileDescriptorProto := &descriptorpb.FileDescriptorProto{
+		Syntax:  proto.String("proto3"),
+		Name:    proto.String("a.proto"),
+		Package: proto.String("a"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("A"),
+			},
+		},
+	}
+	bFileDescriptorProto := &descriptorpb.FileDescriptorProto{
+		Syntax:     proto.String("proto3"),
+		Name:       proto.String("b.proto"),
+		Package:    proto.String("b"),
+		Dependency: []string{"a.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("B"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("a"),
+						JsonName: proto.String("a"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(".a.A"),
+					},
+				},
+			},
+		},
+	}
+
+	image, err := NewImageForFileDescriptorSets(
+		&descriptorpb.FileDescriptorSet{
+			File: []*descriptorpb.FileDescriptorProto{bFileDescriptorProto},
+		},
+		&descriptorpb.FileDescriptorSet{
+			File: []*descriptorpb.FileDescriptorProto{
+				proto.Clone(aFileDescriptorProto).(*descriptorpb.FileDescriptorProto),
+				proto.Clone(bFileDescriptorProto).(*descriptorpb.FileDescriptorProto),
+			},
+		},
+	)
+	require.NoError(t, err)
+	imageFiles := image.Files()
+	require.Len(t, imageFiles, 2)
+	assert.Equal(t, "a.proto", imageFiles[0].Path())
+	assert.Equal(t, "b.proto", imageFiles[1].Path())
+
+	// missing import
+	_, err = NewImageForFileDescriptorSets(
+		&descriptorpb.FileDescriptorSet{
+			File: []*descriptorpb.FileDescriptorProto{bFileDescriptorProto},
+		},
+	)
+	assert.Error(t, err)
+
+	// different definitions of the same file
+	changedAFileDescriptorProto := proto.Clone(aFileDescriptorProto).(*descriptorpb.FileDescriptorProto)
+	changedAFileDescriptorProto.Package = proto.String("changed")
+	_, err = NewImageForFileDescriptorSets(
+		&descriptorpb.FileDescriptorSet{
+			File: []*descriptorpb.FileDescriptorProto{aFileDescriptorProto},
+		},
+		&descriptorpb.FileDescriptorSet{
+			File: []*descriptorpb.FileDescriptorProto{changedAFileDescriptorProto},
+		},
+	)
+	assert.Error(t, err)
+}
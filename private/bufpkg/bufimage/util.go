@@ -286,6 +286,27 @@ func checkExcludePathsExistInImage(image Image, excludeFileOrDirPaths []string)
 	return nil
 }
 
+// mergeFileDescriptorSets returns the files of the FileDescriptorSets, in the
+// order they were first seen, removing identical duplicates.
+func mergeFileDescriptorSets(fileDescriptorSets []*descriptorpb.FileDescriptorSet) ([]*descriptorpb.FileDescriptorProto, error) {
+	var fileDescriptorProtos []*descriptorpb.FileDescriptorProto
+	nameToFileDescriptorProto := make(map[string]*descriptorpb.FileDescriptorProto)
+	for _, fileDescriptorSet := range fileDescriptorSets {
+		for _, fileDescriptorProto := range fileDescriptorSet.GetFile() {
+			name := fileDescriptorProto.GetName()
+			if existingFileDescriptorProto, ok := nameToFileDescriptorProto[name]; ok {
+				if !proto.Equal(existingFileDescriptorProto, fileDescriptorProto) {
+					return nil, fmt.Errorf("%s is defined differently in multiple FileDescriptorSets", name)
+				}
+				continue
+			}
+			nameToFileDescriptorProto[name] = fileDescriptorProto
+			fileDescriptorProtos = append(fileDescriptorProtos, fileDescriptorProto)
+		}
+	}
+	return fileDescriptorProtos, nil
+}
+
 func protoImageFilesToFileDescriptors(protoImageFiles []*imagev1.ImageFile) []protodescriptor.FileDescriptor {
 	fileDescriptors := make([]protodescriptor.FileDescriptor, len(protoImageFiles))
 	for i, protoImageFile := range protoImageFiles {
@@ -44,6 +44,10 @@ type Dependency struct {
 	Owner      string
 	Repository string
 	Commit     string
+	// Digest is the digest of the content of the dependency, such as b3-<hash>.
+	//
+	// This may be empty, for example for lock files written by older versions of buf.
+	Digest string
 }
 
 // ReadConfig reads the lock file at ExternalConfigFilePath relative
@@ -88,6 +92,7 @@ func DependencyForExternalConfigDependencyV1(dep ExternalConfigDependencyV1) Dep
 		Owner:      dep.Owner,
 		Repository: dep.Repository,
 		Commit:     dep.Commit,
+		Digest:     dep.Digest,
 	}
 }
 
@@ -100,6 +105,7 @@ func ExternalConfigDependencyV1ForDependency(dep Dependency) ExternalConfigDepen
 		Owner:      dep.Owner,
 		Repository: dep.Repository,
 		Commit:     dep.Commit,
+		Digest:     dep.Digest,
 	}
 }
 
@@ -122,6 +128,7 @@ func DependencyForExternalConfigDependencyV1Beta1(dep ExternalConfigDependencyV1
 		Owner:      dep.Owner,
 		Repository: dep.Repository,
 		Commit:     dep.Commit,
+		Digest:     dep.Digest,
 	}
 }
 
@@ -134,6 +141,7 @@ func ExternalConfigDependencyV1Beta1ForDependency(dep Dependency) ExternalConfig
 		Owner:      dep.Owner,
 		Repository: dep.Repository,
 		Commit:     dep.Commit,
+		Digest:     dep.Digest,
 	}
 }
 
@@ -44,6 +44,7 @@ func testReadConfig(t *testing.T, version string) {
 				Owner:      "acme",
 				Repository: "weather",
 				Commit:     "e9191fcdc2294e2f8f3b82c528fc90a8",
+				Digest:     "b1-gLO3B_5ClhdU52w1gMOxk4GokvCoM1OqjarxMfjStGQ=",
 			},
 		},
 	}
@@ -79,6 +80,7 @@ func TestWriteReadConfig(t *testing.T) {
 				Owner:      "test2",
 				Repository: "foob2",
 				Commit:     bufmoduletesting.TestCommit,
+				Digest:     "b3-foo",
 			},
 		},
 	}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 
@@ -49,6 +50,9 @@ type ModuleFile interface {
 	bufmoduleref.FileInfo
 	io.ReadCloser
 
+	// Size is the size of the file in bytes.
+	Size() int64
+
 	isModuleFile()
 }
 
@@ -241,6 +245,64 @@ type ModuleReader interface {
 	GetModule(ctx context.Context, modulePin bufmoduleref.ModulePin) (Module, error)
 }
 
+// ErrModuleNotAvailableOffline is wrapped by the errors returned from ModuleReaders that
+// must not access the network when a Module is not available locally.
+var ErrModuleNotAvailableOffline = errors.New("module is not available offline")
+
+// NewDependenciesNotAvailableOfflineError returns a new error that lists the dependencies
+// that are not available offline.
+func NewDependenciesNotAvailableOfflineError(dependencyModulePins []bufmoduleref.ModulePin) error {
+	return newDependenciesNotAvailableOfflineError(dependencyModulePins)
+}
+
+// ErrModuleDigestMismatch is wrapped by the errors returned when the content of a Module
+// does not match the digest of its ModulePin.
+var ErrModuleDigestMismatch = errors.New("module digest does not match the digest in the lock file")
+
+// ValidateModuleDigest validates that the b3 digest of the Module matches the digest of the ModulePin.
+//
+// Returns nil if the ModulePin has no digest, or a digest that is not a b3 digest, such as the
+// b1 digests written to lock files by older versions of buf.
+// Returns an error that wraps ErrModuleDigestMismatch if the digests do not match.
+func ValidateModuleDigest(ctx context.Context, modulePin bufmoduleref.ModulePin, module Module) error {
+	return validateModuleDigest(ctx, modulePin, module)
+}
+
+// ModuleDigestIsVerifiable returns true if ValidateModuleDigest verifies Modules against the digest.
+//
+// Only b3 digests are verifiable.
+func ModuleDigestIsVerifiable(digest string) bool {
+	return moduleDigestIsVerifiable(digest)
+}
+
+// ModuleToDataWithDigest serializes the Module for the ModulePin, and returns the data with
+// the b3 digest of the Module.
+//
+// The digest depends on the module identity, so it is computed for the Module as it is
+// returned by NewModuleForDataWithDigest for the same ModulePin. This is used to store
+// Modules outside of the module cache, such as in the shared cache or the vendor directory.
+func ModuleToDataWithDigest(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+	module Module,
+) ([]byte, string, error) {
+	return moduleToDataWithDigest(ctx, modulePin, module)
+}
+
+// NewModuleForDataWithDigest returns a new Module for the data returned by
+// ModuleToDataWithDigest, with the module identity and commit of the ModulePin.
+//
+// Returns an error if the b3 digest of the Module does not match the stored digest.
+// The Module is not validated against the digest of the ModulePin, see ValidateModuleDigest.
+func NewModuleForDataWithDigest(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+	data []byte,
+	storedDigest string,
+) (Module, error) {
+	return newModuleForDataWithDigest(ctx, modulePin, data, storedDigest)
+}
+
 // NewNopModuleReader returns a new ModuleReader that always returns a storage.IsNotExist error.
 func NewNopModuleReader() ModuleReader {
 	return newNopModuleReader()
@@ -16,8 +16,10 @@ package bufmodulebuild
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"go.uber.org/zap"
 )
 
@@ -69,6 +71,9 @@ func (m *moduleFileSetBuilder) build(
 		// most efficient to bundle all of the modules together like so.
 		dependencyModules = workspace.GetModules()
 	}
+	// We collect all dependencies that are not available offline so that
+	// they can all be reported at once.
+	var offlineDependencyModulePins []bufmoduleref.ModulePin
 	// We know these are unique by remote, owner, repository and
 	// contain all transitive dependencies.
 	for _, dependencyModulePin := range module.DependencyModulePins() {
@@ -81,9 +86,16 @@ func (m *moduleFileSetBuilder) build(
 		}
 		dependencyModule, err := m.moduleReader.GetModule(ctx, dependencyModulePin)
 		if err != nil {
+			if errors.Is(err, bufmodule.ErrModuleNotAvailableOffline) {
+				offlineDependencyModulePins = append(offlineDependencyModulePins, dependencyModulePin)
+				continue
+			}
 			return nil, err
 		}
 		dependencyModules = append(dependencyModules, dependencyModule)
 	}
+	if len(offlineDependencyModulePins) > 0 {
+		return nil, bufmodule.NewDependenciesNotAvailableOfflineError(offlineDependencyModulePins)
+	}
 	return bufmodule.NewModuleFileSet(module, dependencyModules), nil
 }
@@ -15,7 +15,12 @@
 package bufmodulecache
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/gen/proto/apiclient/buf/alpha/registry/v1alpha1/registryv1alpha1apiclient"
 	"github.com/bufbuild/buf/private/pkg/filelock"
 	"github.com/bufbuild/buf/private/pkg/storage"
@@ -28,12 +33,15 @@ type ModuleReaderOption func(*moduleReaderOptions)
 
 // NewModuleReader returns a new ModuleReader that uses cache as a caching layer, and
 // delegate as the source of truth.
+//
+// The time at which each cached module was last used is recorded in accessReadWriteBucket.
 func NewModuleReader(
 	logger *zap.Logger,
 	verbosePrinter verbose.Printer,
 	fileLocker filelock.Locker,
 	dataReadWriteBucket storage.ReadWriteBucket,
 	sumReadWriteBucket storage.ReadWriteBucket,
+	accessReadWriteBucket storage.ReadWriteBucket,
 	delegate bufmodule.ModuleReader,
 	repositoryServiceProvider registryv1alpha1apiclient.RepositoryServiceProvider,
 	options ...ModuleReaderOption,
@@ -44,6 +52,7 @@ func NewModuleReader(
 		fileLocker,
 		dataReadWriteBucket,
 		sumReadWriteBucket,
+		accessReadWriteBucket,
 		delegate,
 		repositoryServiceProvider,
 		options...,
@@ -58,6 +67,169 @@ func ModuleReaderWithExternalPaths() ModuleReaderOption {
 	}
 }
 
+// ModuleReaderWithOffline is used to never access the network.
+//
+// Modules that are neither in the cache nor in the shared cache are not downloaded,
+// and GetModule returns an error that wraps bufmodule.ErrModuleNotAvailableOffline instead.
+func ModuleReaderWithOffline() ModuleReaderOption {
+	return func(moduleReaderOptions *moduleReaderOptions) {
+		moduleReaderOptions.offline = true
+	}
+}
+
+// ModuleReaderWithSharedCache is used to share the cached modules with other machines,
+// such as the runners of a CI fleet, through the given BlobStore.
+//
+// Modules that are not in the local cache are read from the BlobStore before being
+// downloaded, and downloaded modules are written to the BlobStore. Modules are only
+// read from the BlobStore if their ModulePin has a b3 digest, as recorded in the lock
+// file, and modules that do not match this digest are downloaded again.
+func ModuleReaderWithSharedCache(blobStore BlobStore) ModuleReaderOption {
+	return func(moduleReaderOptions *moduleReaderOptions) {
+		moduleReaderOptions.sharedCacheBlobStore = blobStore
+	}
+}
+
+// BlobStore stores the blobs of a shared module cache.
+type BlobStore interface {
+	// Get gets the blob for the key.
+	//
+	// Returns an error that fulfills storage.IsNotExist if there is no blob for the key.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put puts the blob for the key, overwriting any existing blob.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// NewDirBlobStore returns a new BlobStore that stores the blobs in the given bucket.
+//
+// The bucket is typically a directory shared between machines. The blobs are locked
+// with the given Locker, so that concurrent readers and writers never see partial blobs.
+func NewDirBlobStore(
+	fileLocker filelock.Locker,
+	readWriteBucket storage.ReadWriteBucket,
+) BlobStore {
+	return newDirBlobStore(
+		fileLocker,
+		readWriteBucket,
+	)
+}
+
+// NewHTTPBlobStore returns a new BlobStore that stores the blobs on a HTTP cache server.
+//
+// The blob for a key is read with GET baseURL/key and written with PUT baseURL/key.
+// The server must respond with 404 Not Found to a GET for a missing blob.
+//
+// Requests are not authenticated unless HTTPBlobStoreWithToken is used, or the
+// baseURL contains user information.
+func NewHTTPBlobStore(
+	httpClient *http.Client,
+	baseURL string,
+	options ...HTTPBlobStoreOption,
+) BlobStore {
+	return newHTTPBlobStore(
+		httpClient,
+		baseURL,
+		options...,
+	)
+}
+
+// HTTPBlobStoreOption is an option for a new HTTP BlobStore.
+type HTTPBlobStoreOption func(*httpBlobStoreOptions)
+
+// HTTPBlobStoreWithToken returns a new HTTPBlobStoreOption that sends the token
+// as a bearer token in the Authorization header of every request.
+func HTTPBlobStoreWithToken(token string) HTTPBlobStoreOption {
+	return func(httpBlobStoreOptions *httpBlobStoreOptions) {
+		httpBlobStoreOptions.token = token
+	}
+}
+
+// CacheEntry is a module stored in the module cache.
+type CacheEntry struct {
+	// ModulePin is the pin of the cached module.
+	//
+	// Only the remote, owner, repository and commit are set.
+	ModulePin bufmoduleref.ModulePin
+	// Size is the size in bytes of the files of the cached module.
+	Size int64
+	// LastUsed is the last time the cached module was read or written.
+	//
+	// This is the zero time if no access was recorded for the cached module.
+	LastUsed time.Time
+}
+
+// CacheManager manages the entries of the module cache.
+type CacheManager interface {
+	// ListEntries lists the entries of the module cache.
+	//
+	// The entries are sorted by remote, owner, repository and commit.
+	ListEntries(ctx context.Context) ([]CacheEntry, error)
+	// Verify recomputes the digest of all entries of the module cache, and deletes
+	// the entries that do not match their stored digest.
+	//
+	// Returns the deleted entries.
+	Verify(ctx context.Context) ([]CacheEntry, error)
+	// Prune deletes the entries of the module cache selected by the given options.
+	//
+	// If no option is given, no entry is deleted.
+	// Returns the deleted entries.
+	Prune(ctx context.Context, options ...PruneOption) ([]CacheEntry, error)
+}
+
+// NewCacheManager returns a new CacheManager for the module cache stored in the given buckets.
+//
+// The buckets and the file locker must be the same as the ones given to NewModuleReader.
+func NewCacheManager(
+	logger *zap.Logger,
+	fileLocker filelock.Locker,
+	dataReadWriteBucket storage.ReadWriteBucket,
+	sumReadWriteBucket storage.ReadWriteBucket,
+	accessReadWriteBucket storage.ReadWriteBucket,
+) CacheManager {
+	return newCacheManager(
+		logger,
+		fileLocker,
+		dataReadWriteBucket,
+		sumReadWriteBucket,
+		accessReadWriteBucket,
+	)
+}
+
+// PruneOption is an option for Prune.
+type PruneOption func(*pruneOptions)
+
+// PruneWithLastUsedBefore deletes the entries last used before the given time.
+//
+// Entries for which no access was recorded are deleted.
+func PruneWithLastUsedBefore(lastUsedBefore time.Time) PruneOption {
+	return func(pruneOptions *pruneOptions) {
+		pruneOptions.lastUsedBefore = lastUsedBefore
+	}
+}
+
+// PruneWithMaxSize deletes the least recently used entries until the total size
+// of the remaining entries is at most the given number of bytes.
+//
+// Entries for which no access was recorded are deleted first.
+func PruneWithMaxSize(maxSize int64) PruneOption {
+	return func(pruneOptions *pruneOptions) {
+		pruneOptions.maxSize = maxSize
+		pruneOptions.maxSizeSet = true
+	}
+}
+
 type moduleReaderOptions struct {
 	allowCacheExternalPaths bool
+	offline                 bool
+	sharedCacheBlobStore    BlobStore
+}
+
+type httpBlobStoreOptions struct {
+	token string
+}
+
+type pruneOptions struct {
+	lastUsedBefore time.Time
+	maxSize        int64
+	maxSizeSet     bool
 }
@@ -50,6 +50,7 @@ func TestReaderBasic(t *testing.T) {
 		"bar",
 		"main",
 		bufmoduletesting.TestCommit,
+		"",
 		time.Now(),
 	)
 	require.NoError(t, err)
@@ -89,6 +90,7 @@ func TestReaderBasic(t *testing.T) {
 		delegateFileLocker,
 		delegateDataReadWriteBucket,
 		delegateSumReadWriteBucket,
+		storagemem.NewReadWriteBucket(),
 		moduleCacher,
 		repositoryServiceProvider,
 	)
@@ -96,12 +98,14 @@ func TestReaderBasic(t *testing.T) {
 	core, observedLogs := observer.New(zapcore.WarnLevel)
 	// the main does not, so there will be a cache miss
 	mainDataReadWriteBucket, mainSumReadWriteBucket, mainFileLocker := newTestDataSumBucketsAndLocker(t)
+	mainAccessReadWriteBucket := storagemem.NewReadWriteBucket()
 	moduleReader := newModuleReader(
 		zap.New(core),
 		verbose.NopPrinter,
 		mainFileLocker,
 		mainDataReadWriteBucket,
 		mainSumReadWriteBucket,
+		mainAccessReadWriteBucket,
 		delegateModuleReader,
 		repositoryServiceProvider,
 	)
@@ -127,6 +131,9 @@ func TestReaderBasic(t *testing.T) {
 	testFile1HasNoExternalPath(t, ctx, getModule)
 	require.Equal(t, 2, moduleReader.getCount())
 	require.Equal(t, 1, moduleReader.getCacheHits())
+	lastUsed, err := getLastUsed(ctx, mainAccessReadWriteBucket, newCacheKey(modulePin))
+	require.NoError(t, err)
+	require.False(t, lastUsed.IsZero())
 
 	// put some data that will not match the sum and make sure that we have a cache miss
 	require.NoError(t, storage.PutPath(ctx, mainDataReadWriteBucket, normalpath.Join(newCacheKey(modulePin), "1234.proto"), []byte("foo")))
@@ -187,6 +194,89 @@ func TestReaderBasic(t *testing.T) {
 	}).Len())
 }
 
+func TestReaderOffline(t *testing.T) {
+	ctx := context.Background()
+
+	modulePin, err := bufmoduleref.NewModulePin(
+		"buf.build",
+		"foob",
+		"bar",
+		"main",
+		bufmoduletesting.TestCommit,
+		"",
+		time.Now(),
+	)
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		bufmoduletesting.TestDataProto,
+		bufmodule.ModuleWithModuleIdentity(modulePin),
+	)
+	require.NoError(t, err)
+
+	dataReadWriteBucket, sumReadWriteBucket, fileLocker := newTestDataSumBucketsAndLocker(t)
+	moduleReader := newModuleReader(
+		zap.NewNop(),
+		verbose.NopPrinter,
+		fileLocker,
+		dataReadWriteBucket,
+		sumReadWriteBucket,
+		storagemem.NewReadWriteBucket(),
+		// the delegate must never be called when offline
+		bufmodule.NewNopModuleReader(),
+		&fakeRepositoryServiceProvider{},
+		ModuleReaderWithOffline(),
+	)
+	_, err = moduleReader.GetModule(ctx, modulePin)
+	require.ErrorIs(t, err, bufmodule.ErrModuleNotAvailableOffline)
+	require.Contains(t, err.Error(), modulePin.String())
+	require.Equal(t, 0, moduleReader.getCount())
+
+	moduleCacher := newModuleCacher(zap.NewNop(), dataReadWriteBucket, sumReadWriteBucket, false)
+	require.NoError(t, moduleCacher.PutModule(ctx, modulePin, module))
+	_, err = moduleReader.GetModule(ctx, modulePin)
+	require.NoError(t, err)
+	require.Equal(t, 1, moduleReader.getCount())
+	require.Equal(t, 1, moduleReader.getCacheHits())
+}
+
+func TestReaderDigest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modulePin, module := testNewSharedCacheModule(t)
+	digest, err := bufmodule.ModuleDigestB3(ctx, module)
+	require.NoError(t, err)
+	moduleReader := testNewSharedCacheModuleReader(t, &countingModuleReader{module: module}, NewDirBlobStore(filelock.NewNopLocker(), storagemem.NewReadWriteBucket()))
+	for _, validDigest := range []string{digest, "", "b1-foo"} {
+		modulePinWithDigest, err := bufmoduleref.NewModulePinWithDigest(modulePin, validDigest)
+		require.NoError(t, err)
+		_, err = moduleReader.GetModule(ctx, modulePinWithDigest)
+		require.NoError(t, err)
+	}
+	modulePinWithDigest, err := bufmoduleref.NewModulePinWithDigest(modulePin, "b3-foo")
+	require.NoError(t, err)
+	// Both a cache miss and a cache hit are verified.
+	blobStore := NewDirBlobStore(filelock.NewNopLocker(), storagemem.NewReadWriteBucket())
+	missModuleReader := testNewSharedCacheModuleReader(t, &countingModuleReader{module: module}, blobStore)
+	_, err = missModuleReader.GetModule(ctx, modulePinWithDigest)
+	require.ErrorIs(t, err, bufmodule.ErrModuleDigestMismatch)
+	// A module that does not match the lock file is neither cached nor shared.
+	_, err = missModuleReader.cache.GetModule(ctx, modulePin)
+	require.True(t, storage.IsNotExist(err))
+	_, err = blobStore.Get(ctx, newSharedCacheKey(modulePin))
+	require.True(t, storage.IsNotExist(err))
+	// A module from the shared cache that does not match the lock file is not cached.
+	require.NoError(t, newSharedCache(zap.NewNop(), blobStore).PutModule(ctx, modulePin, module))
+	_, err = missModuleReader.GetModule(ctx, modulePinWithDigest)
+	require.ErrorIs(t, err, bufmodule.ErrModuleDigestMismatch)
+	_, err = missModuleReader.cache.GetModule(ctx, modulePin)
+	require.True(t, storage.IsNotExist(err))
+	_, err = moduleReader.GetModule(ctx, modulePinWithDigest)
+	require.ErrorIs(t, err, bufmodule.ErrModuleDigestMismatch)
+	require.Contains(t, err.Error(), modulePin.String())
+	require.Contains(t, err.Error(), digest)
+}
+
 func TestCacherBasic(t *testing.T) {
 	ctx := context.Background()
 
@@ -196,6 +286,7 @@ func TestCacherBasic(t *testing.T) {
 		"bar",
 		"main",
 		bufmoduletesting.TestCommit,
+		"",
 		time.Now(),
 	)
 	require.NoError(t, err)
@@ -237,6 +328,7 @@ func TestModuleReaderCacherWithDocumentation(t *testing.T) {
 		"bar",
 		"main",
 		bufmoduletesting.TestCommit,
+		"",
 		time.Now(),
 	)
 	require.NoError(t, err)
@@ -274,6 +366,7 @@ func TestModuleReaderCacherWithConfiguration(t *testing.T) {
 		"weather",
 		"main",
 		bufmoduletesting.TestCommit,
+		"",
 		time.Now(),
 	)
 	require.NoError(t, err)
@@ -317,6 +410,7 @@ func TestModuleReaderCacherWithLicense(t *testing.T) {
 		"bar",
 		"main",
 		bufmoduletesting.TestCommit,
+		"",
 		time.Now(),
 	)
 	require.NoError(t, err)
@@ -0,0 +1,223 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulecache
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/filelock"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+type cacheManager struct {
+	logger                *zap.Logger
+	fileLocker            filelock.Locker
+	dataReadWriteBucket   storage.ReadWriteBucket
+	sumReadWriteBucket    storage.ReadWriteBucket
+	accessReadWriteBucket storage.ReadWriteBucket
+	cache                 *moduleCacher
+}
+
+func newCacheManager(
+	logger *zap.Logger,
+	fileLocker filelock.Locker,
+	dataReadWriteBucket storage.ReadWriteBucket,
+	sumReadWriteBucket storage.ReadWriteBucket,
+	accessReadWriteBucket storage.ReadWriteBucket,
+) *cacheManager {
+	return &cacheManager{
+		logger:                logger,
+		fileLocker:            fileLocker,
+		dataReadWriteBucket:   dataReadWriteBucket,
+		sumReadWriteBucket:    sumReadWriteBucket,
+		accessReadWriteBucket: accessReadWriteBucket,
+		cache: newModuleCacher(
+			logger,
+			dataReadWriteBucket,
+			sumReadWriteBucket,
+			false,
+		),
+	}
+}
+
+func (c *cacheManager) ListEntries(ctx context.Context) ([]CacheEntry, error) {
+	cacheKeyToSize := make(map[string]int64)
+	if err := c.dataReadWriteBucket.Walk(
+		ctx,
+		"",
+		func(objectInfo storage.ObjectInfo) error {
+			// The cache key is of the form remote/owner/repository/commit, and the
+			// files of the module are stored below it.
+			components := normalpath.Components(objectInfo.Path())
+			if len(components) < 5 {
+				return nil
+			}
+			cacheKeyToSize[normalpath.Join(components[:4]...)] += objectInfo.Size()
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	cacheEntries := make([]CacheEntry, 0, len(cacheKeyToSize))
+	for cacheKey, size := range cacheKeyToSize {
+		components := normalpath.Components(cacheKey)
+		modulePin, err := bufmoduleref.NewModulePin(
+			components[0],
+			components[1],
+			components[2],
+			"",
+			components[3],
+			"",
+			time.Time{},
+		)
+		if err != nil {
+			c.logger.Sugar().Warnf("Ignoring unexpected module cache directory %q: %v", cacheKey, err)
+			continue
+		}
+		lastUsed, err := getLastUsed(ctx, c.accessReadWriteBucket, cacheKey)
+		if err != nil {
+			return nil, err
+		}
+		cacheEntries = append(
+			cacheEntries,
+			CacheEntry{
+				ModulePin: modulePin,
+				Size:      size,
+				LastUsed:  lastUsed,
+			},
+		)
+	}
+	sort.Slice(
+		cacheEntries,
+		func(i int, j int) bool {
+			return newCacheKey(cacheEntries[i].ModulePin) < newCacheKey(cacheEntries[j].ModulePin)
+		},
+	)
+	return cacheEntries, nil
+}
+
+func (c *cacheManager) Verify(ctx context.Context) ([]CacheEntry, error) {
+	cacheEntries, err := c.ListEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var corruptedCacheEntries []CacheEntry
+	for _, cacheEntry := range cacheEntries {
+		corrupted, err := c.verifyEntry(ctx, cacheEntry)
+		if err != nil {
+			return nil, err
+		}
+		if corrupted {
+			corruptedCacheEntries = append(corruptedCacheEntries, cacheEntry)
+		}
+	}
+	return corruptedCacheEntries, nil
+}
+
+func (c *cacheManager) Prune(ctx context.Context, options ...PruneOption) ([]CacheEntry, error) {
+	pruneOptions := &pruneOptions{}
+	for _, option := range options {
+		option(pruneOptions)
+	}
+	cacheEntries, err := c.ListEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Sort from least to most recently used, so that we delete the
+	// least recently used entries first.
+	sort.SliceStable(
+		cacheEntries,
+		func(i int, j int) bool {
+			return cacheEntries[i].LastUsed.Before(cacheEntries[j].LastUsed)
+		},
+	)
+	var totalSize int64
+	for _, cacheEntry := range cacheEntries {
+		totalSize += cacheEntry.Size
+	}
+	var prunedCacheEntries []CacheEntry
+	for _, cacheEntry := range cacheEntries {
+		tooOld := !pruneOptions.lastUsedBefore.IsZero() && cacheEntry.LastUsed.Before(pruneOptions.lastUsedBefore)
+		tooLarge := pruneOptions.maxSizeSet && totalSize > pruneOptions.maxSize
+		if !tooOld && !tooLarge {
+			continue
+		}
+		if err := c.deleteEntry(ctx, cacheEntry); err != nil {
+			return nil, err
+		}
+		totalSize -= cacheEntry.Size
+		prunedCacheEntries = append(prunedCacheEntries, cacheEntry)
+	}
+	return prunedCacheEntries, nil
+}
+
+// verifyEntry deletes the given entry if it is corrupted, and returns whether it was.
+func (c *cacheManager) verifyEntry(ctx context.Context, cacheEntry CacheEntry) (_ bool, retErr error) {
+	unlocker, err := c.fileLocker.Lock(ctx, newCacheKey(cacheEntry.ModulePin))
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, unlocker.Unlock())
+	}()
+	// Errors that are not corruptions, such as a cancelled context or a permission
+	// error, are returned so that valid modules are not deleted.
+	corrupted, err := c.cache.isCorrupted(ctx, cacheEntry.ModulePin)
+	if err != nil || !corrupted {
+		return false, err
+	}
+	if err := c.deleteEntryLocked(ctx, cacheEntry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *cacheManager) deleteEntry(ctx context.Context, cacheEntry CacheEntry) (retErr error) {
+	unlocker, err := c.fileLocker.Lock(ctx, newCacheKey(cacheEntry.ModulePin))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, unlocker.Unlock())
+	}()
+	return c.deleteEntryLocked(ctx, cacheEntry)
+}
+
+// deleteEntryLocked deletes the given entry.
+//
+// The write lock for the entry must be held.
+func (c *cacheManager) deleteEntryLocked(ctx context.Context, cacheEntry CacheEntry) error {
+	cacheKey := newCacheKey(cacheEntry.ModulePin)
+	if err := c.dataReadWriteBucket.DeleteAll(ctx, cacheKey); err != nil {
+		return err
+	}
+	if err := deleteIfExists(ctx, c.sumReadWriteBucket, cacheKey); err != nil {
+		return err
+	}
+	return deleteIfExists(ctx, c.accessReadWriteBucket, cacheKey)
+}
+
+func deleteIfExists(ctx context.Context, readWriteBucket storage.ReadWriteBucket, path string) error {
+	if err := readWriteBucket.Delete(ctx, path); err != nil && !storage.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulecache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/pkg/filelock"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCacheManagerListEntries(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cacheManager, moduleCacher, accessReadWriteBucket := newTestCacheManager(t)
+	lastUsed := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	barModulePin := testPutCacheModule(t, ctx, moduleCacher, "bar")
+	bazModulePin := testPutCacheModule(t, ctx, moduleCacher, "baz")
+	require.NoError(t, putLastUsed(ctx, accessReadWriteBucket, newCacheKey(bazModulePin), lastUsed))
+
+	cacheEntries, err := cacheManager.ListEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, cacheEntries, 2)
+	require.Equal(t, barModulePin.String(), cacheEntries[0].ModulePin.String())
+	require.True(t, cacheEntries[0].LastUsed.IsZero())
+	require.Positive(t, cacheEntries[0].Size)
+	require.Equal(t, bazModulePin.String(), cacheEntries[1].ModulePin.String())
+	require.True(t, lastUsed.Equal(cacheEntries[1].LastUsed))
+	require.Equal(t, cacheEntries[0].Size, cacheEntries[1].Size)
+}
+
+func TestCacheManagerListEntriesMemoryBuckets(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// The sizes come from the buckets, so the cache does not need to be on disk.
+	dataReadWriteBucket := storagemem.NewReadWriteBucket()
+	sumReadWriteBucket := storagemem.NewReadWriteBucket()
+	cacheManager := NewCacheManager(
+		zap.NewNop(),
+		filelock.NewNopLocker(),
+		dataReadWriteBucket,
+		sumReadWriteBucket,
+		storagemem.NewReadWriteBucket(),
+	)
+	moduleCacher := newModuleCacher(zap.NewNop(), dataReadWriteBucket, sumReadWriteBucket, false)
+	barModulePin := testPutCacheModule(t, ctx, moduleCacher, "bar")
+	var expectedSize int64
+	require.NoError(
+		t,
+		dataReadWriteBucket.Walk(
+			ctx,
+			newCacheKey(barModulePin),
+			func(objectInfo storage.ObjectInfo) error {
+				data, err := storage.ReadPath(ctx, dataReadWriteBucket, objectInfo.Path())
+				if err != nil {
+					return err
+				}
+				expectedSize += int64(len(data))
+				return nil
+			},
+		),
+	)
+
+	cacheEntries, err := cacheManager.ListEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, cacheEntries, 1)
+	require.Equal(t, barModulePin.String(), cacheEntries[0].ModulePin.String())
+	require.Equal(t, expectedSize, cacheEntries[0].Size)
+}
+
+func TestCacheManagerVerify(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cacheManager, moduleCacher, accessReadWriteBucket := newTestCacheManager(t)
+	barModulePin := testPutCacheModule(t, ctx, moduleCacher, "bar")
+	bazModulePin := testPutCacheModule(t, ctx, moduleCacher, "baz")
+	require.NoError(t, putLastUsed(ctx, accessReadWriteBucket, newCacheKey(bazModulePin), time.Now()))
+
+	corruptedCacheEntries, err := cacheManager.Verify(ctx)
+	require.NoError(t, err)
+	require.Empty(t, corruptedCacheEntries)
+
+	require.NoError(
+		t,
+		storage.PutPath(
+			ctx,
+			moduleCacher.dataReadWriteBucket,
+			newCacheKey(bazModulePin)+"/"+bufmoduletesting.TestFile1Path,
+			[]byte("corrupted"),
+		),
+	)
+	corruptedCacheEntries, err = cacheManager.Verify(ctx)
+	require.NoError(t, err)
+	require.Len(t, corruptedCacheEntries, 1)
+	require.Equal(t, bazModulePin.String(), corruptedCacheEntries[0].ModulePin.String())
+	cacheEntries, err := cacheManager.ListEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, cacheEntries, 1)
+	require.Equal(t, barModulePin.String(), cacheEntries[0].ModulePin.String())
+	for _, readBucket := range []storage.ReadBucket{moduleCacher.sumReadWriteBucket, accessReadWriteBucket} {
+		exists, err := storage.Exists(ctx, readBucket, newCacheKey(bazModulePin))
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+}
+
+func TestCacheManagerPrune(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cacheManager, moduleCacher, accessReadWriteBucket := newTestCacheManager(t)
+	now := time.Now()
+	// foo has no recorded access, bar is old, baz is recent.
+	fooModulePin := testPutCacheModule(t, ctx, moduleCacher, "foo")
+	barModulePin := testPutCacheModule(t, ctx, moduleCacher, "bar")
+	bazModulePin := testPutCacheModule(t, ctx, moduleCacher, "baz")
+	require.NoError(t, putLastUsed(ctx, accessReadWriteBucket, newCacheKey(barModulePin), now.Add(-48*time.Hour)))
+	require.NoError(t, putLastUsed(ctx, accessReadWriteBucket, newCacheKey(bazModulePin), now))
+	cacheEntries, err := cacheManager.ListEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, cacheEntries, 3)
+	entrySize := cacheEntries[0].Size
+
+	prunedCacheEntries, err := cacheManager.Prune(ctx)
+	require.NoError(t, err)
+	require.Empty(t, prunedCacheEntries)
+
+	prunedCacheEntries, err = cacheManager.Prune(ctx, PruneWithMaxSize(2*entrySize))
+	require.NoError(t, err)
+	require.Len(t, prunedCacheEntries, 1)
+	require.Equal(t, fooModulePin.String(), prunedCacheEntries[0].ModulePin.String())
+
+	prunedCacheEntries, err = cacheManager.Prune(ctx, PruneWithLastUsedBefore(now.Add(-24*time.Hour)))
+	require.NoError(t, err)
+	require.Len(t, prunedCacheEntries, 1)
+	require.Equal(t, barModulePin.String(), prunedCacheEntries[0].ModulePin.String())
+
+	prunedCacheEntries, err = cacheManager.Prune(ctx, PruneWithMaxSize(0))
+	require.NoError(t, err)
+	require.Len(t, prunedCacheEntries, 1)
+	require.Equal(t, bazModulePin.String(), prunedCacheEntries[0].ModulePin.String())
+	cacheEntries, err = cacheManager.ListEntries(ctx)
+	require.NoError(t, err)
+	require.Empty(t, cacheEntries)
+}
+
+func newTestCacheManager(t *testing.T) (CacheManager, *moduleCacher, storage.ReadWriteBucket) {
+	dataReadWriteBucket, sumReadWriteBucket, fileLocker := newTestDataSumBucketsAndLocker(t)
+	accessReadWriteBucket := storagemem.NewReadWriteBucket()
+	cacheManager := NewCacheManager(
+		zap.NewNop(),
+		fileLocker,
+		dataReadWriteBucket,
+		sumReadWriteBucket,
+		accessReadWriteBucket,
+	)
+	moduleCacher := newModuleCacher(zap.NewNop(), dataReadWriteBucket, sumReadWriteBucket, false)
+	return cacheManager, moduleCacher, accessReadWriteBucket
+}
+
+func TestCacheManagerVerifyReadError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dataReadWriteBucket, sumReadWriteBucket, fileLocker := newTestDataSumBucketsAndLocker(t)
+	moduleCacher := newModuleCacher(zap.NewNop(), dataReadWriteBucket, sumReadWriteBucket, false)
+	barModulePin := testPutCacheModule(t, ctx, moduleCacher, "bar")
+	readErr := errors.New("permission denied")
+	cacheManager := NewCacheManager(
+		zap.NewNop(),
+		fileLocker,
+		&failingGetReadWriteBucket{
+			ReadWriteBucket: dataReadWriteBucket,
+			err:             readErr,
+		},
+		sumReadWriteBucket,
+		storagemem.NewReadWriteBucket(),
+	)
+
+	// Errors that are not corruptions are returned, and do not delete the entry.
+	_, err := cacheManager.Verify(ctx)
+	require.ErrorIs(t, err, readErr)
+	cacheEntries, err := cacheManager.ListEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, cacheEntries, 1)
+	require.Equal(t, barModulePin.String(), cacheEntries[0].ModulePin.String())
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	corrupted, err := moduleCacher.isCorrupted(cancelledCtx, barModulePin)
+	require.Error(t, err)
+	require.False(t, corrupted)
+}
+
+func testPutCacheModule(
+	t *testing.T,
+	ctx context.Context,
+	moduleCacher *moduleCacher,
+	repository string,
+) bufmoduleref.ModulePin {
+	modulePin, err := bufmoduleref.NewModulePin(
+		"buf.build",
+		"foob",
+		repository,
+		"",
+		bufmoduletesting.TestCommit,
+		"",
+		time.Time{},
+	)
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		bufmoduletesting.TestDataProto,
+		bufmodule.ModuleWithModuleIdentityAndCommit(modulePin, modulePin.Commit()),
+	)
+	require.NoError(t, err)
+	require.NoError(t, moduleCacher.PutModule(ctx, modulePin, module))
+	return modulePin
+}
+
+// failingGetReadWriteBucket is a ReadWriteBucket that fails to read objects.
+type failingGetReadWriteBucket struct {
+	storage.ReadWriteBucket
+	err error
+}
+
+func (b *failingGetReadWriteBucket) Get(ctx context.Context, path string) (storage.ReadObjectCloser, error) {
+	return nil, b.err
+}
@@ -0,0 +1,60 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulecache
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/pkg/filelock"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"go.uber.org/multierr"
+)
+
+type dirBlobStore struct {
+	fileLocker      filelock.Locker
+	readWriteBucket storage.ReadWriteBucket
+}
+
+func newDirBlobStore(
+	fileLocker filelock.Locker,
+	readWriteBucket storage.ReadWriteBucket,
+) *dirBlobStore {
+	return &dirBlobStore{
+		fileLocker:      fileLocker,
+		readWriteBucket: readWriteBucket,
+	}
+}
+
+func (d *dirBlobStore) Get(ctx context.Context, key string) (_ []byte, retErr error) {
+	unlocker, err := d.fileLocker.RLock(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, unlocker.Unlock())
+	}()
+	return storage.ReadPath(ctx, d.readWriteBucket, key)
+}
+
+func (d *dirBlobStore) Put(ctx context.Context, key string, data []byte) (retErr error) {
+	unlocker, err := d.fileLocker.Lock(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, unlocker.Unlock())
+	}()
+	return storage.PutPath(ctx, d.readWriteBucket, key, data)
+}
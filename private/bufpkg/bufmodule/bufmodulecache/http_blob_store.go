@@ -0,0 +1,98 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulecache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"go.uber.org/multierr"
+)
+
+type httpBlobStore struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+func newHTTPBlobStore(
+	httpClient *http.Client,
+	baseURL string,
+	options ...HTTPBlobStoreOption,
+) *httpBlobStore {
+	httpBlobStoreOptions := &httpBlobStoreOptions{}
+	for _, option := range options {
+		option(httpBlobStoreOptions)
+	}
+	return &httpBlobStore{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      httpBlobStoreOptions.token,
+	}
+}
+
+func (h *httpBlobStore) Get(ctx context.Context, key string) (_ []byte, retErr error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, h.baseURL+"/"+key, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.setAuthorization(request)
+	response, err := h.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, response.Body.Close())
+	}()
+	switch response.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(response.Body)
+	case http.StatusNotFound:
+		return nil, storage.NewErrNotExist(key)
+	default:
+		return nil, fmt.Errorf("unexpected status from shared cache for GET %s: %s", request.URL.Redacted(), response.Status)
+	}
+}
+
+func (h *httpBlobStore) Put(ctx context.Context, key string, data []byte) (retErr error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, h.baseURL+"/"+key, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+	h.setAuthorization(request)
+	response, err := h.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, response.Body.Close())
+	}()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status from shared cache for PUT %s: %s", request.URL.Redacted(), response.Status)
+	}
+	return nil
+}
+
+func (h *httpBlobStore) setAuthorization(request *http.Request) {
+	if h.token != "" {
+		request.Header.Set("Authorization", "Bearer "+h.token)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
@@ -62,7 +63,66 @@ func (m *moduleCacher) GetModule(
 		// In general, we do not want the external path of the cache to be propagated to the user.
 		dataReadWriteBucket = storage.NoExternalPathReadBucket(dataReadWriteBucket)
 	}
-	exists, err := storage.Exists(ctx, dataReadWriteBucket, buflock.ExternalConfigFilePath)
+	return m.getModuleForBuckets(ctx, modulePin, dataReadWriteBucket, m.sumReadWriteBucket)
+}
+
+// isCorrupted returns true if the module for the ModulePin in the cache is corrupted,
+// that is if its files, its buf.lock or its stored digest are missing or invalid.
+//
+// The module is copied into memory before it is checked, so that errors reading the
+// cache, such as permission errors, are returned instead of being reported as corruption.
+func (m *moduleCacher) isCorrupted(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+) (bool, error) {
+	modulePath := newCacheKey(modulePin)
+	dataReadWriteBucket := storagemem.NewReadWriteBucket()
+	if _, err := storage.Copy(
+		ctx,
+		storage.MapReadWriteBucket(m.dataReadWriteBucket, storage.MapOnPrefix(modulePath)),
+		dataReadWriteBucket,
+	); err != nil {
+		return false, err
+	}
+	sumReadWriteBucket := storagemem.NewReadWriteBucket()
+	storedDigestData, err := storage.ReadPath(ctx, m.sumReadWriteBucket, modulePath)
+	if err != nil {
+		if storage.IsNotExist(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	if err := storage.PutPath(ctx, sumReadWriteBucket, modulePath, storedDigestData); err != nil {
+		return false, err
+	}
+	if _, err := m.getModuleForBuckets(ctx, modulePin, dataReadWriteBucket, sumReadWriteBucket); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return false, ctxErr
+		}
+		// The module is in memory, so this is a missing file or a module that
+		// cannot be parsed.
+		m.logger.Debug(
+			"cache_corrupted",
+			zap.String("module_pin", modulePin.String()),
+			zap.Error(err),
+		)
+		return true, nil
+	}
+	return false, nil
+}
+
+// getModuleForBuckets returns the module for the ModulePin in the data bucket, after
+// checking its digest against the digest stored in the sum bucket.
+//
+// The data bucket only contains the files of the module.
+func (m *moduleCacher) getModuleForBuckets(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+	dataReadBucket storage.ReadBucket,
+	sumReadBucket storage.ReadBucket,
+) (bufmodule.Module, error) {
+	modulePath := newCacheKey(modulePin)
+	exists, err := storage.Exists(ctx, dataReadBucket, buflock.ExternalConfigFilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -71,13 +131,13 @@ func (m *moduleCacher) GetModule(
 	}
 	module, err := bufmodule.NewModuleForBucket(
 		ctx,
-		dataReadWriteBucket,
+		dataReadBucket,
 		bufmodule.ModuleWithModuleIdentityAndCommit(modulePin, modulePin.Commit()),
 	)
 	if err != nil {
 		return nil, err
 	}
-	storedDigestData, err := storage.ReadPath(ctx, m.sumReadWriteBucket, modulePath)
+	storedDigestData, err := storage.ReadPath(ctx, sumReadBucket, modulePath)
 	if err != nil {
 		// This can happen if we couldn't find the sum file, which means
 		// we are in an invalid state
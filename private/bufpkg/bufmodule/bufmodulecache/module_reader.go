@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
@@ -34,8 +35,11 @@ type moduleReader struct {
 	verbosePrinter            verbose.Printer
 	fileLocker                filelock.Locker
 	cache                     *moduleCacher
+	sharedCache               *sharedCache
+	accessReadWriteBucket     storage.ReadWriteBucket
 	delegate                  bufmodule.ModuleReader
 	repositoryServiceProvider registryv1alpha1apiclient.RepositoryServiceProvider
+	offline                   bool
 
 	count     int
 	cacheHits int
@@ -48,6 +52,7 @@ func newModuleReader(
 	fileLocker filelock.Locker,
 	dataReadWriteBucket storage.ReadWriteBucket,
 	sumReadWriteBucket storage.ReadWriteBucket,
+	accessReadWriteBucket storage.ReadWriteBucket,
 	delegate bufmodule.ModuleReader,
 	repositoryServiceProvider registryv1alpha1apiclient.RepositoryServiceProvider,
 	options ...ModuleReaderOption,
@@ -56,6 +61,10 @@ func newModuleReader(
 	for _, option := range options {
 		option(moduleReaderOptions)
 	}
+	var sharedCache *sharedCache
+	if moduleReaderOptions.sharedCacheBlobStore != nil {
+		sharedCache = newSharedCache(logger, moduleReaderOptions.sharedCacheBlobStore)
+	}
 	return &moduleReader{
 		logger:         logger,
 		verbosePrinter: verbosePrinter,
@@ -66,8 +75,11 @@ func newModuleReader(
 			sumReadWriteBucket,
 			moduleReaderOptions.allowCacheExternalPaths,
 		),
+		accessReadWriteBucket:     accessReadWriteBucket,
+		sharedCache:               sharedCache,
 		delegate:                  delegate,
 		repositoryServiceProvider: repositoryServiceProvider,
+		offline:                   moduleReaderOptions.offline,
 	}
 }
 
@@ -89,6 +101,12 @@ func (m *moduleReader) GetModule(
 			"cache_hit",
 			zap.String("module_pin", modulePin.String()),
 		)
+		// The cached module matches its stored digest, but may not match
+		// the digest of the lock file.
+		if err := bufmodule.ValidateModuleDigest(ctx, modulePin, module); err != nil {
+			return nil, err
+		}
+		m.recordAccess(ctx, modulePin)
 		m.lock.Lock()
 		m.count++
 		m.cacheHits++
@@ -98,7 +116,6 @@ func (m *moduleReader) GetModule(
 	if !storage.IsNotExist(err) {
 		return nil, err
 	}
-
 	// We now had a IsNotExist error, so we do a write lock and check again (double locking).
 	// If we still have an error, we do a GetModule from the delegate, and put the result.
 	//
@@ -117,6 +134,12 @@ func (m *moduleReader) GetModule(
 			"cache_hit",
 			zap.String("module_pin", modulePin.String()),
 		)
+		// The cached module matches its stored digest, but may not match
+		// the digest of the lock file.
+		if err := bufmodule.ValidateModuleDigest(ctx, modulePin, module); err != nil {
+			return nil, err
+		}
+		m.recordAccess(ctx, modulePin)
 		m.lock.Lock()
 		m.count++
 		m.cacheHits++
@@ -127,6 +150,44 @@ func (m *moduleReader) GetModule(
 		return nil, err
 	}
 
+	if m.sharedCache != nil {
+		module, err = m.sharedCache.GetModule(ctx, modulePin)
+		if err == nil {
+			// We verify the module before it is put to the cache, so that a module that
+			// does not match the lock file is never cached.
+			err = bufmodule.ValidateModuleDigest(ctx, modulePin, module)
+		}
+		if err == nil {
+			m.logger.Debug(
+				"shared_cache_hit",
+				zap.String("module_pin", modulePin.String()),
+			)
+			if err := m.cache.PutModule(
+				ctx,
+				modulePin,
+				module,
+			); err != nil {
+				return nil, err
+			}
+			m.recordAccess(ctx, modulePin)
+			m.lock.Lock()
+			m.count++
+			m.lock.Unlock()
+			return module, nil
+		}
+		if !storage.IsNotExist(err) {
+			// The shared cache is only an optimization, so we fall back to the delegate.
+			m.logger.Sugar().Warnf("Could not read module %q from the shared cache: %v", modulePin.String(), err)
+		}
+	}
+	if m.offline {
+		m.logger.Debug(
+			"cache_miss_offline",
+			zap.String("module_pin", modulePin.String()),
+		)
+		return nil, fmt.Errorf("%s: %w", modulePin.String(), bufmodule.ErrModuleNotAvailableOffline)
+	}
+
 	// We now had a IsNotExist error within a write lock, so go to the delegate and then put.
 	m.logger.Debug(
 		"cache_miss",
@@ -137,6 +198,9 @@ func (m *moduleReader) GetModule(
 	if err != nil {
 		return nil, err
 	}
+	if err := bufmodule.ValidateModuleDigest(ctx, modulePin, module); err != nil {
+		return nil, err
+	}
 	if err := m.cache.PutModule(
 		ctx,
 		modulePin,
@@ -144,6 +208,13 @@ func (m *moduleReader) GetModule(
 	); err != nil {
 		return nil, err
 	}
+	m.recordAccess(ctx, modulePin)
+	if m.sharedCache != nil {
+		if err := m.sharedCache.PutModule(ctx, modulePin, module); err != nil {
+			// The shared cache is only an optimization, so this is not an error.
+			m.logger.Sugar().Warnf("Could not write module %q to the shared cache: %v", modulePin.String(), err)
+		}
+	}
 
 	repositoryService, err := m.repositoryServiceProvider.NewRepositoryService(ctx, modulePin.Remote())
 	if err != nil {
@@ -171,6 +242,19 @@ func (m *moduleReader) GetModule(
 	return module, nil
 }
 
+// recordAccess records that the module for the given pin was used now.
+//
+// This is best-effort, as failing to record the access only affects pruning.
+func (m *moduleReader) recordAccess(ctx context.Context, modulePin bufmoduleref.ModulePin) {
+	if err := putLastUsed(ctx, m.accessReadWriteBucket, newCacheKey(modulePin), time.Now()); err != nil {
+		m.logger.Debug(
+			"cache_access_record_failed",
+			zap.String("module_pin", modulePin.String()),
+			zap.Error(err),
+		)
+	}
+}
+
 func (m *moduleReader) getCount() int {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
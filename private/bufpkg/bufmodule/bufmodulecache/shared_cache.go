@@ -0,0 +1,132 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulecache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// sharedCache reads and writes modules to a BlobStore.
+//
+// Each module is stored as a single blob, so that readers never see a module without
+// its digest. The blob consists of the digest of the module, a newline, and the
+// serialized Module.
+//
+// Anyone who can write to the BlobStore can also write a matching digest, so the stored
+// digest only detects corrupted blobs. Modules are only read from the BlobStore if their
+// pin has a digest from the lock file, and are verified against it.
+type sharedCache struct {
+	logger    *zap.Logger
+	blobStore BlobStore
+}
+
+func newSharedCache(
+	logger *zap.Logger,
+	blobStore BlobStore,
+) *sharedCache {
+	return &sharedCache{
+		logger:    logger,
+		blobStore: blobStore,
+	}
+}
+
+// GetModule gets the module for the pin.
+//
+// Returns an error that fulfills storage.IsNotExist if the module is not in the
+// shared cache, if it does not match its digest, or if the pin has no digest it
+// can be verified against.
+func (s *sharedCache) GetModule(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+) (bufmodule.Module, error) {
+	key := newSharedCacheKey(modulePin)
+	if !bufmodule.ModuleDigestIsVerifiable(modulePin.Digest()) {
+		s.logger.Debug(
+			"shared_cache_skipped_no_digest",
+			zap.String("module_pin", modulePin.String()),
+		)
+		return nil, storage.NewErrNotExist(key)
+	}
+	data, err := s.blobStore.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	module, err := s.getModuleForBlob(ctx, modulePin, data)
+	if err != nil {
+		s.logger.Sugar().Warnf(
+			"Module %q has invalid shared cache state: %v. The module will be downloaded again.",
+			modulePin.String(),
+			err,
+		)
+		return nil, storage.NewErrNotExist(key)
+	}
+	return module, nil
+}
+
+// PutModule puts the module for the pin.
+func (s *sharedCache) PutModule(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+	module bufmodule.Module,
+) error {
+	moduleData, digest, err := bufmodule.ModuleToDataWithDigest(ctx, modulePin, module)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, 0, len(digest)+1+len(moduleData))
+	data = append(data, digest...)
+	data = append(data, '\n')
+	data = append(data, moduleData...)
+	return s.blobStore.Put(ctx, newSharedCacheKey(modulePin), data)
+}
+
+func (s *sharedCache) getModuleForBlob(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+	data []byte,
+) (bufmodule.Module, error) {
+	newlineIndex := bytes.IndexByte(data, '\n')
+	if newlineIndex == -1 {
+		return nil, errors.New("no stored digest could be found")
+	}
+	module, err := bufmodule.NewModuleForDataWithDigest(
+		ctx,
+		modulePin,
+		data[newlineIndex+1:],
+		string(data[:newlineIndex]),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if storedDigest := string(data[:newlineIndex]); storedDigest != modulePin.Digest() {
+		return nil, fmt.Errorf("stored digest %q does not match the digest %q of the lock file", storedDigest, modulePin.Digest())
+	}
+	return module, nil
+}
+
+// newSharedCacheKey returns the key of the blob associated with the given module pin.
+// The key is of the form: v1/module/remote/owner/repository/commit.
+func newSharedCacheKey(modulePin bufmoduleref.ModulePin) string {
+	return normalpath.Join("v1", "module", newCacheKey(modulePin))
+}
@@ -0,0 +1,239 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulecache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	registryv1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/registry/v1alpha1"
+	"github.com/bufbuild/buf/private/pkg/filelock"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/verbose"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReaderSharedCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modulePin, module := testNewSharedCacheModule(t)
+	delegate := &countingModuleReader{module: module}
+	fileLocker, err := filelock.NewLocker(t.TempDir())
+	require.NoError(t, err)
+	sharedReadWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(t.TempDir())
+	require.NoError(t, err)
+	blobStore := NewDirBlobStore(fileLocker, sharedReadWriteBucket)
+
+	// The first runner downloads the module and writes it to the shared cache.
+	_, err = testNewSharedCacheModuleReader(t, delegate, blobStore).GetModule(ctx, modulePin)
+	require.NoError(t, err)
+	require.Equal(t, 1, delegate.getCount())
+
+	// The second runner reads the module from the shared cache.
+	moduleReader := testNewSharedCacheModuleReader(t, delegate, blobStore)
+	getModule, err := moduleReader.GetModule(ctx, modulePin)
+	require.NoError(t, err)
+	require.Equal(t, 1, delegate.getCount())
+	testFile1HasNoExternalPath(t, ctx, getModule)
+	// The module is now in the local cache of the second runner.
+	_, err = moduleReader.GetModule(ctx, modulePin)
+	require.NoError(t, err)
+	require.Equal(t, 1, moduleReader.getCacheHits())
+
+	// An offline runner reads the module from the shared cache.
+	_, err = testNewSharedCacheModuleReader(t, bufmodule.NewNopModuleReader(), blobStore, ModuleReaderWithOffline()).GetModule(ctx, modulePin)
+	require.NoError(t, err)
+
+	// A corrupted module is downloaded again and overwritten in the shared cache.
+	key := newSharedCacheKey(modulePin)
+	data, err := storage.ReadPath(ctx, sharedReadWriteBucket, key)
+	require.NoError(t, err)
+	require.NoError(t, storage.PutPath(ctx, sharedReadWriteBucket, key, append([]byte("b3-foo"), data[strings.IndexByte(string(data), '\n'):]...)))
+	_, err = testNewSharedCacheModuleReader(t, delegate, blobStore).GetModule(ctx, modulePin)
+	require.NoError(t, err)
+	require.Equal(t, 2, delegate.getCount())
+	_, err = testNewSharedCacheModuleReader(t, delegate, blobStore).GetModule(ctx, modulePin)
+	require.NoError(t, err)
+	require.Equal(t, 2, delegate.getCount())
+
+	// A module that matches its stored digest, but not the digest of the lock file, is downloaded again.
+	forgedReadBucket, err := storagemem.NewReadBucket(map[string][]byte{"a.proto": []byte(`syntax = "proto3";`)})
+	require.NoError(t, err)
+	forgedModule, err := bufmodule.NewModuleForBucket(ctx, forgedReadBucket)
+	require.NoError(t, err)
+	require.NoError(t, newSharedCache(zap.NewNop(), blobStore).PutModule(ctx, modulePin, forgedModule))
+	getModule, err = testNewSharedCacheModuleReader(t, delegate, blobStore).GetModule(ctx, modulePin)
+	require.NoError(t, err)
+	require.Equal(t, 3, delegate.getCount())
+	testFile1HasNoExternalPath(t, ctx, getModule)
+
+	// A module without a digest in the lock file is never read from the shared cache.
+	modulePinWithoutDigest, err := bufmoduleref.NewModulePinWithDigest(modulePin, "")
+	require.NoError(t, err)
+	_, err = testNewSharedCacheModuleReader(t, delegate, blobStore).GetModule(ctx, modulePinWithoutDigest)
+	require.NoError(t, err)
+	require.Equal(t, 4, delegate.getCount())
+	_, err = testNewSharedCacheModuleReader(t, bufmodule.NewNopModuleReader(), blobStore, ModuleReaderWithOffline()).GetModule(ctx, modulePinWithoutDigest)
+	require.ErrorIs(t, err, bufmodule.ErrModuleNotAvailableOffline)
+}
+
+func TestHTTPBlobStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var lock sync.Mutex
+	pathToData := make(map[string][]byte)
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(responseWriter http.ResponseWriter, request *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				if request.Header.Get("Authorization") != "Bearer foo" {
+					responseWriter.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if strings.HasPrefix(request.URL.Path, "/cache/error/") {
+					responseWriter.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				switch request.Method {
+				case http.MethodGet:
+					data, ok := pathToData[request.URL.Path]
+					if !ok {
+						responseWriter.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_, _ = responseWriter.Write(data)
+				case http.MethodPut:
+					data, err := io.ReadAll(request.Body)
+					if err != nil {
+						responseWriter.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					pathToData[request.URL.Path] = data
+					responseWriter.WriteHeader(http.StatusCreated)
+				default:
+					responseWriter.WriteHeader(http.StatusMethodNotAllowed)
+				}
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	_, err := NewHTTPBlobStore(server.Client(), server.URL+"/cache/").Get(ctx, "foo/bar")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "401")
+	blobStore := NewHTTPBlobStore(server.Client(), server.URL+"/cache/", HTTPBlobStoreWithToken("foo"))
+
+	_, err = blobStore.Get(ctx, "foo/bar")
+	require.True(t, storage.IsNotExist(err))
+	require.NoError(t, blobStore.Put(ctx, "foo/bar", []byte("baz")))
+	data, err := blobStore.Get(ctx, "foo/bar")
+	require.NoError(t, err)
+	require.Equal(t, "baz", string(data))
+	_, err = blobStore.Get(ctx, "error/foo")
+	require.Error(t, err)
+	require.False(t, storage.IsNotExist(err))
+	require.Error(t, blobStore.Put(ctx, "error/foo", []byte("baz")))
+
+	// The module reader works end to end with the HTTP shared cache.
+	modulePin, module := testNewSharedCacheModule(t)
+	delegate := &countingModuleReader{module: module}
+	_, err = testNewSharedCacheModuleReader(t, delegate, blobStore).GetModule(ctx, modulePin)
+	require.NoError(t, err)
+	_, err = testNewSharedCacheModuleReader(t, delegate, blobStore).GetModule(ctx, modulePin)
+	require.NoError(t, err)
+	require.Equal(t, 1, delegate.getCount())
+}
+
+func testNewSharedCacheModule(t *testing.T) (bufmoduleref.ModulePin, bufmodule.Module) {
+	modulePin, err := bufmoduleref.NewModulePin(
+		"buf.build",
+		"foob",
+		"bar",
+		"main",
+		bufmoduletesting.TestCommit,
+		"",
+		time.Now(),
+	)
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForProto(
+		context.Background(),
+		bufmoduletesting.TestDataProto,
+		bufmodule.ModuleWithModuleIdentityAndCommit(modulePin, modulePin.Commit()),
+	)
+	require.NoError(t, err)
+	// Modules are only read from the shared cache if the pin has a digest.
+	digest, err := bufmodule.ModuleDigestB3(context.Background(), module)
+	require.NoError(t, err)
+	modulePin, err = bufmoduleref.NewModulePinWithDigest(modulePin, digest)
+	require.NoError(t, err)
+	return modulePin, module
+}
+
+// testNewSharedCacheModuleReader returns a new moduleReader with an empty local cache.
+func testNewSharedCacheModuleReader(
+	t *testing.T,
+	delegate bufmodule.ModuleReader,
+	blobStore BlobStore,
+	options ...ModuleReaderOption,
+) *moduleReader {
+	dataReadWriteBucket, sumReadWriteBucket, fileLocker := newTestDataSumBucketsAndLocker(t)
+	return newModuleReader(
+		zap.NewNop(),
+		verbose.NopPrinter,
+		fileLocker,
+		dataReadWriteBucket,
+		sumReadWriteBucket,
+		storagemem.NewReadWriteBucket(),
+		delegate,
+		&fakeRepositoryServiceProvider{
+			repositoryService: &fakeRepositoryService{
+				repository: &registryv1alpha1.Repository{},
+			},
+		},
+		append([]ModuleReaderOption{ModuleReaderWithSharedCache(blobStore)}, options...)...,
+	)
+}
+
+type countingModuleReader struct {
+	module bufmodule.Module
+
+	count int
+	lock  sync.Mutex
+}
+
+func (c *countingModuleReader) GetModule(context.Context, bufmoduleref.ModulePin) (bufmodule.Module, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.count++
+	return c.module, nil
+}
+
+func (c *countingModuleReader) getCount() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.count
+}
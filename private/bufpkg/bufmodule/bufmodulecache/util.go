@@ -15,8 +15,13 @@
 package bufmodulecache
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
 )
 
 // newCacheKey returns the key associated with the given module pin.
@@ -24,3 +29,27 @@ import (
 func newCacheKey(modulePin bufmoduleref.ModulePin) string {
 	return normalpath.Join(modulePin.Remote(), modulePin.Owner(), modulePin.Repository(), modulePin.Commit())
 }
+
+// getLastUsed returns the time at which the module with the given cache key was last used.
+//
+// Returns the zero time if no access was recorded.
+func getLastUsed(ctx context.Context, accessReadBucket storage.ReadBucket, cacheKey string) (time.Time, error) {
+	data, err := storage.ReadPath(ctx, accessReadBucket, cacheKey)
+	if err != nil {
+		if storage.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	lastUsed, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		// An unparseable access record is treated as no access record.
+		return time.Time{}, nil
+	}
+	return lastUsed, nil
+}
+
+// putLastUsed records that the module with the given cache key was last used at the given time.
+func putLastUsed(ctx context.Context, accessWriteBucket storage.WriteBucket, cacheKey string, lastUsed time.Time) error {
+	return storage.PutPath(ctx, accessWriteBucket, cacheKey, []byte(lastUsed.UTC().Format(time.RFC3339Nano)))
+}
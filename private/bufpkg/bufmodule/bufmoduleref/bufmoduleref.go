@@ -279,19 +279,67 @@ type ModulePin interface {
 	Commit() string
 	CreateTime() time.Time
 
+	// Digest is the digest of the content of the module, such as b3-<hash>.
+	//
+	// This is only set for pins read from a lock file that records digests,
+	// and is empty otherwise.
+	Digest() string
+
 	isModulePin()
 }
 
 // NewModulePin returns a new validated ModulePin.
+//
+// The digest may be empty.
 func NewModulePin(
 	remote string,
 	owner string,
 	repository string,
 	branch string,
 	commit string,
+	digest string,
 	createTime time.Time,
 ) (ModulePin, error) {
-	return newModulePin(remote, owner, repository, branch, commit, createTime)
+	return newModulePin(remote, owner, repository, branch, commit, digest, createTime)
+}
+
+// NewModulePinWithDigest returns a copy of the ModulePin with the given digest.
+func NewModulePinWithDigest(modulePin ModulePin, digest string) (ModulePin, error) {
+	return newModulePin(
+		modulePin.Remote(),
+		modulePin.Owner(),
+		modulePin.Repository(),
+		modulePin.Branch(),
+		modulePin.Commit(),
+		digest,
+		modulePin.CreateTime(),
+	)
+}
+
+// InheritModulePinDigests returns the ModulePins with the digests of the previous ModulePins
+// that have the same identity and commit.
+//
+// This is used to preserve the digests of the lock file when pins are resolved again,
+// as resolved pins have no digest.
+func InheritModulePinDigests(modulePins []ModulePin, previousModulePins []ModulePin) ([]ModulePin, error) {
+	identityToPreviousModulePin := make(map[string]ModulePin, len(previousModulePins))
+	for _, previousModulePin := range previousModulePins {
+		identityToPreviousModulePin[previousModulePin.IdentityString()] = previousModulePin
+	}
+	result := make([]ModulePin, len(modulePins))
+	for i, modulePin := range modulePins {
+		result[i] = modulePin
+		previousModulePin, ok := identityToPreviousModulePin[modulePin.IdentityString()]
+		if !ok || previousModulePin.Commit() != modulePin.Commit() || previousModulePin.Digest() == "" || modulePin.Digest() != "" {
+			continue
+		}
+		modulePinWithDigest, err := NewModulePinWithDigest(modulePin, previousModulePin.Digest())
+		if err != nil {
+			return nil, err
+		}
+		result[i] = modulePinWithDigest
+	}
+	return result, nil
 }
 
 // NewModulePinForProto returns a new ModulePin for the given proto ModulePin.
@@ -389,6 +437,7 @@ func ModulePinEqual(a ModulePin, b ModulePin) bool {
 		a.Repository() == b.Repository() &&
 		a.Branch() == b.Branch() &&
 		a.Commit() == b.Commit() &&
+		a.Digest() == b.Digest() &&
 		a.CreateTime().Equal(b.CreateTime())
 }
 
@@ -409,6 +458,7 @@ func DependencyModulePinsForBucket(
 			dep.Repository,
 			"",
 			dep.Commit,
+			dep.Digest,
 			time.Time{},
 		)
 		if err != nil {
@@ -445,6 +495,7 @@ func PutDependencyModulePinsToBucket(
 				Owner:      pin.Owner(),
 				Repository: pin.Repository(),
 				Commit:     pin.Commit(),
+				Digest:     pin.Digest(),
 			},
 		)
 	}
@@ -28,6 +28,7 @@ type modulePin struct {
 	repository string
 	branch     string
 	commit     string
+	digest     string
 	createTime time.Time
 }
 
@@ -37,13 +38,14 @@ func newModulePin(
 	repository string,
 	branch string,
 	commit string,
+	digest string,
 	createTime time.Time,
 ) (*modulePin, error) {
 	protoCreateTime, err := prototime.NewTimestamp(createTime)
 	if err != nil {
 		return nil, err
 	}
-	return newModulePinForProto(
+	modulePin, err := newModulePinForProto(
 		&modulev1alpha1.ModulePin{
 			Remote:     remote,
 			Owner:      owner,
@@ -53,6 +55,12 @@ func newModulePin(
 			CreateTime: protoCreateTime,
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+	// The proto ModulePin has no digest, so we set it separately.
+	modulePin.digest = digest
+	return modulePin, nil
 }
 
 func newModulePinForProto(
@@ -105,6 +113,10 @@ func (m *modulePin) Commit() string {
 	return m.commit
 }
 
+func (m *modulePin) Digest() string {
+	return m.digest
+}
+
 func (m *modulePin) CreateTime() time.Time {
 	return m.createTime
 }
@@ -0,0 +1,80 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmoduleref
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/require"
+)
+
+const testModulePinCommit = "e9191fcdc2294e2f8f3b82c528fc90a8"
+
+func TestDependencyModulePinsDigest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	fooModulePin := testNewModulePin(t, "foo", testModulePinCommit, "b3-foo")
+	barModulePin := testNewModulePin(t, "bar", testModulePinCommit, "")
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	require.NoError(t, PutDependencyModulePinsToBucket(ctx, readWriteBucket, []ModulePin{fooModulePin, barModulePin}))
+	modulePins, err := DependencyModulePinsForBucket(ctx, readWriteBucket)
+	require.NoError(t, err)
+	require.Len(t, modulePins, 2)
+	require.Equal(t, "bar", modulePins[0].Repository())
+	require.Empty(t, modulePins[0].Digest())
+	require.Equal(t, "foo", modulePins[1].Repository())
+	require.Equal(t, "b3-foo", modulePins[1].Digest())
+}
+
+func TestInheritModulePinDigests(t *testing.T) {
+	t.Parallel()
+	previousModulePins := []ModulePin{
+		testNewModulePin(t, "foo", testModulePinCommit, "b3-foo"),
+		testNewModulePin(t, "bar", testModulePinCommit, "b3-bar"),
+	}
+	modulePins, err := InheritModulePinDigests(
+		[]ModulePin{
+			// Same commit, inherits the digest.
+			testNewModulePin(t, "foo", testModulePinCommit, ""),
+			// Different commit, the digest no longer applies.
+			testNewModulePin(t, "bar", "f9191fcdc2294e2f8f3b82c528fc90a8", ""),
+			// New dependency.
+			testNewModulePin(t, "baz", testModulePinCommit, ""),
+		},
+		previousModulePins,
+	)
+	require.NoError(t, err)
+	require.Len(t, modulePins, 3)
+	require.Equal(t, "b3-foo", modulePins[0].Digest())
+	require.Empty(t, modulePins[1].Digest())
+	require.Empty(t, modulePins[2].Digest())
+}
+
+func testNewModulePin(t *testing.T, repository string, commit string, digest string) ModulePin {
+	modulePin, err := NewModulePin(
+		"buf.build",
+		"acme",
+		repository,
+		"",
+		commit,
+		digest,
+		time.Time{},
+	)
+	require.NoError(t, err)
+	return modulePin
+}
@@ -0,0 +1,74 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufmodulevendor manages the vendor directory of a module.
+//
+// The vendor directory contains the pinned dependencies of the module, so that
+// they can be used without accessing the network. Each dependency is stored as a
+// serialized Module together with its digest:
+//
+//	buf.vendor/remote/owner/repository/commit/module.bin
+//	buf.vendor/remote/owner/repository/commit/digest
+//
+// The dependencies are not stored as .proto files, as these would otherwise be
+// built as part of the module.
+package bufmodulevendor
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/storage"
+)
+
+// ExternalDirPath defines the path to the vendor directory, relative to the root of the module.
+const ExternalDirPath = "buf.vendor"
+
+// PutModule writes the Module for the ModulePin to the vendor directory of the WriteBucket.
+func PutModule(
+	ctx context.Context,
+	writeBucket storage.WriteBucket,
+	modulePin bufmoduleref.ModulePin,
+	module bufmodule.Module,
+) error {
+	return putModule(ctx, writeBucket, modulePin, module)
+}
+
+// GetModule reads the Module for the ModulePin from the vendor directory of the ReadBucket.
+//
+// The Module is verified against its stored digest, and against the digest of the ModulePin if set.
+// Returns an error that fufills storage.IsNotExist if the Module is not vendored.
+func GetModule(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	modulePin bufmoduleref.ModulePin,
+) (bufmodule.Module, error) {
+	return getModule(ctx, readBucket, modulePin)
+}
+
+// NewWorkspace returns a new Workspace that contains the vendored dependencies of the
+// Module, in addition to the modules of the given Workspace.
+//
+// The given Workspace may be nil. If the ReadBucket has no vendor directory, the given
+// Workspace is returned as-is. Otherwise, all dependencies of the Module must be
+// vendored.
+func NewWorkspace(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	module bufmodule.Module,
+	workspace bufmodule.Workspace,
+) (bufmodule.Workspace, error) {
+	return newWorkspace(ctx, readBucket, module, workspace)
+}
@@ -0,0 +1,151 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulevendor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutGetModule(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modulePin := newTestModulePin(t, "bar")
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		bufmoduletesting.TestDataProto,
+		bufmodule.ModuleWithModuleIdentityAndCommit(modulePin, modulePin.Commit()),
+	)
+	require.NoError(t, err)
+	readWriteBucket := storagemem.NewReadWriteBucket()
+
+	_, err = GetModule(ctx, readWriteBucket, modulePin)
+	assert.True(t, storage.IsNotExist(err))
+
+	require.NoError(t, PutModule(ctx, readWriteBucket, modulePin, module))
+	getModule, err := GetModule(ctx, readWriteBucket, modulePin)
+	require.NoError(t, err)
+	expectedDigest, err := bufmodule.ModuleDigestB3(ctx, module)
+	require.NoError(t, err)
+	digest, err := bufmodule.ModuleDigestB3(ctx, getModule)
+	require.NoError(t, err)
+	assert.Equal(t, expectedDigest, digest)
+	fileInfos, err := getModule.SourceFileInfos(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, fileInfos)
+	require.NotNil(t, fileInfos[0].ModuleIdentity())
+	assert.Equal(t, modulePin.IdentityString(), fileInfos[0].ModuleIdentity().IdentityString())
+	assert.Equal(t, modulePin.Commit(), fileInfos[0].Commit())
+
+	require.NoError(
+		t,
+		storage.PutPath(
+			ctx,
+			readWriteBucket,
+			normalpath.Join(newModuleDirPath(modulePin), digestFileName),
+			[]byte(bufmoduletesting.TestDigestB3WithLicense),
+		),
+	)
+	_, err = GetModule(ctx, readWriteBucket, modulePin)
+	require.Error(t, err)
+	assert.False(t, storage.IsNotExist(err))
+	assert.Contains(t, err.Error(), "does not match stored digest")
+}
+
+func TestNewWorkspace(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modulePin1 := newTestModulePin(t, "bar")
+	modulePin2 := newTestModulePin(t, "baz")
+	moduleReadWriteBucket := storagemem.NewReadWriteBucket()
+	require.NoError(t, storage.PutPath(ctx, moduleReadWriteBucket, "a.proto", []byte(`syntax = "proto3";`)))
+	require.NoError(
+		t,
+		bufmoduleref.PutDependencyModulePinsToBucket(
+			ctx,
+			moduleReadWriteBucket,
+			[]bufmoduleref.ModulePin{modulePin1, modulePin2},
+		),
+	)
+	module, err := bufmodule.NewModuleForBucket(ctx, moduleReadWriteBucket)
+	require.NoError(t, err)
+
+	// No vendor directory.
+	workspace, err := NewWorkspace(ctx, moduleReadWriteBucket, module, nil)
+	require.NoError(t, err)
+	assert.Nil(t, workspace)
+
+	dependencyModule, err := bufmodule.NewModuleForProto(ctx, bufmoduletesting.TestDataProto)
+	require.NoError(t, err)
+	require.NoError(t, PutModule(ctx, moduleReadWriteBucket, modulePin1, dependencyModule))
+	_, err = NewWorkspace(ctx, moduleReadWriteBucket, module, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), modulePin2.String())
+	assert.NotContains(t, err.Error(), modulePin1.String())
+
+	require.NoError(t, PutModule(ctx, moduleReadWriteBucket, modulePin2, dependencyModule))
+	workspace, err = NewWorkspace(ctx, moduleReadWriteBucket, module, nil)
+	require.NoError(t, err)
+	require.NotNil(t, workspace)
+	assert.Len(t, workspace.GetModules(), 2)
+	_, ok := workspace.GetModule(modulePin1)
+	assert.True(t, ok)
+	_, ok = workspace.GetModule(modulePin2)
+	assert.True(t, ok)
+
+	// A dependency that is provided by the workspace does not need to be vendored.
+	require.NoError(t, moduleReadWriteBucket.DeleteAll(ctx, newModuleDirPath(modulePin2)))
+	workspace, err = NewWorkspace(
+		ctx,
+		moduleReadWriteBucket,
+		module,
+		bufmodule.NewWorkspace(
+			map[string]bufmodule.Module{
+				modulePin2.IdentityString(): dependencyModule,
+			},
+			[]bufmodule.Module{
+				dependencyModule,
+			},
+		),
+	)
+	require.NoError(t, err)
+	assert.Len(t, workspace.GetModules(), 2)
+	_, ok = workspace.GetModule(modulePin2)
+	assert.True(t, ok)
+}
+
+func newTestModulePin(t *testing.T, repository string) bufmoduleref.ModulePin {
+	modulePin, err := bufmoduleref.NewModulePin(
+		"buf.build",
+		"foob",
+		repository,
+		"main",
+		bufmoduletesting.TestCommit,
+		"",
+		time.Now(),
+	)
+	require.NoError(t, err)
+	return modulePin
+}
@@ -0,0 +1,169 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulevendor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+)
+
+const (
+	moduleFileName = "module.bin"
+	digestFileName = "digest"
+)
+
+func putModule(
+	ctx context.Context,
+	writeBucket storage.WriteBucket,
+	modulePin bufmoduleref.ModulePin,
+	module bufmodule.Module,
+) error {
+	data, digest, err := bufmodule.ModuleToDataWithDigest(ctx, modulePin, module)
+	if err != nil {
+		return err
+	}
+	moduleDirPath := newModuleDirPath(modulePin)
+	if err := storage.PutPath(ctx, writeBucket, normalpath.Join(moduleDirPath, moduleFileName), data); err != nil {
+		return err
+	}
+	return storage.PutPath(ctx, writeBucket, normalpath.Join(moduleDirPath, digestFileName), []byte(digest))
+}
+
+func getModule(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	modulePin bufmoduleref.ModulePin,
+) (bufmodule.Module, error) {
+	moduleDirPath := newModuleDirPath(modulePin)
+	data, err := storage.ReadPath(ctx, readBucket, normalpath.Join(moduleDirPath, moduleFileName))
+	if err != nil {
+		return nil, err
+	}
+	storedDigestData, err := storage.ReadPath(ctx, readBucket, normalpath.Join(moduleDirPath, digestFileName))
+	if err != nil {
+		if storage.IsNotExist(err) {
+			return nil, fmt.Errorf("vendored module %q has no stored digest", modulePin.String())
+		}
+		return nil, err
+	}
+	module, err := bufmodule.NewModuleForDataWithDigest(
+		ctx,
+		modulePin,
+		data,
+		strings.TrimSpace(string(storedDigestData)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("vendored module %q is invalid: %w", modulePin.String(), err)
+	}
+	if err := bufmodule.ValidateModuleDigest(ctx, modulePin, module); err != nil {
+		return nil, fmt.Errorf("vendored module %w", err)
+	}
+	return module, nil
+}
+
+func newWorkspace(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	module bufmodule.Module,
+	workspace bufmodule.Workspace,
+) (bufmodule.Workspace, error) {
+	isEmpty, err := storage.IsEmpty(ctx, readBucket, ExternalDirPath)
+	if err != nil {
+		return nil, err
+	}
+	if isEmpty {
+		return workspace, nil
+	}
+	vendorWorkspace := &vendorWorkspace{
+		delegate:     workspace,
+		namedModules: make(map[string]bufmodule.Module),
+	}
+	var missingModulePins []bufmoduleref.ModulePin
+	for _, dependencyModulePin := range module.DependencyModulePins() {
+		if workspace != nil {
+			if _, ok := workspace.GetModule(dependencyModulePin); ok {
+				// This dependency is already provided by the workspace, so it
+				// does not need to be vendored.
+				continue
+			}
+		}
+		dependencyModule, err := getModule(ctx, readBucket, dependencyModulePin)
+		if err != nil {
+			if storage.IsNotExist(err) {
+				missingModulePins = append(missingModulePins, dependencyModulePin)
+				continue
+			}
+			return nil, err
+		}
+		vendorWorkspace.namedModules[dependencyModulePin.IdentityString()] = dependencyModule
+		vendorWorkspace.modules = append(vendorWorkspace.modules, dependencyModule)
+	}
+	if len(missingModulePins) > 0 {
+		var builder strings.Builder
+		_, _ = builder.WriteString(`The vendor directory is out of date with the buf.lock, run "buf mod vendor". The following dependencies are not vendored:`)
+		for _, missingModulePin := range missingModulePins {
+			_, _ = builder.WriteString("\n\t- " + missingModulePin.String())
+		}
+		return nil, fmt.Errorf("%s: %s", ExternalDirPath, builder.String())
+	}
+	return vendorWorkspace, nil
+}
+
+// vendorWorkspace is a Workspace that contains vendored modules in addition
+// to the modules of the delegate, which may be nil.
+type vendorWorkspace struct {
+	delegate bufmodule.Workspace
+	// bufmoduleref.ModuleIdentity -> bufmodule.Module
+	namedModules map[string]bufmodule.Module
+	modules      []bufmodule.Module
+}
+
+func (w *vendorWorkspace) GetModule(moduleIdentity bufmoduleref.ModuleIdentity) (bufmodule.Module, bool) {
+	if w.delegate != nil {
+		if module, ok := w.delegate.GetModule(moduleIdentity); ok {
+			return module, true
+		}
+	}
+	module, ok := w.namedModules[moduleIdentity.IdentityString()]
+	return module, ok
+}
+
+func (w *vendorWorkspace) GetModules() []bufmodule.Module {
+	if w.delegate == nil {
+		return w.modules
+	}
+	delegateModules := w.delegate.GetModules()
+	modules := make([]bufmodule.Module, 0, len(delegateModules)+len(w.modules))
+	modules = append(modules, delegateModules...)
+	return append(modules, w.modules...)
+}
+
+// newModuleDirPath returns the path of the directory of the vendored module for the module pin.
+// The path is of the form: buf.vendor/remote/owner/repository/commit.
+func newModuleDirPath(modulePin bufmoduleref.ModulePin) string {
+	return normalpath.Join(
+		ExternalDirPath,
+		modulePin.Remote(),
+		modulePin.Owner(),
+		modulePin.Repository(),
+		modulePin.Commit(),
+	)
+}
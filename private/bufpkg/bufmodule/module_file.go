@@ -15,23 +15,34 @@
 package bufmodule
 
 import (
-	"io"
-
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/storage"
 )
 
 var _ ModuleFile = &moduleFile{}
 
 type moduleFile struct {
 	bufmoduleref.FileInfo
-	io.ReadCloser
+	readObjectCloser storage.ReadObjectCloser
 }
 
-func newModuleFile(fileInfo bufmoduleref.FileInfo, readCloser io.ReadCloser) moduleFile {
+func newModuleFile(fileInfo bufmoduleref.FileInfo, readObjectCloser storage.ReadObjectCloser) moduleFile {
 	return moduleFile{
-		FileInfo:   fileInfo,
-		ReadCloser: readCloser,
+		FileInfo:         fileInfo,
+		readObjectCloser: readObjectCloser,
 	}
 }
 
+func (m moduleFile) Read(p []byte) (int, error) {
+	return m.readObjectCloser.Read(p)
+}
+
+func (m moduleFile) Close() error {
+	return m.readObjectCloser.Close()
+}
+
+func (m moduleFile) Size() int64 {
+	return m.readObjectCloser.Size()
+}
+
 func (moduleFile) isModuleFile() {}
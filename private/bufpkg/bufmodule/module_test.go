@@ -75,6 +75,7 @@ func TestNewModuleForBucket(t *testing.T) {
 		"baz",
 		"",
 		"62f35d8aed1149c291d606d958a7ce32",
+		"",
 		time.Time{},
 	)
 	require.NoError(t, err)
@@ -122,3 +123,35 @@ deps:
 		"",
 	)
 }
+
+func TestModuleDataWithDigest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modulePin, err := bufmoduleref.NewModulePin(
+		"foo",
+		"bar",
+		"baz",
+		"",
+		"62f35d8aed1149c291d606d958a7ce32",
+		"",
+		time.Time{},
+	)
+	require.NoError(t, err)
+	bucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"a.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	module, err := newModuleForBucket(ctx, bucket)
+	require.NoError(t, err)
+	data, digest, err := ModuleToDataWithDigest(ctx, modulePin, module)
+	require.NoError(t, err)
+	readModule, err := NewModuleForDataWithDigest(ctx, modulePin, data, digest)
+	require.NoError(t, err)
+	readDigest, err := ModuleDigestB3(ctx, readModule)
+	require.NoError(t, err)
+	assert.Equal(t, digest, readDigest)
+	_, err = NewModuleForDataWithDigest(ctx, modulePin, data, "b3-invalid")
+	assert.Error(t, err)
+}
@@ -16,13 +16,17 @@ package bufmodule
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	modulev1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/module/v1alpha1"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"go.uber.org/multierr"
+	"google.golang.org/protobuf/proto"
 )
 
 func putModuleFileToBucket(ctx context.Context, module Module, path string, writeBucket storage.WriteBucket) (retErr error) {
@@ -125,3 +129,93 @@ func modulePinCompareToOnlyCommit(a bufmoduleref.ModulePin, b bufmoduleref.Modul
 	}
 	return 0
 }
+
+func newDependenciesNotAvailableOfflineError(dependencyModulePins []bufmoduleref.ModulePin) error {
+	var builder strings.Builder
+	_, _ = builder.WriteString("the following dependencies are neither in the module cache nor vendored, and cannot be downloaded offline:")
+	for _, dependencyModulePin := range dependencyModulePins {
+		_, _ = builder.WriteString("\n\t- " + dependencyModulePin.String())
+	}
+	return errors.New(builder.String())
+}
+
+func validateModuleDigest(ctx context.Context, modulePin bufmoduleref.ModulePin, module Module) error {
+	expectedDigest := modulePin.Digest()
+	if !moduleDigestIsVerifiable(expectedDigest) {
+		return nil
+	}
+	digest, err := ModuleDigestB3(ctx, module)
+	if err != nil {
+		return err
+	}
+	if digest != expectedDigest {
+		return fmt.Errorf(
+			"%s has digest %q but the lock file expects %q: %w",
+			modulePin.String(),
+			digest,
+			expectedDigest,
+			ErrModuleDigestMismatch,
+		)
+	}
+	return nil
+}
+
+func moduleToDataWithDigest(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+	module Module,
+) ([]byte, string, error) {
+	protoModule, err := ModuleToProtoModule(ctx, module)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := proto.Marshal(protoModule)
+	if err != nil {
+		return nil, "", err
+	}
+	storedModule, err := NewModuleForProto(
+		ctx,
+		protoModule,
+		ModuleWithModuleIdentityAndCommit(modulePin, modulePin.Commit()),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	digest, err := ModuleDigestB3(ctx, storedModule)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, digest, nil
+}
+
+func newModuleForDataWithDigest(
+	ctx context.Context,
+	modulePin bufmoduleref.ModulePin,
+	data []byte,
+	storedDigest string,
+) (Module, error) {
+	protoModule := &modulev1alpha1.Module{}
+	if err := proto.Unmarshal(data, protoModule); err != nil {
+		return nil, err
+	}
+	module, err := NewModuleForProto(
+		ctx,
+		protoModule,
+		ModuleWithModuleIdentityAndCommit(modulePin, modulePin.Commit()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := ModuleDigestB3(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	if digest != storedDigest {
+		return nil, fmt.Errorf("calculated digest %q does not match stored digest %q", digest, storedDigest)
+	}
+	return module, nil
+}
+
+func moduleDigestIsVerifiable(digest string) bool {
+	return strings.HasPrefix(digest, b3DigestPrefix+"-")
+}
@@ -58,6 +58,15 @@ func BuilderWithTimeout(defaultTimeout time.Duration) BuilderOption {
 	}
 }
 
+// BuilderWithInterceptor returns a new BuilderOption that adds the interceptor to all run functions.
+//
+// The interceptor is applied before the interceptors given to NewRunFunc.
+func BuilderWithInterceptor(interceptor Interceptor) BuilderOption {
+	return func(builder *builder) {
+		builder.interceptors = append(builder.interceptors, interceptor)
+	}
+}
+
 // BuilderWithTracing enables zap tracing for the builder.
 func BuilderWithTracing() BuilderOption {
 	return func(builder *builder) {
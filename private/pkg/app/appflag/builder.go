@@ -51,6 +51,8 @@ type builder struct {
 	defaultTimeout time.Duration
 
 	tracing bool
+
+	interceptors []Interceptor
 }
 
 func newBuilder(appName string, options ...BuilderOption) *builder {
@@ -91,7 +93,10 @@ func (b *builder) NewRunFunc(
 	f func(context.Context, Container) error,
 	interceptors ...Interceptor,
 ) func(context.Context, app.Container) error {
-	interceptor := chainInterceptors(interceptors...)
+	allInterceptors := make([]Interceptor, 0, len(b.interceptors)+len(interceptors))
+	allInterceptors = append(allInterceptors, b.interceptors...)
+	allInterceptors = append(allInterceptors, interceptors...)
+	interceptor := chainInterceptors(allInterceptors...)
 	return func(ctx context.Context, appContainer app.Container) error {
 		if interceptor != nil {
 			return b.run(ctx, appContainer, interceptor(f))
@@ -126,29 +126,56 @@ func NewHandler(
 	for _, option := range options {
 		option(handlerOptions)
 	}
+	pluginPath, isProtocProxy, err := findPluginPath(pluginName, handlerOptions)
+	if err != nil {
+		return nil, err
+	}
+	if isProtocProxy {
+		return newProtocProxyHandler(logger, storageosProvider, runner, pluginPath, pluginName), nil
+	}
+	return newBinaryHandler(logger, runner, pluginPath), nil
+}
+
+// FindPluginPath returns the path to the binary that the Handler returned by
+// NewHandler with the same plugin name and options runs.
+//
+// If the plugin is built in to protoc, this is the path to protoc.
+func FindPluginPath(pluginName string, options ...HandlerOption) (string, error) {
+	handlerOptions := newHandlerOptions()
+	for _, option := range options {
+		option(handlerOptions)
+	}
+	pluginPath, _, err := findPluginPath(pluginName, handlerOptions)
+	return pluginPath, err
+}
+
+// findPluginPath returns the path to the binary of the plugin, and whether the
+// binary is protoc, which is proxied to for its builtin plugins.
+func findPluginPath(pluginName string, handlerOptions *handlerOptions) (string, bool, error) {
 	if handlerOptions.pluginPath != "" {
 		pluginPath, err := exec.LookPath(handlerOptions.pluginPath)
 		if err != nil {
-			return nil, err
+			return "", false, err
 		}
-		return newBinaryHandler(logger, runner, pluginPath), nil
+		return pluginPath, false, nil
 	}
 	pluginPath, err := exec.LookPath("protoc-gen-" + pluginName)
 	if err == nil {
-		return newBinaryHandler(logger, runner, pluginPath), nil
+		return pluginPath, false, nil
 	}
 	// we always look for protoc-gen-X first, but if not, check the builtins
 	if _, ok := ProtocProxyPluginNames[pluginName]; ok {
-		if handlerOptions.protocPath == "" {
-			handlerOptions.protocPath = "protoc"
+		protocPath := handlerOptions.protocPath
+		if protocPath == "" {
+			protocPath = "protoc"
 		}
-		protocPath, err := exec.LookPath(handlerOptions.protocPath)
+		protocPath, err := exec.LookPath(protocPath)
 		if err != nil {
-			return nil, err
+			return "", false, err
 		}
-		return newProtocProxyHandler(logger, storageosProvider, runner, protocPath, pluginName), nil
+		return protocPath, true, nil
 	}
-	return nil, fmt.Errorf(
+	return "", false, fmt.Errorf(
 		"could not find protoc plugin for name %s - please make sure protoc-gen-%s is installed and present on your $PATH",
 		pluginName,
 		pluginName,
@@ -0,0 +1,94 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bytesize parses and formats human-readable byte sizes.
+package bytesize
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// unitToMultiplier maps the case-insensitive units accepted by Parse to their number of bytes.
+	unitToMultiplier = map[string]int64{
+		"":    1,
+		"b":   1,
+		"kb":  1000,
+		"mb":  1000 * 1000,
+		"gb":  1000 * 1000 * 1000,
+		"tb":  1000 * 1000 * 1000 * 1000,
+		"kib": 1 << 10,
+		"mib": 1 << 20,
+		"gib": 1 << 30,
+		"tib": 1 << 40,
+	}
+	// formatUnits are the units used by Format, from largest to smallest.
+	formatUnits = []formatUnit{
+		{name: "TB", multiplier: 1000 * 1000 * 1000 * 1000},
+		{name: "GB", multiplier: 1000 * 1000 * 1000},
+		{name: "MB", multiplier: 1000 * 1000},
+		{name: "KB", multiplier: 1000},
+	}
+)
+
+// Parse parses the given size into a number of bytes.
+//
+// The size is a non-negative number optionally followed by a unit, such as
+// 100, 512B, 1.5KB, 500MB, 2GB, 1TB, or one of the binary units KiB, MiB, GiB, TiB.
+// Units are case-insensitive.
+func Parse(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("size is empty")
+	}
+	numberEnd := strings.IndexFunc(
+		s,
+		func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.'
+		},
+	)
+	if numberEnd == -1 {
+		numberEnd = len(s)
+	}
+	number, err := strconv.ParseFloat(s[:numberEnd], 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q: must start with a non-negative number", s)
+	}
+	multiplier, ok := unitToMultiplier[strings.ToLower(strings.TrimSpace(s[numberEnd:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, strings.TrimSpace(s[numberEnd:]))
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
+// Format formats the given number of bytes into a human-readable size.
+//
+// The largest decimal unit for which the size is at least one is used,
+// for example 1.5MB or 512B.
+func Format(size int64) string {
+	for _, formatUnit := range formatUnits {
+		if size >= formatUnit.multiplier {
+			return strconv.FormatFloat(float64(size)/float64(formatUnit.multiplier), 'f', 1, 64) + formatUnit.name
+		}
+	}
+	return strconv.FormatInt(size, 10) + "B"
+}
+
+type formatUnit struct {
+	name       string
+	multiplier int64
+}
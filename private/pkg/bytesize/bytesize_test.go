@@ -0,0 +1,62 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bytesize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	testParse(t, "0", 0)
+	testParse(t, "100", 100)
+	testParse(t, "512B", 512)
+	testParse(t, "1.5KB", 1500)
+	testParse(t, "500MB", 500*1000*1000)
+	testParse(t, "2gb", 2*1000*1000*1000)
+	testParse(t, "2 GB", 2*1000*1000*1000)
+	testParse(t, "1TB", 1000*1000*1000*1000)
+	testParse(t, "1KiB", 1024)
+	testParse(t, "3MiB", 3*1024*1024)
+	testParse(t, "1GiB", 1024*1024*1024)
+	testParseError(t, "")
+	testParseError(t, "MB")
+	testParseError(t, "-1MB")
+	testParseError(t, "1.2.3MB")
+	testParseError(t, "10XB")
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "0B", Format(0))
+	require.Equal(t, "999B", Format(999))
+	require.Equal(t, "1.0KB", Format(1000))
+	require.Equal(t, "1.5MB", Format(1500*1000))
+	require.Equal(t, "2.0GB", Format(2*1000*1000*1000))
+	require.Equal(t, "1.2TB", Format(1234*1000*1000*1000))
+}
+
+func testParse(t *testing.T, s string, expected int64) {
+	size, err := Parse(s)
+	require.NoError(t, err)
+	require.Equal(t, expected, size)
+}
+
+func testParseError(t *testing.T, s string) {
+	_, err := Parse(s)
+	require.Error(t, err)
+}
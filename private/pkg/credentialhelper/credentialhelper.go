@@ -0,0 +1,62 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelper implements the client side of the docker credential helper protocol.
+//
+// A credential helper is a binary that is called with a single argument, one of
+// "get", "store" and "erase", and that stores credentials for server URLs.
+//
+// See https://github.com/docker/docker-credential-helpers for the protocol.
+package credentialhelper
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+)
+
+// Credentials are the credentials for a server.
+type Credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// Helper is a credential helper.
+type Helper interface {
+	// Name returns the name of the binary of the credential helper.
+	Name() string
+	// Get returns the credentials for the server URL.
+	//
+	// Returns nil if there are no credentials for the server URL.
+	Get(ctx context.Context, serverURL string) (*Credentials, error)
+	// Store stores the credentials.
+	Store(ctx context.Context, credentials *Credentials) error
+	// Erase erases the credentials for the server URL.
+	//
+	// Returns false if there were no credentials for the server URL.
+	Erase(ctx context.Context, serverURL string) (bool, error)
+}
+
+// NewHelper returns a new Helper for the binary with the given name.
+//
+// The binary is run with the environment of the container.
+func NewHelper(
+	envContainer app.EnvContainer,
+	runner command.Runner,
+	name string,
+) Helper {
+	return newHelper(envContainer, runner, name)
+}
@@ -0,0 +1,113 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+)
+
+// credentialsNotFoundMessage is the message that credential helpers print to stdout
+// if there are no credentials for a server URL.
+const credentialsNotFoundMessage = "credentials not found"
+
+type helper struct {
+	envContainer app.EnvContainer
+	runner       command.Runner
+	name         string
+}
+
+func newHelper(
+	envContainer app.EnvContainer,
+	runner command.Runner,
+	name string,
+) *helper {
+	return &helper{
+		envContainer: envContainer,
+		runner:       runner,
+		name:         name,
+	}
+}
+
+func (h *helper) Name() string {
+	return h.name
+}
+
+func (h *helper) Get(ctx context.Context, serverURL string) (*Credentials, error) {
+	output, found, err := h.run(ctx, "get", []byte(serverURL))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal(output, credentials); err != nil {
+		return nil, fmt.Errorf("credential helper %s returned invalid credentials: %w", h.name, err)
+	}
+	return credentials, nil
+}
+
+func (h *helper) Store(ctx context.Context, credentials *Credentials) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	_, _, err = h.run(ctx, "store", data)
+	return err
+}
+
+func (h *helper) Erase(ctx context.Context, serverURL string) (bool, error) {
+	_, found, err := h.run(ctx, "erase", []byte(serverURL))
+	return found, err
+}
+
+// run runs the credential helper with the action and input, and returns its output.
+//
+// Returns false if the credential helper reported that there are no credentials
+// for the server URL.
+func (h *helper) run(ctx context.Context, action string, input []byte) ([]byte, bool, error) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	if err := h.runner.Run(
+		ctx,
+		h.name,
+		command.RunWithArgs(action),
+		command.RunWithEnv(app.EnvironMap(h.envContainer)),
+		command.RunWithStdin(bytes.NewReader(input)),
+		command.RunWithStdout(stdout),
+		command.RunWithStderr(stderr),
+	); err != nil {
+		// Credential helpers print errors to stdout.
+		message := strings.TrimSpace(stdout.String())
+		if strings.Contains(message, credentialsNotFoundMessage) {
+			return nil, false, nil
+		}
+		if message == "" {
+			message = strings.TrimSpace(stderr.String())
+		}
+		if message != "" {
+			return nil, false, fmt.Errorf("credential helper %s %s: %s: %w", h.name, action, message, err)
+		}
+		return nil, false, fmt.Errorf("credential helper %s %s: %w", h.name, action, err)
+	}
+	return stdout.Bytes(), true, nil
+}
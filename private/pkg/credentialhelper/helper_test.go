@@ -0,0 +1,104 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelper(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("test credential helpers are shell scripts")
+	}
+	ctx := context.Background()
+	helper := NewHelper(
+		app.NewEnvContainer(nil),
+		command.NewRunner(),
+		testWriteHelperScript(t),
+	)
+	credentials, err := helper.Get(ctx, "buf.build")
+	require.NoError(t, err)
+	assert.Nil(t, credentials)
+	found, err := helper.Erase(ctx, "buf.build")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	expectedCredentials := &Credentials{
+		ServerURL: "buf.build",
+		Username:  "foo",
+		Secret:    "bar",
+	}
+	require.NoError(t, helper.Store(ctx, expectedCredentials))
+	credentials, err = helper.Get(ctx, "buf.build")
+	require.NoError(t, err)
+	assert.Equal(t, expectedCredentials, credentials)
+	found, err = helper.Erase(ctx, "buf.build")
+	require.NoError(t, err)
+	assert.True(t, found)
+	credentials, err = helper.Get(ctx, "buf.build")
+	require.NoError(t, err)
+	assert.Nil(t, credentials)
+
+	_, err = NewHelper(
+		app.NewEnvContainer(nil),
+		command.NewRunner(),
+		filepath.Join(t.TempDir(), "buf-credential-missing"),
+	).Get(ctx, "buf.build")
+	assert.Error(t, err)
+}
+
+// testWriteHelperScript writes a credential helper that stores the credentials
+// for a single server URL in a file, and returns its path.
+func testWriteHelperScript(t *testing.T) string {
+	dirPath := t.TempDir()
+	credentialsFilePath := filepath.Join(dirPath, "credentials")
+	helperPath := filepath.Join(dirPath, "buf-credential-test")
+	script := fmt.Sprintf(`#!/bin/sh
+case "$1" in
+  store)
+    cat > %[1]s
+    ;;
+  get)
+    if [ ! -f %[1]s ]; then
+      echo "credentials not found in native keychain"
+      exit 1
+    fi
+    cat %[1]s
+    ;;
+  erase)
+    if [ ! -f %[1]s ]; then
+      echo "credentials not found in native keychain"
+      exit 1
+    fi
+    rm %[1]s
+    ;;
+esac
+`,
+		credentialsFilePath,
+	)
+	require.NoError(t, os.WriteFile(helperPath, []byte(script), 0700))
+	return helperPath
+}
@@ -24,17 +24,21 @@ import (
 )
 
 type jsonMarshaler struct {
-	resolver      Resolver
-	indent        string
-	useProtoNames bool
+	resolver        Resolver
+	indent          string
+	useProtoNames   bool
+	emitUnpopulated bool
+	useEnumNumbers  bool
 }
 
-func newJSONMarshaler(resolver Resolver, indent string, useProtoNames bool) Marshaler {
-	return &jsonMarshaler{
-		resolver:      resolver,
-		indent:        indent,
-		useProtoNames: useProtoNames,
+func newJSONMarshaler(resolver Resolver, options ...JSONMarshalerOption) *jsonMarshaler {
+	jsonMarshaler := &jsonMarshaler{
+		resolver: resolver,
 	}
+	for _, option := range options {
+		option(jsonMarshaler)
+	}
+	return jsonMarshaler
 }
 
 func (m *jsonMarshaler) Marshal(message proto.Message) ([]byte, error) {
@@ -42,9 +46,11 @@ func (m *jsonMarshaler) Marshal(message proto.Message) ([]byte, error) {
 		return nil, err
 	}
 	options := protojson.MarshalOptions{
-		Resolver:      m.resolver,
-		Indent:        m.indent,
-		UseProtoNames: m.useProtoNames,
+		Resolver:        m.resolver,
+		Indent:          m.indent,
+		UseProtoNames:   m.useProtoNames,
+		EmitUnpopulated: m.emitUnpopulated,
+		UseEnumNumbers:  m.useEnumNumbers,
 	}
 	data, err := options.Marshal(message)
 	if err != nil {
@@ -54,8 +54,8 @@ func NewWireMarshaler() Marshaler {
 //
 // This has the potential to be unstable over time.
 // resolver can be nil if unknown and are only needed for extensions.
-func NewJSONMarshaler(resolver Resolver) Marshaler {
-	return newJSONMarshaler(resolver, "", false)
+func NewJSONMarshaler(resolver Resolver, options ...JSONMarshalerOption) Marshaler {
+	return newJSONMarshaler(resolver, options...)
 }
 
 // NewJSONMarshalerIndent returns a new Marshaler for JSON with indents.
@@ -63,7 +63,7 @@ func NewJSONMarshaler(resolver Resolver) Marshaler {
 // This has the potential to be unstable over time.
 // resolver can be nil if unknown and are only needed for extensions.
 func NewJSONMarshalerIndent(resolver Resolver) Marshaler {
-	return newJSONMarshaler(resolver, "  ", false)
+	return newJSONMarshaler(resolver, JSONMarshalerWithIndent())
 }
 
 // NewJSONMarshalerUseProtoNames returns a new Marshaler for JSON using the proto names for keys.
@@ -71,7 +71,57 @@ func NewJSONMarshalerIndent(resolver Resolver) Marshaler {
 // This has the potential to be unstable over time.
 // resolver can be nil if unknown and are only needed for extensions.
 func NewJSONMarshalerUseProtoNames(resolver Resolver) Marshaler {
-	return newJSONMarshaler(resolver, "", true)
+	return newJSONMarshaler(resolver, JSONMarshalerWithUseProtoNames())
+}
+
+// JSONMarshalerOption is an option for a new JSON or YAML Marshaler.
+type JSONMarshalerOption func(*jsonMarshaler)
+
+// JSONMarshalerWithIndent says to use indents.
+func JSONMarshalerWithIndent() JSONMarshalerOption {
+	return func(jsonMarshaler *jsonMarshaler) {
+		jsonMarshaler.indent = "  "
+	}
+}
+
+// JSONMarshalerWithUseProtoNames says to use the proto names for keys instead of the JSON names.
+func JSONMarshalerWithUseProtoNames() JSONMarshalerOption {
+	return func(jsonMarshaler *jsonMarshaler) {
+		jsonMarshaler.useProtoNames = true
+	}
+}
+
+// JSONMarshalerWithEmitUnpopulated says to emit fields that are not populated,
+// with their default values.
+func JSONMarshalerWithEmitUnpopulated() JSONMarshalerOption {
+	return func(jsonMarshaler *jsonMarshaler) {
+		jsonMarshaler.emitUnpopulated = true
+	}
+}
+
+// JSONMarshalerWithUseEnumNumbers says to use the numbers of enum values instead of their names.
+func JSONMarshalerWithUseEnumNumbers() JSONMarshalerOption {
+	return func(jsonMarshaler *jsonMarshaler) {
+		jsonMarshaler.useEnumNumbers = true
+	}
+}
+
+// NewTextMarshaler returns a new Marshaler for the Protobuf text format.
+//
+// This has the potential to be unstable over time.
+// resolver can be nil if unknown and are only needed for extensions and Any.
+func NewTextMarshaler(resolver Resolver) Marshaler {
+	return newTextMarshaler(resolver)
+}
+
+// NewYAMLMarshaler returns a new Marshaler for YAML.
+//
+// The YAML is the JSON mapping of the message in the YAML block style.
+//
+// This has the potential to be unstable over time.
+// resolver can be nil if unknown and are only needed for extensions and Any.
+func NewYAMLMarshaler(resolver Resolver, options ...JSONMarshalerOption) Marshaler {
+	return newYAMLMarshaler(resolver, options...)
 }
 
 // Unmarshaler unmarshals Messages.
@@ -88,7 +138,50 @@ func NewWireUnmarshaler(resolver Resolver) Unmarshaler {
 
 // NewJSONUnmarshaler returns a new Unmarshaler for json.
 //
+// Unknown fields are discarded by default.
+//
 // resolver can be nil if unknown and are only needed for extensions.
-func NewJSONUnmarshaler(resolver Resolver) Unmarshaler {
-	return newJSONUnmarshaler(resolver)
+func NewJSONUnmarshaler(resolver Resolver, options ...JSONUnmarshalerOption) Unmarshaler {
+	return newJSONUnmarshaler(resolver, options...)
+}
+
+// JSONUnmarshalerOption is an option for a new JSON or YAML Unmarshaler.
+type JSONUnmarshalerOption func(*jsonUnmarshaler)
+
+// JSONUnmarshalerWithDisallowUnknown says to return an error for unknown fields
+// instead of discarding them.
+func JSONUnmarshalerWithDisallowUnknown() JSONUnmarshalerOption {
+	return func(jsonUnmarshaler *jsonUnmarshaler) {
+		jsonUnmarshaler.disallowUnknown = true
+	}
+}
+
+// NewTextUnmarshaler returns a new Unmarshaler for the Protobuf text format.
+//
+// Unknown fields result in an error by default.
+//
+// resolver can be nil if unknown and are only needed for extensions and Any.
+func NewTextUnmarshaler(resolver Resolver, options ...TextUnmarshalerOption) Unmarshaler {
+	return newTextUnmarshaler(resolver, options...)
+}
+
+// TextUnmarshalerOption is an option for a new text Unmarshaler.
+type TextUnmarshalerOption func(*textUnmarshaler)
+
+// TextUnmarshalerWithDiscardUnknown says to discard unknown fields instead of
+// returning an error.
+func TextUnmarshalerWithDiscardUnknown() TextUnmarshalerOption {
+	return func(textUnmarshaler *textUnmarshaler) {
+		textUnmarshaler.discardUnknown = true
+	}
+}
+
+// NewYAMLUnmarshaler returns a new Unmarshaler for YAML.
+//
+// The YAML is read as the JSON mapping of the message, so both the JSON names and the
+// proto names can be used for keys. Unknown fields are discarded by default.
+//
+// resolver can be nil if unknown and are only needed for extensions and Any.
+func NewYAMLUnmarshaler(resolver Resolver, options ...JSONUnmarshalerOption) Unmarshaler {
+	return newYAMLUnmarshaler(resolver, options...)
 }
@@ -0,0 +1,119 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoencoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestYAMLRoundTrip(t *testing.T) {
+	t.Parallel()
+	message := testNewFieldDescriptorProto()
+	data, err := NewYAMLMarshaler(nil, JSONMarshalerWithUseProtoNames()).Marshal(message)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`name: "123"
+number: 5
+default_value: |-
+  a: b
+  c
+json_name: "true"
+options:
+  deprecated: true
+`,
+		string(data),
+	)
+	roundTripMessage := &descriptorpb.FieldDescriptorProto{}
+	require.NoError(t, NewYAMLUnmarshaler(nil).Unmarshal(data, roundTripMessage))
+	assert.True(t, proto.Equal(message, roundTripMessage))
+}
+
+func TestYAMLUnmarshal(t *testing.T) {
+	t.Parallel()
+	message := &descriptorpb.FieldDescriptorProto{}
+	require.NoError(
+		t,
+		NewYAMLUnmarshaler(nil).Unmarshal(
+			[]byte(`# Comments are allowed.
+name: "123"
+number: 0x5
+defaultValue: "a: b\nc"
+jsonName: "true"
+options: {deprecated: true}
+`),
+			message,
+		),
+	)
+	assert.True(t, proto.Equal(testNewFieldDescriptorProto(), message))
+	assert.Error(t, NewYAMLUnmarshaler(nil).Unmarshal([]byte(`? [a]: b`), message))
+	assert.NoError(t, NewYAMLUnmarshaler(nil).Unmarshal([]byte(`unknown: 1`), message))
+	assert.Error(t, NewYAMLUnmarshaler(nil, JSONUnmarshalerWithDisallowUnknown()).Unmarshal([]byte(`unknown: 1`), message))
+}
+
+func TestJSONMarshalerOptions(t *testing.T) {
+	t.Parallel()
+	message := &descriptorpb.FieldDescriptorProto{
+		JsonName: proto.String("foo"),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+	}
+	data, err := NewJSONMarshaler(nil).Marshal(message)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"TYPE_INT32","jsonName":"foo"}`, string(data))
+	data, err = NewJSONMarshaler(
+		nil,
+		JSONMarshalerWithUseProtoNames(),
+		JSONMarshalerWithUseEnumNumbers(),
+	).Marshal(message)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":5,"json_name":"foo"}`, string(data))
+	data, err = NewJSONMarshaler(nil, JSONMarshalerWithEmitUnpopulated()).Marshal(&descriptorpb.SourceCodeInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"location":[]}`, string(data))
+}
+
+func TestTextUnmarshalerOptions(t *testing.T) {
+	t.Parallel()
+	message := &descriptorpb.FieldDescriptorProto{}
+	assert.Error(t, NewTextUnmarshaler(nil).Unmarshal([]byte(`unknown: 1`), message))
+	assert.NoError(t, NewTextUnmarshaler(nil, TextUnmarshalerWithDiscardUnknown()).Unmarshal([]byte(`unknown: 1`), message))
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	t.Parallel()
+	message := testNewFieldDescriptorProto()
+	data, err := NewTextMarshaler(nil).Marshal(message)
+	require.NoError(t, err)
+	roundTripMessage := &descriptorpb.FieldDescriptorProto{}
+	require.NoError(t, NewTextUnmarshaler(nil).Unmarshal(data, roundTripMessage))
+	assert.True(t, proto.Equal(message, roundTripMessage))
+}
+
+func testNewFieldDescriptorProto() *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:         proto.String("123"),
+		Number:       proto.Int32(5),
+		DefaultValue: proto.String("a: b\nc"),
+		JsonName:     proto.String("true"),
+		Options: &descriptorpb.FieldOptions{
+			Deprecated: proto.Bool(true),
+		},
+	}
+}
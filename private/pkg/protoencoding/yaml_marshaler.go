@@ -0,0 +1,56 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoencoding
+
+import (
+	"github.com/bufbuild/buf/private/pkg/encoding"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+type yamlMarshaler struct {
+	jsonMarshaler Marshaler
+}
+
+func newYAMLMarshaler(resolver Resolver, options ...JSONMarshalerOption) *yamlMarshaler {
+	return &yamlMarshaler{
+		jsonMarshaler: newJSONMarshaler(resolver, options...),
+	}
+}
+
+func (m *yamlMarshaler) Marshal(message proto.Message) ([]byte, error) {
+	data, err := m.jsonMarshaler.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML, and decoding into a yaml.Node keeps the order of the fields.
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(data, node); err != nil {
+		return nil, err
+	}
+	resetYAMLNodeStyle(node)
+	return encoding.MarshalYAML(node)
+}
+
+// resetYAMLNodeStyle resets the flow and quoting styles of the JSON that was
+// decoded into the node, so that the node is encoded in the block style.
+//
+// Strings that would be read as another type without quotes are still quoted.
+func resetYAMLNodeStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLNodeStyle(child)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoencoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+type yamlUnmarshaler struct {
+	jsonUnmarshaler Unmarshaler
+}
+
+func newYAMLUnmarshaler(resolver Resolver, options ...JSONUnmarshalerOption) *yamlUnmarshaler {
+	return &yamlUnmarshaler{
+		jsonUnmarshaler: newJSONUnmarshaler(resolver, options...),
+	}
+}
+
+func (m *yamlUnmarshaler) Unmarshal(data []byte, message proto.Message) error {
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(data, node); err != nil {
+		return err
+	}
+	buffer := bytes.NewBuffer(nil)
+	if err := writeYAMLNodeAsJSON(buffer, node); err != nil {
+		return err
+	}
+	return m.jsonUnmarshaler.Unmarshal(buffer.Bytes(), message)
+}
+
+// writeYAMLNodeAsJSON writes the node as the equivalent JSON, keeping the order of the fields.
+//
+// We do not decode into a map, as YAML allows keys that are not strings, such as the
+// integer keys of map fields, which cannot be encoded by encoding/json.
+func writeYAMLNodeAsJSON(buffer *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			_, _ = buffer.WriteString("null")
+			return nil
+		}
+		return writeYAMLNodeAsJSON(buffer, node.Content[0])
+	case yaml.AliasNode:
+		return writeYAMLNodeAsJSON(buffer, node.Alias)
+	case yaml.MappingNode:
+		_ = buffer.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: mapping keys must be scalars", key.Line)
+			}
+			if i > 0 {
+				_ = buffer.WriteByte(',')
+			}
+			if err := writeJSONValue(buffer, key.Value); err != nil {
+				return err
+			}
+			_ = buffer.WriteByte(':')
+			if err := writeYAMLNodeAsJSON(buffer, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		_ = buffer.WriteByte('}')
+		return nil
+	case yaml.SequenceNode:
+		_ = buffer.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				_ = buffer.WriteByte(',')
+			}
+			if err := writeYAMLNodeAsJSON(buffer, child); err != nil {
+				return err
+			}
+		}
+		_ = buffer.WriteByte(']')
+		return nil
+	case yaml.ScalarNode:
+		return writeYAMLScalarAsJSON(buffer, node)
+	default:
+		return fmt.Errorf("line %d: unknown YAML node kind: %v", node.Line, node.Kind)
+	}
+}
+
+func writeYAMLScalarAsJSON(buffer *bytes.Buffer, node *yaml.Node) error {
+	switch node.ShortTag() {
+	case "!!null":
+		_, _ = buffer.WriteString("null")
+		return nil
+	case "!!bool", "!!int", "!!float":
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		// JSON has no representation for these values, protojson reads them as strings.
+		if floatValue, ok := value.(float64); ok {
+			switch {
+			case math.IsNaN(floatValue):
+				value = "NaN"
+			case math.IsInf(floatValue, 1):
+				value = "Infinity"
+			case math.IsInf(floatValue, -1):
+				value = "-Infinity"
+			}
+		}
+		return writeJSONValue(buffer, value)
+	default:
+		return writeJSONValue(buffer, node.Value)
+	}
+}
+
+func writeJSONValue(buffer *bytes.Buffer, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, _ = buffer.Write(data)
+	return nil
+}
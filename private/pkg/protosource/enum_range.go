@@ -41,6 +41,21 @@ func newEnumRange(
 	}
 }
 
+func newFreeEnumRange(enum Enum, start int, end int) EnumRange {
+	return newEnumRange(
+		newLocationDescriptor(
+			newDescriptor(
+				enum.File(),
+				nil,
+			),
+			nil,
+		),
+		enum,
+		start,
+		end,
+	)
+}
+
 func (r *enumRange) Enum() Enum {
 	return r.enum
 }
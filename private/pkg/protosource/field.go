@@ -27,21 +27,24 @@ type field struct {
 	extendee string
 	// this has to be the pointer to the private struct or you have the bug where the
 	// interface is nil but value == nil is false
-	oneof          *oneof
-	proto3Optional bool
-	jsonName       string
-	jsType         FieldOptionsJSType
-	cType          FieldOptionsCType
-	packed         *bool
-	deprecated     bool
-	numberPath     []int32
-	typePath       []int32
-	typeNamePath   []int32
-	jsonNamePath   []int32
-	jsTypePath     []int32
-	cTypePath      []int32
-	packedPath     []int32
-	extendeePath   []int32
+	oneof            *oneof
+	proto3Optional   bool
+	jsonName         string
+	jsType           FieldOptionsJSType
+	cType            FieldOptionsCType
+	packed           *bool
+	deprecated       bool
+	defaultValue     string
+	numberPath       []int32
+	typePath         []int32
+	typeNamePath     []int32
+	jsonNamePath     []int32
+	jsTypePath       []int32
+	cTypePath        []int32
+	packedPath       []int32
+	extendeePath     []int32
+	defaultValuePath []int32
+	deprecatedPath   []int32
 }
 
 func newField(
@@ -60,6 +63,7 @@ func newField(
 	cType FieldOptionsCType,
 	packed *bool,
 	deprecated bool,
+	defaultValue string,
 	numberPath []int32,
 	typePath []int32,
 	typeNamePath []int32,
@@ -68,6 +72,8 @@ func newField(
 	cTypePath []int32,
 	packedPath []int32,
 	extendeePath []int32,
+	defaultValuePath []int32,
+	deprecatedPath []int32,
 ) *field {
 	return &field{
 		namedDescriptor:           namedDescriptor,
@@ -85,6 +91,7 @@ func newField(
 		cType:                     cType,
 		packed:                    packed,
 		deprecated:                deprecated,
+		defaultValue:              defaultValue,
 		numberPath:                numberPath,
 		typePath:                  typePath,
 		typeNamePath:              typeNamePath,
@@ -93,6 +100,8 @@ func newField(
 		cTypePath:                 cTypePath,
 		packedPath:                packedPath,
 		extendeePath:              extendeePath,
+		defaultValuePath:          defaultValuePath,
+		deprecatedPath:            deprecatedPath,
 	}
 }
 
@@ -153,6 +162,10 @@ func (f *field) Deprecated() bool {
 	return f.deprecated
 }
 
+func (f *field) DefaultValue() string {
+	return f.defaultValue
+}
+
 func (f *field) NumberLocation() Location {
 	return f.getLocation(f.numberPath)
 }
@@ -184,3 +197,11 @@ func (f *field) PackedLocation() Location {
 func (f *field) ExtendeeLocation() Location {
 	return f.getLocation(f.extendeePath)
 }
+
+func (f *field) DefaultValueLocation() Location {
+	return f.getLocation(f.defaultValuePath)
+}
+
+func (f *field) DeprecatedLocation() Location {
+	return f.getLocation(f.deprecatedPath)
+}
@@ -227,15 +227,17 @@ func newFile(inputFile InputFile) (*file, error) {
 	f := &file{
 		FileInfo:       inputFile,
 		fileDescriptor: inputFile.FileDescriptor(),
-		optionExtensionDescriptor: newOptionExtensionDescriptor(
-			inputFile.FileDescriptor().GetOptions(),
-		),
 	}
 	descriptor := newDescriptor(
 		f,
 		newLocationStore(f.fileDescriptor.GetSourceCodeInfo().GetLocation()),
 	)
 	f.descriptor = descriptor
+	f.optionExtensionDescriptor = newOptionExtensionDescriptor(
+		inputFile.FileDescriptor().GetOptions(),
+		descriptor,
+		getFileOptionsPath(),
+	)
 
 	if inputFile.IsSyntaxUnspecified() {
 		f.syntax = SyntaxUnspecified
@@ -373,6 +375,8 @@ func (f *file) populateEnum(
 		enumNamedDescriptor,
 		newOptionExtensionDescriptor(
 			enumDescriptorProto.GetOptions(),
+			f.descriptor,
+			getEnumOptionsPath(enumIndex, nestedMessageIndexes...),
 		),
 		enumDescriptorProto.GetOptions().GetAllowAlias(),
 		enumDescriptorProto.GetOptions().GetDeprecated(),
@@ -397,6 +401,8 @@ func (f *file) populateEnum(
 			enumValueNamedDescriptor,
 			newOptionExtensionDescriptor(
 				enumValueDescriptorProto.GetOptions(),
+				f.descriptor,
+				getEnumValueOptionsPath(enumIndex, enumValueIndex, nestedMessageIndexes...),
 			),
 			enum,
 			int(enumValueDescriptorProto.GetNumber()),
@@ -462,6 +468,8 @@ func (f *file) populateMessage(
 		messageNamedDescriptor,
 		newOptionExtensionDescriptor(
 			descriptorProto.GetOptions(),
+			f.descriptor,
+			getMessageOptionsPath(topLevelMessageIndex, nestedMessageIndexes...),
 		),
 		parent,
 		descriptorProto.GetOptions().GetMapEntry(),
@@ -489,6 +497,8 @@ func (f *file) populateMessage(
 			oneofNamedDescriptor,
 			newOptionExtensionDescriptor(
 				oneofDescriptorProto.GetOptions(),
+				f.descriptor,
+				getMessageOneofOptionsPath(oneofIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			),
 			message,
 		)
@@ -542,6 +552,8 @@ func (f *file) populateMessage(
 			fieldNamedDescriptor,
 			newOptionExtensionDescriptor(
 				fieldDescriptorProto.GetOptions(),
+				f.descriptor,
+				getMessageFieldOptionsPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			),
 			message,
 			int(fieldDescriptorProto.GetNumber()),
@@ -556,6 +568,7 @@ func (f *file) populateMessage(
 			cType,
 			packed,
 			fieldDescriptorProto.GetOptions().GetDeprecated(),
+			fieldDescriptorProto.GetDefaultValue(),
 			getMessageFieldNumberPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldTypeNamePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
@@ -564,6 +577,8 @@ func (f *file) populateMessage(
 			getMessageFieldCTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldPackedPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldExtendeePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			getMessageFieldDefaultValuePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			getMessageFieldDeprecatedPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 		)
 		message.addField(field)
 		if oneof != nil {
@@ -616,6 +631,8 @@ func (f *file) populateMessage(
 			fieldNamedDescriptor,
 			newOptionExtensionDescriptor(
 				fieldDescriptorProto.GetOptions(),
+				f.descriptor,
+				getMessageExtensionOptionsPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			),
 			message,
 			int(fieldDescriptorProto.GetNumber()),
@@ -630,6 +647,7 @@ func (f *file) populateMessage(
 			cType,
 			packed,
 			fieldDescriptorProto.GetOptions().GetDeprecated(),
+			fieldDescriptorProto.GetDefaultValue(),
 			getMessageExtensionNumberPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionTypeNamePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
@@ -638,6 +656,8 @@ func (f *file) populateMessage(
 			getMessageExtensionCTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionPackedPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionExtendeePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			getMessageExtensionDefaultValuePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			getMessageExtensionDeprecatedPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 		)
 		if err != nil {
 			return nil, err
@@ -738,6 +758,8 @@ func (f *file) populateService(
 		serviceNamedDescriptor,
 		newOptionExtensionDescriptor(
 			serviceDescriptorProto.GetOptions(),
+			f.descriptor,
+			getServiceOptionsPath(serviceIndex),
 		),
 		serviceDescriptorProto.GetOptions().GetDeprecated(),
 	)
@@ -762,6 +784,8 @@ func (f *file) populateService(
 			methodNamedDescriptor,
 			newOptionExtensionDescriptor(
 				methodDescriptorProto.GetOptions(),
+				f.descriptor,
+				getMethodOptionsPath(serviceIndex, methodIndex),
 			),
 			service,
 			strings.TrimPrefix(methodDescriptorProto.GetInputType(), "."),
@@ -822,6 +846,8 @@ func (f *file) populateExtension(
 		fieldNamedDescriptor,
 		newOptionExtensionDescriptor(
 			fieldDescriptorProto.GetOptions(),
+			f.descriptor,
+			getFileExtensionOptionsPath(fieldIndex),
 		),
 		nil,
 		int(fieldDescriptorProto.GetNumber()),
@@ -836,6 +862,7 @@ func (f *file) populateExtension(
 		cType,
 		packed,
 		fieldDescriptorProto.GetOptions().GetDeprecated(),
+		fieldDescriptorProto.GetDefaultValue(),
 		getFileExtensionNumberPath(fieldIndex),
 		getFileExtensionTypePath(fieldIndex),
 		getFileExtensionTypeNamePath(fieldIndex),
@@ -844,5 +871,7 @@ func (f *file) populateExtension(
 		getFileExtensionCTypePath(fieldIndex),
 		getFileExtensionPackedPath(fieldIndex),
 		getFileExtensionExtendeePath(fieldIndex),
+		getFileExtensionDefaultValuePath(fieldIndex),
+		getFileExtensionDeprecatedPath(fieldIndex),
 	), nil
 }
@@ -16,6 +16,10 @@ package protosource
 
 const (
 	messageRangeInclusiveMax = 536870911
+
+	// The field numbers 19000 to 19999 are reserved for the Protobuf implementation.
+	messageRangeImplementationReservedStart = 19000
+	messageRangeImplementationReservedEnd   = 19999
 )
 
 type messageRange struct {
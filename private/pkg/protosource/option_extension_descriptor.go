@@ -15,18 +15,28 @@
 package protosource
 
 import (
+	"bytes"
+
 	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 type optionExtensionDescriptor struct {
-	message proto.Message
+	message     proto.Message
+	descriptor  descriptor
+	optionsPath []int32
 }
 
-func newOptionExtensionDescriptor(message proto.Message) optionExtensionDescriptor {
+func newOptionExtensionDescriptor(
+	message proto.Message,
+	descriptor descriptor,
+	optionsPath []int32,
+) optionExtensionDescriptor {
 	return optionExtensionDescriptor{
-		message: message,
+		message:     message,
+		descriptor:  descriptor,
+		optionsPath: optionsPath,
 	}
 }
 
@@ -72,3 +82,52 @@ func (o *optionExtensionDescriptor) PresentExtensionNumbers() []int32 {
 
 	return fieldNumbers
 }
+
+func (o *optionExtensionDescriptor) PresentExtensionBytes(fieldNumber int32) []byte {
+	var buffer bytes.Buffer
+	msg := o.message.ProtoReflect()
+	if !msg.IsValid() {
+		return nil
+	}
+	if !msg.Descriptor().ExtensionRanges().Has(protowire.Number(fieldNumber)) {
+		return nil
+	}
+	for b := msg.GetUnknown(); len(b) > 0; {
+		fieldNo, _, n := protowire.ConsumeField(b)
+		if n < 0 {
+			break
+		}
+		if int32(fieldNo) == fieldNumber {
+			_, _ = buffer.Write(b[:n])
+		}
+		b = b[n:]
+	}
+	// See PresentExtensionNumbers for why known extensions are handled separately.
+	var err error
+	msg.Range(func(fieldDescriptor protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if !fieldDescriptor.IsExtension() || int32(fieldDescriptor.Number()) != fieldNumber {
+			return true
+		}
+		extensionMessage := msg.New()
+		extensionMessage.Set(fieldDescriptor, value)
+		var data []byte
+		data, err = proto.MarshalOptions{Deterministic: true}.Marshal(extensionMessage.Interface())
+		if err == nil {
+			_, _ = buffer.Write(data)
+		}
+		return false
+	})
+	if err != nil || buffer.Len() == 0 {
+		return nil
+	}
+	return buffer.Bytes()
+}
+
+func (o *optionExtensionDescriptor) PresentExtensionLocation(fieldNumber int32) Location {
+	if len(o.optionsPath) == 0 {
+		return nil
+	}
+	path := make([]int32, len(o.optionsPath), len(o.optionsPath)+1)
+	copy(path, o.optionsPath)
+	return o.descriptor.getLocation(append(path, fieldNumber))
+}
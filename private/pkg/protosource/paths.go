@@ -37,6 +37,10 @@ var (
 	syntaxPathKey               = getPathKey([]int32{12})
 )
 
+func getFileOptionsPath() []int32 {
+	return []int32{8}
+}
+
 func getDependencyPath(dependencyIndex int) []int32 {
 	return []int32{3, int32(dependencyIndex)}
 }
@@ -53,6 +57,10 @@ func getMessageNamePath(messageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessagePath(messageIndex, nestedMessageIndexes...), 1)
 }
 
+func getMessageOptionsPath(messageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessagePath(messageIndex, nestedMessageIndexes...), 7)
+}
+
 func getMessageMessageSetWireFormatPath(messageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessagePath(messageIndex, nestedMessageIndexes...), 7, 1)
 }
@@ -97,6 +105,18 @@ func getMessageFieldPackedPath(fieldIndex int, topLevelMessageIndex int, nestedM
 	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 8, 2)
 }
 
+func getMessageFieldDefaultValuePath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 7)
+}
+
+func getMessageFieldDeprecatedPath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 8, 3)
+}
+
+func getMessageFieldOptionsPath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 8)
+}
+
 func getMessageFieldExtendeePath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 2)
 }
@@ -137,6 +157,18 @@ func getMessageExtensionPackedPath(extensionIndex int, topLevelMessageIndex int,
 	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 8, 2)
 }
 
+func getMessageExtensionDefaultValuePath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 7)
+}
+
+func getMessageExtensionDeprecatedPath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 8, 3)
+}
+
+func getMessageExtensionOptionsPath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 8)
+}
+
 func getMessageExtensionExtendeePath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 2)
 }
@@ -145,6 +177,10 @@ func getMessageOneofPath(oneofIndex int, topLevelMessageIndex int, nestedMessage
 	return append(getMessagePath(topLevelMessageIndex, nestedMessageIndexes...), 8, int32(oneofIndex))
 }
 
+func getMessageOneofOptionsPath(oneofIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageOneofPath(oneofIndex, topLevelMessageIndex, nestedMessageIndexes...), 2)
+}
+
 func getMessageOneofNamePath(oneofIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessageOneofPath(oneofIndex, topLevelMessageIndex, nestedMessageIndexes...), 1)
 }
@@ -171,10 +207,15 @@ func getEnumPath(enumIndex int, nestedMessageIndexes ...int) []int32 {
 	}
 	return append(path, 4, int32(enumIndex))
 }
+
 func getEnumNamePath(enumIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 1)
 }
 
+func getEnumOptionsPath(enumIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 3)
+}
+
 func getEnumAllowAliasPath(enumIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 3, 2)
 }
@@ -191,6 +232,10 @@ func getEnumValueNumberPath(enumIndex int, enumValueIndex int, nestedMessageInde
 	return append(getEnumValuePath(enumIndex, enumValueIndex, nestedMessageIndexes...), 2)
 }
 
+func getEnumValueOptionsPath(enumIndex int, enumValueIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getEnumValuePath(enumIndex, enumValueIndex, nestedMessageIndexes...), 3)
+}
+
 func getEnumReservedRangePath(enumIndex int, reservedRangeIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 4, int32(reservedRangeIndex))
 }
@@ -207,6 +252,10 @@ func getServiceNamePath(serviceIndex int) []int32 {
 	return append(getServicePath(serviceIndex), 1)
 }
 
+func getServiceOptionsPath(serviceIndex int) []int32 {
+	return append(getServicePath(serviceIndex), 3)
+}
+
 func getMethodPath(serviceIndex int, methodIndex int) []int32 {
 	return []int32{6, int32(serviceIndex), 2, int32(methodIndex)}
 }
@@ -223,6 +272,10 @@ func getMethodOutputTypePath(serviceIndex int, methodIndex int) []int32 {
 	return append(getMethodPath(serviceIndex, methodIndex), 3)
 }
 
+func getMethodOptionsPath(serviceIndex int, methodIndex int) []int32 {
+	return append(getMethodPath(serviceIndex, methodIndex), 4)
+}
+
 func getMethodIdempotencyLevelPath(serviceIndex int, methodIndex int) []int32 {
 	return append(getMethodPath(serviceIndex, methodIndex), 4, 34)
 }
@@ -263,6 +316,18 @@ func getFileExtensionPackedPath(fieldIndex int) []int32 {
 	return append(getFileExtensionPath(fieldIndex), 8, 2)
 }
 
+func getFileExtensionDefaultValuePath(fieldIndex int) []int32 {
+	return append(getFileExtensionPath(fieldIndex), 7)
+}
+
+func getFileExtensionDeprecatedPath(fieldIndex int) []int32 {
+	return append(getFileExtensionPath(fieldIndex), 8, 3)
+}
+
+func getFileExtensionOptionsPath(fieldIndex int) []int32 {
+	return append(getFileExtensionPath(fieldIndex), 8)
+}
+
 func getFileExtensionExtendeePath(fieldIndex int) []int32 {
 	return append(getFileExtensionPath(fieldIndex), 2)
 }
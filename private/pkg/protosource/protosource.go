@@ -129,6 +129,18 @@ type OptionExtensionDescriptor interface {
 	// PresentExtensionNumbers returns field numbers for all options that
 	// have a set value on this descriptor.
 	PresentExtensionNumbers() []int32
+	// PresentExtensionBytes returns the wire format of the option with the given
+	// field number, or nil if the option does not have a set value on this descriptor.
+	//
+	// This allows options to be compared without knowing their types, as the
+	// extensions that define custom options are generally not known to buf.
+	PresentExtensionBytes(fieldNumber int32) []byte
+	// PresentExtensionLocation returns the location of the option with the given
+	// field number.
+	//
+	// Can return nil, for example if the option is set with an aggregate value
+	// that only has locations for its sub-fields.
+	PresentExtensionLocation(fieldNumber int32) Location
 }
 
 // Location defines source code info location information.
@@ -371,6 +383,12 @@ type Field interface {
 	// See the comments on descriptor.proto
 	Packed() *bool
 	Deprecated() bool
+	// DefaultValue is the default value of the field as it appears in the
+	// FieldDescriptorProto, i.e. the name of the value for enums, and the
+	// C-escaped value for bytes.
+	//
+	// Empty string if there is no default value.
+	DefaultValue() string
 	// Empty string unless the field is part of an extension
 	Extendee() string
 
@@ -382,6 +400,8 @@ type Field interface {
 	CTypeLocation() Location
 	PackedLocation() Location
 	ExtendeeLocation() Location
+	DefaultValueLocation() Location
+	DeprecatedLocation() Location
 }
 
 // Oneof is a oneof descriptor.
@@ -1078,6 +1098,110 @@ func FreeMessageRanges(message Message) []MessageRange {
 	return unused
 }
 
+// SuggestedMessageFieldNumbers returns up to count free field numbers for the given message
+// that are safe to use for new fields.
+//
+// The numbers are greater than all field numbers and reserved numbers of the message, so
+// that numbers of deleted fields that were not reserved are not reused. Extension ranges
+// and the numbers reserved for the Protobuf implementation are skipped.
+func SuggestedMessageFieldNumbers(message Message, count int) []int {
+	highest := 0
+	for _, field := range message.Fields() {
+		if field.Number() > highest {
+			highest = field.Number()
+		}
+	}
+	for _, reservedMessageRange := range message.ReservedMessageRanges() {
+		if reservedMessageRange.End() > highest {
+			highest = reservedMessageRange.End()
+		}
+	}
+	var suggested []int
+	for _, freeRange := range FreeMessageRanges(message) {
+		start := freeRange.Start()
+		if start <= highest {
+			start = highest + 1
+		}
+		for number := start; number <= freeRange.End() && len(suggested) < count; number++ {
+			if number >= messageRangeImplementationReservedStart && number <= messageRangeImplementationReservedEnd {
+				number = messageRangeImplementationReservedEnd
+				continue
+			}
+			suggested = append(suggested, number)
+		}
+	}
+	return suggested
+}
+
+// FreeEnumRanges returns the free enum ranges for the given enum.
+//
+// Only non-negative numbers are considered.
+func FreeEnumRanges(enum Enum) []EnumRange {
+	used := enum.ReservedEnumRanges()
+	for _, value := range enum.Values() {
+		used = append(
+			used,
+			newFreeEnumRange(enum, value.Number(), value.Number()),
+		)
+	}
+	sort.Slice(used, func(i, j int) bool {
+		return used[i].Start() < used[j].Start()
+	})
+	// now compute the inverse (unused ranges)
+	unused := make([]EnumRange, 0, len(used)+1)
+	last := -1
+	for _, r := range used {
+		if r.Start() <= last+1 {
+			if r.End() > last {
+				last = r.End()
+			}
+			continue
+		}
+		unused = append(
+			unused,
+			newFreeEnumRange(enum, last+1, r.Start()-1),
+		)
+		last = r.End()
+	}
+	if last < enumRangeInclusiveMax {
+		unused = append(
+			unused,
+			newFreeEnumRange(enum, last+1, enumRangeInclusiveMax),
+		)
+	}
+	return unused
+}
+
+// SuggestedEnumNumbers returns up to count free numbers for the given enum
+// that are safe to use for new values.
+//
+// The numbers are greater than all value numbers and reserved numbers of the enum, so
+// that numbers of deleted values that were not reserved are not reused.
+func SuggestedEnumNumbers(enum Enum, count int) []int {
+	highest := -1
+	for _, value := range enum.Values() {
+		if value.Number() > highest {
+			highest = value.Number()
+		}
+	}
+	for _, reservedEnumRange := range enum.ReservedEnumRanges() {
+		if reservedEnumRange.End() > highest {
+			highest = reservedEnumRange.End()
+		}
+	}
+	var suggested []int
+	for _, freeRange := range FreeEnumRanges(enum) {
+		start := freeRange.Start()
+		if start <= highest {
+			start = highest + 1
+		}
+		for number := start; number <= freeRange.End() && len(suggested) < count; number++ {
+			suggested = append(suggested, number)
+		}
+	}
+	return suggested
+}
+
 func freeMessageRangeStringSuffix(freeRange MessageRange) string {
 	start := freeRange.Start()
 	end := freeRange.End()
@@ -154,6 +154,8 @@ type ObjectInfo interface {
 	//   Path: baz/bat.proto
 	//   ExternalPath: s3://foo/baz/bat.proto
 	ExternalPath() string
+	// Size is the size of the object in bytes.
+	Size() int64
 }
 
 // ReadObject is an object read from a bucket.
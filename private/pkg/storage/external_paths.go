@@ -71,6 +71,7 @@ func disableObjectInfoExternalPath(objectInfo ObjectInfo) ObjectInfo {
 	return storageutil.NewObjectInfo(
 		objectInfo.Path(),
 		objectInfo.Path(),
+		objectInfo.Size(),
 	)
 }
 
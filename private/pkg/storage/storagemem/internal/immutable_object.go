@@ -44,7 +44,7 @@ func NewImmutableObject(
 		externalPath = normalpath.Unnormalize(path)
 	}
 	return &ImmutableObject{
-		ObjectInfo: storageutil.NewObjectInfo(path, externalPath),
+		ObjectInfo: storageutil.NewObjectInfo(path, externalPath, int64(len(data))),
 		data:       data,
 	}
 }
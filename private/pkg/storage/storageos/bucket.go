@@ -60,7 +60,8 @@ func (b *bucket) Get(ctx context.Context, path string) (storage.ReadObjectCloser
 	if err != nil {
 		return nil, err
 	}
-	if err := b.validateExternalPath(path, externalPath); err != nil {
+	fileInfo, err := b.validateExternalPath(path, externalPath)
+	if err != nil {
 		return nil, err
 	}
 	resolvedPath := externalPath
@@ -78,6 +79,7 @@ func (b *bucket) Get(ctx context.Context, path string) (storage.ReadObjectCloser
 	return newReadObjectCloser(
 		path,
 		externalPath,
+		fileInfo.Size(),
 		file,
 	), nil
 }
@@ -87,13 +89,15 @@ func (b *bucket) Stat(ctx context.Context, path string) (storage.ObjectInfo, err
 	if err != nil {
 		return nil, err
 	}
-	if err := b.validateExternalPath(path, externalPath); err != nil {
+	fileInfo, err := b.validateExternalPath(path, externalPath)
+	if err != nil {
 		return nil, err
 	}
 	// we could use fileInfo.Name() however we might as well use the externalPath
 	return storageutil.NewObjectInfo(
 		path,
 		externalPath,
+		fileInfo.Size(),
 	), nil
 }
 
@@ -143,6 +147,7 @@ func (b *bucket) Walk(
 					storageutil.NewObjectInfo(
 						path,
 						externalPath,
+						fileInfo.Size(),
 					),
 				); err != nil {
 					return err
@@ -241,7 +246,7 @@ func (b *bucket) getExternalPath(path string) (string, error) {
 	return normalpath.Unnormalize(realClean), nil
 }
 
-func (b *bucket) validateExternalPath(path string, externalPath string) error {
+func (b *bucket) validateExternalPath(path string, externalPath string) (os.FileInfo, error) {
 	// this is potentially introducing two calls to a file
 	// instead of one, ie we do both Stat and Open as opposed to just Open
 	// we do this to make sure we are only reading regular files
@@ -254,7 +259,7 @@ func (b *bucket) validateExternalPath(path string, externalPath string) error {
 	}
 	if err != nil {
 		if os.IsNotExist(err) {
-			return storage.NewErrNotExist(path)
+			return nil, storage.NewErrNotExist(path)
 		}
 		// The path might have a regular file in one of its
 		// elements (e.g. 'foo/bar/baz.proto' where 'bar' is a
@@ -273,7 +278,7 @@ func (b *bucket) validateExternalPath(path string, externalPath string) error {
 		if len(elements) == 1 {
 			// The path is a single element, so there aren't
 			// any other files to check.
-			return err
+			return nil, err
 		}
 		for i := len(elements) - 1; i >= 0; i-- {
 			parentFileInfo, err := os.Stat(filepath.Join(elements[:i]...))
@@ -284,17 +289,17 @@ func (b *bucket) validateExternalPath(path string, externalPath string) error {
 				// This error primarily serves as a sentinel error,
 				// but we preserve the original path argument so that
 				// the error still makes sense to the user.
-				return storage.NewErrNotExist(path)
+				return nil, storage.NewErrNotExist(path)
 			}
 		}
-		return err
+		return nil, err
 	}
 	if !fileInfo.Mode().IsRegular() {
 		// making this a user error as any access means this was generally requested
 		// by the user, since we only call the function for Walk on regular files
-		return storage.NewErrNotExist(path)
+		return nil, storage.NewErrNotExist(path)
 	}
-	return nil
+	return fileInfo, nil
 }
 
 func (b *bucket) getExternalPrefix(prefix string) (string, error) {
@@ -321,12 +326,14 @@ type readObjectCloser struct {
 func newReadObjectCloser(
 	path string,
 	externalPath string,
+	size int64,
 	file *os.File,
 ) *readObjectCloser {
 	return &readObjectCloser{
 		ObjectInfo: storageutil.NewObjectInfo(
 			path,
 			externalPath,
+			size,
 		),
 		file: file,
 	}
@@ -78,6 +78,7 @@ func AssertObjectInfo(
 		storageutil.NewObjectInfo(
 			path,
 			externalPath,
+			objectInfo.Size(),
 		),
 		objectInfo,
 	)
@@ -101,11 +102,13 @@ func AssertPathToContent(
 	expectedPathToContent map[string]string,
 ) {
 	var paths []string
+	pathToSize := make(map[string]int64)
 	require.NoError(t, readBucket.Walk(
 		context.Background(),
 		walkPrefix,
 		func(objectInfo storage.ObjectInfo) error {
 			paths = append(paths, objectInfo.Path())
+			pathToSize[objectInfo.Path()] = objectInfo.Size()
 			return nil
 		},
 	))
@@ -114,10 +117,13 @@ func AssertPathToContent(
 	for _, path := range paths {
 		expectedContent, ok := expectedPathToContent[path]
 		assert.True(t, ok, path)
-		_, err := readBucket.Stat(context.Background(), path)
+		assert.Equal(t, int64(len(expectedContent)), pathToSize[path], path)
+		objectInfo, err := readBucket.Stat(context.Background(), path)
 		require.NoError(t, err, path)
+		assert.Equal(t, int64(len(expectedContent)), objectInfo.Size(), path)
 		readObjectCloser, err := readBucket.Get(context.Background(), path)
 		require.NoError(t, err, path)
+		assert.Equal(t, int64(len(expectedContent)), readObjectCloser.Size(), path)
 		data, err := io.ReadAll(readObjectCloser)
 		assert.NoError(t, err, path)
 		assert.NoError(t, readObjectCloser.Close())
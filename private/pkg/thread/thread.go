@@ -68,7 +68,11 @@ func Parallelize(ctx context.Context, jobs []func(context.Context) error, option
 	if multiplier < 1 {
 		multiplier = 1
 	}
-	semaphoreC := make(chan struct{}, Parallelism()*multiplier)
+	parallelism := Parallelism() * multiplier
+	if parallelizeOptions.maxParallelism > 0 {
+		parallelism = parallelizeOptions.maxParallelism
+	}
+	semaphoreC := make(chan struct{}, parallelism)
 	var retErr error
 	var wg sync.WaitGroup
 	var lock sync.Mutex
@@ -127,6 +131,17 @@ func ParallelizeWithMultiplier(multiplier int) ParallelizeOption {
 	}
 }
 
+// ParallelizeWithMaxParallelism returns a new ParallelizeOption that will run
+// at most the given number of jobs at once, regardless of Parallelism().
+//
+// This takes precedence over ParallelizeWithMultiplier.
+// A max parallelism of <1 has no meaning.
+func ParallelizeWithMaxParallelism(maxParallelism int) ParallelizeOption {
+	return func(parallelizeOptions *parallelizeOptions) {
+		parallelizeOptions.maxParallelism = maxParallelism
+	}
+}
+
 // ParallelizeWithCancel returns a new ParallelizeOption that will call the
 // given context.CancelFunc if any job fails.
 func ParallelizeWithCancel(cancel context.CancelFunc) ParallelizeOption {
@@ -136,8 +151,9 @@ func ParallelizeWithCancel(cancel context.CancelFunc) ParallelizeOption {
 }
 
 type parallelizeOptions struct {
-	multiplier int
-	cancel     context.CancelFunc
+	multiplier     int
+	maxParallelism int
+	cancel         context.CancelFunc
 }
 
 func newParallelizeOptions() *parallelizeOptions {
@@ -17,6 +17,7 @@ package thread
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/atomic"
@@ -40,3 +41,45 @@ func TestParallelizeWithImmediateCancellation(t *testing.T) {
 	assert.Nil(t, err, "parallelize error")
 	assert.Equal(t, int64(0), executed.Load(), "jobs executed")
 }
+
+func TestParallelizeWithMaxParallelism(t *testing.T) {
+	t.Parallel()
+	const maxParallelism = 3
+	var running atomic.Int64
+	var peak atomic.Int64
+	// full is signaled once maxParallelism jobs are running at the same time,
+	// and release unblocks all the jobs.
+	full := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var jobs []func(context.Context) error
+	for i := 0; i < 20; i++ {
+		jobs = append(jobs, func(_ context.Context) error {
+			current := running.Inc()
+			for {
+				currentPeak := peak.Load()
+				if current <= currentPeak || peak.CAS(currentPeak, current) {
+					break
+				}
+			}
+			if current == maxParallelism {
+				select {
+				case full <- struct{}{}:
+				default:
+				}
+			}
+			<-release
+			running.Dec()
+			return nil
+		})
+	}
+	errC := make(chan error, 1)
+	go func() {
+		errC <- Parallelize(context.Background(), jobs, ParallelizeWithMaxParallelism(maxParallelism), ParallelizeWithMultiplier(4))
+	}()
+	<-full
+	// Give any job that ignores the limit time to start before releasing them.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	assert.Nil(t, <-errC, "parallelize error")
+	assert.Equal(t, int64(maxParallelism), peak.Load(), "max running jobs")
+}